@@ -78,7 +78,8 @@ type aggregatorUnit struct {
 }
 
 // outputUnit is a group of Outputs and their source channel.  Metrics on the
-// channel are written to all outputs.
+// channel are written to all outputs, except for outputs in a failover group
+// where each metric is written to one member of the group.
 //
 //                            ┌────────┐
 //                       ┌──▶ │ Output │
@@ -92,6 +93,15 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+	targets []metricTarget
+	// deadLetter receives the metrics rejected permanently by the outputs.
+	deadLetter *models.RunningOutput
+}
+
+// metricTarget receives the metrics of the outputUnit, either an output or a
+// failover group of outputs.
+type metricTarget interface {
+	AddMetric(telegraf.Metric)
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -636,14 +646,27 @@ func (a *Agent) push(
 
 // startOutputs calls Connect on all outputs and returns the source channel.
 // If an error occurs calling Connect all stared plugins have Close called.
+// Members of a failover group which fail to connect are removed from the
+// group instead, as long as one member of the group connects.
 func (a *Agent) startOutputs(
 	ctx context.Context,
 	outputs []*models.RunningOutput,
 ) (chan<- telegraf.Metric, *outputUnit, error) {
+	groups := make(map[string]*models.RunningOutputGroup, len(a.Config.OutputGroups))
+	for _, group := range a.Config.OutputGroups {
+		groups[group.Name] = group
+	}
+
 	src := make(chan telegraf.Metric, 100)
 	unit := &outputUnit{src: src}
 	for _, output := range outputs {
 		err := a.connectOutput(ctx, output)
+		if err != nil && groups[output.Config.FailoverGroup] != nil {
+			log.Printf("E! [agent] Removing output %s from failover group %q: %v",
+				output.LogName(), output.Config.FailoverGroup, err)
+			groups[output.Config.FailoverGroup].RemoveOutput(output)
+			continue
+		}
 		if err != nil {
 			for _, output := range unit.outputs {
 				output.Close()
@@ -652,30 +675,41 @@ func (a *Agent) startOutputs(
 		}
 
 		unit.outputs = append(unit.outputs, output)
+		if output.Config.DeadLetter {
+			unit.deadLetter = output
+		} else if output.Config.FailoverGroup == "" {
+			unit.targets = append(unit.targets, output)
+		}
+	}
+
+	for _, group := range a.Config.OutputGroups {
+		if len(group.Outputs()) == 0 {
+			for _, output := range unit.outputs {
+				output.Close()
+			}
+			return nil, nil, fmt.Errorf("no output of failover group %q connected", group.Name)
+		}
+		unit.targets = append(unit.targets, group)
+	}
+
+	if unit.deadLetter != nil {
+		for _, output := range unit.outputs {
+			if output != unit.deadLetter {
+				output.SetDeadLetter(unit.deadLetter)
+			}
+		}
 	}
 
 	return src, unit, nil
 }
 
-// connectOutputs connects to all outputs.
+// connectOutput connects to the output, retrying according to the retry
+// policy of the output.
 func (a *Agent) connectOutput(ctx context.Context, output *models.RunningOutput) error {
-	log.Printf("D! [agent] Attempting connection to [%s]", output.LogName())
-	err := output.Output.Connect()
+	err := output.Connect(ctx)
 	if err != nil {
-		log.Printf("E! [agent] Failed to connect to [%s], retrying in 15s, "+
-			"error was '%s'", output.LogName(), err)
-
-		err := internal.SleepContext(ctx, 15*time.Second)
-		if err != nil {
-			return err
-		}
-
-		err = output.Output.Connect()
-		if err != nil {
-			return fmt.Errorf("Error connecting to output %q: %w", output.LogName(), err)
-		}
+		return fmt.Errorf("Error connecting to output %q: %w", output.LogName(), err)
 	}
-	log.Printf("D! [agent] Successfully connected to %s", output.LogName())
 	return nil
 }
 
@@ -693,6 +727,11 @@ func (a *Agent) runOutputs(
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// The dead-letter output is flushed on shutdown after the other outputs,
+	// as their last writes may reject metrics.
+	var deadLetterWg sync.WaitGroup
+	deadLetterCtx, deadLetterCancel := context.WithCancel(context.Background())
+
 	for _, output := range unit.outputs {
 		interval := interval
 		// Overwrite agent flush_interval if this plugin has its own.
@@ -706,23 +745,32 @@ func (a *Agent) runOutputs(
 			jitter = *output.Config.FlushJitter
 		}
 
-		wg.Add(1)
+		outputWg, outputCtx := &wg, ctx
+		if output == unit.deadLetter {
+			outputWg, outputCtx = &deadLetterWg, deadLetterCtx
+		}
+
+		outputWg.Add(1)
 		go func(output *models.RunningOutput) {
-			defer wg.Done()
+			defer outputWg.Done()
 
 			ticker := NewRollingTicker(interval, jitter)
 			defer ticker.Stop()
 
-			a.flushLoop(ctx, output, ticker)
+			a.flushLoop(outputCtx, output, ticker)
 		}(output)
 	}
 
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(a.Config.Outputs)-1 {
-				output.AddMetric(metric)
+		if len(unit.targets) == 0 {
+			metric.Drop()
+			continue
+		}
+		for i, target := range unit.targets {
+			if i == len(unit.targets)-1 {
+				target.AddMetric(metric)
 			} else {
-				output.AddMetric(metric.Copy())
+				target.AddMetric(metric.Copy())
 			}
 		}
 	}
@@ -730,6 +778,8 @@ func (a *Agent) runOutputs(
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
 	cancel()
 	wg.Wait()
+	deadLetterCancel()
+	deadLetterWg.Wait()
 
 	return nil
 }
@@ -756,14 +806,14 @@ func (a *Agent) flushLoop(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteNow))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteNow))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
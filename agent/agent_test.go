@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	"github.com/stretchr/testify/assert"
@@ -108,6 +112,42 @@ func TestAgent_LoadOutput(t *testing.T) {
 	assert.Equal(t, 3, len(a.Config.Outputs))
 }
 
+type connectOutput struct {
+	err error
+}
+
+func (o *connectOutput) Connect() error                        { return o.err }
+func (o *connectOutput) Close() error                          { return nil }
+func (o *connectOutput) Description() string                   { return "" }
+func (o *connectOutput) SampleConfig() string                  { return "" }
+func (o *connectOutput) Write(metrics []telegraf.Metric) error { return nil }
+
+func TestAgent_StartOutputsFailoverGroup(t *testing.T) {
+	newGroup := func(errs ...error) (*Agent, []*models.RunningOutput) {
+		c := config.NewConfig()
+		group := models.NewRunningOutputGroup("startup")
+		for _, err := range errs {
+			output := models.NewRunningOutput("connect", &connectOutput{err: err},
+				&models.OutputConfig{Name: "connect", FailoverGroup: "startup"}, 10, 100)
+			require.NoError(t, group.AddOutput(output))
+			c.Outputs = append(c.Outputs, output)
+		}
+		c.OutputGroups = append(c.OutputGroups, group)
+		return &Agent{Config: c}, c.Outputs
+	}
+
+	// The group starts with the members which connected.
+	a, outputs := newGroup(errors.New("unreachable"), nil)
+	_, unit, err := a.startOutputs(context.Background(), outputs)
+	require.NoError(t, err)
+	require.Equal(t, outputs[1:], unit.outputs)
+	require.Equal(t, outputs[1:], a.Config.OutputGroups[0].Outputs())
+
+	a, outputs = newGroup(errors.New("unreachable"), errors.New("unreachable"))
+	_, _, err = a.startOutputs(context.Background(), outputs)
+	require.EqualError(t, err, `no output of failover group "startup" connected`)
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
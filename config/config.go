@@ -64,6 +64,8 @@ type Config struct {
 	Inputs      []*models.RunningInput
 	Outputs     []*models.RunningOutput
 	Aggregators []*models.RunningAggregator
+	// OutputGroups are the failover groups of the outputs in config order
+	OutputGroups []*models.RunningOutputGroup
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors    models.RunningProcessors
 	AggProcessors models.RunningProcessors
@@ -1008,10 +1010,39 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	ro := models.NewRunningOutput(name, output, outputConfig,
 		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+	if outputConfig.DeadLetter {
+		if outputConfig.FailoverGroup != "" {
+			return fmt.Errorf("dead-letter output %s cannot be part of a failover group", ro.LogName())
+		}
+		for _, o := range c.Outputs {
+			if o.Config.DeadLetter {
+				return fmt.Errorf("dead-letter output %s already defined, cannot add %s",
+					o.LogName(), ro.LogName())
+			}
+		}
+	}
+	if outputConfig.FailoverGroup != "" {
+		if err := c.outputGroup(outputConfig.FailoverGroup).AddOutput(ro); err != nil {
+			return err
+		}
+	}
 	c.Outputs = append(c.Outputs, ro)
 	return nil
 }
 
+// outputGroup returns the failover group with the name, creating the group if
+// it does not exist yet.
+func (c *Config) outputGroup(name string) *models.RunningOutputGroup {
+	for _, group := range c.OutputGroups {
+		if group.Name == name {
+			return group
+		}
+	}
+	group := models.NewRunningOutputGroup(name)
+	c.OutputGroups = append(c.OutputGroups, group)
+	return group
+}
+
 func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
@@ -1220,7 +1251,7 @@ func buildProcessor(name string, tbl *ast.Table) (*models.ProcessorConfig, error
 }
 
 // buildFilter builds a Filter
-// (tagpass/tagdrop/namepass/namedrop/fieldpass/fielddrop) to
+// (tagpass/tagdrop/namepass/namedrop/fieldpass/fielddrop/metricpass) to
 // be inserted into the models.OutputConfig/models.InputConfig
 // to be used for glob filtering on tags and measurements
 func buildFilter(tbl *ast.Table) (models.Filter, error) {
@@ -1339,6 +1370,15 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 			}
 		}
 	}
+
+	if node, ok := tbl.Fields["metricpass"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				f.MetricPass = str.Value
+			}
+		}
+	}
+
 	if err := f.Compile(); err != nil {
 		return f, err
 	}
@@ -1353,6 +1393,7 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 	delete(tbl.Fields, "tagpass")
 	delete(tbl.Fields, "tagexclude")
 	delete(tbl.Fields, "taginclude")
+	delete(tbl.Fields, "metricpass")
 	return f, nil
 }
 
@@ -2223,6 +2264,148 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["dead_letter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				oc.DeadLetter, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["failover_group"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.FailoverGroup = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["failover_buffer_threshold"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.FailoverBufferThreshold = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["failback_interval"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.FailbackInterval = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_initial_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.InitialBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.MaxBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_multiplier"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Float:
+				f, err := v.Float()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Multiplier = f
+			case *ast.Integer:
+				i, err := v.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Multiplier = float64(i)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Float:
+				f, err := v.Float()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Jitter = f
+			case *ast.Integer:
+				i, err := v.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Jitter = float64(i)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_connect_attempts"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.ConnectAttempts = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_threshold"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerThreshold = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_timeout"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerTimeout = dur
+			}
+		}
+	}
+
 	delete(tbl.Fields, "flush_interval")
 	delete(tbl.Fields, "flush_jitter")
 	delete(tbl.Fields, "metric_buffer_limit")
@@ -2231,6 +2414,17 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_prefix")
+	delete(tbl.Fields, "dead_letter")
+	delete(tbl.Fields, "failover_group")
+	delete(tbl.Fields, "failover_buffer_threshold")
+	delete(tbl.Fields, "failback_interval")
+	delete(tbl.Fields, "retry_initial_backoff")
+	delete(tbl.Fields, "retry_max_backoff")
+	delete(tbl.Fields, "retry_multiplier")
+	delete(tbl.Fields, "retry_jitter")
+	delete(tbl.Fields, "retry_connect_attempts")
+	delete(tbl.Fields, "circuit_breaker_threshold")
+	delete(tbl.Fields, "circuit_breaker_timeout")
 
 	return oc, nil
 }
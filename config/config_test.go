@@ -236,6 +236,57 @@ func TestConfig_InlineTables(t *testing.T) {
 	assert.Equal(t, []string{"org_id"}, c.Outputs[0].Config.Filter.TagInclude)
 }
 
+func TestConfig_FailoverGroup(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/failover_group.toml")
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 3)
+	require.Len(t, c.OutputGroups, 1)
+
+	group := c.OutputGroups[0]
+	require.Equal(t, "http", group.Name)
+	require.Equal(t, []*models.RunningOutput{c.Outputs[0], c.Outputs[1]}, group.Outputs())
+
+	require.Equal(t, "http", c.Outputs[0].Config.FailoverGroup)
+	require.Equal(t, 5000, c.Outputs[0].Config.FailoverBufferThreshold)
+	require.Equal(t, 30*time.Second, c.Outputs[0].Config.FailbackInterval)
+	require.Equal(t, "", c.Outputs[2].Config.FailoverGroup)
+
+	outputHTTP, ok := c.Outputs[1].Output.(*httpOut.HTTP)
+	require.True(t, ok)
+	require.Equal(t, "http://secondary.example.org:8080/telegraf", outputHTTP.URL)
+}
+
+func TestConfig_RetryPolicy(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/retry.toml")
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 1)
+
+	require.Equal(t, models.RetryConfig{
+		InitialBackoff:          time.Second,
+		MaxBackoff:              2 * time.Minute,
+		Multiplier:              1.5,
+		Jitter:                  0.2,
+		ConnectAttempts:         5,
+		CircuitBreakerThreshold: 10,
+		CircuitBreakerTimeout:   5 * time.Minute,
+	}, c.Outputs[0].Config.Retry)
+}
+
+func TestConfig_DeadLetter(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/dead_letter.toml")
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 2)
+	require.False(t, c.Outputs[0].Config.DeadLetter)
+	require.True(t, c.Outputs[1].Config.DeadLetter)
+
+	c = NewConfig()
+	err = c.LoadConfig("./testdata/dead_letter_duplicate.toml")
+	require.Error(t, err)
+}
+
 func TestConfig_SliceComment(t *testing.T) {
 	t.Skipf("Skipping until #3642 is resolved")
 
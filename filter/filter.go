@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/gobwas/glob"
@@ -19,12 +20,90 @@ type Filter interface {
 //   f.Match("network") // true
 //   f.Match("memory")  // false
 //
+// Filters prefixed with "re:" are regular expressions, and filters prefixed
+// with "!" are negations.  A string matches if it matches any of the
+// non-negated filters, or there are none, and none of the negated filters:
+//
+//   f, _ := Compile([]string{"re:^cpu[0-9]+$", "!cpu0"})
+//   f.Match("cpu1") // true
+//   f.Match("cpu0") // false
+//
 func Compile(filters []string) (Filter, error) {
 	// return if there is nothing to compile
 	if len(filters) == 0 {
 		return nil, nil
 	}
 
+	var include, exclude []string
+	for _, filter := range filters {
+		if strings.HasPrefix(filter, negatePrefix) {
+			exclude = append(exclude, strings.TrimPrefix(filter, negatePrefix))
+		} else {
+			include = append(include, filter)
+		}
+	}
+
+	if len(exclude) == 0 {
+		return compileMatchers(include)
+	}
+
+	in, err := compileMatchers(include)
+	if err != nil {
+		return nil, err
+	}
+	ex, err := compileMatchers(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &IncludeExcludeFilter{include: in, exclude: ex}, nil
+}
+
+const (
+	regexPrefix  = "re:"
+	negatePrefix = "!"
+)
+
+// compileMatchers compiles a list of glob and "re:" prefixed filters into a
+// Filter matching any of them.
+func compileMatchers(filters []string) (Filter, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	var globs []string
+	var matchers anyFilter
+	for _, filter := range filters {
+		if !strings.HasPrefix(filter, regexPrefix) {
+			globs = append(globs, filter)
+			continue
+		}
+		re, err := regexp.Compile(strings.TrimPrefix(filter, regexPrefix))
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, &regexFilter{re: re})
+	}
+
+	if len(globs) > 0 {
+		g, err := compileGlobs(globs)
+		if err != nil {
+			return nil, err
+		}
+		if len(matchers) == 0 {
+			return g, nil
+		}
+		matchers = append(matchers, g)
+	}
+
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return matchers, nil
+}
+
+// compileGlobs compiles a list of glob patterns, using a non-globbing
+// filter if none of them contain glob meta characters.
+func compileGlobs(filters []string) (Filter, error) {
 	// check if we can compile a non-glob filter
 	noGlob := true
 	for _, filter := range filters {
@@ -67,6 +146,26 @@ func (f *filtersingle) Match(s string) bool {
 	return f.s == s
 }
 
+type regexFilter struct {
+	re *regexp.Regexp
+}
+
+func (f *regexFilter) Match(s string) bool {
+	return f.re.MatchString(s)
+}
+
+// anyFilter matches if any of its filters match.
+type anyFilter []Filter
+
+func (f anyFilter) Match(s string) bool {
+	for _, filter := range f {
+		if filter.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
 func compileFilterNoGlob(filters []string) Filter {
 	if len(filters) == 1 {
 		return &filtersingle{s: filters[0]}
@@ -37,6 +37,49 @@ func TestCompile(t *testing.T) {
 	assert.True(t, f.Match("network"))
 }
 
+func TestCompileRegex(t *testing.T) {
+	f, err := Compile([]string{"re:^cpu[0-9]+$"})
+	assert.NoError(t, err)
+	assert.True(t, f.Match("cpu0"))
+	assert.True(t, f.Match("cpu12"))
+	assert.False(t, f.Match("cpu"))
+	assert.False(t, f.Match("cpu-total"))
+
+	f, err = Compile([]string{"re:^cpu[0-9]+$", "mem", "net*"})
+	assert.NoError(t, err)
+	assert.True(t, f.Match("cpu0"))
+	assert.True(t, f.Match("mem"))
+	assert.True(t, f.Match("network"))
+	assert.False(t, f.Match("cpu"))
+	assert.False(t, f.Match("memory"))
+
+	_, err = Compile([]string{"re:("})
+	assert.Error(t, err)
+}
+
+func TestCompileNegation(t *testing.T) {
+	f, err := Compile([]string{"!/var/lib/docker/*"})
+	assert.NoError(t, err)
+	assert.True(t, f.Match("/"))
+	assert.True(t, f.Match("/var/lib"))
+	assert.False(t, f.Match("/var/lib/docker/overlay2"))
+
+	f, err = Compile([]string{"cpu*", "!cpu-total"})
+	assert.NoError(t, err)
+	assert.True(t, f.Match("cpu0"))
+	assert.False(t, f.Match("cpu-total"))
+	assert.False(t, f.Match("mem"))
+
+	f, err = Compile([]string{"!re:^cpu[0-9]+$", "!mem"})
+	assert.NoError(t, err)
+	assert.True(t, f.Match("cpu-total"))
+	assert.False(t, f.Match("cpu1"))
+	assert.False(t, f.Match("mem"))
+
+	_, err = Compile([]string{"!re:("})
+	assert.Error(t, err)
+}
+
 func TestIncludeExclude(t *testing.T) {
 	tags := []string{}
 	labels := []string{"best", "com_influxdata", "timeseries", "com_influxdata_telegraf", "ever"}
@@ -75,6 +118,15 @@ func BenchmarkFilterSingleNoGlobTrue(b *testing.B) {
 	benchbool = tmp
 }
 
+func BenchmarkFilterRegex(b *testing.B) {
+	f, _ := Compile([]string{"re:^net[0-9]+$", "!net0"})
+	var tmp bool
+	for n := 0; n < b.N; n++ {
+		tmp = f.Match("net1")
+	}
+	benchbool = tmp
+}
+
 func BenchmarkFilter(b *testing.B) {
 	f, _ := Compile([]string{"cpu", "mem", "net*"})
 	var tmp bool
@@ -0,0 +1,480 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// node is an element of the parsed expression tree.
+type node interface {
+	eval(env *env) (interface{}, error)
+}
+
+// env is the state an expression is evaluated in.
+type env struct {
+	metric telegraf.Metric
+
+	// missing, if not nil, is used as the value of tags and fields that
+	// do not exist on the metric.
+	missing interface{}
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(*env) (interface{}, error) {
+	return n.value, nil
+}
+
+type nameNode struct{}
+
+func (n *nameNode) eval(env *env) (interface{}, error) {
+	return env.metric.Name(), nil
+}
+
+type timeNode struct{}
+
+func (n *timeNode) eval(env *env) (interface{}, error) {
+	return env.metric.Time(), nil
+}
+
+type tagNode struct {
+	key string
+}
+
+func (n *tagNode) eval(env *env) (interface{}, error) {
+	if v, ok := env.metric.GetTag(n.key); ok {
+		return v, nil
+	}
+	if env.missing != nil {
+		return env.missing, nil
+	}
+	return nil, &MissingError{Kind: "tag", Key: n.key}
+}
+
+type fieldNode struct {
+	key string
+}
+
+func (n *fieldNode) eval(env *env) (interface{}, error) {
+	if v, ok := env.metric.GetField(n.key); ok {
+		return v, nil
+	}
+	if env.missing != nil {
+		return env.missing, nil
+	}
+	return nil, &MissingError{Kind: "field", Key: n.key}
+}
+
+// hasNode reports whether a tag or field is present on the metric.
+type hasNode struct {
+	operand node
+}
+
+func (n *hasNode) eval(env *env) (interface{}, error) {
+	var ok bool
+	switch operand := n.operand.(type) {
+	case *tagNode:
+		ok = env.metric.HasTag(operand.key)
+	case *fieldNode:
+		ok = env.metric.HasField(operand.key)
+	}
+	return ok, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env *env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "!":
+		if b, ok := v.(bool); ok {
+			return !b, nil
+		}
+	case "-":
+		switch v := v.(type) {
+		case int64:
+			return -v, nil
+		case uint64:
+			return -int64(v), nil
+		case float64:
+			return -v, nil
+		case time.Duration:
+			return -v, nil
+		}
+	}
+	return nil, fmt.Errorf("operator %q not defined for %s", n.op, typeName(v))
+}
+
+// logicalNode implements the short-circuiting boolean operators.
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(env *env) (interface{}, error) {
+	left, err := evalBool(n.left, env, n.op)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&" && !left) || (n.op == "||" && left) {
+		return left, nil
+	}
+	return evalBool(n.right, env, n.op)
+}
+
+func evalBool(n node, env *env, op string) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("operator %q not defined for %s", op, typeName(v))
+	}
+	return b, nil
+}
+
+type matchNode struct {
+	negate  bool
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n *matchNode) eval(env *env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("pattern matching not defined for %s", typeName(v))
+	}
+	return n.re.MatchString(s) != n.negate, nil
+}
+
+type callNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (n *callNode) eval(env *env) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	v, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %v", n.name, err)
+	}
+	return v, nil
+}
+
+// lazyCallNode calls a function which evaluates its own arguments.
+type lazyCallNode struct {
+	name string
+	fn   func(env *env, args []node) (interface{}, error)
+	args []node
+}
+
+func (n *lazyCallNode) eval(env *env) (interface{}, error) {
+	v, err := n.fn(env, n.args)
+	if err != nil {
+		if _, ok := err.(*MissingError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s(): %v", n.name, err)
+	}
+	return v, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env *env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	}
+	return arithmetic(n.op, left, right)
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var cmp int
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, mismatch(op, left, right)
+		}
+		cmp = compareOrdered(l < r, l > r)
+	case bool:
+		r, ok := right.(bool)
+		if !ok || (op != "==" && op != "!=") {
+			return nil, mismatch(op, left, right)
+		}
+		if l != r {
+			cmp = 1
+		}
+	case time.Time:
+		r, ok := right.(time.Time)
+		if !ok {
+			return nil, mismatch(op, left, right)
+		}
+		cmp = compareOrdered(l.Before(r), l.After(r))
+	case time.Duration:
+		r, ok := right.(time.Duration)
+		if !ok {
+			return nil, mismatch(op, left, right)
+		}
+		cmp = compareOrdered(l < r, l > r)
+	default:
+		var ok bool
+		cmp, ok = compareNumbers(left, right)
+		if !ok {
+			return nil, mismatch(op, left, right)
+		}
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// compareNumbers compares two numeric values, taking care not to lose
+// precision when both are integers.
+func compareNumbers(left, right interface{}) (int, bool) {
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return compareOrdered(l < r, l > r), true
+		case uint64:
+			if l < 0 {
+				return -1, true
+			}
+			return compareOrdered(uint64(l) < r, uint64(l) > r), true
+		}
+	case uint64:
+		switch r := right.(type) {
+		case uint64:
+			return compareOrdered(l < r, l > r), true
+		case int64:
+			if r < 0 {
+				return 1, true
+			}
+			return compareOrdered(l < uint64(r), l > uint64(r)), true
+		}
+	}
+
+	l, ok := toFloat(left)
+	if !ok {
+		return 0, false
+	}
+	r, ok := toFloat(right)
+	if !ok {
+		return 0, false
+	}
+	return compareOrdered(l < r, l > r), true
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+		return nil, mismatch(op, left, right)
+	case time.Time:
+		switch r := right.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return l.Add(r), nil
+			case "-":
+				return l.Add(-r), nil
+			}
+		case time.Time:
+			if op == "-" {
+				return l.Sub(r), nil
+			}
+		}
+		return nil, mismatch(op, left, right)
+	case time.Duration:
+		switch r := right.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			}
+		case time.Time:
+			if op == "+" {
+				return r.Add(l), nil
+			}
+		}
+		return nil, mismatch(op, left, right)
+	}
+
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return intArithmetic(op, l, r)
+		case uint64:
+			return intArithmetic(op, l, int64(r))
+		}
+	case uint64:
+		switch r := right.(type) {
+		case uint64:
+			return uintArithmetic(op, l, r)
+		case int64:
+			return intArithmetic(op, int64(l), r)
+		}
+	}
+
+	l, ok := toFloat(left)
+	if !ok {
+		return nil, mismatch(op, left, right)
+	}
+	r, ok := toFloat(right)
+	if !ok {
+		return nil, mismatch(op, left, right)
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	default:
+		return math.Mod(l, r), nil
+	}
+}
+
+func intArithmetic(op string, l, r int64) (interface{}, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+	if r == 0 {
+		return nil, fmt.Errorf("integer division by zero")
+	}
+	if op == "/" {
+		return l / r, nil
+	}
+	return l % r, nil
+}
+
+func uintArithmetic(op string, l, r uint64) (interface{}, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+	if r == 0 {
+		return nil, fmt.Errorf("integer division by zero")
+	}
+	if op == "/" {
+		return l / r, nil
+	}
+	return l % r, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func mismatch(op string, left, right interface{}) error {
+	return fmt.Errorf("operator %q not defined for %s and %s", op, typeName(left), typeName(right))
+}
+
+// typeName returns the expression language name for the type of a value.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case int64:
+		return "int"
+	case uint64:
+		return "uint"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "time"
+	case time.Duration:
+		return "duration"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,83 @@
+// Package expr implements a small typed expression language evaluated
+// against a telegraf.Metric.
+//
+// An expression can reference the metric name as `name`, its timestamp as
+// `time`, and its tags and fields as `tags.key` and `fields.key` (or
+// `tags["key"]` for keys that are not valid identifiers).  Values are typed
+// as int, uint, float, string, bool, time or duration and the usual
+// arithmetic (+ - * / %), comparison (== != < <= > >=), regular expression
+// matching (=~ !~) and boolean (&& || !) operators are supported.
+//
+//   name == "cpu" && fields.usage_idle > 99
+//   tags.path !~ "^/var/lib/docker/" || has(fields.errors)
+//   time > now() - duration("1h")
+//
+// Referencing a tag or field that does not exist on the metric is an
+// evaluation error of type *MissingError; use has() to test for presence.
+//
+// Builtin functions include type conversions (int, float, string), math
+// functions (min, max, abs, log, sqrt, pow, round, ...) and the conditional
+// if(cond, a, b), which only evaluates the selected branch.
+package expr
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// MissingError is returned when an expression references a tag or field
+// that is not present on the metric.
+type MissingError struct {
+	Kind string
+	Key  string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.Kind, e.Key)
+}
+
+// Expression is a compiled expression which can be evaluated concurrently.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses the source into an Expression.
+func Compile(source string) (*Expression, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Eval evaluates the expression against the metric.  The result is one of
+// int64, uint64, float64, string, bool, time.Time or time.Duration.
+func (e *Expression) Eval(m telegraf.Metric) (interface{}, error) {
+	return e.root.eval(&env{metric: m})
+}
+
+// EvalDefault evaluates the expression against the metric, using def as the
+// value of any referenced tag or field that does not exist on the metric.
+func (e *Expression) EvalDefault(m telegraf.Metric, def interface{}) (interface{}, error) {
+	return e.root.eval(&env{metric: m, missing: def})
+}
+
+// EvalBool evaluates the expression against the metric and returns an error
+// if the result is not a boolean.
+func (e *Expression) EvalBool(m telegraf.Metric) (bool, error) {
+	v, err := e.Eval(m)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression result is %s, not bool", typeName(v))
+	}
+	return b, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
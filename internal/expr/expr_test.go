@@ -0,0 +1,193 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{
+			"cpu":     "cpu0",
+			"host":    "web-01",
+			"my-tag":  "x",
+			"numeric": "42",
+		},
+		map[string]interface{}{
+			"usage_idle": 99.5,
+			"count":      int64(10),
+			"total":      uint64(20),
+			"status":     "ok",
+			"up":         true,
+		},
+		time.Unix(1500000000, 0),
+	)
+
+	tests := []struct {
+		name     string
+		source   string
+		expected interface{}
+	}{
+		{"name", `name`, "cpu"},
+		{"name equals", `name == "cpu"`, true},
+		{"single quotes", `name == 'cpu'`, true},
+		{"tag", `tags.host`, "web-01"},
+		{"tag brackets", `tags["my-tag"]`, "x"},
+		{"field float", `fields.usage_idle > 99`, true},
+		{"field int", `fields.count + 1`, int64(11)},
+		{"field uint", `fields.total + fields.total`, uint64(40)},
+		{"mixed int uint", `fields.total - fields.count`, int64(10)},
+		{"mixed int float", `fields.count / 4.0`, 2.5},
+		{"int division", `fields.count / 4`, int64(2)},
+		{"modulo", `fields.count % 3`, int64(1)},
+		{"precedence", `1 + 2 * 3`, int64(7)},
+		{"parens", `(1 + 2) * 3`, int64(9)},
+		{"unary minus", `-fields.count`, int64(-10)},
+		{"not", `!fields.up`, false},
+		{"and", `name == "cpu" && fields.usage_idle > 99`, true},
+		{"or", `name == "mem" || tags.cpu == "cpu0"`, true},
+		{"short circuit and", `name == "mem" && fields.missing > 1`, false},
+		{"short circuit or", `name == "cpu" || fields.missing > 1`, true},
+		{"regex", `tags.host =~ "^web-"`, true},
+		{"negated regex", `tags.host !~ "^db-"`, true},
+		{"string concat", `name + "_" + tags.cpu`, "cpu_cpu0"},
+		{"has tag", `has(tags.cpu)`, true},
+		{"has missing field", `has(fields.missing)`, false},
+		{"numeric tag", `int(tags.numeric) > 40`, true},
+		{"float conversion", `float(fields.count)`, 10.0},
+		{"string conversion", `string(fields.count)`, "10"},
+		{"time", `time < now() - duration("1h")`, true},
+		{"time difference", `time - time`, time.Duration(0)},
+		{"scientific", `1e3`, 1000.0},
+		{"uint compare", `fields.total > -1`, true},
+		{"min", `min(fields.count, fields.total, 3)`, int64(3)},
+		{"max", `max(fields.count, fields.usage_idle)`, 99.5},
+		{"abs", `abs(-fields.count)`, int64(10)},
+		{"log", `log(100, 10)`, 2.0},
+		{"log10", `log10(1000)`, 3.0},
+		{"sqrt", `sqrt(16)`, 4.0},
+		{"pow", `pow(2, 10)`, 1024.0},
+		{"round", `round(fields.usage_idle)`, 100.0},
+		{"if true", `if(fields.up, "up", "down")`, "up"},
+		{"if false", `if(fields.count > 100, 1, 0)`, int64(0)},
+		{"if lazy", `if(has(fields.missing), fields.missing, -1)`, int64(-1)},
+		{"percent", `fields.count / float(fields.total) * 100`, 50.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source)
+			require.NoError(t, err)
+			v, err := e.Eval(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{"host": "web-01"},
+		map[string]interface{}{"value": int64(1), "status": "ok"},
+		time.Unix(0, 0),
+	)
+
+	tests := []struct {
+		name    string
+		source  string
+		missing bool
+	}{
+		{"missing field", `fields.missing > 1`, true},
+		{"missing tag", `tags.missing == "a"`, true},
+		{"type mismatch", `fields.status > 1`, false},
+		{"bool arithmetic", `true + 1`, false},
+		{"logical on number", `fields.value && true`, false},
+		{"division by zero", `fields.value / 0`, false},
+		{"regex on number", `fields.value =~ "1"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source)
+			require.NoError(t, err)
+			_, err = e.Eval(m)
+			require.Error(t, err)
+			_, ok := err.(*MissingError)
+			require.Equal(t, tt.missing, ok)
+		})
+	}
+}
+
+func TestEvalDefault(t *testing.T) {
+	m := testutil.MustMetric("mem",
+		map[string]string{},
+		map[string]interface{}{"used": int64(5)},
+		time.Unix(0, 0),
+	)
+
+	e, err := Compile(`fields.used + fields.cached`)
+	require.NoError(t, err)
+
+	_, err = e.Eval(m)
+	require.IsType(t, &MissingError{}, err)
+
+	v, err := e.EvalDefault(m, int64(0))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), v)
+
+	e, err = Compile(`has(fields.cached)`)
+	require.NoError(t, err)
+	v, err = e.EvalDefault(m, int64(0))
+	require.NoError(t, err)
+	require.Equal(t, false, v)
+}
+
+func TestEvalBool(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": int64(1)},
+		time.Unix(0, 0),
+	)
+
+	e, err := Compile(`fields.value == 1`)
+	require.NoError(t, err)
+	ok, err := e.EvalBool(m)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	e, err = Compile(`fields.value`)
+	require.NoError(t, err)
+	_, err = e.EvalBool(m)
+	require.Error(t, err)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`name ==`,
+		`(name == "cpu"`,
+		`"unterminated`,
+		`unknown > 1`,
+		`tags`,
+		`tags[1]`,
+		`nosuchfunc(1)`,
+		`int(1, 2)`,
+		`if(true, 1)`,
+		`min()`,
+		`has(name)`,
+		`name =~ tags.host`,
+		`name =~ "("`,
+		`name == "cpu" extra`,
+		`name # "cpu"`,
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			_, err := Compile(source)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type function struct {
+	minArgs int
+	maxArgs int // negative for variadic functions
+	call    func(args []interface{}) (interface{}, error)
+
+	// lazy functions receive their arguments unevaluated.
+	lazy func(env *env, args []node) (interface{}, error)
+}
+
+// functions are the builtin functions available to expressions.  The
+// special function has() is handled by the parser.
+var functions = map[string]function{
+	"now":      {minArgs: 0, maxArgs: 0, call: fnNow},
+	"duration": {minArgs: 1, maxArgs: 1, call: fnDuration},
+	"int":      {minArgs: 1, maxArgs: 1, call: fnInt},
+	"float":    {minArgs: 1, maxArgs: 1, call: fnFloat},
+	"string":   {minArgs: 1, maxArgs: 1, call: fnString},
+	"if":       {minArgs: 3, maxArgs: 3, lazy: fnIf},
+	"min":      {minArgs: 1, maxArgs: -1, call: fnMin},
+	"max":      {minArgs: 1, maxArgs: -1, call: fnMax},
+	"abs":      {minArgs: 1, maxArgs: 1, call: fnAbs},
+	"log":      {minArgs: 1, maxArgs: 2, call: fnLog},
+	"log10":    {minArgs: 1, maxArgs: 1, call: floatFunc(math.Log10)},
+	"exp":      {minArgs: 1, maxArgs: 1, call: floatFunc(math.Exp)},
+	"sqrt":     {minArgs: 1, maxArgs: 1, call: floatFunc(math.Sqrt)},
+	"pow":      {minArgs: 2, maxArgs: 2, call: fnPow},
+	"floor":    {minArgs: 1, maxArgs: 1, call: floatFunc(math.Floor)},
+	"ceil":     {minArgs: 1, maxArgs: 1, call: floatFunc(math.Ceil)},
+	"round":    {minArgs: 1, maxArgs: 1, call: floatFunc(math.Round)},
+}
+
+func fnNow([]interface{}) (interface{}, error) {
+	return time.Now(), nil
+}
+
+func fnDuration(args []interface{}) (interface{}, error) {
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+	}
+	return time.ParseDuration(s)
+}
+
+func fnInt(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case time.Time:
+		return v.UnixNano(), nil
+	case time.Duration:
+		return int64(v), nil
+	}
+	return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+}
+
+func fnFloat(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64, uint64, float64:
+		f, _ := toFloat(v)
+		return f, nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+}
+
+func fnString(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+}
+
+// fnIf evaluates to its second argument if the first is true and to its
+// third otherwise.  Only the selected branch is evaluated.
+func fnIf(env *env, args []node) (interface{}, error) {
+	cond, err := evalBool(args[0], env, "if")
+	if err != nil {
+		return nil, err
+	}
+	if cond {
+		return args[1].eval(env)
+	}
+	return args[2].eval(env)
+}
+
+func fnMin(args []interface{}) (interface{}, error) {
+	return extremum(args, -1)
+}
+
+func fnMax(args []interface{}) (interface{}, error) {
+	return extremum(args, 1)
+}
+
+// extremum returns the smallest (sign < 0) or largest (sign > 0) of the
+// numeric arguments, keeping its original type.
+func extremum(args []interface{}, sign int) (interface{}, error) {
+	result := args[0]
+	if _, ok := toFloat(result); !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(result))
+	}
+	for _, arg := range args[1:] {
+		cmp, ok := compareNumbers(arg, result)
+		if !ok {
+			return nil, fmt.Errorf("not defined for %s", typeName(arg))
+		}
+		if cmp*sign > 0 {
+			result = arg
+		}
+	}
+	return result, nil
+}
+
+func fnAbs(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		if v < 0 {
+			return -v, nil
+		}
+		return v, nil
+	case uint64:
+		return v, nil
+	case float64:
+		return math.Abs(v), nil
+	case time.Duration:
+		if v < 0 {
+			return -v, nil
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+}
+
+// fnLog returns the natural logarithm of its first argument, or the
+// logarithm in the base given by the optional second argument.
+func fnLog(args []interface{}) (interface{}, error) {
+	x, ok := toFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+	}
+	if len(args) == 1 {
+		return math.Log(x), nil
+	}
+	base, ok := toFloat(args[1])
+	if !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(args[1]))
+	}
+	return math.Log(x) / math.Log(base), nil
+}
+
+func fnPow(args []interface{}) (interface{}, error) {
+	x, ok := toFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+	}
+	y, ok := toFloat(args[1])
+	if !ok {
+		return nil, fmt.Errorf("not defined for %s", typeName(args[1]))
+	}
+	return math.Pow(x, y), nil
+}
+
+// floatFunc adapts a single argument float function to a builtin.
+func floatFunc(fn func(float64) float64) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		x, ok := toFloat(args[0])
+		if !ok {
+			return nil, fmt.Errorf("not defined for %s", typeName(args[0]))
+		}
+		return fn(x), nil
+	}
+}
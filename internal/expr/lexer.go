@@ -0,0 +1,155 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokInt
+	tokFloat
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators lists the recognised operators, longest first so that the lexer
+// always consumes the longest possible match.
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=", "=~", "!~",
+	"+", "-", "*", "/", "%", "<", ">", "!", "(", ")", "[", "]", ",", ".",
+}
+
+// lex splits the source into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(src) {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case r == '"' || r == '\'':
+			tok, err := lexString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case r >= '0' && r <= '9':
+			tok := lexNumber(src, pos)
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case r == '_' || unicode.IsLetter(r):
+			end := pos
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[pos:end], pos: pos})
+			pos = end
+		default:
+			var op string
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: pos})
+	return tokens, nil
+}
+
+// lexString consumes a quoted string starting at pos.  The token text keeps
+// the quotes; it is unquoted by the parser.
+func lexString(src string, pos int) (token, error) {
+	quote := src[pos]
+	for i := pos + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return token{kind: tokString, text: src[pos : i+1], pos: pos}, nil
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string at position %d", pos)
+}
+
+// lexNumber consumes an integer or floating point literal starting at pos.
+func lexNumber(src string, pos int) token {
+	kind := tokInt
+	end := pos
+	digits := func() {
+		for end < len(src) && src[end] >= '0' && src[end] <= '9' {
+			end++
+		}
+	}
+
+	digits()
+	if end+1 < len(src) && src[end] == '.' && src[end+1] >= '0' && src[end+1] <= '9' {
+		kind = tokFloat
+		end++
+		digits()
+	}
+	if end < len(src) && (src[end] == 'e' || src[end] == 'E') {
+		next := end + 1
+		if next < len(src) && (src[next] == '+' || src[next] == '-') {
+			next++
+		}
+		if next < len(src) && src[next] >= '0' && src[next] <= '9' {
+			kind = tokFloat
+			end = next
+			digits()
+		}
+	}
+	return token{kind: kind, text: src[pos:end], pos: pos}
+}
+
+// unquote converts a quoted string token into its value.
+func unquote(text string) (string, error) {
+	quote := text[0]
+	s := text[1 : len(text)-1]
+	var sb strings.Builder
+	for len(s) > 0 {
+		r, multibyte, tail, err := strconv.UnquoteChar(s, quote)
+		if err != nil {
+			return "", err
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			sb.WriteByte(byte(r))
+		} else {
+			sb.WriteRune(r)
+		}
+		s = tail
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,335 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// parser is a recursive descent parser producing the node tree of an
+// expression.  Operator precedence, from lowest to highest, is:
+//
+//   ||
+//   &&
+//   == != < <= > >= =~ !~
+//   + -
+//   * / %
+//   ! - (unary)
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators.
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q but found %s at position %d", op, tok, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "=~", "!~")
+	if !ok {
+		return left, nil
+	}
+
+	if op == "=~" || op == "!~" {
+		tok := p.next()
+		if tok.kind != tokString {
+			return nil, fmt.Errorf("operator %q requires a string literal pattern at position %d", op, tok.pos)
+		}
+		pattern, err := unquote(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string at position %d: %v", tok.pos, err)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern at position %d: %v", tok.pos, err)
+		}
+		return &matchNode{negate: op == "!~", operand: left, re: re}, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokInt:
+		v, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			// Fall back to unsigned for values above the int64 range.
+			u, uerr := strconv.ParseUint(tok.text, 10, 64)
+			if uerr != nil {
+				return nil, fmt.Errorf("invalid integer %s at position %d", tok, tok.pos)
+			}
+			return &literalNode{value: u}, nil
+		}
+		return &literalNode{value: v}, nil
+	case tokFloat:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", tok, tok.pos)
+		}
+		return &literalNode{value: v}, nil
+	case tokString:
+		v, err := unquote(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string at position %d: %v", tok.pos, err)
+		}
+		return &literalNode{value: v}, nil
+	case tokIdent:
+		return p.parseIdent(tok)
+	case tokOp:
+		if tok.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+}
+
+func (p *parser) parseIdent(tok token) (node, error) {
+	if p.peek().kind == tokOp && p.peek().text == "(" {
+		return p.parseCall(tok)
+	}
+
+	switch tok.text {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	case "name":
+		return &nameNode{}, nil
+	case "time":
+		return &timeNode{}, nil
+	case "tags", "fields":
+		key, err := p.parseKey(tok)
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "tags" {
+			return &tagNode{key: key}, nil
+		}
+		return &fieldNode{key: key}, nil
+	}
+	return nil, fmt.Errorf("unknown identifier %s at position %d", tok, tok.pos)
+}
+
+// parseKey parses the key selector following "tags" or "fields", either
+// in the form of `.key` or `["key"]`.
+func (p *parser) parseKey(tok token) (string, error) {
+	if _, ok := p.accept("."); ok {
+		key := p.next()
+		if key.kind != tokIdent {
+			return "", fmt.Errorf("expected key after %s at position %d", tok, key.pos)
+		}
+		return key.text, nil
+	}
+
+	if _, ok := p.accept("["); ok {
+		key := p.next()
+		if key.kind != tokString {
+			return "", fmt.Errorf("expected string key after %s at position %d", tok, key.pos)
+		}
+		value, err := unquote(key.text)
+		if err != nil {
+			return "", fmt.Errorf("invalid string at position %d: %v", key.pos, err)
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+
+	return "", fmt.Errorf("%s must be followed by a key at position %d", tok, tok.pos)
+}
+
+func (p *parser) parseCall(tok token) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); ok {
+				continue
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if tok.text == "has" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("function \"has\" takes exactly one argument at position %d", tok.pos)
+		}
+		switch args[0].(type) {
+		case *tagNode, *fieldNode:
+		default:
+			return nil, fmt.Errorf("function \"has\" requires a tag or field argument at position %d", tok.pos)
+		}
+		return &hasNode{operand: args[0]}, nil
+	}
+
+	fn, ok := functions[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", tok, tok.pos)
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments for function %s at position %d", tok, tok.pos)
+	}
+	if fn.lazy != nil {
+		return &lazyCallNode{name: tok.text, fn: fn.lazy, args: args}, nil
+	}
+	return &callNode{name: tok.text, fn: fn.call, args: args}, nil
+}
@@ -0,0 +1,57 @@
+package snmp
+
+import (
+	"fmt"
+	"time"
+)
+
+// Conversion returns the conversion of the values of a textual convention,
+// as understood by the snmp input: "hwaddr", "ipaddr", "datetime" or empty if
+// the values need no conversion.
+func Conversion(tc string) string {
+	switch tc {
+	case "MacAddress", "PhysAddress":
+		return "hwaddr"
+	case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
+		return "ipaddr"
+	case "DateAndTime":
+		return "datetime"
+	}
+	return ""
+}
+
+// NodeConversion returns the conversion of the nearest textual convention of
+// the node having one.
+func NodeConversion(n *Node) string {
+	for _, tc := range n.TextualConventions {
+		if conv := Conversion(tc); conv != "" {
+			return conv
+		}
+	}
+	return ""
+}
+
+// DateAndTime formats an 8 or 11 byte DateAndTime value, as defined by
+// SNMPv2-TC, as RFC3339 timestamp.  Values without time zone are taken as UTC.
+func DateAndTime(bs []byte) (string, error) {
+	if len(bs) != 8 && len(bs) != 11 {
+		return "", fmt.Errorf("invalid length (%d) for datetime conversion", len(bs))
+	}
+
+	loc := time.UTC
+	if len(bs) == 11 {
+		offset := (int(bs[9])*60 + int(bs[10])) * 60
+		switch bs[8] {
+		case '+':
+		case '-':
+			offset = -offset
+		default:
+			return "", fmt.Errorf("invalid direction from UTC '%c' for datetime conversion", bs[8])
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	year := int(bs[0])<<8 | int(bs[1])
+	t := time.Date(year, time.Month(bs[2]), int(bs[3]), int(bs[4]), int(bs[5]), int(bs[6]), int(bs[7])*100000000, loc)
+	return t.Format(time.RFC3339Nano), nil
+}
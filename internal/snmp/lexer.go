@@ -0,0 +1,130 @@
+package snmp
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF        tokenKind = iota
+	tokenIdentifier           // names and keywords, e.g. ifDescr, OBJECT-TYPE
+	tokenNumber               // decimal numbers, possibly negative
+	tokenString               // quoted strings, without the quotes
+	tokenBinary               // binary and hexadecimal strings, e.g. '01'H
+	tokenSymbol               // punctuation, e.g. ::=, {, .., (
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// lex splits a MIB module into tokens, dropping the comments.  ASN.1 comments
+// start with "--" and end at the end of the line or at the next "--".
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			i += 2
+			for i < len(src) && src[i] != '\n' {
+				if src[i] == '-' && i+1 < len(src) && src[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start := line
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("line %d: unterminated string", start)
+				}
+				if src[i] == '"' {
+					// a doubled quote is a quote within the string
+					if i+1 < len(src) && src[i+1] == '"' {
+						sb.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if src[i] == '\n' {
+					line++
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, value: sb.String(), line: start})
+		case c == '\'':
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end < 0 || i+end+2 >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated binary string", line)
+			}
+			value := src[i : i+end+3]
+			line += strings.Count(value, "\n")
+			tokens = append(tokens, token{kind: tokenBinary, value: value, line: line})
+			i += end + 3
+		case isDigit(c) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: src[start:i], line: line})
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i]) || src[i] == '_' ||
+				(src[i] == '-' && i+1 < len(src) && src[i+1] != '-')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: src[start:i], line: line})
+		case strings.HasPrefix(src[i:], "::="):
+			tokens = append(tokens, token{kind: tokenSymbol, value: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(src[i:], ".."):
+			tokens = append(tokens, token{kind: tokenSymbol, value: "..", line: line})
+			i += 2
+		case strings.IndexByte("{}()[],;|.:<>=@!*&^%$#~`/\\?+-", c) >= 0:
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(c), line: line})
+			i++
+		default:
+			// ignore other bytes, e.g. stray non-ASCII characters
+			i++
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,606 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ErrNotFound is returned when an OID can not be resolved with the loaded
+// MIB modules.
+var ErrNotFound = errors.New("not found in the loaded MIB modules")
+
+// baseTypes are the types of the SMI, textual conventions and type
+// assignments are resolved up to one of them.
+var baseTypes = map[string]bool{
+	"INTEGER":           true,
+	"OCTET STRING":      true,
+	"OBJECT IDENTIFIER": true,
+	"BITS":              true,
+	"SEQUENCE":          true,
+	"SEQUENCE OF":       true,
+	"CHOICE":            true,
+	"Integer32":         true,
+	"Unsigned32":        true,
+	"Counter32":         true,
+	"Counter64":         true,
+	"Gauge32":           true,
+	"TimeTicks":         true,
+	"IpAddress":         true,
+	"Opaque":            true,
+	"Counter":           true,
+	"Gauge":             true,
+	"NetworkAddress":    true,
+}
+
+// Node is a node of the OID tree defined by the loaded MIB modules.
+type Node struct {
+	// Module and Name of the definition of the node, both are empty for
+	// nodes only known by number.
+	Module string
+	Name   string
+	// OID is the numeric OID with a leading dot.
+	OID string
+	// Kind is the macro defining the node, e.g. "OBJECT-TYPE" or "OBJECT
+	// IDENTIFIER".
+	Kind string
+	// Syntax is the base type of an OBJECT-TYPE, e.g. "OCTET STRING" or
+	// "Counter32".
+	Syntax string
+	// TextualConventions of the syntax, the nearest first, e.g.
+	// ["DisplayString"].
+	TextualConventions []string
+	// DisplayHint of the nearest textual convention having one.
+	DisplayHint string
+	// Enums maps the values of an enumeration to their names.
+	Enums map[int64]string
+	// Index are the names of the INDEX objects of a table entry, for
+	// augmenting entries the index of the augmented entry.
+	Index []string
+
+	number   uint32
+	parent   *Node
+	children map[uint32]*Node
+}
+
+// Parent returns the parent of the node, nil for the top level nodes.
+func (n *Node) Parent() *Node {
+	if n.parent == nil || n.parent.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+// Children returns the child nodes ordered by their number.
+func (n *Node) Children() []*Node {
+	children := make([]*Node, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].number < children[j].number
+	})
+	return children
+}
+
+// TextualConvention returns the nearest textual convention of the syntax,
+// empty if the syntax has none.
+func (n *Node) TextualConvention() string {
+	if len(n.TextualConventions) == 0 {
+		return ""
+	}
+	return n.TextualConventions[0]
+}
+
+func (n *Node) child(number uint32) *Node {
+	if c, ok := n.children[number]; ok {
+		return c
+	}
+	c := &Node{
+		OID:      n.OID + "." + strconv.FormatUint(uint64(number), 10),
+		number:   number,
+		parent:   n,
+		children: make(map[uint32]*Node),
+	}
+	n.children[number] = c
+	return c
+}
+
+func (n *Node) childByName(name string) *Node {
+	for _, c := range n.children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Translation is an OID resolved with the loaded MIB modules.
+type Translation struct {
+	// Module of the nearest named node of the OID.
+	Module string
+	// Name of the nearest named node followed by the remaining
+	// sub-identifiers, e.g. "ifDescr.1".
+	Name string
+	// OID is the numeric OID with a leading dot.
+	OID string
+	// Node is the nearest named node of the OID.
+	Node *Node
+}
+
+// Mibs is a set of MIB modules.
+type Mibs struct {
+	sync.RWMutex
+
+	modules map[string]*module
+	paths   map[string]bool
+
+	root  *Node
+	nodes map[string]map[string]*Node
+	// builtin are the builtin modules not replaced by a loaded module
+	builtin map[string]bool
+}
+
+// NewMibs returns a set of MIB modules containing only the builtin modules.
+func NewMibs() *Mibs {
+	m := &Mibs{
+		modules: make(map[string]*module),
+		paths:   make(map[string]bool),
+	}
+	m.build()
+	return m
+}
+
+var defaultMibs = NewMibs()
+
+// DefaultMibs returns the MIB modules shared by the plugins.
+func DefaultMibs() *Mibs {
+	return defaultMibs
+}
+
+// LoadPath loads the MIB modules of the files within the directories, or of
+// the files, of the paths.  Paths already loaded are skipped.  Files not
+// containing a MIB module are logged and skipped.
+func (m *Mibs) LoadPath(paths []string, log telegraf.Logger) error {
+	m.Lock()
+	defer m.Unlock()
+
+	loaded := false
+	for _, path := range paths {
+		if m.paths[path] {
+			continue
+		}
+
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			mod, err := parseModule(string(src))
+			if err != nil {
+				if log != nil {
+					log.Debugf("Skipping %s: %v", file, err)
+				}
+				return nil
+			}
+			if _, ok := m.modules[mod.name]; ok {
+				if log != nil {
+					log.Debugf("Skipping %s: module %s already loaded", file, mod.name)
+				}
+				return nil
+			}
+			m.modules[mod.name] = mod
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("loading MIB modules of %q: %v", path, err)
+		}
+		m.paths[path] = true
+		loaded = true
+	}
+
+	if loaded {
+		m.build()
+	}
+	return nil
+}
+
+// Loaded returns true if any MIB module, besides the builtin ones, has been
+// loaded.
+func (m *Mibs) Loaded() bool {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.modules) > 0
+}
+
+// LoadModule loads a MIB module from its source.
+func (m *Mibs) LoadModule(src string) error {
+	mod, err := parseModule(src)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.modules[mod.name] = mod
+	m.build()
+	return nil
+}
+
+// Lookup returns the node of the object of the module, or of any module if
+// the module is empty.
+func (m *Mibs) Lookup(module, name string) (*Node, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if n := m.lookup(module, name); n != nil {
+		return n, nil
+	}
+	if module != "" {
+		return nil, fmt.Errorf("%s::%s %v", module, name, ErrNotFound)
+	}
+	return nil, fmt.Errorf("%s %v", name, ErrNotFound)
+}
+
+func (m *Mibs) lookup(module, name string) *Node {
+	if module != "" {
+		return m.nodes[module][name]
+	}
+
+	modules := make([]string, 0, len(m.nodes))
+	for mod := range m.nodes {
+		modules = append(modules, mod)
+	}
+	sort.Strings(modules)
+	for _, mod := range modules {
+		if n, ok := m.nodes[mod][name]; ok {
+			return n
+		}
+	}
+	switch name {
+	case "ccitt":
+		return m.root.children[0]
+	case "iso":
+		return m.root.children[1]
+	case "joint-iso-ccitt":
+		return m.root.children[2]
+	}
+	return nil
+}
+
+// Translate resolves an OID given as number, e.g. ".1.3.6.1.2.1.2.2.1.2.1",
+// as name, e.g. "ifDescr.1" or "IF-MIB::ifDescr.1", or a mix of both, e.g.
+// ".iso.3.6.1".
+//
+// OIDs below a node of the builtin modules, which only define the top of the
+// tree, are not found: e.g. ".1.3.6.1.2.1.1.3.0" would otherwise translate to
+// "mib-2.1.3.0" when the module defining sysUpTime is not loaded, and keep
+// callers from resolving the OID by other means.
+func (m *Mibs) Translate(oid string) (*Translation, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	var module string
+	path := oid
+	if i := strings.Index(oid, "::"); i >= 0 {
+		module, path = oid[:i], oid[i+2:]
+		if _, ok := m.nodes[module]; !ok {
+			return nil, fmt.Errorf("%s: unknown MIB module %s", oid, module)
+		}
+	}
+
+	absolute := strings.HasPrefix(path, ".")
+	labels := strings.Split(strings.TrimPrefix(path, "."), ".")
+	if len(labels) == 0 || labels[0] == "" {
+		return nil, fmt.Errorf("%s: invalid OID", oid)
+	}
+
+	node := m.root
+	if !absolute && !isNumber(labels[0]) {
+		node = m.lookup(module, labels[0])
+		if node == nil {
+			return nil, fmt.Errorf("%s %v", oid, ErrNotFound)
+		}
+		labels = labels[1:]
+	}
+
+	// descend as far as the tree is known
+	var suffix []string
+	for i, label := range labels {
+		var next *Node
+		if n, err := strconv.ParseUint(label, 10, 32); err == nil {
+			next = node.children[uint32(n)]
+		} else {
+			next = node.childByName(label)
+			if next == nil {
+				return nil, fmt.Errorf("%s: unknown object %s", oid, label)
+			}
+		}
+		if next == nil {
+			suffix = labels[i:]
+			break
+		}
+		node = next
+	}
+	for _, s := range suffix {
+		if !isNumber(s) {
+			return nil, fmt.Errorf("%s: unknown object %s", oid, s)
+		}
+	}
+
+	// nodes without definition are named after the nearest named ancestor
+	for node.Name == "" && node != m.root {
+		suffix = append([]string{strconv.FormatUint(uint64(node.number), 10)}, suffix...)
+		node = node.parent
+	}
+	if node == m.root {
+		return nil, fmt.Errorf("%s %v", oid, ErrNotFound)
+	}
+	if len(suffix) > 0 && (node.Module == "" || m.builtin[node.Module]) {
+		return nil, fmt.Errorf("%s %v", oid, ErrNotFound)
+	}
+
+	t := &Translation{
+		Module: node.Module,
+		Name:   node.Name,
+		OID:    node.OID,
+		Node:   node,
+	}
+	if len(suffix) > 0 {
+		t.Name += "." + strings.Join(suffix, ".")
+		t.OID += "." + strings.Join(suffix, ".")
+	}
+	return t, nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+// build builds the OID tree of the loaded and builtin modules.
+func (m *Mibs) build() {
+	modules := make(map[string]*module, len(m.modules)+len(builtinModules))
+	m.builtin = make(map[string]bool, len(builtinModules))
+	for name, src := range builtinModules {
+		if _, ok := m.modules[name]; ok {
+			continue
+		}
+		mod, err := parseModule(src)
+		if err != nil {
+			panic(fmt.Sprintf("builtin MIB module %s: %v", name, err))
+		}
+		modules[name] = mod
+		m.builtin[name] = true
+	}
+	for name, mod := range m.modules {
+		modules[name] = mod
+	}
+
+	m.root = &Node{children: make(map[uint32]*Node)}
+	for number, name := range []string{"ccitt", "iso", "joint-iso-ccitt"} {
+		n := m.root.child(uint32(number))
+		n.Name = name
+		n.Kind = "OBJECT IDENTIFIER"
+	}
+	m.nodes = make(map[string]map[string]*Node, len(modules))
+
+	r := &resolver{
+		mibs:      m,
+		modules:   modules,
+		resolved:  make(map[*definition]*Node),
+		resolving: make(map[*definition]bool),
+	}
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, def := range modules[name].definitions {
+			r.definition(modules[name], def)
+		}
+	}
+
+	// the index of augmenting entries is the one of the augmented entry
+	for _, name := range names {
+		mod := modules[name]
+		for _, def := range mod.definitions {
+			if def.augments == "" {
+				continue
+			}
+			n, ok := r.resolved[def]
+			augmented := r.name(mod, def.augments)
+			if ok && n != nil && augmented != nil && n.Module == mod.name && n.Name == def.name {
+				n.Index = augmented.Index
+			}
+		}
+	}
+}
+
+// resolver resolves the OID values of the definitions to nodes.
+type resolver struct {
+	mibs      *Mibs
+	modules   map[string]*module
+	resolved  map[*definition]*Node
+	resolving map[*definition]bool
+}
+
+// find returns the definition of the name in the scope of the module, i.e.
+// defined by the module, imported by it, or defined by any module.
+func (r *resolver) find(mod *module, name string) (*module, *definition) {
+	for _, def := range mod.definitions {
+		if def.name == name {
+			return mod, def
+		}
+	}
+	if from, ok := mod.imports[name]; ok {
+		if imported, ok := r.modules[from]; ok && imported != mod {
+			for _, def := range imported.definitions {
+				if def.name == name {
+					return imported, def
+				}
+			}
+			if from, ok := imported.imports[name]; ok {
+				if reexported, ok := r.modules[from]; ok {
+					return r.find(reexported, name)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(r.modules))
+	for name := range r.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, def := range r.modules[n].definitions {
+			if def.name == name {
+				return r.modules[n], def
+			}
+		}
+	}
+	return nil, nil
+}
+
+// name resolves the name in the scope of the module.
+func (r *resolver) name(mod *module, name string) *Node {
+	switch name {
+	case "ccitt":
+		return r.mibs.root.children[0]
+	case "iso":
+		return r.mibs.root.children[1]
+	case "joint-iso-ccitt":
+		return r.mibs.root.children[2]
+	}
+	if defMod, def := r.find(mod, name); def != nil {
+		return r.definition(defMod, def)
+	}
+	return nil
+}
+
+// definition resolves the node of the definition, nil if its OID value can
+// not be resolved.
+func (r *resolver) definition(mod *module, def *definition) *Node {
+	if n, ok := r.resolved[def]; ok {
+		return n
+	}
+	if r.resolving[def] {
+		return nil
+	}
+	r.resolving[def] = true
+	defer delete(r.resolving, def)
+
+	first := def.value[0]
+	var node *Node
+	switch {
+	case first.name != "":
+		node = r.name(mod, first.name)
+		if node == nil && first.hasNumber {
+			node = r.mibs.root.child(first.number)
+		}
+	default:
+		node = r.mibs.root.child(first.number)
+	}
+	if node == nil {
+		r.resolved[def] = nil
+		return nil
+	}
+
+	for _, c := range def.value[1:] {
+		if !c.hasNumber {
+			r.resolved[def] = nil
+			return nil
+		}
+		node = node.child(c.number)
+		if c.name != "" && node.Name == "" {
+			// named intermediate node, e.g. "org(3)"
+			node.Module = mod.name
+			node.Name = c.name
+			node.Kind = "OBJECT IDENTIFIER"
+		}
+	}
+
+	if node.Kind == "" || node.Name == "" || (node.Module == mod.name && node.Name == def.name) {
+		node.Module = mod.name
+		node.Name = def.name
+		node.Kind = def.kind
+		if def.syntax != nil {
+			r.syntax(node, mod, def.syntax)
+		}
+		node.Index = def.index
+	}
+
+	if r.mibs.nodes[mod.name] == nil {
+		r.mibs.nodes[mod.name] = make(map[string]*Node)
+	}
+	r.mibs.nodes[mod.name][def.name] = node
+	r.resolved[def] = node
+	return node
+}
+
+// syntax resolves the syntax of the object up to its base type.
+func (r *resolver) syntax(node *Node, mod *module, ref *typeRef) {
+	node.Enums = ref.enums
+	name := ref.name
+	for depth := 0; !baseTypes[name] && depth < 16; depth++ {
+		typeMod, td := r.findType(mod, name)
+		if td == nil {
+			break
+		}
+		if td.textualConvention {
+			node.TextualConventions = append(node.TextualConventions, name)
+			if node.DisplayHint == "" {
+				node.DisplayHint = td.displayHint
+			}
+		}
+		if node.Enums == nil {
+			node.Enums = td.syntax.enums
+		}
+		mod, name = typeMod, td.syntax.name
+	}
+	node.Syntax = name
+}
+
+// findType returns the type definition of the name in the scope of the
+// module.
+func (r *resolver) findType(mod *module, name string) (*module, *typeDefinition) {
+	if td, ok := mod.types[name]; ok {
+		return mod, td
+	}
+	if from, ok := mod.imports[name]; ok {
+		if imported, ok := r.modules[from]; ok {
+			if td, ok := imported.types[name]; ok {
+				return imported, td
+			}
+		}
+	}
+
+	names := make([]string, 0, len(r.modules))
+	for name := range r.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if td, ok := r.modules[n].types[name]; ok {
+			return r.modules[n], td
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,146 @@
+package snmp
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestMibs(t *testing.T) *Mibs {
+	m := NewMibs()
+	require.NoError(t, m.LoadPath([]string{"testdata"}, testutil.Logger{}))
+	return m
+}
+
+func TestTranslate(t *testing.T) {
+	m := loadTestMibs(t)
+
+	tests := []struct {
+		oid    string
+		module string
+		name   string
+		numOID string
+	}{
+		{"TEST-MIB::testName.0", "TEST-MIB", "testName.0", ".1.3.6.1.4.1.99999.1.1.0"},
+		{"testDescr", "TEST-MIB", "testDescr", ".1.3.6.1.4.1.99999.1.2.1.2"},
+		{".1.3.6.1.4.1.99999.1.2.1.2.7", "TEST-MIB", "testDescr.7", ".1.3.6.1.4.1.99999.1.2.1.2.7"},
+		{".iso.3.6.1.4.1.99999", "TEST-MIB", "testMIB", ".1.3.6.1.4.1.99999"},
+		{".1.3.6.1.2.1", "SNMPv2-SMI", "mib-2", ".1.3.6.1.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.oid, func(t *testing.T) {
+			tr, err := m.Translate(tt.oid)
+			require.NoError(t, err)
+			require.Equal(t, tt.module, tr.Module)
+			require.Equal(t, tt.name, tr.Name)
+			require.Equal(t, tt.numOID, tr.OID)
+		})
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	m := loadTestMibs(t)
+
+	for _, oid := range []string{"unknownObject", "NO-MIB::testName", "testDescr.foo", ".5.1", ""} {
+		_, err := m.Translate(oid)
+		require.Error(t, err, oid)
+	}
+}
+
+func TestTranslateBelowBuiltin(t *testing.T) {
+	m := loadTestMibs(t)
+
+	// Objects of modules which are not loaded are not named after the nodes
+	// of the builtin modules.
+	for _, oid := range []string{".1.3.6.1.2.1.1.3.0", ".1.3.6.1.2.1.2.2.1.2.1", "enterprises.99998.1", ".1.5"} {
+		_, err := m.Translate(oid)
+		require.Error(t, err, oid)
+		require.Contains(t, err.Error(), ErrNotFound.Error())
+	}
+
+	// A loaded module replaces the builtin one.
+	require.NoError(t, m.LoadModule(`
+SNMPv2-SMI DEFINITIONS ::= BEGIN
+org            OBJECT IDENTIFIER ::= { iso 3 }
+dod            OBJECT IDENTIFIER ::= { org 6 }
+internet       OBJECT IDENTIFIER ::= { dod 1 }
+private        OBJECT IDENTIFIER ::= { internet 4 }
+enterprises    OBJECT IDENTIFIER ::= { private 1 }
+END
+`))
+	tr, err := m.Translate("enterprises.99998.1")
+	require.NoError(t, err)
+	require.Equal(t, "enterprises.99998.1", tr.Name)
+}
+
+func TestSyntax(t *testing.T) {
+	m := loadTestMibs(t)
+
+	n, err := m.Lookup("TEST-MIB", "testName")
+	require.NoError(t, err)
+	require.Equal(t, "OCTET STRING", n.Syntax)
+	require.Equal(t, []string{"TestName", "DisplayString"}, n.TextualConventions)
+	require.Equal(t, "32a", n.DisplayHint)
+
+	n, err = m.Lookup("", "testMac")
+	require.NoError(t, err)
+	require.Equal(t, "MacAddress", n.TextualConvention())
+
+	n, err = m.Lookup("", "testStatus")
+	require.NoError(t, err)
+	require.Equal(t, "INTEGER", n.Syntax)
+	require.Equal(t, map[int64]string{1: "up", 2: "down"}, n.Enums)
+
+	n, err = m.Lookup("", "testPackets")
+	require.NoError(t, err)
+	require.Equal(t, "Counter32", n.Syntax)
+	require.Empty(t, n.TextualConvention())
+}
+
+func TestTable(t *testing.T) {
+	m := loadTestMibs(t)
+
+	table, err := m.Lookup("TEST-MIB", "testTable")
+	require.NoError(t, err)
+	entries := table.Children()
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	require.Equal(t, "testEntry", entry.Name)
+	require.Equal(t, []string{"testIndex"}, entry.Index)
+	require.Equal(t, table, entry.Parent())
+
+	var columns []string
+	for _, c := range entry.Children() {
+		columns = append(columns, c.Name)
+	}
+	require.Equal(t, []string{"testIndex", "testDescr", "testMac", "testChanged", "testStatus", "testPackets"}, columns)
+
+	ext, err := m.Lookup("TEST-MIB", "testExtEntry")
+	require.NoError(t, err)
+	require.Equal(t, []string{"testIndex"}, ext.Index)
+}
+
+func TestLoadModule(t *testing.T) {
+	m := NewMibs()
+	require.NoError(t, m.LoadModule(`
+OTHER-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM RFC1155-SMI;
+other OBJECT IDENTIFIER ::= { enterprises 1 2 }
+otherTrap TRAP-TYPE
+    ENTERPRISE other
+    DESCRIPTION "A trap."
+    ::= 5
+END
+`))
+
+	tr, err := m.Translate("OTHER-MIB::other")
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.4.1.1.2", tr.OID)
+
+	tr, err = m.Translate("otherTrap")
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.4.1.1.2.0.5", tr.OID)
+
+	require.Error(t, m.LoadModule("not a module"))
+}
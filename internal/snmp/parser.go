@@ -0,0 +1,543 @@
+package snmp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// oidComponent is a component of an OID value, e.g. "ifEntry", "2" or
+// "mib-2(1)".  Either the name or the number, or both, are set.
+type oidComponent struct {
+	name      string
+	number    uint32
+	hasNumber bool
+}
+
+// typeRef is the syntax of an object or the definition of a type.
+type typeRef struct {
+	// name of the base type, e.g. "OCTET STRING", or of the referenced type
+	name string
+	// entry type of a "SEQUENCE OF" table
+	sequenceOf string
+	// named numbers of an enumeration or the bits of BITS
+	enums map[int64]string
+}
+
+// definition is an assignment of a module defining an OID, e.g. an
+// OBJECT-TYPE or an OBJECT IDENTIFIER.
+type definition struct {
+	name   string
+	kind   string
+	value  []oidComponent
+	syntax *typeRef
+	// names of the INDEX objects of a table entry
+	index []string
+	// table entry augmented by a table entry
+	augments string
+	// enterprise of a SMIv1 trap, the value is the trap number
+	enterprise string
+	line       int
+}
+
+// typeDefinition is a TEXTUAL-CONVENTION or a type assignment.
+type typeDefinition struct {
+	name        string
+	syntax      *typeRef
+	displayHint string
+	// textualConvention is true for TEXTUAL-CONVENTION definitions
+	textualConvention bool
+}
+
+// module is a parsed MIB module.
+type module struct {
+	name string
+	// imports maps the imported names to their modules
+	imports     map[string]string
+	definitions []*definition
+	types       map[string]*typeDefinition
+}
+
+// macros defining an OID value.
+var oidMacros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+	"TRAP-TYPE":          true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseModule parses a MIB module.
+func parseModule(src string) (*module, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.module()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(value string) bool {
+	t := p.peek()
+	return (t.kind == tokenSymbol || t.kind == tokenIdentifier) && t.value == value
+}
+
+func (p *parser) accept(value string) bool {
+	if p.is(value) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if t := p.next(); t.value != value || (t.kind != tokenSymbol && t.kind != tokenIdentifier) {
+		return fmt.Errorf("line %d: found %s, expecting %q", t.line, t, value)
+	}
+	return nil
+}
+
+func (p *parser) identifier() (string, error) {
+	t := p.next()
+	if t.kind != tokenIdentifier {
+		return "", fmt.Errorf("line %d: found %s, expecting an identifier", t.line, t)
+	}
+	return t.value, nil
+}
+
+// skipBalanced skips the tokens up to the closing bracket matching the opening
+// bracket at the current position.
+func (p *parser) skipBalanced(open, close string) error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: missing %q", t.line, close)
+		case t.kind == tokenSymbol && t.value == open:
+			depth++
+		case t.kind == tokenSymbol && t.value == close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) module() (*module, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		// module OID of ASN.1 modules
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	for !p.is("::=") && p.peek().kind != tokenEOF {
+		// e.g. IMPLICIT TAGS
+		p.next()
+	}
+	if err := p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	m := &module{
+		name:    name,
+		imports: make(map[string]string),
+		types:   make(map[string]*typeDefinition),
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("line %d: missing END of module %s", t.line, name)
+		case p.is("END"):
+			return m, nil
+		case p.accept("IMPORTS"):
+			if err := p.imports(m); err != nil {
+				return nil, err
+			}
+		case p.accept("EXPORTS"):
+			for !p.accept(";") {
+				if p.next().kind == tokenEOF {
+					return nil, fmt.Errorf("line %d: missing ';' after EXPORTS", t.line)
+				}
+			}
+		case t.kind == tokenIdentifier:
+			if err := p.assignment(m); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s", t.line, t)
+		}
+	}
+}
+
+func (p *parser) imports(m *module) error {
+	var names []string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: missing ';' after IMPORTS", t.line)
+		case t.kind == tokenSymbol && t.value == ";":
+			return nil
+		case t.kind == tokenSymbol && t.value == ",":
+		case t.kind == tokenIdentifier && t.value == "FROM":
+			from, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			for _, n := range names {
+				m.imports[n] = from
+			}
+			names = names[:0]
+		case t.kind == tokenIdentifier:
+			names = append(names, t.value)
+		}
+	}
+}
+
+// assignment parses a definition of the module body.
+func (p *parser) assignment(m *module) error {
+	start := p.next()
+	name := start.value
+
+	switch {
+	case p.is("MACRO"):
+		// macro definitions, e.g. OBJECT-TYPE in SNMPv2-SMI, end with END
+		for !p.accept("END") {
+			if p.next().kind == tokenEOF {
+				return fmt.Errorf("line %d: missing END of macro %s", start.line, name)
+			}
+		}
+		return nil
+	case p.is("OBJECT") && p.peekAt(1).value == "IDENTIFIER":
+		p.next()
+		p.next()
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		value, err := p.oidValue()
+		if err != nil {
+			return err
+		}
+		m.definitions = append(m.definitions, &definition{
+			name:  name,
+			kind:  "OBJECT IDENTIFIER",
+			value: value,
+			line:  start.line,
+		})
+		return nil
+	case p.peek().kind == tokenIdentifier && oidMacros[p.peek().value]:
+		return p.macro(m, name, p.next().value, start.line)
+	case p.is("::=") && p.peekAt(1).value == "{":
+		// OID value without type, accepted by net-snmp
+		p.next()
+		value, err := p.oidValue()
+		if err != nil {
+			return err
+		}
+		m.definitions = append(m.definitions, &definition{
+			name:  name,
+			kind:  "OBJECT IDENTIFIER",
+			value: value,
+			line:  start.line,
+		})
+		return nil
+	case p.accept("::="):
+		return p.typeAssignment(m, name)
+	}
+
+	// value assignments, e.g. "maxValue INTEGER ::= 10", are skipped
+	for !p.accept("::=") {
+		if p.next().kind == tokenEOF {
+			return fmt.Errorf("line %d: unexpected end of file after %q", start.line, name)
+		}
+	}
+	return p.skipValue()
+}
+
+// skipValue skips the value of a value assignment.
+func (p *parser) skipValue() error {
+	if p.is("{") {
+		return p.skipBalanced("{", "}")
+	}
+	p.next()
+	return nil
+}
+
+// macro parses an invocation of a macro defining an OID value.
+func (p *parser) macro(m *module, name, kind string, line int) error {
+	def := &definition{name: name, kind: kind, line: line}
+	for !p.accept("::=") {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: missing value of %s", line, name)
+		case p.is("{"):
+			if err := p.skipBalanced("{", "}"); err != nil {
+				return err
+			}
+		case kind == "OBJECT-TYPE" && def.syntax == nil && p.accept("SYNTAX"):
+			syntax, err := p.typeRef()
+			if err != nil {
+				return err
+			}
+			def.syntax = syntax
+		case kind == "OBJECT-TYPE" && p.accept("INDEX"):
+			index, err := p.nameList()
+			if err != nil {
+				return err
+			}
+			def.index = index
+		case kind == "OBJECT-TYPE" && p.accept("AUGMENTS"):
+			augments, err := p.nameList()
+			if err != nil {
+				return err
+			}
+			if len(augments) > 0 {
+				def.augments = augments[0]
+			}
+		case kind == "TRAP-TYPE" && p.accept("ENTERPRISE"):
+			enterprise, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			def.enterprise = enterprise
+		default:
+			p.next()
+		}
+	}
+
+	if kind == "TRAP-TYPE" {
+		t := p.next()
+		number, err := strconv.ParseUint(t.value, 10, 32)
+		if t.kind != tokenNumber || err != nil {
+			return fmt.Errorf("line %d: found %s, expecting a trap number", t.line, t)
+		}
+		def.value = []oidComponent{
+			{name: def.enterprise},
+			{number: 0, hasNumber: true},
+			{number: uint32(number), hasNumber: true},
+		}
+	} else {
+		value, err := p.oidValue()
+		if err != nil {
+			return err
+		}
+		def.value = value
+	}
+	m.definitions = append(m.definitions, def)
+	return nil
+}
+
+// typeAssignment parses a TEXTUAL-CONVENTION or a type assignment following
+// "Name ::=".
+func (p *parser) typeAssignment(m *module, name string) error {
+	if !p.accept("TEXTUAL-CONVENTION") {
+		syntax, err := p.typeRef()
+		if err != nil {
+			return err
+		}
+		m.types[name] = &typeDefinition{name: name, syntax: syntax}
+		return nil
+	}
+
+	def := &typeDefinition{name: name, textualConvention: true}
+	for def.syntax == nil {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("line %d: missing SYNTAX of %s", t.line, name)
+		case p.accept("DISPLAY-HINT"):
+			if t := p.next(); t.kind == tokenString {
+				def.displayHint = t.value
+			}
+		case p.accept("SYNTAX"):
+			syntax, err := p.typeRef()
+			if err != nil {
+				return err
+			}
+			def.syntax = syntax
+		default:
+			p.next()
+		}
+	}
+	m.types[name] = def
+	return nil
+}
+
+// typeRef parses a type, e.g. "INTEGER { up(1), down(2) }", "OCTET STRING
+// (SIZE (0..255))" or "SEQUENCE OF IfEntry".
+func (p *parser) typeRef() (*typeRef, error) {
+	if p.is("[") {
+		// tagged types of SMI, e.g. [APPLICATION 1] IMPLICIT INTEGER
+		if err := p.skipBalanced("[", "]"); err != nil {
+			return nil, err
+		}
+		p.accept("IMPLICIT")
+		p.accept("EXPLICIT")
+	}
+
+	ref := &typeRef{}
+	t := p.next()
+	if t.kind != tokenIdentifier {
+		return nil, fmt.Errorf("line %d: found %s, expecting a type", t.line, t)
+	}
+	switch {
+	case t.value == "OCTET" && p.accept("STRING"):
+		ref.name = "OCTET STRING"
+	case t.value == "OBJECT" && p.accept("IDENTIFIER"):
+		ref.name = "OBJECT IDENTIFIER"
+	case t.value == "SEQUENCE" && p.accept("OF"):
+		entry, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		ref.name = "SEQUENCE OF"
+		ref.sequenceOf = entry
+		return ref, nil
+	case t.value == "SEQUENCE" || t.value == "CHOICE":
+		ref.name = t.value
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+		return ref, nil
+	default:
+		ref.name = t.value
+	}
+
+	if p.is("{") {
+		enums, err := p.namedNumbers()
+		if err != nil {
+			return nil, err
+		}
+		ref.enums = enums
+	}
+	if p.is("(") {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	return ref, nil
+}
+
+// namedNumbers parses an enumeration like "{ up(1), down(2) }".
+func (p *parser) namedNumbers() (map[int64]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	enums := make(map[int64]string)
+	for !p.accept("}") {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("line %d: missing '}'", t.line)
+		case t.kind == tokenIdentifier && p.accept("("):
+			n := p.next()
+			v, err := strconv.ParseInt(n.value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: found %s, expecting a number", n.line, n)
+			}
+			enums[v] = t.value
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return enums, nil
+}
+
+// nameList parses a list of names like "{ ifIndex, IMPLIED ifName }".
+func (p *parser) nameList() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for !p.accept("}") {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("line %d: missing '}'", t.line)
+		case t.kind == tokenIdentifier && t.value != "IMPLIED":
+			names = append(names, t.value)
+		}
+	}
+	return names, nil
+}
+
+// oidValue parses an OID value like "{ iso org(3) dod(6) 1 }".
+func (p *parser) oidValue() ([]oidComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var value []oidComponent
+	for !p.accept("}") {
+		t := p.next()
+		switch t.kind {
+		case tokenNumber:
+			n, err := strconv.ParseUint(t.value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid sub-identifier %s", t.line, t)
+			}
+			value = append(value, oidComponent{number: uint32(n), hasNumber: true})
+		case tokenIdentifier:
+			c := oidComponent{name: t.value}
+			if p.accept("(") {
+				n := p.next()
+				number, err := strconv.ParseUint(n.value, 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid sub-identifier %s", n.line, n)
+				}
+				c.number, c.hasNumber = uint32(number), true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			value = append(value, c)
+		default:
+			return nil, fmt.Errorf("line %d: found %s in OID value", t.line, t)
+		}
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("empty OID value")
+	}
+	return value, nil
+}
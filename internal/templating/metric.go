@@ -0,0 +1,120 @@
+package templating
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// MetricTemplate renders a Go template from the name, tags and time of a
+// metric, for example to choose the destination of the metric in an output.
+//
+// The template can use {{.Name}}, {{.Tag "key"}}, {{.Tags}} and {{.Time}}; a
+// missing tag renders as the empty string.
+type MetricTemplate struct {
+	text   string
+	tmpl   *template.Template
+	escape func(string) string
+}
+
+// MetricGroup is a group of metrics rendering the same value.
+type MetricGroup struct {
+	Value   string
+	Metrics []telegraf.Metric
+}
+
+// templateMetric is the data passed to the template.
+type templateMetric struct {
+	metric telegraf.Metric
+	escape func(string) string
+}
+
+func (m templateMetric) Name() string {
+	return m.escape(m.metric.Name())
+}
+
+func (m templateMetric) Tag(key string) string {
+	value, _ := m.metric.GetTag(key)
+	return m.escape(value)
+}
+
+func (m templateMetric) Tags() map[string]string {
+	tags := make(map[string]string, len(m.metric.TagList()))
+	for _, tag := range m.metric.TagList() {
+		tags[m.escape(tag.Key)] = m.escape(tag.Value)
+	}
+	return tags
+}
+
+func (m templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
+
+// NewMetricTemplate parses the template text.
+func NewMetricTemplate(text string) (*MetricTemplate, error) {
+	tmpl, err := template.New("metric").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricTemplate{text: text, tmpl: tmpl, escape: noEscape}, nil
+}
+
+// SetEscape sets a function applied to the name, the tag keys and the tag
+// values of the metrics when rendering, for example to keep them from adding
+// path separators to a file name.
+func (t *MetricTemplate) SetEscape(escape func(string) string) {
+	t.escape = escape
+}
+
+func noEscape(s string) string {
+	return s
+}
+
+// IsMetricTemplate returns true if the text contains template actions, it is
+// used by options accepting both static values and templates.
+func IsMetricTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// String returns the template text.
+func (t *MetricTemplate) String() string {
+	return t.text
+}
+
+// Render executes the template for the metric.
+func (t *MetricTemplate) Render(metric telegraf.Metric) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, templateMetric{metric: metric, escape: t.escape}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Group renders the template for each metric and groups the metrics by the
+// rendered value, in the order of the first appearance of the values.
+// Metrics the template fails for are not part of any group, the last error
+// is returned along with the groups.
+func (t *MetricTemplate) Group(metrics []telegraf.Metric) ([]MetricGroup, error) {
+	var groups []MetricGroup
+	var lastErr error
+	index := make(map[string]int)
+	for _, metric := range metrics {
+		value, err := t.Render(metric)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, MetricGroup{Value: value})
+		}
+		groups[i].Metrics = append(groups[i].Metrics, metric)
+	}
+	return groups, lastErr
+}
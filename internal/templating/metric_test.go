@@ -0,0 +1,113 @@
+package templating
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricTemplateRender(t *testing.T) {
+	metric := testutil.MustMetric("cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{"value": 1.0},
+		time.Date(2021, 4, 13, 10, 0, 0, 0, time.UTC),
+	)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "static",
+			text:     "telegraf",
+			expected: "telegraf",
+		},
+		{
+			name:     "name and tag",
+			text:     `{{.Name}}/{{.Tag "host"}}`,
+			expected: "cpu/a",
+		},
+		{
+			name:     "missing tag",
+			text:     `{{.Name}}-{{.Tag "missing"}}-{{.Tags.missing}}`,
+			expected: "cpu--",
+		},
+		{
+			name:     "tags",
+			text:     `{{range $k, $v := .Tags}}{{$k}}={{$v}};{{end}}`,
+			expected: "cpu=cpu0;host=a;",
+		},
+		{
+			name:     "time",
+			text:     `{{.Time.Format "2006-01-02"}}`,
+			expected: "2021-04-13",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewMetricTemplate(tt.text)
+			require.NoError(t, err)
+			require.Equal(t, tt.text, tmpl.String())
+
+			actual, err := tmpl.Render(metric)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestMetricTemplateGroup(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+
+	tmpl, err := NewMetricTemplate(`{{.Tag "host"}}`)
+	require.NoError(t, err)
+	groups, err := tmpl.Group(metrics)
+	require.NoError(t, err)
+	require.Equal(t, []MetricGroup{
+		{Value: "b", Metrics: []telegraf.Metric{metrics[0], metrics[2]}},
+		{Value: "a", Metrics: []telegraf.Metric{metrics[1]}},
+	}, groups)
+}
+
+func TestMetricTemplateErrors(t *testing.T) {
+	_, err := NewMetricTemplate("{{.Name")
+	require.Error(t, err)
+
+	tmpl, err := NewMetricTemplate("{{.Unknown}}")
+	require.NoError(t, err)
+	groups, err := tmpl.Group([]telegraf.Metric{testutil.TestMetric(1.0)})
+	require.Error(t, err)
+	require.Empty(t, groups)
+}
+
+func TestIsMetricTemplate(t *testing.T) {
+	require.True(t, IsMetricTemplate("/tmp/{{.Name}}.out"))
+	require.False(t, IsMetricTemplate("/tmp/metrics.out"))
+}
+
+func TestMetricTemplateEscape(t *testing.T) {
+	metric := testutil.MustMetric("cpu",
+		map[string]string{"host": "../a"},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(0, 0),
+	)
+
+	tmpl, err := NewMetricTemplate(`{{.Name}}/{{.Tag "host"}}/{{range $k, $v := .Tags}}{{$k}}={{$v}}{{end}}`)
+	require.NoError(t, err)
+	tmpl.SetEscape(func(s string) string {
+		return strings.Replace(s, "/", "_", -1)
+	})
+
+	actual, err := tmpl.Render(metric)
+	require.NoError(t, err)
+	require.Equal(t, "cpu/.._a/host=.._a", actual)
+}
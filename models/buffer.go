@@ -237,6 +237,17 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 	b.BufferSize.Set(int64(b.length()))
 }
 
+// Release removes the batch, acquired from Batch(), from the buffer without
+// marking it as written or dropped.  The caller takes ownership of the
+// metrics, for example to add them to the buffer of another output.
+func (b *Buffer) Release(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
 // dist returns the distance between two indexes.  Because this data structure
 // uses a half open range the arguments must both either left side or right
 // side pairs.
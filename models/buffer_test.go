@@ -509,6 +509,28 @@ func TestBuffer_RejectLeavesBatch(t *testing.T) {
 	require.Equal(t, 3, b.Len())
 }
 
+func TestBuffer_ReleaseRemovesBatch(t *testing.T) {
+	var accept, reject int
+	mm := &MockMetric{
+		Metric: Metric(),
+		AcceptF: func() {
+			accept++
+		},
+		RejectF: func() {
+			reject++
+		},
+	}
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(mm, mm, mm)
+	batch := b.Batch(2)
+	b.Release(batch)
+	require.Equal(t, 1, b.Len())
+	require.Equal(t, 0, accept)
+	require.Equal(t, 0, reject)
+	require.Equal(t, int64(0), b.MetricsWritten.Get())
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+}
+
 func TestBuffer_AcceptWritesOverwrittenBatch(t *testing.T) {
 	m := Metric()
 	b := setup(NewBuffer("test", "", 5))
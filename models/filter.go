@@ -2,9 +2,12 @@ package models
 
 import (
 	"fmt"
+	"log"
+	"sync/atomic"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/expr"
 )
 
 // TagFilter is the name of a tag, and the values on which to filter
@@ -34,6 +37,12 @@ type Filter struct {
 	TagInclude []string
 	tagInclude filter.Filter
 
+	MetricPass string
+	metricPass *expr.Expression
+	// evalErrorLogged is set once an evaluation error of metricPass was
+	// logged, so a failing expression does not flood the log.
+	evalErrorLogged int32
+
 	isActive bool
 }
 
@@ -46,7 +55,8 @@ func (f *Filter) Compile() error {
 		len(f.TagInclude) == 0 &&
 		len(f.TagExclude) == 0 &&
 		len(f.TagPass) == 0 &&
-		len(f.TagDrop) == 0 {
+		len(f.TagDrop) == 0 &&
+		f.MetricPass == "" {
 		return nil
 	}
 
@@ -91,11 +101,19 @@ func (f *Filter) Compile() error {
 			return fmt.Errorf("Error compiling 'tagpass', %s", err)
 		}
 	}
+
+	if f.MetricPass != "" {
+		f.metricPass, err = expr.Compile(f.MetricPass)
+		if err != nil {
+			return fmt.Errorf("Error compiling 'metricpass', %s", err)
+		}
+	}
 	return nil
 }
 
 // Select returns true if the metric matches according to the
-// namepass/namedrop and tagpass/tagdrop filters.  The metric is not modified.
+// namepass/namedrop, tagpass/tagdrop and metricpass filters.  The metric is
+// not modified.
 func (f *Filter) Select(metric telegraf.Metric) bool {
 	if !f.isActive {
 		return true
@@ -109,6 +127,10 @@ func (f *Filter) Select(metric telegraf.Metric) bool {
 		return false
 	}
 
+	if !f.shouldMetricPass(metric) {
+		return false
+	}
+
 	return true
 }
 
@@ -219,6 +241,26 @@ func (f *Filter) shouldTagsPass(tags []*telegraf.Tag) bool {
 	return true
 }
 
+// shouldMetricPass returns true if the metric should pass, false if should drop
+// based on the metricpass expression.  Metrics for which the expression
+// cannot be evaluated, for example because a referenced field is missing,
+// are dropped.  The first evaluation error is logged at debug level.
+func (f *Filter) shouldMetricPass(metric telegraf.Metric) bool {
+	if f.metricPass == nil {
+		return true
+	}
+
+	pass, err := f.metricPass.EvalBool(metric)
+	if err != nil {
+		if atomic.CompareAndSwapInt32(&f.evalErrorLogged, 0, 1) {
+			log.Printf("D! Dropping %q metric as metricpass %q cannot be evaluated: %v; further errors of this expression are not logged",
+				metric.Name(), f.MetricPass, err)
+		}
+		return false
+	}
+	return pass
+}
+
 // filterFields removes fields according to fieldpass/fielddrop.
 func (f *Filter) filterFields(metric telegraf.Metric) {
 	filterKeys := []string{}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -523,3 +527,140 @@ func BenchmarkFilter(b *testing.B) {
 		})
 	}
 }
+
+func TestFilter_MetricPass(t *testing.T) {
+	f := Filter{
+		MetricPass: `!(name == "cpu" && fields.usage_idle > 99)`,
+	}
+	require.NoError(t, f.Compile())
+	require.True(t, f.IsActive())
+
+	tests := []struct {
+		name     string
+		metric   telegraf.Metric
+		expected bool
+	}{
+		{
+			name: "idle cpu is dropped",
+			metric: testutil.MustMetric("cpu",
+				map[string]string{},
+				map[string]interface{}{"usage_idle": 99.5},
+				time.Now()),
+			expected: false,
+		},
+		{
+			name: "busy cpu passes",
+			metric: testutil.MustMetric("cpu",
+				map[string]string{},
+				map[string]interface{}{"usage_idle": 20.0},
+				time.Now()),
+			expected: true,
+		},
+		{
+			name: "other measurement passes",
+			metric: testutil.MustMetric("mem",
+				map[string]string{},
+				map[string]interface{}{"used": int64(42)},
+				time.Now()),
+			expected: true,
+		},
+		{
+			name: "missing field is dropped",
+			metric: testutil.MustMetric("cpu",
+				map[string]string{},
+				map[string]interface{}{"usage_user": 20.0},
+				time.Now()),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, f.Select(tt.metric))
+		})
+	}
+}
+
+func TestFilter_MetricPassWithNamePass(t *testing.T) {
+	f := Filter{
+		NamePass:   []string{"http_*"},
+		MetricPass: `fields.status_code >= 500`,
+	}
+	require.NoError(t, f.Compile())
+
+	m := testutil.MustMetric("http_response",
+		map[string]string{},
+		map[string]interface{}{"status_code": int64(503)},
+		time.Now())
+	require.True(t, f.Select(m))
+
+	m = testutil.MustMetric("http_response",
+		map[string]string{},
+		map[string]interface{}{"status_code": int64(200)},
+		time.Now())
+	require.False(t, f.Select(m))
+
+	m = testutil.MustMetric("proxy",
+		map[string]string{},
+		map[string]interface{}{"status_code": int64(503)},
+		time.Now())
+	require.False(t, f.Select(m))
+}
+
+func TestFilter_MetricPassLogsEvalErrorOnce(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	f := Filter{
+		MetricPass: `fields.usage_idle > 99`,
+	}
+	require.NoError(t, f.Compile())
+
+	m := testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"usage_user": 20.0},
+		time.Now())
+	require.False(t, f.Select(m))
+	require.False(t, f.Select(m))
+
+	require.Equal(t, 1, strings.Count(buf.String(), "D! Dropping \"cpu\" metric"))
+}
+
+func TestFilter_MetricPassCompileError(t *testing.T) {
+	f := Filter{
+		MetricPass: `fields.value >`,
+	}
+	require.Error(t, f.Compile())
+}
+
+func TestFilter_TagPassRegexAndNegation(t *testing.T) {
+	f := Filter{
+		NamePass: []string{"re:^disk(io)?$"},
+		TagPass: []TagFilter{
+			{
+				Name:   "path",
+				Filter: []string{"!/var/lib/docker/*"},
+			},
+		},
+	}
+	require.NoError(t, f.Compile())
+
+	m := testutil.MustMetric("disk",
+		map[string]string{"path": "/"},
+		map[string]interface{}{"free": int64(1)},
+		time.Now())
+	require.True(t, f.Select(m))
+
+	m = testutil.MustMetric("disk",
+		map[string]string{"path": "/var/lib/docker/overlay2"},
+		map[string]interface{}{"free": int64(1)},
+		time.Now())
+	require.False(t, f.Select(m))
+
+	m = testutil.MustMetric("disks",
+		map[string]string{"path": "/"},
+		map[string]interface{}{"free": int64(1)},
+		time.Now())
+	require.False(t, f.Select(m))
+}
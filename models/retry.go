@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// Default delay before retrying to connect if no backoff is configured.
+	DEFAULT_CONNECT_RETRY_DELAY = 15 * time.Second
+
+	// Default number of connection attempts.
+	DEFAULT_CONNECT_ATTEMPTS = 2
+
+	// Default factor the backoff grows by after each failure.
+	DEFAULT_RETRY_MULTIPLIER = 2.0
+
+	// Default upper limit of the backoff.
+	DEFAULT_RETRY_MAX_BACKOFF = 5 * time.Minute
+
+	// Default time the circuit breaker stays open.
+	DEFAULT_CIRCUIT_BREAKER_TIMEOUT = time.Minute
+)
+
+// RetryConfig is the retry policy of an output for connecting and writing.
+type RetryConfig struct {
+	// Backoff after the first failure, zero to retry a write at the next
+	// flush and a connect after DEFAULT_CONNECT_RETRY_DELAY.
+	InitialBackoff time.Duration
+	// Upper limit of the backoff.
+	MaxBackoff time.Duration
+	// Factor the backoff grows by after each consecutive failure.
+	Multiplier float64
+	// Fraction of the backoff randomly subtracted from it, between 0 and 1.
+	Jitter float64
+	// Number of connection attempts before giving up.
+	ConnectAttempts int
+
+	// Number of consecutive write failures opening the circuit breaker, zero
+	// to disable the circuit breaker.
+	CircuitBreakerThreshold int
+	// Time the open circuit breaker skips writes before allowing a trial
+	// write.
+	CircuitBreakerTimeout time.Duration
+}
+
+// backoff returns the delay after the number of consecutive failures, before
+// jitter is applied.
+func (c *RetryConfig) backoff(failures int) time.Duration {
+	if c.InitialBackoff <= 0 || failures <= 0 {
+		return 0
+	}
+
+	multiplier := c.Multiplier
+	if multiplier < 1 {
+		multiplier = DEFAULT_RETRY_MULTIPLIER
+	}
+	max := c.MaxBackoff
+	if max <= 0 {
+		max = DEFAULT_RETRY_MAX_BACKOFF
+	}
+
+	backoff := float64(c.InitialBackoff) * math.Pow(multiplier, float64(failures-1))
+	if backoff >= float64(max) {
+		return max
+	}
+	return time.Duration(backoff)
+}
+
+// jittered returns the backoff after the number of consecutive failures with
+// a random part of up to Jitter subtracted.
+func (c *RetryConfig) jittered(failures int) time.Duration {
+	backoff := c.backoff(failures)
+	if c.Jitter <= 0 {
+		return backoff
+	}
+	jitter := math.Min(c.Jitter, 1)
+	return backoff - internal.RandomDuration(time.Duration(jitter*float64(backoff)))
+}
+
+// connectDelay returns the delay before the next connection attempt after the
+// number of failed attempts.
+func (c *RetryConfig) connectDelay(failures int) time.Duration {
+	if c.InitialBackoff <= 0 {
+		return DEFAULT_CONNECT_RETRY_DELAY
+	}
+	return c.jittered(failures)
+}
+
+// connectAttempts returns the number of connection attempts.
+func (c *RetryConfig) connectAttempts() int {
+	if c.ConnectAttempts <= 0 {
+		return DEFAULT_CONNECT_ATTEMPTS
+	}
+	return c.ConnectAttempts
+}
+
+// retryState tracks the consecutive write failures of an output to back off
+// and to open the circuit breaker.
+type retryState struct {
+	sync.Mutex
+	config *RetryConfig
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+
+	failures    int
+	nextAttempt time.Time
+	open        bool
+}
+
+// allow returns an error if a write must not be attempted now.
+func (s *retryState) allow() error {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	if !now.Before(s.nextAttempt) {
+		return nil
+	}
+	if s.open {
+		return fmt.Errorf("circuit breaker open after %d failures, next attempt in %s",
+			s.failures, s.nextAttempt.Sub(now))
+	}
+	return fmt.Errorf("backing off after %d failures, next attempt in %s",
+		s.failures, s.nextAttempt.Sub(now))
+}
+
+// success resets the state after a successful write, it returns true if the
+// circuit breaker was open.
+func (s *retryState) success() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	wasOpen := s.open
+	s.failures = 0
+	s.nextAttempt = time.Time{}
+	s.open = false
+	return wasOpen
+}
+
+// failure records a failed write, it returns true if the circuit breaker
+// opened.
+func (s *retryState) failure() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	s.failures++
+	wasOpen := s.open
+	threshold := s.config.CircuitBreakerThreshold
+	s.open = threshold > 0 && s.failures >= threshold
+	if s.open {
+		timeout := s.config.CircuitBreakerTimeout
+		if timeout <= 0 {
+			timeout = DEFAULT_CIRCUIT_BREAKER_TIMEOUT
+		}
+		s.nextAttempt = now.Add(timeout)
+	} else {
+		s.nextAttempt = now.Add(s.config.jittered(s.failures))
+	}
+	return s.open && !wasOpen
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryConfigBackoff(t *testing.T) {
+	config := RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+	}
+	require.Equal(t, time.Duration(0), config.backoff(0))
+	require.Equal(t, time.Second, config.backoff(1))
+	require.Equal(t, 2*time.Second, config.backoff(2))
+	require.Equal(t, 8*time.Second, config.backoff(4))
+	require.Equal(t, 10*time.Second, config.backoff(5))
+	require.Equal(t, 10*time.Second, config.backoff(100))
+
+	config.Multiplier = 1.5
+	require.Equal(t, 2250*time.Millisecond, config.backoff(3))
+
+	require.Equal(t, time.Duration(0), (&RetryConfig{}).backoff(3))
+}
+
+func TestRetryConfigJitter(t *testing.T) {
+	config := RetryConfig{
+		InitialBackoff: time.Second,
+		Jitter:         0.5,
+	}
+	for i := 0; i < 100; i++ {
+		backoff := config.jittered(2)
+		require.True(t, backoff > time.Second && backoff <= 2*time.Second, backoff)
+	}
+}
+
+func TestRunningOutputWriteBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{
+		Retry: RetryConfig{InitialBackoff: 10 * time.Second},
+	}, 10, 100)
+	ro.retry.now = func() time.Time { return now }
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	m.failWrite = true
+	require.Error(t, ro.Write())
+
+	// Writes are skipped until the backoff elapsed.
+	m.failWrite = false
+	now = now.Add(5 * time.Second)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 0)
+	require.Equal(t, 1, ro.BufferLength())
+
+	now = now.Add(5 * time.Second)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+}
+
+func TestRunningOutputWriteNowIgnoresBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{
+		Retry: RetryConfig{InitialBackoff: time.Minute},
+	}, 10, 100)
+	ro.retry.now = func() time.Time { return now }
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	m.failWrite = true
+	require.Error(t, ro.WriteBatch())
+
+	m.failWrite = false
+	require.NoError(t, ro.WriteBatch())
+	require.Len(t, m.Metrics(), 0)
+	require.NoError(t, ro.WriteNow())
+	require.Len(t, m.Metrics(), 1)
+}
+
+func TestRunningOutputCircuitBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{
+		Retry: RetryConfig{
+			CircuitBreakerThreshold: 3,
+			CircuitBreakerTimeout:   time.Minute,
+		},
+	}, 10, 100)
+	ro.retry.now = func() time.Time { return now }
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	m.failWrite = true
+
+	// Without backoff each flush is attempted until the breaker opens.
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	require.NoError(t, ro.Write())
+	require.NoError(t, ro.Write())
+
+	// After the timeout a trial write is attempted, opening the breaker again
+	// on failure.
+	now = now.Add(time.Minute)
+	require.Error(t, ro.Write())
+	require.NoError(t, ro.Write())
+
+	m.failWrite = false
+	now = now.Add(time.Minute)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+	require.False(t, ro.retry.open)
+	require.Equal(t, 0, ro.retry.failures)
+}
+
+type connectOutput struct {
+	mockOutput
+	failures int
+	connects int
+}
+
+func (m *connectOutput) Connect() error {
+	m.connects++
+	if m.connects <= m.failures {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func TestRunningOutputConnectRetry(t *testing.T) {
+	m := &connectOutput{failures: 2}
+	ro := NewRunningOutput("test", m, &OutputConfig{
+		Retry: RetryConfig{
+			InitialBackoff:  time.Millisecond,
+			ConnectAttempts: 3,
+		},
+	}, 10, 100)
+	require.NoError(t, ro.Connect(context.Background()))
+	require.Equal(t, 3, m.connects)
+
+	m = &connectOutput{failures: 3}
+	ro = NewRunningOutput("test", m, &OutputConfig{
+		Retry: RetryConfig{
+			InitialBackoff:  time.Millisecond,
+			ConnectAttempts: 3,
+		},
+	}, 10, 100)
+	require.Error(t, ro.Connect(context.Background()))
+	require.Equal(t, 3, m.connects)
+}
+
+func TestRunningOutputConnectCanceled(t *testing.T) {
+	m := &connectOutput{failures: 1}
+	ro := NewRunningOutput("test", m, &OutputConfig{}, 10, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, ro.Connect(ctx))
+	require.Equal(t, 1, m.connects)
+}
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -15,6 +18,9 @@ const (
 
 	// Default number of metrics kept. It should be a multiple of batch size.
 	DEFAULT_METRIC_BUFFER_LIMIT = 10000
+
+	// Default time a failed member of a failover group is skipped.
+	DEFAULT_FAILBACK_INTERVAL = time.Minute
 )
 
 // OutputConfig containing name and filter
@@ -31,6 +37,21 @@ type OutputConfig struct {
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
+
+	// Name of the failover group of the output, empty if the output is not
+	// part of a group.
+	FailoverGroup string
+	// Number of buffered metrics above which the group routes new metrics
+	// to the next member, zero to disable.
+	FailoverBufferThreshold int
+	// Time the group skips the output after a failed write.
+	FailbackInterval time.Duration
+
+	// DeadLetter marks the output receiving the metrics other outputs
+	// rejected permanently, instead of the metrics of the inputs.
+	DeadLetter bool
+
+	Retry RetryConfig
 }
 
 // RunningOutput contains the output configuration
@@ -38,6 +59,7 @@ type RunningOutput struct {
 	// Must be 64-bit aligned
 	newMetricsCount int64
 	droppedMetrics  int64
+	failedUntil     int64
 
 	Output            telegraf.Output
 	Config            *OutputConfig
@@ -45,12 +67,17 @@ type RunningOutput struct {
 	MetricBatchSize   int
 
 	MetricsFiltered selfstat.Stat
+	MetricsRejected selfstat.Stat
 	WriteTime       selfstat.Stat
 
 	BatchReady chan time.Time
 
 	buffer *Buffer
 	log    telegraf.Logger
+	group  *RunningOutputGroup
+	retry  *retryState
+
+	deadLetter *RunningOutput
 
 	aggMutex sync.Mutex
 }
@@ -99,12 +126,18 @@ func NewRunningOutput(
 			"metrics_filtered",
 			tags,
 		),
+		MetricsRejected: selfstat.Register(
+			"write",
+			"metrics_rejected",
+			tags,
+		),
 		WriteTime: selfstat.RegisterTiming(
 			"write",
 			"write_time_ns",
 			tags,
 		),
-		log: logger,
+		log:   logger,
+		retry: &retryState{config: &config.Retry, now: time.Now},
 	}
 
 	return ro
@@ -130,6 +163,29 @@ func (r *RunningOutput) Init() error {
 	return nil
 }
 
+// Connect connects the output, retrying with the backoff of the retry policy
+// until the connection attempts are exhausted or the context is done.
+func (ro *RunningOutput) Connect(ctx context.Context) error {
+	attempts := ro.Config.Retry.connectAttempts()
+	for attempt := 1; ; attempt++ {
+		ro.log.Debugf("Attempting connection")
+		err := ro.Output.Connect()
+		if err == nil {
+			ro.log.Debugf("Successfully connected")
+			return nil
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		delay := ro.Config.Retry.connectDelay(attempt)
+		ro.log.Errorf("Failed to connect, retrying in %s, error was '%s'", delay, err)
+		if err := internal.SleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
 // AddMetric adds a metric to the output.
 //
 // Takes ownership of metric
@@ -178,8 +234,19 @@ func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 }
 
 // Write writes all metrics to the output, stopping when all have been sent on
-// or error.
+// or error.  While backing off after failed writes or while the circuit
+// breaker is open no write is attempted.
 func (ro *RunningOutput) Write() error {
+	if err := ro.retry.allow(); err != nil {
+		ro.log.Debugf("Skipping write: %v", err)
+		return nil
+	}
+	return ro.WriteNow()
+}
+
+// WriteNow writes all metrics to the output like Write, ignoring the backoff
+// and the circuit breaker, for example for the last write on shutdown.
+func (ro *RunningOutput) WriteNow() error {
 	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
 		ro.aggMutex.Lock()
 		metrics := output.Push()
@@ -200,31 +267,157 @@ func (ro *RunningOutput) Write() error {
 			break
 		}
 
-		err := ro.write(batch)
-		if err != nil {
-			ro.buffer.Reject(batch)
+		if err := ro.writeBatch(batch); err != nil {
 			return err
 		}
-		ro.buffer.Accept(batch)
 	}
 	return nil
 }
 
 // WriteBatch writes a single batch of metrics to the output.
 func (ro *RunningOutput) WriteBatch() error {
+	if err := ro.retry.allow(); err != nil {
+		ro.log.Debugf("Skipping write: %v", err)
+		return nil
+	}
+
 	batch := ro.buffer.Batch(ro.MetricBatchSize)
 	if len(batch) == 0 {
 		return nil
 	}
 
-	err := ro.write(batch)
-	if err != nil {
-		ro.buffer.Reject(batch)
+	return ro.writeBatch(batch)
+}
+
+// writeBatch writes the batch, acquired from the buffer, and accepts or
+// rejects it.  Metrics rejected permanently by the output are removed from the
+// batch and passed to the dead-letter output, metrics the output wrote
+// despite an error are removed from the batch and accepted.
+func (ro *RunningOutput) writeBatch(batch []telegraf.Metric) error {
+	rejected, written, err := ro.write(batch)
+	if len(rejected) > 0 {
+		batch = ro.removeRejected(batch, rejected)
+	}
+	if err != nil && len(written) > 0 {
+		batch = ro.removeWritten(batch, written)
+	}
+
+	if err != nil && len(batch) > 0 {
+		ro.writeFailed(batch)
 		return err
 	}
 	ro.buffer.Accept(batch)
+	return err
+}
 
-	return nil
+// SetDeadLetter sets the output receiving the metrics rejected permanently by
+// this output, nil to drop them.
+func (ro *RunningOutput) SetDeadLetter(output *RunningOutput) {
+	ro.deadLetter = output
+}
+
+// removeRejected returns the batch without the rejected metrics.  The rejected
+// metrics are added to the dead-letter output, tagged with the name of this
+// output and with the error as field, or dropped if there is no dead-letter
+// output.
+func (ro *RunningOutput) removeRejected(batch []telegraf.Metric, rejected []telegraf.MetricError) []telegraf.Metric {
+	errs := make(map[telegraf.Metric]error, len(rejected))
+	for _, r := range rejected {
+		errs[r.Metric] = r.Err
+	}
+
+	remaining := make([]telegraf.Metric, 0, len(batch))
+	var removed []telegraf.MetricError
+	for _, metric := range batch {
+		err, ok := errs[metric]
+		if !ok {
+			remaining = append(remaining, metric)
+			continue
+		}
+		removed = append(removed, telegraf.MetricError{Metric: metric, Err: err})
+	}
+	if len(removed) == 0 {
+		return batch
+	}
+	ro.MetricsRejected.Incr(int64(len(removed)))
+
+	if ro.deadLetter == nil {
+		ro.log.Errorf("Dropping %d metrics rejected permanently: %v",
+			len(removed), removed[0].Err)
+		for _, r := range removed {
+			ro.buffer.metricDropped(r.Metric)
+		}
+		return remaining
+	}
+
+	ro.log.Warnf("Passing %d metrics rejected permanently to %s: %v",
+		len(removed), ro.deadLetter.LogName(), removed[0].Err)
+	for _, r := range removed {
+		r.Metric.AddTag("dead_letter_output", ro.LogName())
+		if r.Err != nil {
+			r.Metric.AddField("dead_letter_error", r.Err.Error())
+		}
+		ro.deadLetter.AddMetric(r.Metric)
+	}
+	return remaining
+}
+
+// removeWritten returns the batch without the written metrics, which are
+// marked as written.
+func (ro *RunningOutput) removeWritten(batch []telegraf.Metric, written []telegraf.Metric) []telegraf.Metric {
+	done := make(map[telegraf.Metric]bool, len(written))
+	for _, metric := range written {
+		done[metric] = true
+	}
+
+	remaining := make([]telegraf.Metric, 0, len(batch))
+	for _, metric := range batch {
+		if done[metric] {
+			ro.buffer.metricWritten(metric)
+			continue
+		}
+		remaining = append(remaining, metric)
+	}
+	return remaining
+}
+
+// writeFailed handles a batch the output failed to write.  If the output is a
+// member of a failover group the batch and the rest of the buffer are
+// redirected to the next available member, otherwise the batch is returned to
+// the buffer.
+func (ro *RunningOutput) writeFailed(batch []telegraf.Metric) {
+	if ro.group != nil && ro.group.redirect(ro, batch) {
+		return
+	}
+	ro.buffer.Reject(batch)
+}
+
+// addRedirected adds metrics redirected from another member of the failover
+// group.  The metrics have already been filtered and modified by the member.
+func (ro *RunningOutput) addRedirected(metrics []telegraf.Metric) {
+	dropped := ro.buffer.Add(metrics...)
+	atomic.AddInt64(&ro.droppedMetrics, int64(dropped))
+
+	if ro.buffer.Len() >= ro.MetricBatchSize {
+		select {
+		case ro.BatchReady <- time.Now():
+		default:
+		}
+	}
+}
+
+// available returns true if the failover group can route new metrics to the
+// output: it did not fail within the failback interval, it does not back off
+// and its buffer is below the threshold.
+func (ro *RunningOutput) available(now time.Time) bool {
+	if now.UnixNano() < atomic.LoadInt64(&ro.failedUntil) {
+		return false
+	}
+	if ro.retry.allow() != nil {
+		return false
+	}
+	threshold := ro.Config.FailoverBufferThreshold
+	return threshold <= 0 || ro.buffer.Len() < threshold
 }
 
 // Close closes the output
@@ -235,7 +428,10 @@ func (r *RunningOutput) Close() {
 	}
 }
 
-func (r *RunningOutput) write(metrics []telegraf.Metric) error {
+// write writes the metrics to the output and returns the metrics the output
+// rejected permanently and the metrics written despite the error of the other
+// metrics, along with this error.
+func (r *RunningOutput) write(metrics []telegraf.Metric) ([]telegraf.MetricError, []telegraf.Metric, error) {
 	dropped := atomic.LoadInt64(&r.droppedMetrics)
 	if dropped > 0 {
 		r.log.Warnf("Metric buffer overflow; %d metrics have been dropped", dropped)
@@ -247,10 +443,29 @@ func (r *RunningOutput) write(metrics []telegraf.Metric) error {
 	elapsed := time.Since(start)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
 
-	if err == nil {
-		r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
+	var rejected []telegraf.MetricError
+	var written []telegraf.Metric
+	var partial *telegraf.PartialWriteError
+	if errors.As(err, &partial) {
+		rejected = partial.Rejected
+		written = partial.Written
+		err = partial.Err
 	}
-	return err
+
+	if err != nil {
+		if r.retry.failure() {
+			r.log.Warnf("Circuit breaker opened after %d consecutive failures",
+				r.Config.Retry.CircuitBreakerThreshold)
+		}
+		return rejected, written, err
+	}
+
+	if r.retry.success() {
+		r.log.Infof("Circuit breaker closed")
+	}
+	atomic.StoreInt64(&r.failedUntil, 0)
+	r.log.Debugf("Wrote batch of %d metrics in %s", len(metrics), elapsed)
+	return rejected, nil, nil
 }
 
 func (r *RunningOutput) LogBufferStatus() {
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// RunningOutputGroup is a failover group of outputs.  Metrics are routed to
+// the first available member in the order the members were added, so the
+// first member is the primary and the others are secondaries.
+//
+// A member is unavailable for its failback interval after a failed write,
+// while it backs off according to its retry policy and while its buffer
+// exceeds its buffer threshold.  When a write of a member
+// fails its buffered metrics are redirected to the next available member.
+type RunningOutputGroup struct {
+	Name    string
+	members []*groupMember
+	active  int
+	log     telegraf.Logger
+
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+}
+
+type groupMember struct {
+	output *RunningOutput
+
+	MetricsRouted     selfstat.Stat
+	MetricsRedirected selfstat.Stat
+	Active            selfstat.Stat
+}
+
+// NewRunningOutputGroup returns an empty failover group.
+func NewRunningOutputGroup(name string) *RunningOutputGroup {
+	return &RunningOutputGroup{
+		Name:   name,
+		active: -1,
+		log:    NewLogger("failover", name, ""),
+		now:    time.Now,
+	}
+}
+
+// AddOutput adds the output as the last member of the group.
+func (g *RunningOutputGroup) AddOutput(output *RunningOutput) error {
+	if _, ok := output.Output.(telegraf.AggregatingOutput); ok {
+		return fmt.Errorf("aggregating output %s cannot be part of a failover group",
+			output.LogName())
+	}
+	if output.group != nil {
+		return fmt.Errorf("output %s is already part of failover group %q",
+			output.LogName(), output.group.Name)
+	}
+
+	tags := map[string]string{"group": g.Name, "output": output.Config.Name}
+	if output.Config.Alias != "" {
+		tags["alias"] = output.Config.Alias
+	}
+
+	member := &groupMember{
+		output: output,
+		MetricsRouted: selfstat.Register(
+			"failover",
+			"metrics_routed",
+			tags,
+		),
+		MetricsRedirected: selfstat.Register(
+			"failover",
+			"metrics_redirected",
+			tags,
+		),
+		Active: selfstat.Register(
+			"failover",
+			"active",
+			tags,
+		),
+	}
+	output.group = g
+	g.members = append(g.members, member)
+	return nil
+}
+
+// RemoveOutput removes the output from the group, for example when it failed
+// to connect.
+func (g *RunningOutputGroup) RemoveOutput(output *RunningOutput) {
+	for i, member := range g.members {
+		if member.output != output {
+			continue
+		}
+		member.Active.Set(0)
+		g.members = append(g.members[:i], g.members[i+1:]...)
+		g.active = -1
+		output.group = nil
+		return
+	}
+}
+
+// Outputs returns the members of the group in order.
+func (g *RunningOutputGroup) Outputs() []*RunningOutput {
+	outputs := make([]*RunningOutput, 0, len(g.members))
+	for _, member := range g.members {
+		outputs = append(outputs, member.output)
+	}
+	return outputs
+}
+
+// AddMetric adds the metric to the first available member, or to the primary
+// if no member is available.
+//
+// Takes ownership of metric
+func (g *RunningOutputGroup) AddMetric(metric telegraf.Metric) {
+	if len(g.members) == 0 {
+		metric.Drop()
+		return
+	}
+
+	active := 0
+	now := g.now()
+	for i, member := range g.members {
+		if member.output.available(now) {
+			active = i
+			break
+		}
+	}
+	g.activate(active)
+
+	member := g.members[active]
+	member.MetricsRouted.Incr(1)
+	member.output.AddMetric(metric)
+}
+
+// activate marks the member at the index as the one receiving new metrics.
+func (g *RunningOutputGroup) activate(index int) {
+	if index == g.active {
+		return
+	}
+
+	if g.active >= 0 {
+		from := g.members[g.active]
+		to := g.members[index]
+		if index < g.active {
+			g.log.Infof("Failing back from %s to %s",
+				from.output.LogName(), to.output.LogName())
+		} else {
+			g.log.Warnf("Failing over from %s to %s",
+				from.output.LogName(), to.output.LogName())
+		}
+		from.Active.Set(0)
+	}
+	g.members[index].Active.Set(1)
+	g.active = index
+}
+
+// redirect marks the output as failed and moves the failed batch, acquired
+// from the buffer of the output, and the rest of the buffer to the next
+// available member.  It returns false if there is no available member after
+// the output, in which case the batch is left to the caller.
+func (g *RunningOutputGroup) redirect(output *RunningOutput, batch []telegraf.Metric) bool {
+	now := g.now()
+	failback := output.Config.FailbackInterval
+	if failback <= 0 {
+		failback = DEFAULT_FAILBACK_INTERVAL
+	}
+	atomic.StoreInt64(&output.failedUntil, now.Add(failback).UnixNano())
+
+	var from, to *groupMember
+	for _, member := range g.members {
+		if member.output == output {
+			from = member
+			continue
+		}
+		if from != nil && member.output.available(now) {
+			to = member
+			break
+		}
+	}
+	if from == nil || to == nil {
+		return false
+	}
+
+	// Metrics may have been added to the buffer during the write, so the
+	// buffer holds metrics older and newer than the batch.  Returning the
+	// batch restores it between them; the whole buffer is then taken, ordered
+	// from newest to oldest, and added from oldest to newest to keep the
+	// order in the buffer of the target.
+	output.buffer.Reject(batch)
+	all := output.buffer.Batch(output.MetricBufferLimit)
+	output.buffer.Release(all)
+
+	metrics := make([]telegraf.Metric, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		metrics = append(metrics, all[i])
+	}
+
+	output.log.Warnf("Redirecting %d metrics to %s", len(metrics), to.output.LogName())
+	from.MetricsRedirected.Incr(int64(len(metrics)))
+	to.output.addRedirected(metrics)
+	return true
+}
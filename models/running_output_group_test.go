@@ -0,0 +1,215 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupOutput(t *testing.T, group *RunningOutputGroup, name string, config OutputConfig) (*RunningOutput, *mockOutput) {
+	m := &mockOutput{}
+	config.Name = name
+	config.FailoverGroup = group.Name
+	ro := NewRunningOutput(name, m, &config, 10, 100)
+	require.NoError(t, group.AddOutput(ro))
+	return ro, m
+}
+
+func TestRunningOutputGroup_Primary(t *testing.T) {
+	g := NewRunningOutputGroup("primary")
+	primary, pm := newGroupOutput(t, g, "primary_a", OutputConfig{})
+	secondary, sm := newGroupOutput(t, g, "primary_b", OutputConfig{})
+	require.Equal(t, []*RunningOutput{primary, secondary}, g.Outputs())
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+	require.NoError(t, primary.Write())
+	require.NoError(t, secondary.Write())
+
+	require.Len(t, pm.Metrics(), 5)
+	require.Len(t, sm.Metrics(), 0)
+}
+
+func TestRunningOutputGroup_FailoverAndFailback(t *testing.T) {
+	now := time.Unix(1000, 0)
+	g := NewRunningOutputGroup("failover")
+	g.now = func() time.Time { return now }
+
+	primary, pm := newGroupOutput(t, g, "failover_a", OutputConfig{
+		FailbackInterval: time.Minute,
+	})
+	secondary, sm := newGroupOutput(t, g, "failover_b", OutputConfig{})
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+
+	// The failed write redirects all buffered metrics to the secondary.
+	pm.failWrite = true
+	primary.MetricBatchSize = 2
+	require.Error(t, primary.Write())
+	require.Equal(t, 0, primary.BufferLength())
+	require.Equal(t, 5, secondary.BufferLength())
+
+	// New metrics are routed to the secondary until the failback interval
+	// elapsed.
+	for _, metric := range next5 {
+		g.AddMetric(metric)
+	}
+	require.NoError(t, secondary.Write())
+	testutil.RequireMetricsEqual(t, reverse(append(first5, next5...)), sm.Metrics())
+
+	pm.failWrite = false
+	now = now.Add(time.Minute)
+	g.AddMetric(testutil.TestMetric(101, "metric11"))
+	require.Equal(t, 1, primary.BufferLength())
+	require.NoError(t, primary.Write())
+	require.Len(t, pm.Metrics(), 1)
+}
+
+func TestRunningOutputGroup_RedirectKeepsOrder(t *testing.T) {
+	g := NewRunningOutputGroup("order")
+	primary, pm := newGroupOutput(t, g, "order_a", OutputConfig{})
+	secondary, sm := newGroupOutput(t, g, "order_b", OutputConfig{})
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+
+	// Metrics added during the failed write are newer than the batch.
+	pm.failWrite = true
+	pm.onWrite = func() {
+		for _, metric := range next5 {
+			primary.AddMetric(metric)
+		}
+	}
+	primary.MetricBatchSize = 2
+	require.Error(t, primary.Write())
+	require.Equal(t, 0, primary.BufferLength())
+	require.Equal(t, 10, secondary.BufferLength())
+
+	secondary.MetricBatchSize = 10
+	require.NoError(t, secondary.Write())
+	testutil.RequireMetricsEqual(t, reverse(append(first5, next5...)), sm.Metrics())
+}
+
+func TestRunningOutputGroup_RemoveOutput(t *testing.T) {
+	g := NewRunningOutputGroup("remove")
+	primary, _ := newGroupOutput(t, g, "remove_a", OutputConfig{})
+	secondary, _ := newGroupOutput(t, g, "remove_b", OutputConfig{})
+
+	g.RemoveOutput(primary)
+	require.Equal(t, []*RunningOutput{secondary}, g.Outputs())
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+	require.Equal(t, 0, primary.BufferLength())
+	require.Equal(t, 5, secondary.BufferLength())
+}
+
+func TestRunningOutputGroup_BufferThreshold(t *testing.T) {
+	g := NewRunningOutputGroup("threshold")
+	primary, _ := newGroupOutput(t, g, "threshold_a", OutputConfig{
+		FailoverBufferThreshold: 3,
+	})
+	secondary, _ := newGroupOutput(t, g, "threshold_b", OutputConfig{})
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+	require.Equal(t, 3, primary.BufferLength())
+	require.Equal(t, 2, secondary.BufferLength())
+}
+
+func TestRunningOutputGroup_NoAvailableMember(t *testing.T) {
+	g := NewRunningOutputGroup("unavailable")
+	primary, pm := newGroupOutput(t, g, "unavailable_a", OutputConfig{})
+	secondary, sm := newGroupOutput(t, g, "unavailable_b", OutputConfig{})
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+
+	// Both members failed, the primary receives new metrics and the last
+	// member keeps its failed batch.
+	pm.failWrite = true
+	sm.failWrite = true
+	require.Error(t, primary.Write())
+	require.Error(t, secondary.Write())
+	require.Equal(t, 5, secondary.BufferLength())
+
+	g.AddMetric(testutil.TestMetric(101, "metric6"))
+	require.Equal(t, 1, primary.BufferLength())
+
+	// The primary keeps its batch as no member after it is available.
+	require.Error(t, primary.Write())
+	require.Equal(t, 1, primary.BufferLength())
+}
+
+func TestRunningOutputGroup_AddOutputErrors(t *testing.T) {
+	g := NewRunningOutputGroup("errors")
+	ro, _ := newGroupOutput(t, g, "errors_a", OutputConfig{})
+	require.Error(t, g.AddOutput(ro))
+	require.Error(t, NewRunningOutputGroup("other").AddOutput(ro))
+}
+
+func TestRunningOutputGroup_InternalMetrics(t *testing.T) {
+	now := time.Unix(1000, 0)
+	g := NewRunningOutputGroup("stats")
+	g.now = func() time.Time { return now }
+	primary, pm := newGroupOutput(t, g, "stats_a", OutputConfig{})
+	newGroupOutput(t, g, "stats_b", OutputConfig{})
+
+	for _, metric := range first5 {
+		g.AddMetric(metric)
+	}
+	pm.failWrite = true
+	require.Error(t, primary.Write())
+	g.AddMetric(testutil.TestMetric(101, "metric6"))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"internal_failover",
+			map[string]string{
+				"group":  "stats",
+				"output": "stats_a",
+			},
+			map[string]interface{}{
+				"active":             0,
+				"metrics_redirected": 5,
+				"metrics_routed":     5,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"internal_failover",
+			map[string]string{
+				"group":  "stats",
+				"output": "stats_b",
+			},
+			map[string]interface{}{
+				"active":             1,
+				"metrics_redirected": 0,
+				"metrics_routed":     1,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range selfstat.Metrics() {
+		group, _ := m.GetTag("group")
+		if m.Name() == "internal_failover" && group == "stats" {
+			actual = append(actual, m)
+		}
+	}
+
+	testutil.RequireMetricsEqual(t, expected, actual,
+		testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -494,6 +494,7 @@ func TestInternalMetrics(t *testing.T) {
 				"metrics_added":    0,
 				"metrics_dropped":  0,
 				"metrics_filtered": 0,
+				"metrics_rejected": 0,
 				"metrics_written":  0,
 				"write_time_ns":    0,
 			},
@@ -519,6 +520,9 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+
+	// called at the start of each write
+	onWrite func()
 }
 
 func (m *mockOutput) Connect() error {
@@ -540,6 +544,9 @@ func (m *mockOutput) SampleConfig() string {
 func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	m.Lock()
 	defer m.Unlock()
+	if m.onWrite != nil {
+		m.onWrite()
+	}
 	if m.failWrite {
 		return fmt.Errorf("Failed Write!")
 	}
@@ -587,3 +594,130 @@ func (m *perfOutput) Write(metrics []telegraf.Metric) error {
 	}
 	return nil
 }
+
+// rejectingOutput rejects the metrics named "rejected" permanently.
+type rejectingOutput struct {
+	mockOutput
+	err error
+}
+
+func (m *rejectingOutput) Write(metrics []telegraf.Metric) error {
+	var rejected []telegraf.MetricError
+	var written []telegraf.Metric
+	for _, metric := range metrics {
+		if metric.Name() == "rejected" {
+			rejected = append(rejected, telegraf.MetricError{
+				Metric: metric,
+				Err:    fmt.Errorf("field type conflict"),
+			})
+			continue
+		}
+		written = append(written, metric)
+	}
+	if m.err == nil {
+		m.mockOutput.Write(written)
+	}
+	return &telegraf.PartialWriteError{Rejected: rejected, Err: m.err}
+}
+
+func TestRunningOutputDeadLetter(t *testing.T) {
+	m := &rejectingOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{Name: "test"}, 10, 100)
+	sm := &mockOutput{}
+	sink := NewRunningOutput("sink", sm, &OutputConfig{DeadLetter: true}, 10, 100)
+	ro.SetDeadLetter(sink)
+
+	ro.AddMetric(testutil.TestMetric(1, "accepted"))
+	ro.AddMetric(testutil.TestMetric(2, "rejected"))
+	require.NoError(t, ro.Write())
+	require.Equal(t, 0, ro.BufferLength())
+	require.Len(t, m.Metrics(), 1)
+
+	require.NoError(t, sink.Write())
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"rejected",
+			map[string]string{
+				"tag1":               "value1",
+				"dead_letter_output": "outputs.test",
+			},
+			map[string]interface{}{
+				"value":             2,
+				"dead_letter_error": "field type conflict",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, sm.Metrics(), testutil.IgnoreTime())
+}
+
+func TestRunningOutputRejectedDropped(t *testing.T) {
+	m := &rejectingOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{}, 10, 100)
+
+	var rejected int
+	mm := &MockMetric{
+		Metric:  testutil.TestMetric(1, "rejected"),
+		RejectF: func() { rejected++ },
+	}
+	ro.AddMetric(mm)
+	require.NoError(t, ro.Write())
+	require.Equal(t, 0, ro.BufferLength())
+	require.Equal(t, 1, rejected)
+}
+
+func TestRunningOutputRejectedWithError(t *testing.T) {
+	m := &rejectingOutput{err: fmt.Errorf("connection refused")}
+	ro := NewRunningOutput("test", m, &OutputConfig{}, 10, 100)
+
+	ro.AddMetric(testutil.TestMetric(1, "accepted"))
+	ro.AddMetric(testutil.TestMetric(2, "rejected"))
+	require.Error(t, ro.Write())
+	// Only the rejected metric is removed from the buffer.
+	require.Equal(t, 1, ro.BufferLength())
+
+	m.err = nil
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+	require.Equal(t, "accepted", m.Metrics()[0].Name())
+}
+
+// partialOutput writes the metrics named "written" and fails to write the
+// other metrics.
+type partialOutput struct {
+	mockOutput
+}
+
+func (m *partialOutput) Write(metrics []telegraf.Metric) error {
+	var written []telegraf.Metric
+	for _, metric := range metrics {
+		if metric.Name() == "written" {
+			written = append(written, metric)
+		}
+	}
+	m.mockOutput.Write(written)
+	if len(written) == len(metrics) {
+		return nil
+	}
+	return &telegraf.PartialWriteError{Err: fmt.Errorf("connection refused"), Written: written}
+}
+
+func TestRunningOutputPartiallyWritten(t *testing.T) {
+	m := &partialOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{}, 10, 100)
+
+	var accepted int
+	mm := &MockMetric{
+		Metric:  testutil.TestMetric(1, "written"),
+		AcceptF: func() { accepted++ },
+	}
+	ro.AddMetric(mm)
+	ro.AddMetric(testutil.TestMetric(2, "failed"))
+	require.Error(t, ro.Write())
+	require.Equal(t, 1, accepted)
+
+	// Only the failed metric is retried.
+	require.Equal(t, 1, ro.BufferLength())
+	require.Error(t, ro.WriteNow())
+	require.Len(t, m.Metrics(), 1)
+}
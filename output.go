@@ -1,5 +1,7 @@
 package telegraf
 
+import "fmt"
+
 type Output interface {
 	PluginDescriber
 
@@ -24,3 +26,41 @@ type AggregatingOutput interface {
 	// Reset signals the the aggregator period is completed.
 	Reset()
 }
+
+// MetricError is the error of a metric an Output permanently failed to write.
+type MetricError struct {
+	Metric Metric
+	Err    error
+}
+
+// PartialWriteError may be returned by the Write function of an Output to
+// classify metrics of the batch as permanently rejected, for example because
+// the destination refused their content.  Writing these metrics again cannot
+// succeed, so instead of being retried they are removed from the buffer and
+// passed to the dead-letter output if one is configured.
+type PartialWriteError struct {
+	// Rejected are the permanently rejected metrics of the batch.
+	Rejected []MetricError
+	// Err is the error writing the other metrics of the batch, these metrics
+	// are retried.  If Err is nil the other metrics were written.
+	Err error
+	// Written are the metrics of the batch written despite Err, for example
+	// by an output sending the batch in several requests.  They are not
+	// retried.
+	Written []Metric
+}
+
+func (e *PartialWriteError) Error() string {
+	msg := fmt.Sprintf("%d metrics rejected", len(e.Rejected))
+	if len(e.Rejected) > 0 {
+		msg += fmt.Sprintf(" (%v)", e.Rejected[0].Err)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
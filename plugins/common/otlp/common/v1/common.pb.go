@@ -0,0 +1,441 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: opentelemetry/proto/common/v1/common.proto
+
+package v1
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type AnyValue struct {
+	// Types that are valid to be assigned to Value:
+	//	*AnyValue_StringValue
+	//	*AnyValue_BoolValue
+	//	*AnyValue_IntValue
+	//	*AnyValue_DoubleValue
+	//	*AnyValue_ArrayValue
+	//	*AnyValue_KvlistValue
+	//	*AnyValue_BytesValue
+	Value                isAnyValue_Value `protobuf_oneof:"value"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *AnyValue) Reset()         { *m = AnyValue{} }
+func (m *AnyValue) String() string { return proto.CompactTextString(m) }
+func (*AnyValue) ProtoMessage()    {}
+func (*AnyValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{0}
+}
+
+func (m *AnyValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AnyValue.Unmarshal(m, b)
+}
+func (m *AnyValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AnyValue.Marshal(b, m, deterministic)
+}
+func (m *AnyValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AnyValue.Merge(m, src)
+}
+func (m *AnyValue) XXX_Size() int {
+	return xxx_messageInfo_AnyValue.Size(m)
+}
+func (m *AnyValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_AnyValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AnyValue proto.InternalMessageInfo
+
+type isAnyValue_Value interface {
+	isAnyValue_Value()
+}
+
+type AnyValue_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type AnyValue_BoolValue struct {
+	BoolValue bool `protobuf:"varint,2,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type AnyValue_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type AnyValue_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,4,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type AnyValue_ArrayValue struct {
+	ArrayValue *ArrayValue `protobuf:"bytes,5,opt,name=array_value,json=arrayValue,proto3,oneof"`
+}
+
+type AnyValue_KvlistValue struct {
+	KvlistValue *KeyValueList `protobuf:"bytes,6,opt,name=kvlist_value,json=kvlistValue,proto3,oneof"`
+}
+
+type AnyValue_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,7,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+func (*AnyValue_StringValue) isAnyValue_Value() {}
+
+func (*AnyValue_BoolValue) isAnyValue_Value() {}
+
+func (*AnyValue_IntValue) isAnyValue_Value() {}
+
+func (*AnyValue_DoubleValue) isAnyValue_Value() {}
+
+func (*AnyValue_ArrayValue) isAnyValue_Value() {}
+
+func (*AnyValue_KvlistValue) isAnyValue_Value() {}
+
+func (*AnyValue_BytesValue) isAnyValue_Value() {}
+
+func (m *AnyValue) GetValue() isAnyValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *AnyValue) GetStringValue() string {
+	if x, ok := m.GetValue().(*AnyValue_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *AnyValue) GetBoolValue() bool {
+	if x, ok := m.GetValue().(*AnyValue_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *AnyValue) GetIntValue() int64 {
+	if x, ok := m.GetValue().(*AnyValue_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (m *AnyValue) GetDoubleValue() float64 {
+	if x, ok := m.GetValue().(*AnyValue_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (m *AnyValue) GetArrayValue() *ArrayValue {
+	if x, ok := m.GetValue().(*AnyValue_ArrayValue); ok {
+		return x.ArrayValue
+	}
+	return nil
+}
+
+func (m *AnyValue) GetKvlistValue() *KeyValueList {
+	if x, ok := m.GetValue().(*AnyValue_KvlistValue); ok {
+		return x.KvlistValue
+	}
+	return nil
+}
+
+func (m *AnyValue) GetBytesValue() []byte {
+	if x, ok := m.GetValue().(*AnyValue_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*AnyValue) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*AnyValue_StringValue)(nil),
+		(*AnyValue_BoolValue)(nil),
+		(*AnyValue_IntValue)(nil),
+		(*AnyValue_DoubleValue)(nil),
+		(*AnyValue_ArrayValue)(nil),
+		(*AnyValue_KvlistValue)(nil),
+		(*AnyValue_BytesValue)(nil),
+	}
+}
+
+type ArrayValue struct {
+	Values               []*AnyValue `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ArrayValue) Reset()         { *m = ArrayValue{} }
+func (m *ArrayValue) String() string { return proto.CompactTextString(m) }
+func (*ArrayValue) ProtoMessage()    {}
+func (*ArrayValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{1}
+}
+
+func (m *ArrayValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ArrayValue.Unmarshal(m, b)
+}
+func (m *ArrayValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ArrayValue.Marshal(b, m, deterministic)
+}
+func (m *ArrayValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArrayValue.Merge(m, src)
+}
+func (m *ArrayValue) XXX_Size() int {
+	return xxx_messageInfo_ArrayValue.Size(m)
+}
+func (m *ArrayValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArrayValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ArrayValue proto.InternalMessageInfo
+
+func (m *ArrayValue) GetValues() []*AnyValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type KeyValueList struct {
+	Values               []*KeyValue `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *KeyValueList) Reset()         { *m = KeyValueList{} }
+func (m *KeyValueList) String() string { return proto.CompactTextString(m) }
+func (*KeyValueList) ProtoMessage()    {}
+func (*KeyValueList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{2}
+}
+
+func (m *KeyValueList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyValueList.Unmarshal(m, b)
+}
+func (m *KeyValueList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyValueList.Marshal(b, m, deterministic)
+}
+func (m *KeyValueList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyValueList.Merge(m, src)
+}
+func (m *KeyValueList) XXX_Size() int {
+	return xxx_messageInfo_KeyValueList.Size(m)
+}
+func (m *KeyValueList) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyValueList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyValueList proto.InternalMessageInfo
+
+func (m *KeyValueList) GetValues() []*KeyValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type KeyValue struct {
+	Key                  string    `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                *AnyValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *KeyValue) Reset()         { *m = KeyValue{} }
+func (m *KeyValue) String() string { return proto.CompactTextString(m) }
+func (*KeyValue) ProtoMessage()    {}
+func (*KeyValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{3}
+}
+
+func (m *KeyValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyValue.Unmarshal(m, b)
+}
+func (m *KeyValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyValue.Marshal(b, m, deterministic)
+}
+func (m *KeyValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyValue.Merge(m, src)
+}
+func (m *KeyValue) XXX_Size() int {
+	return xxx_messageInfo_KeyValue.Size(m)
+}
+func (m *KeyValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyValue proto.InternalMessageInfo
+
+func (m *KeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyValue) GetValue() *AnyValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+type StringKeyValue struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StringKeyValue) Reset()         { *m = StringKeyValue{} }
+func (m *StringKeyValue) String() string { return proto.CompactTextString(m) }
+func (*StringKeyValue) ProtoMessage()    {}
+func (*StringKeyValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{4}
+}
+
+func (m *StringKeyValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StringKeyValue.Unmarshal(m, b)
+}
+func (m *StringKeyValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StringKeyValue.Marshal(b, m, deterministic)
+}
+func (m *StringKeyValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StringKeyValue.Merge(m, src)
+}
+func (m *StringKeyValue) XXX_Size() int {
+	return xxx_messageInfo_StringKeyValue.Size(m)
+}
+func (m *StringKeyValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_StringKeyValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StringKeyValue proto.InternalMessageInfo
+
+func (m *StringKeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *StringKeyValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type InstrumentationLibrary struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InstrumentationLibrary) Reset()         { *m = InstrumentationLibrary{} }
+func (m *InstrumentationLibrary) String() string { return proto.CompactTextString(m) }
+func (*InstrumentationLibrary) ProtoMessage()    {}
+func (*InstrumentationLibrary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_62ba46dcb97aa817, []int{5}
+}
+
+func (m *InstrumentationLibrary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InstrumentationLibrary.Unmarshal(m, b)
+}
+func (m *InstrumentationLibrary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InstrumentationLibrary.Marshal(b, m, deterministic)
+}
+func (m *InstrumentationLibrary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InstrumentationLibrary.Merge(m, src)
+}
+func (m *InstrumentationLibrary) XXX_Size() int {
+	return xxx_messageInfo_InstrumentationLibrary.Size(m)
+}
+func (m *InstrumentationLibrary) XXX_DiscardUnknown() {
+	xxx_messageInfo_InstrumentationLibrary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InstrumentationLibrary proto.InternalMessageInfo
+
+func (m *InstrumentationLibrary) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *InstrumentationLibrary) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*AnyValue)(nil), "opentelemetry.proto.common.v1.AnyValue")
+	proto.RegisterType((*ArrayValue)(nil), "opentelemetry.proto.common.v1.ArrayValue")
+	proto.RegisterType((*KeyValueList)(nil), "opentelemetry.proto.common.v1.KeyValueList")
+	proto.RegisterType((*KeyValue)(nil), "opentelemetry.proto.common.v1.KeyValue")
+	proto.RegisterType((*StringKeyValue)(nil), "opentelemetry.proto.common.v1.StringKeyValue")
+	proto.RegisterType((*InstrumentationLibrary)(nil), "opentelemetry.proto.common.v1.InstrumentationLibrary")
+}
+
+func init() {
+	proto.RegisterFile("opentelemetry/proto/common/v1/common.proto", fileDescriptor_62ba46dcb97aa817)
+}
+
+var fileDescriptor_62ba46dcb97aa817 = []byte{
+	// 440 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x5d, 0x8b, 0x13, 0x31,
+	0x14, 0x6d, 0xda, 0xed, 0xd7, 0x9d, 0x22, 0x12, 0x44, 0xfa, 0xb2, 0x18, 0xeb, 0x83, 0xa3, 0x42,
+	0x87, 0x5d, 0xdf, 0x64, 0x45, 0xb6, 0x82, 0x54, 0xb6, 0x62, 0x19, 0xc1, 0x07, 0x7d, 0x90, 0x8c,
+	0x9b, 0xad, 0x61, 0x33, 0xc9, 0x90, 0x64, 0x06, 0xe7, 0xdf, 0xfa, 0x53, 0x24, 0x1f, 0xb3, 0x5d,
+	0x7d, 0xe8, 0xb2, 0x6f, 0x37, 0x27, 0xe7, 0x9c, 0x7b, 0x2e, 0x37, 0x81, 0x97, 0xaa, 0x62, 0xd2,
+	0x32, 0xc1, 0x4a, 0x66, 0x75, 0x9b, 0x55, 0x5a, 0x59, 0x95, 0xfd, 0x54, 0x65, 0xa9, 0x64, 0xd6,
+	0x9c, 0xc4, 0x6a, 0xe9, 0x61, 0x7c, 0xfc, 0x0f, 0x37, 0x80, 0xcb, 0xc8, 0x68, 0x4e, 0x16, 0x7f,
+	0xfa, 0x30, 0x39, 0x97, 0xed, 0x57, 0x2a, 0x6a, 0x86, 0x9f, 0xc1, 0xcc, 0x58, 0xcd, 0xe5, 0xee,
+	0x47, 0xe3, 0xce, 0x73, 0x44, 0x50, 0x3a, 0x5d, 0xf7, 0xf2, 0x24, 0xa0, 0x81, 0xf4, 0x04, 0xa0,
+	0x50, 0x4a, 0x44, 0x4a, 0x9f, 0xa0, 0x74, 0xb2, 0xee, 0xe5, 0x53, 0x87, 0x05, 0xc2, 0x31, 0x4c,
+	0xb9, 0xb4, 0xf1, 0x7e, 0x40, 0x50, 0x3a, 0x58, 0xf7, 0xf2, 0x09, 0x97, 0xf6, 0xa6, 0xc9, 0xa5,
+	0xaa, 0x0b, 0xc1, 0x22, 0xe3, 0x88, 0xa0, 0x14, 0xb9, 0x26, 0x01, 0x0d, 0xa4, 0x0d, 0x24, 0x54,
+	0x6b, 0xda, 0x46, 0xce, 0x90, 0xa0, 0x34, 0x39, 0x7d, 0xb1, 0x3c, 0x38, 0xcb, 0xf2, 0xdc, 0x29,
+	0xbc, 0x7e, 0xdd, 0xcb, 0x81, 0xde, 0x9c, 0xf0, 0x16, 0x66, 0xd7, 0x8d, 0xe0, 0xa6, 0x0b, 0x35,
+	0xf2, 0x76, 0xaf, 0xee, 0xb0, 0xbb, 0x60, 0x41, 0xbe, 0xe1, 0xc6, 0xba, 0x7c, 0xc1, 0x22, 0x38,
+	0x3e, 0x85, 0xa4, 0x68, 0x2d, 0x33, 0xd1, 0x70, 0x4c, 0x50, 0x3a, 0x73, 0x4d, 0x3d, 0xe8, 0x29,
+	0xab, 0x31, 0x0c, 0xfd, 0xe5, 0xe2, 0x13, 0xc0, 0x3e, 0x19, 0x7e, 0x07, 0x23, 0x0f, 0x9b, 0x39,
+	0x22, 0x83, 0x34, 0x39, 0x7d, 0x7e, 0xd7, 0x50, 0x71, 0x39, 0x79, 0x94, 0x2d, 0x3e, 0xc3, 0xec,
+	0x76, 0xb2, 0x7b, 0x1b, 0x5e, 0xb0, 0xff, 0x0c, 0xbf, 0xc3, 0xa4, 0xc3, 0xf0, 0x43, 0x18, 0x5c,
+	0xb3, 0x36, 0x2c, 0x3e, 0x77, 0x25, 0x7e, 0x0b, 0xc3, 0xfd, 0xa6, 0xef, 0x11, 0x37, 0x0e, 0x7f,
+	0x06, 0x0f, 0xbe, 0xf8, 0xc7, 0x73, 0xa0, 0xc5, 0xa3, 0xdb, 0x2d, 0xa6, 0x51, 0xf9, 0xa6, 0x3f,
+	0x47, 0x8b, 0x0f, 0xf0, 0xf8, 0xa3, 0x34, 0x56, 0xd7, 0x25, 0x93, 0x96, 0x5a, 0xae, 0xe4, 0x86,
+	0x17, 0x9a, 0xea, 0x16, 0x63, 0x38, 0x92, 0xb4, 0x8c, 0x4f, 0x34, 0xf7, 0x35, 0x9e, 0xc3, 0xb8,
+	0x61, 0xda, 0x70, 0x25, 0xa3, 0x53, 0x77, 0x5c, 0x29, 0x20, 0x5c, 0x1d, 0x4e, 0xbe, 0x4a, 0xde,
+	0xfb, 0x72, 0xeb, 0xe0, 0x2d, 0xfa, 0x76, 0xb6, 0xe3, 0xf6, 0x57, 0x5d, 0x38, 0x42, 0xc6, 0xe5,
+	0x95, 0xa8, 0x7f, 0x5f, 0x52, 0x4b, 0x33, 0xa7, 0xdf, 0x69, 0x7a, 0x95, 0x55, 0xa2, 0xde, 0x71,
+	0x69, 0xba, 0x6f, 0xa7, 0xac, 0xa8, 0xf6, 0x5f, 0xb0, 0x18, 0x79, 0xf7, 0xd7, 0x7f, 0x07, 0x00,
+	0x9f, 0x6c, 0x6d, 0x14, 0xaa, 0x03, 0x00, 0x00,
+}
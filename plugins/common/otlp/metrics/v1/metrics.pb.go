@@ -0,0 +1,1500 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: opentelemetry/proto/metrics/v1/metrics.proto
+
+package v1
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	v11 "github.com/influxdata/telegraf/plugins/common/otlp/common/v1"
+	v1 "github.com/influxdata/telegraf/plugins/common/otlp/resource/v1"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type AggregationTemporality int32
+
+const (
+	AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED AggregationTemporality = 0
+	AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA       AggregationTemporality = 1
+	AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE  AggregationTemporality = 2
+)
+
+var AggregationTemporality_name = map[int32]string{
+	0: "AGGREGATION_TEMPORALITY_UNSPECIFIED",
+	1: "AGGREGATION_TEMPORALITY_DELTA",
+	2: "AGGREGATION_TEMPORALITY_CUMULATIVE",
+}
+
+var AggregationTemporality_value = map[string]int32{
+	"AGGREGATION_TEMPORALITY_UNSPECIFIED": 0,
+	"AGGREGATION_TEMPORALITY_DELTA":       1,
+	"AGGREGATION_TEMPORALITY_CUMULATIVE":  2,
+}
+
+func (x AggregationTemporality) String() string {
+	return proto.EnumName(AggregationTemporality_name, int32(x))
+}
+
+func (AggregationTemporality) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{0}
+}
+
+type ResourceMetrics struct {
+	Resource                      *v1.Resource                     `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	InstrumentationLibraryMetrics []*InstrumentationLibraryMetrics `protobuf:"bytes,2,rep,name=instrumentation_library_metrics,json=instrumentationLibraryMetrics,proto3" json:"instrumentation_library_metrics,omitempty"`
+	SchemaUrl                     string                           `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl,proto3" json:"schema_url,omitempty"`
+	XXX_NoUnkeyedLiteral          struct{}                         `json:"-"`
+	XXX_unrecognized              []byte                           `json:"-"`
+	XXX_sizecache                 int32                            `json:"-"`
+}
+
+func (m *ResourceMetrics) Reset()         { *m = ResourceMetrics{} }
+func (m *ResourceMetrics) String() string { return proto.CompactTextString(m) }
+func (*ResourceMetrics) ProtoMessage()    {}
+func (*ResourceMetrics) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{0}
+}
+
+func (m *ResourceMetrics) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResourceMetrics.Unmarshal(m, b)
+}
+func (m *ResourceMetrics) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResourceMetrics.Marshal(b, m, deterministic)
+}
+func (m *ResourceMetrics) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResourceMetrics.Merge(m, src)
+}
+func (m *ResourceMetrics) XXX_Size() int {
+	return xxx_messageInfo_ResourceMetrics.Size(m)
+}
+func (m *ResourceMetrics) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResourceMetrics.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResourceMetrics proto.InternalMessageInfo
+
+func (m *ResourceMetrics) GetResource() *v1.Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *ResourceMetrics) GetInstrumentationLibraryMetrics() []*InstrumentationLibraryMetrics {
+	if m != nil {
+		return m.InstrumentationLibraryMetrics
+	}
+	return nil
+}
+
+func (m *ResourceMetrics) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type InstrumentationLibraryMetrics struct {
+	InstrumentationLibrary *v11.InstrumentationLibrary `protobuf:"bytes,1,opt,name=instrumentation_library,json=instrumentationLibrary,proto3" json:"instrumentation_library,omitempty"`
+	Metrics                []*Metric                   `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	SchemaUrl              string                      `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl,proto3" json:"schema_url,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}                    `json:"-"`
+	XXX_unrecognized       []byte                      `json:"-"`
+	XXX_sizecache          int32                       `json:"-"`
+}
+
+func (m *InstrumentationLibraryMetrics) Reset()         { *m = InstrumentationLibraryMetrics{} }
+func (m *InstrumentationLibraryMetrics) String() string { return proto.CompactTextString(m) }
+func (*InstrumentationLibraryMetrics) ProtoMessage()    {}
+func (*InstrumentationLibraryMetrics) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{1}
+}
+
+func (m *InstrumentationLibraryMetrics) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InstrumentationLibraryMetrics.Unmarshal(m, b)
+}
+func (m *InstrumentationLibraryMetrics) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InstrumentationLibraryMetrics.Marshal(b, m, deterministic)
+}
+func (m *InstrumentationLibraryMetrics) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InstrumentationLibraryMetrics.Merge(m, src)
+}
+func (m *InstrumentationLibraryMetrics) XXX_Size() int {
+	return xxx_messageInfo_InstrumentationLibraryMetrics.Size(m)
+}
+func (m *InstrumentationLibraryMetrics) XXX_DiscardUnknown() {
+	xxx_messageInfo_InstrumentationLibraryMetrics.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InstrumentationLibraryMetrics proto.InternalMessageInfo
+
+func (m *InstrumentationLibraryMetrics) GetInstrumentationLibrary() *v11.InstrumentationLibrary {
+	if m != nil {
+		return m.InstrumentationLibrary
+	}
+	return nil
+}
+
+func (m *InstrumentationLibraryMetrics) GetMetrics() []*Metric {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func (m *InstrumentationLibraryMetrics) GetSchemaUrl() string {
+	if m != nil {
+		return m.SchemaUrl
+	}
+	return ""
+}
+
+type Metric struct {
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Unit        string `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//	*Metric_IntGauge
+	//	*Metric_Gauge
+	//	*Metric_IntSum
+	//	*Metric_Sum
+	//	*Metric_IntHistogram
+	//	*Metric_Histogram
+	//	*Metric_Summary
+	Data                 isMetric_Data `protobuf_oneof:"data"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Metric) Reset()         { *m = Metric{} }
+func (m *Metric) String() string { return proto.CompactTextString(m) }
+func (*Metric) ProtoMessage()    {}
+func (*Metric) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{2}
+}
+
+func (m *Metric) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Metric.Unmarshal(m, b)
+}
+func (m *Metric) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Metric.Marshal(b, m, deterministic)
+}
+func (m *Metric) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Metric.Merge(m, src)
+}
+func (m *Metric) XXX_Size() int {
+	return xxx_messageInfo_Metric.Size(m)
+}
+func (m *Metric) XXX_DiscardUnknown() {
+	xxx_messageInfo_Metric.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Metric proto.InternalMessageInfo
+
+func (m *Metric) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Metric) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Metric) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+type isMetric_Data interface {
+	isMetric_Data()
+}
+
+type Metric_IntGauge struct {
+	IntGauge *IntGauge `protobuf:"bytes,4,opt,name=int_gauge,json=intGauge,proto3,oneof"`
+}
+
+type Metric_Gauge struct {
+	Gauge *Gauge `protobuf:"bytes,5,opt,name=gauge,proto3,oneof"`
+}
+
+type Metric_IntSum struct {
+	IntSum *IntSum `protobuf:"bytes,6,opt,name=int_sum,json=intSum,proto3,oneof"`
+}
+
+type Metric_Sum struct {
+	Sum *Sum `protobuf:"bytes,7,opt,name=sum,proto3,oneof"`
+}
+
+type Metric_IntHistogram struct {
+	IntHistogram *IntHistogram `protobuf:"bytes,8,opt,name=int_histogram,json=intHistogram,proto3,oneof"`
+}
+
+type Metric_Histogram struct {
+	Histogram *Histogram `protobuf:"bytes,9,opt,name=histogram,proto3,oneof"`
+}
+
+type Metric_Summary struct {
+	Summary *Summary `protobuf:"bytes,11,opt,name=summary,proto3,oneof"`
+}
+
+func (*Metric_IntGauge) isMetric_Data() {}
+
+func (*Metric_Gauge) isMetric_Data() {}
+
+func (*Metric_IntSum) isMetric_Data() {}
+
+func (*Metric_Sum) isMetric_Data() {}
+
+func (*Metric_IntHistogram) isMetric_Data() {}
+
+func (*Metric_Histogram) isMetric_Data() {}
+
+func (*Metric_Summary) isMetric_Data() {}
+
+func (m *Metric) GetData() isMetric_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *Metric) GetIntGauge() *IntGauge {
+	if x, ok := m.GetData().(*Metric_IntGauge); ok {
+		return x.IntGauge
+	}
+	return nil
+}
+
+func (m *Metric) GetGauge() *Gauge {
+	if x, ok := m.GetData().(*Metric_Gauge); ok {
+		return x.Gauge
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *Metric) GetIntSum() *IntSum {
+	if x, ok := m.GetData().(*Metric_IntSum); ok {
+		return x.IntSum
+	}
+	return nil
+}
+
+func (m *Metric) GetSum() *Sum {
+	if x, ok := m.GetData().(*Metric_Sum); ok {
+		return x.Sum
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *Metric) GetIntHistogram() *IntHistogram {
+	if x, ok := m.GetData().(*Metric_IntHistogram); ok {
+		return x.IntHistogram
+	}
+	return nil
+}
+
+func (m *Metric) GetHistogram() *Histogram {
+	if x, ok := m.GetData().(*Metric_Histogram); ok {
+		return x.Histogram
+	}
+	return nil
+}
+
+func (m *Metric) GetSummary() *Summary {
+	if x, ok := m.GetData().(*Metric_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Metric) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Metric_IntGauge)(nil),
+		(*Metric_Gauge)(nil),
+		(*Metric_IntSum)(nil),
+		(*Metric_Sum)(nil),
+		(*Metric_IntHistogram)(nil),
+		(*Metric_Histogram)(nil),
+		(*Metric_Summary)(nil),
+	}
+}
+
+// Deprecated: Do not use.
+type IntGauge struct {
+	DataPoints           []*IntDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *IntGauge) Reset()         { *m = IntGauge{} }
+func (m *IntGauge) String() string { return proto.CompactTextString(m) }
+func (*IntGauge) ProtoMessage()    {}
+func (*IntGauge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{3}
+}
+
+func (m *IntGauge) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntGauge.Unmarshal(m, b)
+}
+func (m *IntGauge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntGauge.Marshal(b, m, deterministic)
+}
+func (m *IntGauge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntGauge.Merge(m, src)
+}
+func (m *IntGauge) XXX_Size() int {
+	return xxx_messageInfo_IntGauge.Size(m)
+}
+func (m *IntGauge) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntGauge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntGauge proto.InternalMessageInfo
+
+func (m *IntGauge) GetDataPoints() []*IntDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+type Gauge struct {
+	DataPoints           []*NumberDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Gauge) Reset()         { *m = Gauge{} }
+func (m *Gauge) String() string { return proto.CompactTextString(m) }
+func (*Gauge) ProtoMessage()    {}
+func (*Gauge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{4}
+}
+
+func (m *Gauge) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Gauge.Unmarshal(m, b)
+}
+func (m *Gauge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Gauge.Marshal(b, m, deterministic)
+}
+func (m *Gauge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Gauge.Merge(m, src)
+}
+func (m *Gauge) XXX_Size() int {
+	return xxx_messageInfo_Gauge.Size(m)
+}
+func (m *Gauge) XXX_DiscardUnknown() {
+	xxx_messageInfo_Gauge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Gauge proto.InternalMessageInfo
+
+func (m *Gauge) GetDataPoints() []*NumberDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+type IntSum struct {
+	DataPoints             []*IntDataPoint        `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,proto3,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool                   `protobuf:"varint,3,opt,name=is_monotonic,json=isMonotonic,proto3" json:"is_monotonic,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}               `json:"-"`
+	XXX_unrecognized       []byte                 `json:"-"`
+	XXX_sizecache          int32                  `json:"-"`
+}
+
+func (m *IntSum) Reset()         { *m = IntSum{} }
+func (m *IntSum) String() string { return proto.CompactTextString(m) }
+func (*IntSum) ProtoMessage()    {}
+func (*IntSum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{5}
+}
+
+func (m *IntSum) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntSum.Unmarshal(m, b)
+}
+func (m *IntSum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntSum.Marshal(b, m, deterministic)
+}
+func (m *IntSum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntSum.Merge(m, src)
+}
+func (m *IntSum) XXX_Size() int {
+	return xxx_messageInfo_IntSum.Size(m)
+}
+func (m *IntSum) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntSum.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntSum proto.InternalMessageInfo
+
+func (m *IntSum) GetDataPoints() []*IntDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *IntSum) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+func (m *IntSum) GetIsMonotonic() bool {
+	if m != nil {
+		return m.IsMonotonic
+	}
+	return false
+}
+
+type Sum struct {
+	DataPoints             []*NumberDataPoint     `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,proto3,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool                   `protobuf:"varint,3,opt,name=is_monotonic,json=isMonotonic,proto3" json:"is_monotonic,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}               `json:"-"`
+	XXX_unrecognized       []byte                 `json:"-"`
+	XXX_sizecache          int32                  `json:"-"`
+}
+
+func (m *Sum) Reset()         { *m = Sum{} }
+func (m *Sum) String() string { return proto.CompactTextString(m) }
+func (*Sum) ProtoMessage()    {}
+func (*Sum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{6}
+}
+
+func (m *Sum) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sum.Unmarshal(m, b)
+}
+func (m *Sum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sum.Marshal(b, m, deterministic)
+}
+func (m *Sum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sum.Merge(m, src)
+}
+func (m *Sum) XXX_Size() int {
+	return xxx_messageInfo_Sum.Size(m)
+}
+func (m *Sum) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sum.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sum proto.InternalMessageInfo
+
+func (m *Sum) GetDataPoints() []*NumberDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *Sum) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+func (m *Sum) GetIsMonotonic() bool {
+	if m != nil {
+		return m.IsMonotonic
+	}
+	return false
+}
+
+// Deprecated: Do not use.
+type IntHistogram struct {
+	DataPoints             []*IntHistogramDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality   `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,proto3,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}                 `json:"-"`
+	XXX_unrecognized       []byte                   `json:"-"`
+	XXX_sizecache          int32                    `json:"-"`
+}
+
+func (m *IntHistogram) Reset()         { *m = IntHistogram{} }
+func (m *IntHistogram) String() string { return proto.CompactTextString(m) }
+func (*IntHistogram) ProtoMessage()    {}
+func (*IntHistogram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{7}
+}
+
+func (m *IntHistogram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntHistogram.Unmarshal(m, b)
+}
+func (m *IntHistogram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntHistogram.Marshal(b, m, deterministic)
+}
+func (m *IntHistogram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntHistogram.Merge(m, src)
+}
+func (m *IntHistogram) XXX_Size() int {
+	return xxx_messageInfo_IntHistogram.Size(m)
+}
+func (m *IntHistogram) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntHistogram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntHistogram proto.InternalMessageInfo
+
+func (m *IntHistogram) GetDataPoints() []*IntHistogramDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *IntHistogram) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+type Histogram struct {
+	DataPoints             []*HistogramDataPoint  `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,proto3,enum=opentelemetry.proto.metrics.v1.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}               `json:"-"`
+	XXX_unrecognized       []byte                 `json:"-"`
+	XXX_sizecache          int32                  `json:"-"`
+}
+
+func (m *Histogram) Reset()         { *m = Histogram{} }
+func (m *Histogram) String() string { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()    {}
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{8}
+}
+
+func (m *Histogram) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Histogram.Unmarshal(m, b)
+}
+func (m *Histogram) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Histogram.Marshal(b, m, deterministic)
+}
+func (m *Histogram) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Histogram.Merge(m, src)
+}
+func (m *Histogram) XXX_Size() int {
+	return xxx_messageInfo_Histogram.Size(m)
+}
+func (m *Histogram) XXX_DiscardUnknown() {
+	xxx_messageInfo_Histogram.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Histogram proto.InternalMessageInfo
+
+func (m *Histogram) GetDataPoints() []*HistogramDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+func (m *Histogram) GetAggregationTemporality() AggregationTemporality {
+	if m != nil {
+		return m.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+type Summary struct {
+	DataPoints           []*SummaryDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Summary) Reset()         { *m = Summary{} }
+func (m *Summary) String() string { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()    {}
+func (*Summary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{9}
+}
+
+func (m *Summary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Summary.Unmarshal(m, b)
+}
+func (m *Summary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Summary.Marshal(b, m, deterministic)
+}
+func (m *Summary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Summary.Merge(m, src)
+}
+func (m *Summary) XXX_Size() int {
+	return xxx_messageInfo_Summary.Size(m)
+}
+func (m *Summary) XXX_DiscardUnknown() {
+	xxx_messageInfo_Summary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Summary proto.InternalMessageInfo
+
+func (m *Summary) GetDataPoints() []*SummaryDataPoint {
+	if m != nil {
+		return m.DataPoints
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+type IntDataPoint struct {
+	Labels               []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	StartTimeUnixNano    uint64                `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano         uint64                `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Value                int64                 `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Exemplars            []*IntExemplar        `protobuf:"bytes,5,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *IntDataPoint) Reset()         { *m = IntDataPoint{} }
+func (m *IntDataPoint) String() string { return proto.CompactTextString(m) }
+func (*IntDataPoint) ProtoMessage()    {}
+func (*IntDataPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{10}
+}
+
+func (m *IntDataPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntDataPoint.Unmarshal(m, b)
+}
+func (m *IntDataPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntDataPoint.Marshal(b, m, deterministic)
+}
+func (m *IntDataPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntDataPoint.Merge(m, src)
+}
+func (m *IntDataPoint) XXX_Size() int {
+	return xxx_messageInfo_IntDataPoint.Size(m)
+}
+func (m *IntDataPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntDataPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntDataPoint proto.InternalMessageInfo
+
+func (m *IntDataPoint) GetLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *IntDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *IntDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *IntDataPoint) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *IntDataPoint) GetExemplars() []*IntExemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+type NumberDataPoint struct {
+	Attributes        []*v11.KeyValue       `protobuf:"bytes,7,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Labels            []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"` // Deprecated: Do not use.
+	StartTimeUnixNano uint64                `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64                `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//	*NumberDataPoint_AsDouble
+	//	*NumberDataPoint_AsInt
+	Value                isNumberDataPoint_Value `protobuf_oneof:"value"`
+	Exemplars            []*Exemplar             `protobuf:"bytes,5,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *NumberDataPoint) Reset()         { *m = NumberDataPoint{} }
+func (m *NumberDataPoint) String() string { return proto.CompactTextString(m) }
+func (*NumberDataPoint) ProtoMessage()    {}
+func (*NumberDataPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{11}
+}
+
+func (m *NumberDataPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NumberDataPoint.Unmarshal(m, b)
+}
+func (m *NumberDataPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NumberDataPoint.Marshal(b, m, deterministic)
+}
+func (m *NumberDataPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NumberDataPoint.Merge(m, src)
+}
+func (m *NumberDataPoint) XXX_Size() int {
+	return xxx_messageInfo_NumberDataPoint.Size(m)
+}
+func (m *NumberDataPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_NumberDataPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NumberDataPoint proto.InternalMessageInfo
+
+func (m *NumberDataPoint) GetAttributes() []*v11.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *NumberDataPoint) GetLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *NumberDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+type isNumberDataPoint_Value interface {
+	isNumberDataPoint_Value()
+}
+
+type NumberDataPoint_AsDouble struct {
+	AsDouble float64 `protobuf:"fixed64,4,opt,name=as_double,json=asDouble,proto3,oneof"`
+}
+
+type NumberDataPoint_AsInt struct {
+	AsInt int64 `protobuf:"fixed64,6,opt,name=as_int,json=asInt,proto3,oneof"`
+}
+
+func (*NumberDataPoint_AsDouble) isNumberDataPoint_Value() {}
+
+func (*NumberDataPoint_AsInt) isNumberDataPoint_Value() {}
+
+func (m *NumberDataPoint) GetValue() isNumberDataPoint_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *NumberDataPoint) GetAsDouble() float64 {
+	if x, ok := m.GetValue().(*NumberDataPoint_AsDouble); ok {
+		return x.AsDouble
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetAsInt() int64 {
+	if x, ok := m.GetValue().(*NumberDataPoint_AsInt); ok {
+		return x.AsInt
+	}
+	return 0
+}
+
+func (m *NumberDataPoint) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*NumberDataPoint) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*NumberDataPoint_AsDouble)(nil),
+		(*NumberDataPoint_AsInt)(nil),
+	}
+}
+
+// Deprecated: Do not use.
+type IntHistogramDataPoint struct {
+	Labels               []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	StartTimeUnixNano    uint64                `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano         uint64                `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Count                uint64                `protobuf:"fixed64,4,opt,name=count,proto3" json:"count,omitempty"`
+	Sum                  int64                 `protobuf:"fixed64,5,opt,name=sum,proto3" json:"sum,omitempty"`
+	BucketCounts         []uint64              `protobuf:"fixed64,6,rep,packed,name=bucket_counts,json=bucketCounts,proto3" json:"bucket_counts,omitempty"`
+	ExplicitBounds       []float64             `protobuf:"fixed64,7,rep,packed,name=explicit_bounds,json=explicitBounds,proto3" json:"explicit_bounds,omitempty"`
+	Exemplars            []*IntExemplar        `protobuf:"bytes,8,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *IntHistogramDataPoint) Reset()         { *m = IntHistogramDataPoint{} }
+func (m *IntHistogramDataPoint) String() string { return proto.CompactTextString(m) }
+func (*IntHistogramDataPoint) ProtoMessage()    {}
+func (*IntHistogramDataPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{12}
+}
+
+func (m *IntHistogramDataPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntHistogramDataPoint.Unmarshal(m, b)
+}
+func (m *IntHistogramDataPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntHistogramDataPoint.Marshal(b, m, deterministic)
+}
+func (m *IntHistogramDataPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntHistogramDataPoint.Merge(m, src)
+}
+func (m *IntHistogramDataPoint) XXX_Size() int {
+	return xxx_messageInfo_IntHistogramDataPoint.Size(m)
+}
+func (m *IntHistogramDataPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntHistogramDataPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntHistogramDataPoint proto.InternalMessageInfo
+
+func (m *IntHistogramDataPoint) GetLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *IntHistogramDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *IntHistogramDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *IntHistogramDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *IntHistogramDataPoint) GetSum() int64 {
+	if m != nil {
+		return m.Sum
+	}
+	return 0
+}
+
+func (m *IntHistogramDataPoint) GetBucketCounts() []uint64 {
+	if m != nil {
+		return m.BucketCounts
+	}
+	return nil
+}
+
+func (m *IntHistogramDataPoint) GetExplicitBounds() []float64 {
+	if m != nil {
+		return m.ExplicitBounds
+	}
+	return nil
+}
+
+func (m *IntHistogramDataPoint) GetExemplars() []*IntExemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+type HistogramDataPoint struct {
+	Attributes           []*v11.KeyValue       `protobuf:"bytes,9,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Labels               []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"` // Deprecated: Do not use.
+	StartTimeUnixNano    uint64                `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano         uint64                `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Count                uint64                `protobuf:"fixed64,4,opt,name=count,proto3" json:"count,omitempty"`
+	Sum                  float64               `protobuf:"fixed64,5,opt,name=sum,proto3" json:"sum,omitempty"`
+	BucketCounts         []uint64              `protobuf:"fixed64,6,rep,packed,name=bucket_counts,json=bucketCounts,proto3" json:"bucket_counts,omitempty"`
+	ExplicitBounds       []float64             `protobuf:"fixed64,7,rep,packed,name=explicit_bounds,json=explicitBounds,proto3" json:"explicit_bounds,omitempty"`
+	Exemplars            []*Exemplar           `protobuf:"bytes,8,rep,name=exemplars,proto3" json:"exemplars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *HistogramDataPoint) Reset()         { *m = HistogramDataPoint{} }
+func (m *HistogramDataPoint) String() string { return proto.CompactTextString(m) }
+func (*HistogramDataPoint) ProtoMessage()    {}
+func (*HistogramDataPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{13}
+}
+
+func (m *HistogramDataPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistogramDataPoint.Unmarshal(m, b)
+}
+func (m *HistogramDataPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistogramDataPoint.Marshal(b, m, deterministic)
+}
+func (m *HistogramDataPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistogramDataPoint.Merge(m, src)
+}
+func (m *HistogramDataPoint) XXX_Size() int {
+	return xxx_messageInfo_HistogramDataPoint.Size(m)
+}
+func (m *HistogramDataPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistogramDataPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistogramDataPoint proto.InternalMessageInfo
+
+func (m *HistogramDataPoint) GetAttributes() []*v11.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *HistogramDataPoint) GetLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetSum() float64 {
+	if m != nil {
+		return m.Sum
+	}
+	return 0
+}
+
+func (m *HistogramDataPoint) GetBucketCounts() []uint64 {
+	if m != nil {
+		return m.BucketCounts
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetExplicitBounds() []float64 {
+	if m != nil {
+		return m.ExplicitBounds
+	}
+	return nil
+}
+
+func (m *HistogramDataPoint) GetExemplars() []*Exemplar {
+	if m != nil {
+		return m.Exemplars
+	}
+	return nil
+}
+
+type SummaryDataPoint struct {
+	Attributes           []*v11.KeyValue                     `protobuf:"bytes,7,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Labels               []*v11.StringKeyValue               `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"` // Deprecated: Do not use.
+	StartTimeUnixNano    uint64                              `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano         uint64                              `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Count                uint64                              `protobuf:"fixed64,4,opt,name=count,proto3" json:"count,omitempty"`
+	Sum                  float64                             `protobuf:"fixed64,5,opt,name=sum,proto3" json:"sum,omitempty"`
+	QuantileValues       []*SummaryDataPoint_ValueAtQuantile `protobuf:"bytes,6,rep,name=quantile_values,json=quantileValues,proto3" json:"quantile_values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                            `json:"-"`
+	XXX_unrecognized     []byte                              `json:"-"`
+	XXX_sizecache        int32                               `json:"-"`
+}
+
+func (m *SummaryDataPoint) Reset()         { *m = SummaryDataPoint{} }
+func (m *SummaryDataPoint) String() string { return proto.CompactTextString(m) }
+func (*SummaryDataPoint) ProtoMessage()    {}
+func (*SummaryDataPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{14}
+}
+
+func (m *SummaryDataPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SummaryDataPoint.Unmarshal(m, b)
+}
+func (m *SummaryDataPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SummaryDataPoint.Marshal(b, m, deterministic)
+}
+func (m *SummaryDataPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SummaryDataPoint.Merge(m, src)
+}
+func (m *SummaryDataPoint) XXX_Size() int {
+	return xxx_messageInfo_SummaryDataPoint.Size(m)
+}
+func (m *SummaryDataPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_SummaryDataPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SummaryDataPoint proto.InternalMessageInfo
+
+func (m *SummaryDataPoint) GetAttributes() []*v11.KeyValue {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *SummaryDataPoint) GetLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *SummaryDataPoint) GetStartTimeUnixNano() uint64 {
+	if m != nil {
+		return m.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetSum() float64 {
+	if m != nil {
+		return m.Sum
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint) GetQuantileValues() []*SummaryDataPoint_ValueAtQuantile {
+	if m != nil {
+		return m.QuantileValues
+	}
+	return nil
+}
+
+type SummaryDataPoint_ValueAtQuantile struct {
+	Quantile             float64  `protobuf:"fixed64,1,opt,name=quantile,proto3" json:"quantile,omitempty"`
+	Value                float64  `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) Reset()         { *m = SummaryDataPoint_ValueAtQuantile{} }
+func (m *SummaryDataPoint_ValueAtQuantile) String() string { return proto.CompactTextString(m) }
+func (*SummaryDataPoint_ValueAtQuantile) ProtoMessage()    {}
+func (*SummaryDataPoint_ValueAtQuantile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{14, 0}
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SummaryDataPoint_ValueAtQuantile.Unmarshal(m, b)
+}
+func (m *SummaryDataPoint_ValueAtQuantile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SummaryDataPoint_ValueAtQuantile.Marshal(b, m, deterministic)
+}
+func (m *SummaryDataPoint_ValueAtQuantile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SummaryDataPoint_ValueAtQuantile.Merge(m, src)
+}
+func (m *SummaryDataPoint_ValueAtQuantile) XXX_Size() int {
+	return xxx_messageInfo_SummaryDataPoint_ValueAtQuantile.Size(m)
+}
+func (m *SummaryDataPoint_ValueAtQuantile) XXX_DiscardUnknown() {
+	xxx_messageInfo_SummaryDataPoint_ValueAtQuantile.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SummaryDataPoint_ValueAtQuantile proto.InternalMessageInfo
+
+func (m *SummaryDataPoint_ValueAtQuantile) GetQuantile() float64 {
+	if m != nil {
+		return m.Quantile
+	}
+	return 0
+}
+
+func (m *SummaryDataPoint_ValueAtQuantile) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+// Deprecated: Do not use.
+type IntExemplar struct {
+	FilteredLabels       []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=filtered_labels,json=filteredLabels,proto3" json:"filtered_labels,omitempty"`
+	TimeUnixNano         uint64                `protobuf:"fixed64,2,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Value                int64                 `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	SpanId               []byte                `protobuf:"bytes,4,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	TraceId              []byte                `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *IntExemplar) Reset()         { *m = IntExemplar{} }
+func (m *IntExemplar) String() string { return proto.CompactTextString(m) }
+func (*IntExemplar) ProtoMessage()    {}
+func (*IntExemplar) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{15}
+}
+
+func (m *IntExemplar) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntExemplar.Unmarshal(m, b)
+}
+func (m *IntExemplar) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntExemplar.Marshal(b, m, deterministic)
+}
+func (m *IntExemplar) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntExemplar.Merge(m, src)
+}
+func (m *IntExemplar) XXX_Size() int {
+	return xxx_messageInfo_IntExemplar.Size(m)
+}
+func (m *IntExemplar) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntExemplar.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntExemplar proto.InternalMessageInfo
+
+func (m *IntExemplar) GetFilteredLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.FilteredLabels
+	}
+	return nil
+}
+
+func (m *IntExemplar) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+func (m *IntExemplar) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *IntExemplar) GetSpanId() []byte {
+	if m != nil {
+		return m.SpanId
+	}
+	return nil
+}
+
+func (m *IntExemplar) GetTraceId() []byte {
+	if m != nil {
+		return m.TraceId
+	}
+	return nil
+}
+
+type Exemplar struct {
+	FilteredAttributes []*v11.KeyValue       `protobuf:"bytes,7,rep,name=filtered_attributes,json=filteredAttributes,proto3" json:"filtered_attributes,omitempty"`
+	FilteredLabels     []*v11.StringKeyValue `protobuf:"bytes,1,rep,name=filtered_labels,json=filteredLabels,proto3" json:"filtered_labels,omitempty"` // Deprecated: Do not use.
+	TimeUnixNano       uint64                `protobuf:"fixed64,2,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//	*Exemplar_AsDouble
+	//	*Exemplar_AsInt
+	Value                isExemplar_Value `protobuf_oneof:"value"`
+	SpanId               []byte           `protobuf:"bytes,4,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	TraceId              []byte           `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Exemplar) Reset()         { *m = Exemplar{} }
+func (m *Exemplar) String() string { return proto.CompactTextString(m) }
+func (*Exemplar) ProtoMessage()    {}
+func (*Exemplar) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3c3112f9fa006917, []int{16}
+}
+
+func (m *Exemplar) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Exemplar.Unmarshal(m, b)
+}
+func (m *Exemplar) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Exemplar.Marshal(b, m, deterministic)
+}
+func (m *Exemplar) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Exemplar.Merge(m, src)
+}
+func (m *Exemplar) XXX_Size() int {
+	return xxx_messageInfo_Exemplar.Size(m)
+}
+func (m *Exemplar) XXX_DiscardUnknown() {
+	xxx_messageInfo_Exemplar.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Exemplar proto.InternalMessageInfo
+
+func (m *Exemplar) GetFilteredAttributes() []*v11.KeyValue {
+	if m != nil {
+		return m.FilteredAttributes
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (m *Exemplar) GetFilteredLabels() []*v11.StringKeyValue {
+	if m != nil {
+		return m.FilteredLabels
+	}
+	return nil
+}
+
+func (m *Exemplar) GetTimeUnixNano() uint64 {
+	if m != nil {
+		return m.TimeUnixNano
+	}
+	return 0
+}
+
+type isExemplar_Value interface {
+	isExemplar_Value()
+}
+
+type Exemplar_AsDouble struct {
+	AsDouble float64 `protobuf:"fixed64,3,opt,name=as_double,json=asDouble,proto3,oneof"`
+}
+
+type Exemplar_AsInt struct {
+	AsInt int64 `protobuf:"fixed64,6,opt,name=as_int,json=asInt,proto3,oneof"`
+}
+
+func (*Exemplar_AsDouble) isExemplar_Value() {}
+
+func (*Exemplar_AsInt) isExemplar_Value() {}
+
+func (m *Exemplar) GetValue() isExemplar_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Exemplar) GetAsDouble() float64 {
+	if x, ok := m.GetValue().(*Exemplar_AsDouble); ok {
+		return x.AsDouble
+	}
+	return 0
+}
+
+func (m *Exemplar) GetAsInt() int64 {
+	if x, ok := m.GetValue().(*Exemplar_AsInt); ok {
+		return x.AsInt
+	}
+	return 0
+}
+
+func (m *Exemplar) GetSpanId() []byte {
+	if m != nil {
+		return m.SpanId
+	}
+	return nil
+}
+
+func (m *Exemplar) GetTraceId() []byte {
+	if m != nil {
+		return m.TraceId
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Exemplar) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Exemplar_AsDouble)(nil),
+		(*Exemplar_AsInt)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterEnum("opentelemetry.proto.metrics.v1.AggregationTemporality", AggregationTemporality_name, AggregationTemporality_value)
+	proto.RegisterType((*ResourceMetrics)(nil), "opentelemetry.proto.metrics.v1.ResourceMetrics")
+	proto.RegisterType((*InstrumentationLibraryMetrics)(nil), "opentelemetry.proto.metrics.v1.InstrumentationLibraryMetrics")
+	proto.RegisterType((*Metric)(nil), "opentelemetry.proto.metrics.v1.Metric")
+	proto.RegisterType((*IntGauge)(nil), "opentelemetry.proto.metrics.v1.IntGauge")
+	proto.RegisterType((*Gauge)(nil), "opentelemetry.proto.metrics.v1.Gauge")
+	proto.RegisterType((*IntSum)(nil), "opentelemetry.proto.metrics.v1.IntSum")
+	proto.RegisterType((*Sum)(nil), "opentelemetry.proto.metrics.v1.Sum")
+	proto.RegisterType((*IntHistogram)(nil), "opentelemetry.proto.metrics.v1.IntHistogram")
+	proto.RegisterType((*Histogram)(nil), "opentelemetry.proto.metrics.v1.Histogram")
+	proto.RegisterType((*Summary)(nil), "opentelemetry.proto.metrics.v1.Summary")
+	proto.RegisterType((*IntDataPoint)(nil), "opentelemetry.proto.metrics.v1.IntDataPoint")
+	proto.RegisterType((*NumberDataPoint)(nil), "opentelemetry.proto.metrics.v1.NumberDataPoint")
+	proto.RegisterType((*IntHistogramDataPoint)(nil), "opentelemetry.proto.metrics.v1.IntHistogramDataPoint")
+	proto.RegisterType((*HistogramDataPoint)(nil), "opentelemetry.proto.metrics.v1.HistogramDataPoint")
+	proto.RegisterType((*SummaryDataPoint)(nil), "opentelemetry.proto.metrics.v1.SummaryDataPoint")
+	proto.RegisterType((*SummaryDataPoint_ValueAtQuantile)(nil), "opentelemetry.proto.metrics.v1.SummaryDataPoint.ValueAtQuantile")
+	proto.RegisterType((*IntExemplar)(nil), "opentelemetry.proto.metrics.v1.IntExemplar")
+	proto.RegisterType((*Exemplar)(nil), "opentelemetry.proto.metrics.v1.Exemplar")
+}
+
+func init() {
+	proto.RegisterFile("opentelemetry/proto/metrics/v1/metrics.proto", fileDescriptor_3c3112f9fa006917)
+}
+
+var fileDescriptor_3c3112f9fa006917 = []byte{
+	// 1303 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0xdf, 0x6f, 0x1b, 0xc5,
+	0x13, 0xcf, 0xf9, 0xe2, 0xb3, 0x3d, 0x4e, 0x13, 0x7f, 0xf7, 0x5b, 0x9a, 0x23, 0x52, 0xc0, 0x75,
+	0xa1, 0x0d, 0xa5, 0xd8, 0x34, 0xa8, 0x20, 0x21, 0x55, 0xaa, 0x93, 0xb8, 0xc9, 0xa9, 0x49, 0xea,
+	0x6e, 0x9c, 0x40, 0x2b, 0xd0, 0x69, 0x6d, 0x6f, 0xdd, 0x15, 0x77, 0x7b, 0xe6, 0x6e, 0x2f, 0x4a,
+	0xfe, 0x80, 0xbe, 0xf1, 0xc2, 0x1f, 0xc4, 0xdf, 0x80, 0x78, 0xe0, 0x01, 0xf5, 0x4f, 0xe0, 0x01,
+	0x9e, 0x79, 0x01, 0xed, 0xde, 0x9d, 0x7f, 0xa4, 0x97, 0xd8, 0x4d, 0x5b, 0x29, 0x82, 0xb7, 0xdd,
+	0xd9, 0x99, 0xcf, 0xcd, 0x7c, 0x66, 0x67, 0x66, 0x6d, 0xb8, 0xe5, 0xf5, 0x29, 0x17, 0xd4, 0xa1,
+	0x2e, 0x15, 0xfe, 0x71, 0xad, 0xef, 0x7b, 0xc2, 0xab, 0xc9, 0x35, 0xeb, 0x04, 0xb5, 0xc3, 0xdb,
+	0xc9, 0xb2, 0xaa, 0x0e, 0xd0, 0x7b, 0x63, 0xda, 0x91, 0xb0, 0x9a, 0xa8, 0x1c, 0xde, 0x5e, 0xba,
+	0x99, 0x86, 0xd6, 0xf1, 0x5c, 0xd7, 0xe3, 0x12, 0x2c, 0x5a, 0x45, 0x66, 0x4b, 0xd5, 0x34, 0x5d,
+	0x9f, 0x06, 0x5e, 0xe8, 0x77, 0xa8, 0xd4, 0x4e, 0xd6, 0x91, 0x7e, 0xe5, 0x6f, 0x0d, 0x16, 0x70,
+	0x2c, 0xda, 0x89, 0x3e, 0x89, 0x1a, 0x90, 0x4f, 0xb4, 0x4c, 0xad, 0xac, 0xad, 0x14, 0x57, 0x3f,
+	0xaa, 0xa6, 0xb9, 0x38, 0x80, 0x3a, 0xbc, 0x5d, 0x4d, 0x30, 0xf0, 0xc0, 0x14, 0x3d, 0xd7, 0xe0,
+	0x7d, 0xc6, 0x03, 0xe1, 0x87, 0x2e, 0xe5, 0x82, 0x08, 0xe6, 0x71, 0xdb, 0x61, 0x6d, 0x9f, 0xf8,
+	0xc7, 0x76, 0x1c, 0x9d, 0x99, 0x29, 0xeb, 0x2b, 0xc5, 0xd5, 0xbb, 0xd5, 0xb3, 0x19, 0xa8, 0x5a,
+	0xe3, 0x30, 0xdb, 0x11, 0x4a, 0xec, 0x2f, 0x5e, 0x66, 0x67, 0x1d, 0xa3, 0x65, 0x80, 0xa0, 0xf3,
+	0x8c, 0xba, 0xc4, 0x0e, 0x7d, 0xc7, 0xd4, 0xcb, 0xda, 0x4a, 0x01, 0x17, 0x22, 0xc9, 0xbe, 0xef,
+	0x54, 0xfe, 0xd4, 0x60, 0xf9, 0x4c, 0x7c, 0xc4, 0x61, 0xf1, 0x94, 0x38, 0x62, 0x7a, 0xee, 0xa4,
+	0xfa, 0x1f, 0xe7, 0xe5, 0x54, 0xf7, 0xf1, 0x95, 0x74, 0xbf, 0xd1, 0x3d, 0xc8, 0x8d, 0xf3, 0x73,
+	0x7d, 0x12, 0x3f, 0x91, 0xa7, 0x38, 0xe7, 0x4e, 0x17, 0xf2, 0xcf, 0xb3, 0x60, 0x44, 0x26, 0x08,
+	0xc1, 0x2c, 0x27, 0x6e, 0x94, 0xe7, 0x02, 0x56, 0x6b, 0x54, 0x86, 0x62, 0x97, 0x06, 0x1d, 0x9f,
+	0xf5, 0xa5, 0x57, 0x66, 0x46, 0x1d, 0x8d, 0x8a, 0xa4, 0x55, 0xc8, 0x99, 0x88, 0x91, 0xd5, 0x1a,
+	0x3d, 0x80, 0x02, 0xe3, 0xc2, 0xee, 0x91, 0xb0, 0x47, 0xcd, 0x59, 0xc5, 0xcb, 0xca, 0xe4, 0xbc,
+	0x8a, 0x4d, 0xa9, 0xbf, 0x96, 0x31, 0xb5, 0xad, 0x19, 0x9c, 0x67, 0xf1, 0x1e, 0xdd, 0x85, 0x6c,
+	0x04, 0x94, 0x55, 0x40, 0x1f, 0x4e, 0x02, 0x52, 0x56, 0x5b, 0x33, 0x38, 0xb2, 0x42, 0x0d, 0xc8,
+	0x49, 0x5f, 0x82, 0xd0, 0x35, 0x8d, 0xb2, 0x36, 0x0d, 0x83, 0x16, 0x17, 0x7b, 0xa1, 0x1b, 0xfb,
+	0x61, 0x30, 0xb5, 0x43, 0x5f, 0x80, 0x2e, 0x21, 0x72, 0x0a, 0xe2, 0xda, 0x24, 0x88, 0xbd, 0xd0,
+	0xdd, 0x9a, 0xc1, 0xd2, 0x02, 0x7d, 0x05, 0x97, 0xe4, 0xf7, 0x9f, 0xb1, 0x40, 0x78, 0x3d, 0x9f,
+	0xb8, 0x66, 0x5e, 0x41, 0xdc, 0x9a, 0xc2, 0x8b, 0xad, 0xc4, 0x26, 0xf6, 0x65, 0x8e, 0x8d, 0xc8,
+	0x90, 0x05, 0x85, 0x21, 0x68, 0xe1, 0x8c, 0xda, 0x1c, 0x01, 0x1d, 0x58, 0x6f, 0xcd, 0xe0, 0xa1,
+	0x35, 0x5a, 0x87, 0x5c, 0x10, 0xba, 0xae, 0xbc, 0xc5, 0x45, 0x05, 0x74, 0x63, 0x8a, 0x00, 0xa5,
+	0xfa, 0xd6, 0x0c, 0x4e, 0x2c, 0xd7, 0x0c, 0x98, 0xed, 0x12, 0x41, 0x2a, 0xdf, 0x42, 0x3e, 0xc9,
+	0x25, 0xda, 0x81, 0xa2, 0x94, 0xd9, 0x7d, 0x8f, 0x71, 0x11, 0x98, 0x5a, 0x59, 0x9f, 0x32, 0xf4,
+	0x0d, 0x22, 0x48, 0x53, 0x1a, 0x61, 0xe8, 0x26, 0xcb, 0xe0, 0xcb, 0x8c, 0xa9, 0x55, 0x1e, 0x43,
+	0x36, 0xc2, 0x6e, 0xa6, 0x61, 0xd7, 0x26, 0x61, 0xef, 0x86, 0x6e, 0x9b, 0xfa, 0xa9, 0xf0, 0x95,
+	0x3f, 0x34, 0x30, 0xa2, 0xe4, 0xbf, 0x61, 0xc7, 0x91, 0x07, 0x8b, 0xa4, 0xd7, 0xf3, 0x69, 0x2f,
+	0x6a, 0x19, 0x82, 0xba, 0x7d, 0xcf, 0x27, 0x0e, 0x13, 0xc7, 0xaa, 0xa4, 0xe6, 0x57, 0x3f, 0x9f,
+	0x04, 0x5d, 0x1f, 0x9a, 0xb7, 0x86, 0xd6, 0xf8, 0x0a, 0x49, 0x95, 0xa3, 0xab, 0x30, 0xc7, 0x02,
+	0xdb, 0xf5, 0xb8, 0x27, 0x3c, 0xce, 0x3a, 0xaa, 0x3a, 0xf3, 0xb8, 0xc8, 0x82, 0x9d, 0x44, 0xa4,
+	0xc8, 0xfc, 0x5d, 0x03, 0x5d, 0x86, 0xfb, 0xc6, 0xb9, 0xbc, 0x88, 0x11, 0x57, 0x5e, 0x68, 0x30,
+	0x37, 0x5a, 0x56, 0xe8, 0x20, 0x2d, 0xec, 0x3b, 0xaf, 0x52, 0x99, 0x17, 0x23, 0x78, 0x95, 0xcb,
+	0x5f, 0x34, 0x28, 0x0c, 0x43, 0xdb, 0x4b, 0x0b, 0x6d, 0x75, 0xea, 0xfe, 0x70, 0x31, 0xe2, 0xaa,
+	0x7c, 0x03, 0xb9, 0xb8, 0xd3, 0xa0, 0x47, 0x69, 0x01, 0x7d, 0x3a, 0x65, 0x9f, 0x4a, 0xaf, 0xf7,
+	0x1f, 0x33, 0xea, 0x3e, 0x0c, 0x0e, 0x51, 0x03, 0x0c, 0x87, 0xb4, 0xa9, 0x93, 0xc0, 0x7f, 0x32,
+	0x61, 0x98, 0xef, 0x09, 0x9f, 0xf1, 0xde, 0x03, 0x7a, 0x7c, 0x40, 0x9c, 0x90, 0xe2, 0xd8, 0x18,
+	0xd5, 0xe0, 0x72, 0x20, 0x88, 0x2f, 0x6c, 0xc1, 0x5c, 0x6a, 0x87, 0x9c, 0x1d, 0xd9, 0x9c, 0x70,
+	0x4f, 0x71, 0x64, 0xe0, 0xff, 0xa9, 0xb3, 0x16, 0x73, 0xe9, 0x3e, 0x67, 0x47, 0xbb, 0x84, 0x7b,
+	0xe8, 0x03, 0x98, 0x3f, 0xa1, 0xaa, 0x2b, 0xd5, 0x39, 0x31, 0xaa, 0x75, 0x19, 0xb2, 0x87, 0xf2,
+	0x3b, 0x6a, 0xa2, 0x96, 0x70, 0xb4, 0x91, 0x63, 0x80, 0x1e, 0x51, 0xb7, 0xef, 0x10, 0x3f, 0x30,
+	0xb3, 0xca, 0xed, 0x8f, 0xa7, 0xb8, 0xc1, 0x8d, 0xd8, 0x06, 0x0f, 0xad, 0xd5, 0x2d, 0x7a, 0xae,
+	0xc3, 0xc2, 0x89, 0xba, 0x46, 0x9b, 0x00, 0x44, 0x08, 0x9f, 0xb5, 0x43, 0x41, 0x03, 0x33, 0x57,
+	0xd6, 0x4f, 0x9d, 0x10, 0x43, 0x6a, 0x06, 0xa4, 0x8c, 0x98, 0x22, 0xeb, 0xb5, 0xf8, 0x95, 0x53,
+	0xf0, 0x6d, 0x73, 0xbc, 0x0c, 0x05, 0x12, 0xd8, 0x5d, 0x2f, 0x6c, 0x3b, 0x11, 0xcf, 0xea, 0x2d,
+	0x42, 0x82, 0x0d, 0x25, 0x41, 0x8b, 0x60, 0x90, 0xc0, 0x66, 0x5c, 0xa8, 0xb7, 0x44, 0x49, 0xbe,
+	0x32, 0x48, 0x60, 0x71, 0x81, 0xee, 0xbf, 0x9c, 0x85, 0x89, 0x2f, 0x9e, 0x94, 0x14, 0xac, 0xe5,
+	0xe2, 0x1c, 0x57, 0xfe, 0xca, 0xc0, 0x3b, 0xa9, 0x8d, 0xe6, 0xe2, 0x5f, 0xd2, 0x8e, 0x17, 0x72,
+	0xa1, 0xc8, 0x33, 0x70, 0xb4, 0x41, 0xa5, 0xe8, 0xf5, 0x94, 0x55, 0x17, 0x57, 0x2e, 0xd1, 0x35,
+	0xb8, 0xd4, 0x0e, 0x3b, 0xdf, 0x51, 0x61, 0x2b, 0x8d, 0xc0, 0x34, 0xca, 0xba, 0x04, 0x8b, 0x84,
+	0xeb, 0x4a, 0x86, 0x6e, 0xc0, 0x02, 0x3d, 0xea, 0x3b, 0xac, 0xc3, 0x84, 0xdd, 0xf6, 0x42, 0xde,
+	0x8d, 0x6e, 0x9f, 0x86, 0xe7, 0x13, 0xf1, 0x9a, 0x92, 0x8e, 0x17, 0x41, 0xfe, 0xb5, 0x8b, 0xe0,
+	0x27, 0x1d, 0x50, 0x0a, 0xf3, 0xe3, 0x75, 0x50, 0xf8, 0x37, 0xd7, 0xc1, 0xc4, 0x34, 0x6a, 0x6f,
+	0x23, 0x8d, 0xf7, 0x5f, 0x4e, 0xe3, 0x79, 0xaa, 0xa8, 0xf2, 0xab, 0x0e, 0xa5, 0x93, 0x9d, 0xff,
+	0xbf, 0xd4, 0xc5, 0xa6, 0xcd, 0x1e, 0x83, 0x85, 0xef, 0x43, 0xc2, 0x05, 0x73, 0xa8, 0xad, 0xda,
+	0x4e, 0x94, 0xbf, 0xe2, 0xea, 0xbd, 0x57, 0x9d, 0xab, 0x55, 0x15, 0x5b, 0x5d, 0x3c, 0x8a, 0xe1,
+	0xf0, 0x7c, 0x02, 0xac, 0x0e, 0x82, 0xa5, 0x75, 0x58, 0x38, 0xa1, 0x82, 0x96, 0x20, 0x9f, 0x28,
+	0xa9, 0xdf, 0x9c, 0x1a, 0x1e, 0xec, 0x87, 0xb3, 0x2e, 0xa3, 0x0e, 0xe2, 0xa6, 0xf8, 0x9b, 0x06,
+	0xc5, 0x91, 0xb2, 0x45, 0x07, 0xb0, 0xf0, 0x94, 0x39, 0x82, 0xfa, 0xb4, 0x6b, 0xbf, 0x4e, 0x4f,
+	0x9c, 0x4f, 0x50, 0xb6, 0xa3, 0xb4, 0xbc, 0xcc, 0x72, 0xe6, 0xac, 0x79, 0xac, 0x8f, 0xce, 0xe3,
+	0x45, 0xc8, 0x05, 0x7d, 0xc2, 0x6d, 0xd6, 0x55, 0xec, 0xcf, 0x61, 0x43, 0x6e, 0xad, 0x2e, 0x7a,
+	0x17, 0xf2, 0xc2, 0x27, 0x1d, 0x2a, 0x4f, 0xb2, 0xea, 0x24, 0xa7, 0xf6, 0x56, 0x57, 0xf5, 0x9c,
+	0x17, 0x19, 0xc8, 0x0f, 0x02, 0xfb, 0x1a, 0xfe, 0x3f, 0x08, 0xec, 0xfc, 0x97, 0x16, 0x25, 0x18,
+	0xf5, 0xe1, 0xe5, 0x7d, 0xf2, 0x66, 0x28, 0x53, 0xb7, 0xf8, 0x7c, 0xb4, 0x8d, 0x8d, 0x58, 0x7d,
+	0xfa, 0x11, 0x7b, 0x0e, 0x62, 0x07, 0xe3, 0xf4, 0xe6, 0x0f, 0x1a, 0x5c, 0x49, 0x7f, 0x7b, 0xa2,
+	0x1b, 0x70, 0xad, 0xbe, 0xb9, 0x89, 0x1b, 0x9b, 0xf5, 0x96, 0xf5, 0x70, 0xd7, 0x6e, 0x35, 0x76,
+	0x9a, 0x0f, 0x71, 0x7d, 0xdb, 0x6a, 0x3d, 0xb6, 0xf7, 0x77, 0xf7, 0x9a, 0x8d, 0x75, 0xeb, 0xbe,
+	0xd5, 0xd8, 0x28, 0xcd, 0xa0, 0xab, 0xb0, 0x7c, 0x9a, 0xe2, 0x46, 0x63, 0xbb, 0x55, 0x2f, 0x69,
+	0xe8, 0x3a, 0x54, 0x4e, 0x53, 0x59, 0xdf, 0xdf, 0xd9, 0xdf, 0xae, 0xb7, 0xac, 0x83, 0x46, 0x29,
+	0xb3, 0xe6, 0xc3, 0x55, 0xe6, 0x4d, 0xa8, 0xb1, 0xb5, 0xb9, 0xf8, 0x4f, 0xa7, 0xa6, 0x3c, 0x68,
+	0x6a, 0x4f, 0xee, 0xf6, 0x98, 0x78, 0x16, 0xb6, 0x65, 0x4e, 0x6a, 0x8c, 0x3f, 0x75, 0xc2, 0x23,
+	0xf9, 0x96, 0xad, 0x49, 0x84, 0x9e, 0x4f, 0x9e, 0xd6, 0xfa, 0x4e, 0xd8, 0x63, 0x3c, 0x48, 0xfe,
+	0x0e, 0xf4, 0x84, 0xd3, 0x1f, 0xf9, 0xa3, 0xb1, 0x6d, 0xa8, 0x0f, 0x7c, 0xf6, 0xcf, 0x00, 0xee,
+	0x01, 0x31, 0xfb, 0x91, 0x14, 0x00, 0x00,
+}
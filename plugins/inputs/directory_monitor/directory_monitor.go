@@ -0,0 +1,417 @@
+package directory_monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+const (
+	defaultStableDuration      = time.Second
+	defaultMaxUndeliveredFiles = 10
+
+	// maxMoveAttempts limits the names tried for a moved file
+	maxMoveAttempts = 100
+)
+
+type empty struct{}
+type semaphore chan empty
+
+type DirectoryMonitor struct {
+	Directory           string            `toml:"directory"`
+	FinishedDirectory   string            `toml:"finished_directory"`
+	ErrorDirectory      string            `toml:"error_directory"`
+	FilesToMonitor      []string          `toml:"files_to_monitor"`
+	FilesToIgnore       []string          `toml:"files_to_ignore"`
+	StableDuration      internal.Duration `toml:"stable_duration"`
+	ContentEncoding     string            `toml:"content_encoding"`
+	FileTag             string            `toml:"file_tag"`
+	MaxUndeliveredFiles int               `toml:"max_undelivered_files"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	parserFunc parsers.ParserFunc
+	acc        telegraf.TrackingAccumulator
+	sem        semaphore
+	wg         sync.WaitGroup
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	// pending are the files whose metrics are not yet delivered
+	pending map[telegraf.TrackingID]string
+	// processing are the files whose metrics were added but which are not
+	// moved yet
+	processing map[string]bool
+	mu         sync.Mutex
+}
+
+const sampleConfig = `
+  ## Directory to monitor for new files.
+  directory = ""
+
+  ## Directory to move files to once all of their metrics are written by the
+  ## outputs.
+  finished_directory = ""
+
+  ## Directory to move files to if they cannot be read or parsed, or if their
+  ## metrics are rejected by an output.
+  error_directory = ""
+
+  ## File names to process and to ignore in the directory.  These accept
+  ## standard unix glob matching rules against the name of the file, ie:
+  ##   "*.csv"      -> all files ending in .csv
+  ##   "metrics-*"  -> all files starting with metrics-
+  ## By default all files are processed; files in subdirectories are never
+  ## processed.
+  # files_to_monitor = ["*"]
+  # files_to_ignore = [".*"]
+
+  ## Minimum time since the last modification of a file before it is
+  ## processed, so that files still being written are not picked up.
+  # stable_duration = "1s"
+
+  ## Content encoding of the files, can be "identity", "gzip", or "auto" to
+  ## decompress only files ending in ".gz".
+  # content_encoding = "identity"
+
+  ## Name of a tag containing the name of the file the metrics were parsed
+  ## from.  Leave empty to disable.
+  # file_tag = ""
+
+  ## Maximum number of files whose metrics have not yet been written by the
+  ## outputs.  Once reached, new files are left in the directory until the
+  ## metrics of the previous ones are delivered.
+  # max_undelivered_files = 10
+
+  ## Data format to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+func (d *DirectoryMonitor) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DirectoryMonitor) Description() string {
+	return "Ingest files dropped into a directory and move them once processed"
+}
+
+func (d *DirectoryMonitor) Init() error {
+	if d.Directory == "" || d.FinishedDirectory == "" || d.ErrorDirectory == "" {
+		return errors.New("directory, finished_directory and error_directory must be set")
+	}
+	for _, dir := range []string{d.FinishedDirectory, d.ErrorDirectory} {
+		if filepath.Clean(dir) == filepath.Clean(d.Directory) {
+			return fmt.Errorf("%q must differ from the monitored directory", dir)
+		}
+	}
+
+	if len(d.FilesToMonitor) == 0 {
+		d.FilesToMonitor = []string{"*"}
+	}
+	for _, pattern := range append(d.FilesToMonitor, d.FilesToIgnore...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %v", pattern, err)
+		}
+	}
+
+	switch d.ContentEncoding {
+	case "", "identity", "gzip", "auto":
+	default:
+		return fmt.Errorf("invalid content_encoding %q", d.ContentEncoding)
+	}
+
+	if d.MaxUndeliveredFiles <= 0 {
+		return errors.New("max_undelivered_files must be positive")
+	}
+	d.sem = make(semaphore, d.MaxUndeliveredFiles)
+	d.pending = make(map[telegraf.TrackingID]string)
+	d.processing = make(map[string]bool)
+	return nil
+}
+
+func (d *DirectoryMonitor) Start(acc telegraf.Accumulator) error {
+	for _, dir := range []string{d.FinishedDirectory, d.ErrorDirectory} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	d.acc = acc.WithTracking(d.MaxUndeliveredFiles)
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case info := <-d.acc.Delivered():
+				d.onDelivery(info)
+			}
+		}
+	}()
+	return nil
+}
+
+// Gather processes the stable files of the directory, as long as the
+// metrics of less than max_undelivered_files files are undelivered.
+func (d *DirectoryMonitor) Gather(acc telegraf.Accumulator) error {
+	files, err := d.stableFiles(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		select {
+		case d.sem <- empty{}:
+		default:
+			d.Log.Debugf("Maximum of undelivered files reached, deferring %q", file)
+			return nil
+		}
+		d.processFile(file)
+	}
+	return nil
+}
+
+func (d *DirectoryMonitor) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *DirectoryMonitor) SetParserFunc(fn parsers.ParserFunc) {
+	d.parserFunc = fn
+}
+
+// stableFiles returns the files of the directory matching the patterns, which
+// are not processed yet and were not modified for the stable duration.
+func (d *DirectoryMonitor) stableFiles(now time.Time) ([]string, error) {
+	infos, err := ioutil.ReadDir(d.Directory)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var files []string
+	for _, info := range infos {
+		if !info.Mode().IsRegular() || !d.matches(info.Name()) {
+			continue
+		}
+		if now.Sub(info.ModTime()) < d.StableDuration.Duration {
+			continue
+		}
+		file := filepath.Join(d.Directory, info.Name())
+		if d.processing[file] {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (d *DirectoryMonitor) matches(name string) bool {
+	for _, pattern := range d.FilesToIgnore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return false
+		}
+	}
+	for _, pattern := range d.FilesToMonitor {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// processFile parses the file and adds its metrics as a tracking group; the
+// file is moved once the group is delivered.  Files which cannot be parsed
+// are moved to the error directory, and files without metrics to the
+// finished directory right away.
+func (d *DirectoryMonitor) processFile(file string) {
+	metrics, err := d.parseFile(file)
+	if err != nil {
+		<-d.sem
+		d.acc.AddError(fmt.Errorf("processing %q: %v", file, err))
+		d.moveFile(file, d.ErrorDirectory)
+		return
+	}
+	if len(metrics) == 0 {
+		<-d.sem
+		d.Log.Debugf("No metrics in %q", file)
+		d.moveFile(file, d.FinishedDirectory)
+		return
+	}
+
+	if d.FileTag != "" {
+		for _, m := range metrics {
+			m.AddTag(d.FileTag, filepath.Base(file))
+		}
+	}
+
+	// Hold the lock while adding the group, so that its delivery cannot be
+	// handled before the file is registered as pending.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.processing[file] = true
+	id := d.acc.AddTrackingMetricGroup(metrics)
+	d.pending[id] = file
+}
+
+func (d *DirectoryMonitor) parseFile(file string) ([]telegraf.Metric, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	encoding := d.ContentEncoding
+	if encoding == "auto" {
+		encoding = "identity"
+		if strings.HasSuffix(file, ".gz") {
+			encoding = "gzip"
+		}
+	}
+	if encoding == "gzip" {
+		r, err = internal.NewGzipReader(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new parser for every file, as some parsers like csv keep state
+	// between calls.
+	parser, err := d.parserFunc()
+	if err != nil {
+		return nil, fmt.Errorf("creating parser: %v", err)
+	}
+	return parser.Parse(content)
+}
+
+func (d *DirectoryMonitor) onDelivery(info telegraf.DeliveryInfo) {
+	d.mu.Lock()
+	file, ok := d.pending[info.ID()]
+	delete(d.pending, info.ID())
+	d.mu.Unlock()
+	if !ok {
+		return
+	}
+	<-d.sem
+
+	dir := d.FinishedDirectory
+	if !info.Delivered() {
+		d.Log.Errorf("Metrics of %q were not delivered", file)
+		dir = d.ErrorDirectory
+	}
+
+	// Files which cannot be moved stay marked as processing, so that their
+	// metrics are not added again.
+	if d.moveFile(file, dir) {
+		d.mu.Lock()
+		delete(d.processing, file)
+		d.mu.Unlock()
+	}
+}
+
+// moveFile moves the file into dir, copying it if it is on another device,
+// and reports whether it succeeded.  Files in dir are never overwritten: if
+// the name is taken the time of the move, and if needed a counter, is added
+// to the name.
+func (d *DirectoryMonitor) moveFile(file, dir string) bool {
+	name := filepath.Base(file)
+	ext := filepath.Ext(name)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	var dst string
+	var err error
+	for i := 0; i < maxMoveAttempts; i++ {
+		switch i {
+		case 0:
+			dst = filepath.Join(dir, name)
+		case 1:
+			dst = filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+stamp+ext)
+		default:
+			dst = filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+stamp+"-"+strconv.Itoa(i-1)+ext)
+		}
+		err = linkFile(file, dst)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err == nil {
+		err = os.Remove(file)
+	}
+	if err != nil {
+		d.Log.Errorf("Moving %q to %q: %v", file, dir, err)
+		return false
+	}
+	d.Log.Debugf("Moved %q to %q", file, dst)
+	return true
+}
+
+// linkFile creates dst as hard link of src, or as copy if src cannot be
+// linked, for example because it is on another device.  Unlike a rename it
+// fails if dst exists.
+func linkFile(src, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil || os.IsExist(err) {
+		return err
+	}
+	return copyFile(src, dst)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+func init() {
+	inputs.Add("directory_monitor", func() telegraf.Input {
+		return &DirectoryMonitor{
+			StableDuration:      internal.Duration{Duration: defaultStableDuration},
+			ContentEncoding:     "identity",
+			MaxUndeliveredFiles: defaultMaxUndeliveredFiles,
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package directory_monitor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetricMaker struct{}
+
+func (tm *testMetricMaker) LogName() string {
+	return "directory_monitor"
+}
+
+func (tm *testMetricMaker) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	return metric
+}
+
+func (tm *testMetricMaker) Log() telegraf.Logger {
+	return testutil.Logger{}
+}
+
+// setup returns a started plugin monitoring a new temporary directory, and
+// the channel the metrics of the plugin are sent to.
+func setup(t *testing.T, d *DirectoryMonitor) (*DirectoryMonitor, chan telegraf.Metric, func()) {
+	tmp, err := ioutil.TempDir("", "directory_monitor")
+	require.NoError(t, err)
+
+	d.Directory = filepath.Join(tmp, "spool")
+	d.FinishedDirectory = filepath.Join(tmp, "finished")
+	d.ErrorDirectory = filepath.Join(tmp, "error")
+	d.Log = testutil.Logger{}
+	if d.MaxUndeliveredFiles == 0 {
+		d.MaxUndeliveredFiles = defaultMaxUndeliveredFiles
+	}
+	d.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			MetricName: "directory_monitor",
+			DataFormat: "influx",
+		})
+	})
+	require.NoError(t, os.Mkdir(d.Directory, 0755))
+	require.NoError(t, d.Init())
+
+	metrics := make(chan telegraf.Metric, 100)
+	require.NoError(t, d.Start(agent.NewAccumulator(&testMetricMaker{}, metrics)))
+
+	return d, metrics, func() {
+		d.Stop()
+		os.RemoveAll(tmp)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func requireFile(t *testing.T, dir, name string) {
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "%s not in %s", name, dir)
+}
+
+func requireNoFile(t *testing.T, dir, name string) {
+	_, err := os.Stat(filepath.Join(dir, name))
+	require.True(t, os.IsNotExist(err), "%s in %s", name, dir)
+}
+
+func TestMoveDeliveredFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{FileTag: "file"})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "a.txt", "cpu value=1 1500000000000000000\ncpu value=2 1500000000000000000\n")
+	require.NoError(t, d.Gather(nil))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"file": "a.txt"},
+			map[string]interface{}{"value": 1.0}, time.Unix(1500000000, 0)),
+		testutil.MustMetric("cpu", map[string]string{"file": "a.txt"},
+			map[string]interface{}{"value": 2.0}, time.Unix(1500000000, 0)),
+	}
+	actual := []telegraf.Metric{<-metrics, <-metrics}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The file is neither moved nor processed again until the metrics are
+	// delivered.
+	require.NoError(t, d.Gather(nil))
+	require.Len(t, metrics, 0)
+	requireFile(t, d.Directory, "a.txt")
+
+	actual[0].Accept()
+	actual[1].Accept()
+	requireFile(t, d.FinishedDirectory, "a.txt")
+	requireNoFile(t, d.Directory, "a.txt")
+	requireNoFile(t, d.ErrorDirectory, "a.txt")
+}
+
+func TestMoveRejectedFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "a.txt", "cpu value=1 1500000000000000000\n")
+	require.NoError(t, d.Gather(nil))
+
+	m := <-metrics
+	m.Reject()
+	requireFile(t, d.ErrorDirectory, "a.txt")
+	requireNoFile(t, d.Directory, "a.txt")
+}
+
+func TestMoveInvalidFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "invalid.txt", "cpu value=\n")
+	writeFile(t, d.Directory, "empty.txt", "")
+	require.NoError(t, d.Gather(nil))
+
+	require.Len(t, metrics, 0)
+	requireFile(t, d.ErrorDirectory, "invalid.txt")
+	requireFile(t, d.FinishedDirectory, "empty.txt")
+}
+
+func TestMoveKeepsExistingFiles(t *testing.T) {
+	d, _, cleanup := setup(t, &DirectoryMonitor{})
+	defer cleanup()
+
+	writeFile(t, d.ErrorDirectory, "a.txt", "old")
+	for i := 0; i < 3; i++ {
+		writeFile(t, d.Directory, "a.txt", "cpu value=\n")
+		require.NoError(t, d.Gather(nil))
+		requireNoFile(t, d.Directory, "a.txt")
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(d.ErrorDirectory, "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "old", string(content))
+
+	files, err := filepath.Glob(filepath.Join(d.ErrorDirectory, "a-*Z*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, "cpu value=\n", string(content))
+	}
+}
+
+func TestGzipFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{ContentEncoding: "auto"})
+	defer cleanup()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("cpu value=1 1500000000000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	writeFile(t, d.Directory, "a.txt.gz", buf.String())
+	writeFile(t, d.Directory, "b.txt", "mem value=2 1500000000000000000\n")
+	require.NoError(t, d.Gather(nil))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{},
+			map[string]interface{}{"value": 1.0}, time.Unix(1500000000, 0)),
+		testutil.MustMetric("mem", map[string]string{},
+			map[string]interface{}{"value": 2.0}, time.Unix(1500000000, 0)),
+	}
+	actual := []telegraf.Metric{<-metrics, <-metrics}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
+
+func TestFilePatterns(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{
+		FilesToMonitor: []string{"*.txt"},
+		FilesToIgnore:  []string{"skip*"},
+	})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "a.txt", "cpu value=1 1500000000000000000\n")
+	writeFile(t, d.Directory, "a.csv", "cpu value=2 1500000000000000000\n")
+	writeFile(t, d.Directory, "skip.txt", "cpu value=3 1500000000000000000\n")
+	require.NoError(t, d.Gather(nil))
+
+	m := <-metrics
+	require.Equal(t, 1.0, m.Fields()["value"])
+	require.Len(t, metrics, 0)
+}
+
+func TestUnstableFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{
+		StableDuration: internal.Duration{Duration: time.Hour},
+	})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "new.txt", "cpu value=1 1500000000000000000\n")
+	writeFile(t, d.Directory, "old.txt", "cpu value=2 1500000000000000000\n")
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(d.Directory, "old.txt"), old, old))
+	require.NoError(t, d.Gather(nil))
+
+	m := <-metrics
+	require.Equal(t, 2.0, m.Fields()["value"])
+	require.Len(t, metrics, 0)
+}
+
+func TestMaxUndeliveredFiles(t *testing.T) {
+	d, metrics, cleanup := setup(t, &DirectoryMonitor{MaxUndeliveredFiles: 1})
+	defer cleanup()
+
+	writeFile(t, d.Directory, "a.txt", "cpu value=1 1500000000000000000\n")
+	writeFile(t, d.Directory, "b.txt", "cpu value=2 1500000000000000000\n")
+	old := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(d.Directory, "a.txt"), old, old))
+	require.NoError(t, d.Gather(nil))
+
+	// The oldest file is processed first
+	m := <-metrics
+	require.Equal(t, 1.0, m.Fields()["value"])
+	require.Len(t, metrics, 0)
+
+	m.Accept()
+	requireFile(t, d.FinishedDirectory, "a.txt")
+	require.NoError(t, d.Gather(nil))
+	m = <-metrics
+	require.Equal(t, 2.0, m.Fields()["value"])
+	m.Accept()
+	requireFile(t, d.FinishedDirectory, "b.txt")
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *DirectoryMonitor
+	}{
+		{
+			name: "missing directory",
+			d:    &DirectoryMonitor{FinishedDirectory: "/tmp/finished", ErrorDirectory: "/tmp/error", MaxUndeliveredFiles: 1},
+		},
+		{
+			name: "same directory",
+			d:    &DirectoryMonitor{Directory: "/tmp/spool", FinishedDirectory: "/tmp/spool/", ErrorDirectory: "/tmp/error", MaxUndeliveredFiles: 1},
+		},
+		{
+			name: "invalid pattern",
+			d:    &DirectoryMonitor{Directory: "/tmp/spool", FinishedDirectory: "/tmp/finished", ErrorDirectory: "/tmp/error", FilesToMonitor: []string{"["}, MaxUndeliveredFiles: 1},
+		},
+		{
+			name: "invalid content encoding",
+			d:    &DirectoryMonitor{Directory: "/tmp/spool", FinishedDirectory: "/tmp/finished", ErrorDirectory: "/tmp/error", ContentEncoding: "zstd", MaxUndeliveredFiles: 1},
+		},
+		{
+			name: "no undelivered files",
+			d:    &DirectoryMonitor{Directory: "/tmp/spool", FinishedDirectory: "/tmp/finished", ErrorDirectory: "/tmp/error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.d.Init())
+		})
+	}
+}
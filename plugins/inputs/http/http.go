@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,8 @@ import (
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 type HTTP struct {
@@ -32,10 +35,20 @@ type HTTP struct {
 	// Absolute path to file with Bearer token
 	BearerToken string `toml:"bearer_token"`
 
+	// OAuth2 Client Credentials Grant
+	ClientID     string   `toml:"client_id"`
+	ClientSecret string   `toml:"client_secret"`
+	TokenURL     string   `toml:"token_url"`
+	Scopes       []string `toml:"scopes"`
+
 	SuccessStatusCodes []int `toml:"success_status_codes"`
 
 	Timeout internal.Duration `toml:"timeout"`
 
+	Pagination Pagination `toml:"pagination"`
+
+	Log telegraf.Logger `toml:"-"`
+
 	client *http.Client
 
 	// The parser will automatically be set by Telegraf core code because
@@ -63,6 +76,13 @@ var sampleConfig = `
   # username = "username"
   # password = "pa$$word"
 
+  ## OAuth2 Client Credentials Grant; the token is requested before the
+  ## first request and refreshed when it expires.
+  # client_id = "clientid"
+  # client_secret = "secret"
+  # token_url = "https://indentityprovider/oauth2/v1/token"
+  # scopes = ["urn:opc:idm:__myscopes__"]
+
   ## HTTP entity-body to send with POST/PUT requests.
   # body = ""
 
@@ -88,6 +108,33 @@ var sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   # data_format = "influx"
+
+  ## Follow the pages of paged responses.
+  # [inputs.http.pagination]
+  #   ## Pagination strategy, one of:
+  #   ##   "link"   - follow the rel="next" URL of Link headers
+  #   ##   "cursor" - send the cursor found in the response body
+  #   ##   "page"   - increase a page number query parameter
+  #   ##   "offset" - increase an offset query parameter by page_size
+  #   strategy = "link"
+  #
+  #   ## Maximum number of pages requested per URL and interval.
+  #   # max_pages = 10
+  #
+  #   ## GJSON path of the cursor in the response body, and the query
+  #   ## parameter to send it as.  If cursor_param is empty the cursor is
+  #   ## requested as the URL of the next page.
+  #   # cursor_path = "meta.next_cursor"
+  #   # cursor_param = "cursor"
+  #
+  #   ## Query parameters for page numbers and offsets.  The page number
+  #   ## starts at page_start; the limit parameter, if set, is sent with
+  #   ## page_size.  Pages are followed until one has no metrics.
+  #   # page_param = "page"
+  #   # page_start = 1
+  #   # offset_param = "offset"
+  #   # limit_param = "limit"
+  #   # page_size = 100
 `
 
 // SampleConfig returns the default configuration of the Input
@@ -106,6 +153,10 @@ func (h *HTTP) Init() error {
 		return err
 	}
 
+	if err := h.Pagination.init(); err != nil {
+		return err
+	}
+
 	h.client = &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: tlsCfg,
@@ -114,6 +165,17 @@ func (h *HTTP) Init() error {
 		Timeout: h.Timeout.Duration,
 	}
 
+	if h.ClientID != "" && h.ClientSecret != "" && h.TokenURL != "" {
+		oauthConfig := clientcredentials.Config{
+			ClientID:     h.ClientID,
+			ClientSecret: h.ClientSecret,
+			TokenURL:     h.TokenURL,
+			Scopes:       h.Scopes,
+		}
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, h.client)
+		h.client = oauthConfig.Client(ctx)
+	}
+
 	// Set default as [200]
 	if len(h.SuccessStatusCodes) == 0 {
 		h.SuccessStatusCodes = []int{200}
@@ -145,7 +207,7 @@ func (h *HTTP) SetParser(parser parsers.Parser) {
 	h.parser = parser
 }
 
-// Gathers data from a particular URL
+// Gathers data from a particular URL, following its pages
 // Parameters:
 //     acc    : The telegraf Accumulator to use
 //     url    : endpoint to send request to
@@ -156,21 +218,64 @@ func (h *HTTP) gatherURL(
 	acc telegraf.Accumulator,
 	url string,
 ) error {
-	body, err := makeRequestBodyReader(h.ContentEncoding, h.Body)
+	page, err := h.Pagination.first(url)
 	if err != nil {
 		return err
 	}
+
+	for n := 0; page != ""; n++ {
+		if n == h.Pagination.MaxPages {
+			h.Log.Warnf("Stopped after %d pages of %q", n, url)
+			return nil
+		}
+
+		resp, body, err := h.request(page)
+		if err != nil {
+			return err
+		}
+
+		metrics, err := h.parser.Parse(body)
+		if err != nil {
+			return err
+		}
+
+		for _, metric := range metrics {
+			if !metric.HasTag("url") {
+				metric.AddTag("url", url)
+			}
+			acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		}
+
+		next, err := h.Pagination.next(url, n, page, resp, body, len(metrics))
+		if err != nil {
+			return err
+		}
+		if next == page {
+			return fmt.Errorf("next page of %q is the same page", page)
+		}
+		page = next
+	}
+
+	return nil
+}
+
+// request sends a request to the URL and returns the response and its body.
+func (h *HTTP) request(url string) (*http.Response, []byte, error) {
+	body, err := makeRequestBodyReader(h.ContentEncoding, h.Body)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer body.Close()
 
 	request, err := http.NewRequest(h.Method, url, body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	if h.BearerToken != "" {
 		token, err := ioutil.ReadFile(h.BearerToken)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		bearer := "Bearer " + strings.Trim(string(token), "\n")
 		request.Header.Set("Authorization", bearer)
@@ -194,7 +299,7 @@ func (h *HTTP) gatherURL(
 
 	resp, err := h.client.Do(request)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
@@ -207,7 +312,7 @@ func (h *HTTP) gatherURL(
 	}
 
 	if !responseHasSuccessCode {
-		return fmt.Errorf("received status code %d (%s), expected any value out of %v",
+		return nil, nil, fmt.Errorf("received status code %d (%s), expected any value out of %v",
 			resp.StatusCode,
 			http.StatusText(resp.StatusCode),
 			h.SuccessStatusCodes)
@@ -215,22 +320,10 @@ func (h *HTTP) gatherURL(
 
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
-	}
-
-	metrics, err := h.parser.Parse(b)
-	if err != nil {
-		return err
-	}
-
-	for _, metric := range metrics {
-		if !metric.HasTag("url") {
-			metric.AddTag("url", url)
-		}
-		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		return nil, nil, err
 	}
 
-	return nil
+	return resp, b, nil
 }
 
 func makeRequestBodyReader(contentEncoding, body string) (io.ReadCloser, error) {
@@ -248,8 +341,9 @@ func makeRequestBodyReader(contentEncoding, body string) (io.ReadCloser, error)
 func init() {
 	inputs.Add("http", func() telegraf.Input {
 		return &HTTP{
-			Timeout: internal.Duration{Duration: time.Second * 5},
-			Method:  "GET",
+			Timeout:    internal.Duration{Duration: time.Second * 5},
+			Method:     "GET",
+			Pagination: Pagination{PageStart: 1},
 		}
 	})
 }
@@ -252,3 +252,285 @@ func TestBodyAndContentEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestPagination(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		pagination plugin.Pagination
+		handler    func(w http.ResponseWriter, r *http.Request)
+		expected   []float64
+		requests   []string
+	}{
+		{
+			name:       "link header",
+			pagination: plugin.Pagination{Strategy: "link"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.RequestURI() {
+				case "/endpoint":
+					w.Header().Add("Link", `</endpoint?after=1>; rel="next", </endpoint>; rel="first"`)
+					_, _ = w.Write([]byte(`{"a": 1}`))
+				case "/endpoint?after=1":
+					w.Header().Add("Link", `</endpoint>; rel="first"`)
+					_, _ = w.Write([]byte(`{"a": 2}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			expected: []float64{1, 2},
+			requests: []string{"/endpoint", "/endpoint?after=1"},
+		},
+		{
+			name:       "cursor parameter",
+			pagination: plugin.Pagination{Strategy: "cursor", CursorPath: "meta.next", CursorParam: "cursor"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("cursor") {
+				case "":
+					_, _ = w.Write([]byte(`{"a": 1, "meta": {"next": "abc"}}`))
+				case "abc":
+					_, _ = w.Write([]byte(`{"a": 2, "meta": {"next": null}}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			expected: []float64{1, 2},
+			requests: []string{"/endpoint?key=value", "/endpoint?cursor=abc&key=value"},
+		},
+		{
+			name:       "cursor url",
+			pagination: plugin.Pagination{Strategy: "cursor", CursorPath: "next"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/endpoint":
+					_, _ = w.Write([]byte(`{"a": 1, "next": "/endpoint/2"}`))
+				case "/endpoint/2":
+					_, _ = w.Write([]byte(`{"a": 2}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			expected: []float64{1, 2},
+			requests: []string{"/endpoint?key=value", "/endpoint/2"},
+		},
+		{
+			name:       "page number",
+			pagination: plugin.Pagination{Strategy: "page", PageStart: 1},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("page") {
+				case "1", "2":
+					_, _ = w.Write([]byte(`[{"a": ` + r.URL.Query().Get("page") + `}]`))
+				default:
+					_, _ = w.Write([]byte(`[]`))
+				}
+			},
+			expected: []float64{1, 2},
+			requests: []string{"/endpoint?key=value&page=1", "/endpoint?key=value&page=2", "/endpoint?key=value&page=3"},
+		},
+		{
+			name:       "offset",
+			pagination: plugin.Pagination{Strategy: "offset", LimitParam: "limit", PageSize: 2},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("offset") {
+				case "0":
+					_, _ = w.Write([]byte(`[{"a": 1}, {"a": 2}]`))
+				case "2":
+					_, _ = w.Write([]byte(`[{"a": 3}]`))
+				default:
+					_, _ = w.Write([]byte(`[]`))
+				}
+			},
+			expected: []float64{1, 2, 3},
+			requests: []string{
+				"/endpoint?key=value&limit=2&offset=0",
+				"/endpoint?key=value&limit=2&offset=2",
+				"/endpoint?key=value&limit=2&offset=4",
+			},
+		},
+		{
+			name:       "max pages",
+			pagination: plugin.Pagination{Strategy: "page", MaxPages: 2},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`[{"a": ` + r.URL.Query().Get("page") + `}]`))
+			},
+			expected: []float64{0, 1},
+			requests: []string{"/endpoint?key=value&page=0", "/endpoint?key=value&page=1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.RequestURI())
+				tt.handler(w, r)
+			})
+
+			url := ts.URL + "/endpoint"
+			if tt.pagination.Strategy != "link" {
+				url += "?key=value"
+			}
+			plugin := &plugin.HTTP{
+				URLs:       []string{url},
+				Pagination: tt.pagination,
+				Log:        testutil.Logger{},
+			}
+
+			parser, err := parsers.NewParser(&parsers.Config{
+				DataFormat: "json",
+				MetricName: "metricName",
+			})
+			require.NoError(t, err)
+			plugin.SetParser(parser)
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(plugin.Gather))
+
+			var values []float64
+			for _, m := range acc.Metrics {
+				require.Equal(t, url, m.Tags["url"])
+				values = append(values, m.Fields["a"].(float64))
+			}
+			require.Equal(t, tt.expected, values)
+			require.Equal(t, tt.requests, requests)
+		})
+	}
+}
+
+func TestPaginationOtherHost(t *testing.T) {
+	var otherRequests int
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherRequests++
+		_, _ = w.Write([]byte(`{"a": 2}`))
+	}))
+	defer other.Close()
+
+	tests := []struct {
+		name       string
+		pagination plugin.Pagination
+		handler    func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:       "link header",
+			pagination: plugin.Pagination{Strategy: "link"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Link", "<"+other.URL+`/endpoint?after=1>; rel="next"`)
+				_, _ = w.Write([]byte(`{"a": 1}`))
+			},
+		},
+		{
+			name:       "cursor url",
+			pagination: plugin.Pagination{Strategy: "cursor", CursorPath: "next"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"a": 1, "next": "` + other.URL + `/endpoint?after=1"}`))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = append(authorization, r.Header.Get("Authorization"))
+				tt.handler(w, r)
+			}))
+			defer ts.Close()
+
+			plugin := &plugin.HTTP{
+				URLs:       []string{ts.URL + "/endpoint"},
+				Username:   "user",
+				Password:   "secret",
+				Pagination: tt.pagination,
+				Log:        testutil.Logger{},
+			}
+
+			parser, err := parsers.NewParser(&parsers.Config{
+				DataFormat: "json",
+				MetricName: "metricName",
+			})
+			require.NoError(t, err)
+			plugin.SetParser(parser)
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Len(t, acc.Errors, 1)
+			require.Contains(t, acc.Errors[0].Error(), "is not on "+ts.URL)
+
+			// The first page is gathered, the credentials are not sent to
+			// the other host.
+			require.Len(t, acc.Metrics, 1)
+			require.Len(t, authorization, 1)
+			require.NotEmpty(t, authorization[0])
+			require.Equal(t, 0, otherRequests)
+		})
+	}
+}
+
+func TestPaginationInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination plugin.Pagination
+	}{
+		{name: "unknown strategy", pagination: plugin.Pagination{Strategy: "foo"}},
+		{name: "cursor without path", pagination: plugin.Pagination{Strategy: "cursor"}},
+		{name: "offset without page size", pagination: plugin.Pagination{Strategy: "offset"}},
+		{name: "negative max pages", pagination: plugin.Pagination{Strategy: "link", MaxPages: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &plugin.HTTP{
+				URLs:       []string{"http://localhost/endpoint"},
+				Pagination: tt.pagination,
+			}
+			require.Error(t, plugin.Init())
+		})
+	}
+}
+
+func TestOAuthClientCredentialsGrant(t *testing.T) {
+	var tokenRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+			username, password, _ := r.BasicAuth()
+			require.Equal(t, "howdy", username)
+			require.Equal(t, "secret", password)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "ssla5Cw6ji", "token_type": "bearer", "expires_in": 3600}`))
+		case "/endpoint":
+			if r.Header.Get("Authorization") != "Bearer ssla5Cw6ji" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(simpleJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:         []string{ts.URL + "/endpoint"},
+		ClientID:     "howdy",
+		ClientSecret: "secret",
+		TokenURL:     ts.URL + "/token",
+	}
+
+	parser, err := parsers.NewParser(&parsers.Config{
+		DataFormat: "json",
+		MetricName: "metricName",
+	})
+	require.NoError(t, err)
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, 1, tokenRequests)
+}
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Pagination strategies
+const (
+	paginationNone   = ""
+	paginationLink   = "link"
+	paginationCursor = "cursor"
+	paginationPage   = "page"
+	paginationOffset = "offset"
+)
+
+// Pagination configures how the pages of a paged response are followed.
+type Pagination struct {
+	Strategy string `toml:"strategy"`
+	MaxPages int    `toml:"max_pages"`
+
+	// Cursor tokens; CursorPath is a GJSON path into the response body.  The
+	// cursor is sent as CursorParam query parameter, or requested as next URL
+	// if CursorParam is empty.
+	CursorPath  string `toml:"cursor_path"`
+	CursorParam string `toml:"cursor_param"`
+
+	// Page numbers and offsets
+	PageParam   string `toml:"page_param"`
+	PageStart   int    `toml:"page_start"`
+	OffsetParam string `toml:"offset_param"`
+	LimitParam  string `toml:"limit_param"`
+	PageSize    int    `toml:"page_size"`
+}
+
+func (p *Pagination) init() error {
+	switch p.Strategy {
+	case paginationNone, paginationLink:
+	case paginationCursor:
+		if p.CursorPath == "" {
+			return fmt.Errorf("cursor_path is required for cursor pagination")
+		}
+	case paginationPage:
+		if p.PageParam == "" {
+			p.PageParam = "page"
+		}
+	case paginationOffset:
+		if p.OffsetParam == "" {
+			p.OffsetParam = "offset"
+		}
+		if p.PageSize <= 0 {
+			return fmt.Errorf("page_size is required for offset pagination")
+		}
+	default:
+		return fmt.Errorf("unknown pagination strategy %q", p.Strategy)
+	}
+
+	if p.MaxPages < 0 {
+		return fmt.Errorf("max_pages must not be negative")
+	}
+	if p.MaxPages == 0 {
+		p.MaxPages = 10
+	}
+	return nil
+}
+
+// first returns the URL of the first page of the given URL.
+func (p *Pagination) first(u string) (string, error) {
+	switch p.Strategy {
+	case paginationPage, paginationOffset:
+		return p.pageURL(u, 0)
+	default:
+		return u, nil
+	}
+}
+
+// next returns the URL of the page following page n, given the URL, response
+// and number of metrics of page n, or an empty string if it was the last page.
+// Next page URLs returned by the server must have the scheme and host of the
+// URL, as the credentials are sent with every request.
+func (p *Pagination) next(base string, n int, current string, resp *http.Response, body []byte, metrics int) (string, error) {
+	switch p.Strategy {
+	case paginationLink:
+		next := linkNext(resp.Header["Link"])
+		if next == "" {
+			return "", nil
+		}
+		return resolveSameOrigin(base, current, next)
+	case paginationCursor:
+		cursor := gjson.GetBytes(body, p.CursorPath)
+		if !cursor.Exists() || cursor.Type == gjson.Null || cursor.String() == "" {
+			return "", nil
+		}
+		if p.CursorParam == "" {
+			return resolveSameOrigin(base, current, cursor.String())
+		}
+		return setQuery(base, map[string]string{p.CursorParam: cursor.String()})
+	case paginationPage, paginationOffset:
+		// Without an indication of the number of pages, an empty page
+		// marks the end.
+		if metrics == 0 {
+			return "", nil
+		}
+		return p.pageURL(base, n+1)
+	default:
+		return "", nil
+	}
+}
+
+// pageURL returns the URL of page n, counting from zero, for page number and
+// offset pagination.
+func (p *Pagination) pageURL(base string, n int) (string, error) {
+	params := make(map[string]string)
+	if p.Strategy == paginationPage {
+		params[p.PageParam] = strconv.Itoa(p.PageStart + n)
+		if p.LimitParam != "" && p.PageSize > 0 {
+			params[p.LimitParam] = strconv.Itoa(p.PageSize)
+		}
+	} else {
+		params[p.OffsetParam] = strconv.Itoa(n * p.PageSize)
+		if p.LimitParam != "" {
+			params[p.LimitParam] = strconv.Itoa(p.PageSize)
+		}
+	}
+	return setQuery(base, params)
+}
+
+func setQuery(base string, params map[string]string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func resolve(current, ref string) (string, error) {
+	u, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return u.ResolveReference(r).String(), nil
+}
+
+// resolveSameOrigin resolves ref relative to the current URL, and returns an
+// error if the result differs from base in scheme or host.
+func resolveSameOrigin(base, current, ref string) (string, error) {
+	next, err := resolve(current, ref)
+	if err != nil {
+		return "", err
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != b.Scheme || !strings.EqualFold(u.Host, b.Host) {
+		return "", fmt.Errorf("next page %q is not on %s://%s", next, b.Scheme, b.Host)
+	}
+	return next, nil
+}
+
+// linkNext returns the target of the "next" relation of RFC 8288 Link
+// headers.
+func linkNext(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -34,12 +34,17 @@ type Modbus struct {
 	Coils            []fieldContainer  `toml:"coils"`
 	HoldingRegisters []fieldContainer  `toml:"holding_registers"`
 	InputRegisters   []fieldContainer  `toml:"input_registers"`
-	registers        []register
-	isConnected      bool
-	tcpHandler       *mb.TCPClientHandler
-	rtuHandler       *mb.RTUClientHandler
-	asciiHandler     *mb.ASCIIClientHandler
-	client           mb.Client
+
+	ConfigurationType string              `toml:"configuration_type"`
+	Requests          []requestDefinition `toml:"request"`
+
+	registers    []register
+	requests     []request
+	isConnected  bool
+	tcpHandler   *mb.TCPClientHandler
+	rtuHandler   *mb.RTUClientHandler
+	asciiHandler *mb.ASCIIClientHandler
+	client       mb.Client
 }
 
 type register struct {
@@ -82,6 +87,7 @@ const sampleConfig = `
 
   ## Slave ID - addresses a MODBUS device on the bus
   ## Range: 0 - 255 [0 = broadcast; 248 - 255 = reserved]
+  ## Not used with the "request" configuration type.
   slave_id = 1
 
   ## Timeout for each request
@@ -103,6 +109,13 @@ const sampleConfig = `
   # stop_bits = 1
   # transmission_mode = "RTU"
 
+  ## Configuration type, one of:
+  ##   register - the registers of a single slave device are defined by
+  ##              register type below
+  ##   request  - the fields of one or more slave devices are defined in
+  ##              "request" sections, see the end of this configuration
+  # configuration_type = "register"
+
 
   ## Measurements
   ##
@@ -149,6 +162,60 @@ const sampleConfig = `
     { name = "tank_ph",      byte_order = "AB",   data_type = "INT16",   scale=1.0,     address = [1]},
     { name = "pump1_speed",  byte_order = "ABCD", data_type = "INT32",   scale=1.0,     address = [3,4]},
   ]
+
+  ## Request definitions, used with configuration_type = "request" instead
+  ## of the register definitions above.
+  ##
+  ## Fields of a request section are read with as few requests as possible:
+  ## consecutive addresses are read together up to max_registers coils or
+  ## registers per read.  Failed reads skip the remaining reads of the slave
+  ## device in this interval, other slave devices are still read.
+  # [[inputs.modbus.request]]
+  #   ## ID of the slave device to read.
+  #   slave_id = 1
+  #
+  #   ## Register type, one of "coil", "discrete", "holding" or "input".
+  #   register = "holding"
+  #
+  #   ## Byte order of multi-register values, one of:
+  #   ##   ABCD - big endian
+  #   ##   DCBA - little endian
+  #   ##   BADC - big endian with swapped bytes within each register
+  #   ##   CDAB - big endian with swapped registers
+  #   byte_order = "ABCD"
+  #
+  #   ## Measurement name of the fields, defaults to "modbus".
+  #   # measurement = "modbus"
+  #
+  #   ## Maximum number of coils or registers of a single read, defaults to
+  #   ## the protocol limits of 2000 coils or 125 registers.
+  #   # max_registers = 125
+  #
+  #   ## Maximum number of unused addresses between fields read together.
+  #   ## Reading unused addresses can fail on devices without these
+  #   ## addresses.
+  #   # max_gap = 0
+  #
+  #   ## Fields of the request
+  #   ## name        - the field name
+  #   ## address     - the address of the coil or the first register
+  #   ## type        - the data type of registers, one of INT16, UINT16,
+  #   ##               INT32, UINT32, INT64, UINT64, FLOAT32, FLOAT64 or BIT
+  #   ## bit         - the bit of a 16-bit register of type BIT, 0 is the
+  #   ##               least significant bit
+  #   ## scale       - the factor to multiply the value with; scaled values
+  #   ##               are floats
+  #   ## measurement - the (optional) measurement name of the field
+  #   fields = [
+  #     { name = "voltage",  address = 0, type = "INT16",   scale = 0.1 },
+  #     { name = "energy",   address = 1, type = "FLOAT32" },
+  #     { name = "running",  address = 3, type = "BIT", bit = 0 },
+  #     { name = "alarm",    address = 3, type = "BIT", bit = 7 },
+  #   ]
+  #
+  #   ## Additional tags of the fields
+  #   [inputs.modbus.request.tags]
+  #     machine = "impresser"
 `
 
 // SampleConfig returns a basic configuration for the plugin
@@ -171,6 +238,14 @@ func (m *Modbus) Init() error {
 		return fmt.Errorf("retries cannot be negative")
 	}
 
+	switch m.ConfigurationType {
+	case "", cConfigurationRegister:
+	case cConfigurationRequest:
+		return m.initRequests()
+	default:
+		return fmt.Errorf("invalid configuration type '%s'", m.ConfigurationType)
+	}
+
 	err := m.InitRegister(m.DiscreteInputs, cDiscreteInputs)
 	if err != nil {
 		return err
@@ -653,6 +728,10 @@ func (m *Modbus) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if m.ConfigurationType == cConfigurationRequest {
+		return m.gatherRequests(acc)
+	}
+
 	timestamp := time.Now()
 	for retry := 0; retry <= m.Retries; retry += 1 {
 		timestamp = time.Now()
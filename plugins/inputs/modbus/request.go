@@ -0,0 +1,424 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	mb "github.com/goburrow/modbus"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Configuration types
+const (
+	cConfigurationRegister = "register"
+	cConfigurationRequest  = "request"
+)
+
+// Maximum number of coils or registers of a single read as defined by the
+// Modbus application protocol specification.
+const (
+	maxBitsPerRead      = 2000
+	maxRegistersPerRead = 125
+)
+
+// requestDefinition is a "request" section of the configuration, the fields
+// of one register type of a slave device.
+type requestDefinition struct {
+	SlaveID      int                      `toml:"slave_id"`
+	RegisterType string                   `toml:"register"`
+	ByteOrder    string                   `toml:"byte_order"`
+	Measurement  string                   `toml:"measurement"`
+	MaxRegisters int                      `toml:"max_registers"`
+	MaxGap       int                      `toml:"max_gap"`
+	Fields       []requestFieldDefinition `toml:"fields"`
+	Tags         map[string]string        `toml:"tags"`
+}
+
+type requestFieldDefinition struct {
+	Measurement string  `toml:"measurement"`
+	Name        string  `toml:"name"`
+	Address     uint16  `toml:"address"`
+	DataType    string  `toml:"type"`
+	Bit         uint8   `toml:"bit"`
+	Scale       float64 `toml:"scale"`
+}
+
+// request is a single read of a contiguous range of coils or registers.
+type request struct {
+	slaveID      byte
+	registerType string
+	address      uint16
+	length       uint16
+	fields       []requestField
+	tags         map[string]string
+}
+
+// requestField is a field of a request, decoded from length coils or
+// registers starting at address.
+type requestField struct {
+	measurement string
+	name        string
+	address     uint16
+	length      uint16
+	dataType    string
+	bit         uint8
+	byteOrder   string
+	scale       float64
+}
+
+var requestRegisterTypes = map[string]string{
+	"coil":     cCoils,
+	"discrete": cDiscreteInputs,
+	"holding":  cHoldingRegisters,
+	"input":    cInputRegisters,
+}
+
+// registerCount returns the number of registers of the data type.
+func registerCount(dataType string) (uint16, bool) {
+	switch dataType {
+	case "INT16", "UINT16", "BIT":
+		return 1, true
+	case "INT32", "UINT32", "FLOAT32":
+		return 2, true
+	case "INT64", "UINT64", "FLOAT64":
+		return 4, true
+	default:
+		return 0, false
+	}
+}
+
+// initRequests validates the request definitions and splits their fields
+// into reads.
+func (m *Modbus) initRequests() error {
+	if len(m.DiscreteInputs) > 0 || len(m.Coils) > 0 || len(m.HoldingRegisters) > 0 || len(m.InputRegisters) > 0 {
+		return fmt.Errorf("register definitions cannot be used with configuration type '%s'", cConfigurationRequest)
+	}
+
+	m.requests = nil
+	for i, def := range m.Requests {
+		requests, err := newRequests(def)
+		if err != nil {
+			return fmt.Errorf("request %d: %v", i+1, err)
+		}
+		m.requests = append(m.requests, requests...)
+	}
+	return nil
+}
+
+func newRequests(def requestDefinition) ([]request, error) {
+	if def.SlaveID < 0 || def.SlaveID > 255 {
+		return nil, fmt.Errorf("invalid slave id %d", def.SlaveID)
+	}
+
+	if def.RegisterType == "" {
+		def.RegisterType = "holding"
+	}
+	registerType, ok := requestRegisterTypes[def.RegisterType]
+	if !ok {
+		return nil, fmt.Errorf("invalid register type '%s'", def.RegisterType)
+	}
+	isBit := registerType == cCoils || registerType == cDiscreteInputs
+
+	if def.ByteOrder == "" {
+		def.ByteOrder = "ABCD"
+	}
+	switch def.ByteOrder {
+	case "ABCD", "DCBA", "BADC", "CDAB":
+	default:
+		return nil, fmt.Errorf("invalid byte order '%s'", def.ByteOrder)
+	}
+
+	limit := maxRegistersPerRead
+	if isBit {
+		limit = maxBitsPerRead
+	}
+	if def.MaxRegisters == 0 {
+		def.MaxRegisters = limit
+	}
+	if def.MaxRegisters < 1 || def.MaxRegisters > limit {
+		return nil, fmt.Errorf("invalid max_registers %d, must be between 1 and %d", def.MaxRegisters, limit)
+	}
+	if def.MaxGap < 0 {
+		return nil, fmt.Errorf("max_gap cannot be negative")
+	}
+
+	if def.Measurement == "" {
+		def.Measurement = "modbus"
+	}
+
+	if len(def.Fields) == 0 {
+		return nil, fmt.Errorf("no fields defined")
+	}
+
+	nameEncountered := make(map[string]bool)
+	fields := make([]requestField, 0, len(def.Fields))
+	for _, f := range def.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("empty field name")
+		}
+
+		field := requestField{
+			measurement: def.Measurement,
+			name:        f.Name,
+			address:     f.Address,
+			length:      1,
+			dataType:    f.DataType,
+			bit:         f.Bit,
+			byteOrder:   def.ByteOrder,
+			scale:       f.Scale,
+		}
+		if f.Measurement != "" {
+			field.measurement = f.Measurement
+		}
+
+		canonicalName := field.measurement + "." + field.name
+		if nameEncountered[canonicalName] {
+			return nil, fmt.Errorf("name '%s' is duplicated in measurement '%s'", field.name, field.measurement)
+		}
+		nameEncountered[canonicalName] = true
+
+		if isBit {
+			if f.DataType != "" || f.Scale != 0 {
+				return nil, fmt.Errorf("type and scale cannot be set for '%s' of register type '%s'", f.Name, def.RegisterType)
+			}
+		} else {
+			field.length, ok = registerCount(f.DataType)
+			if !ok {
+				return nil, fmt.Errorf("invalid data type '%s' of '%s'", f.DataType, f.Name)
+			}
+			if f.DataType == "BIT" && f.Bit > 15 {
+				return nil, fmt.Errorf("invalid bit %d of '%s'", f.Bit, f.Name)
+			}
+		}
+		if f.DataType != "BIT" && f.Bit != 0 {
+			return nil, fmt.Errorf("bit can only be set for type 'BIT' of '%s'", f.Name)
+		}
+		if int(field.length) > def.MaxRegisters {
+			return nil, fmt.Errorf("'%s' needs %d registers, more than max_registers %d", f.Name, field.length, def.MaxRegisters)
+		}
+		if int(field.address)+int(field.length) > math.MaxUint16+1 {
+			return nil, fmt.Errorf("address %d of '%s' out of range", field.address, f.Name)
+		}
+
+		fields = append(fields, field)
+	}
+
+	tags := map[string]string{
+		"slave_id": strconv.Itoa(def.SlaveID),
+	}
+	for k, v := range def.Tags {
+		tags[k] = v
+	}
+
+	return groupFields(byte(def.SlaveID), registerType, fields, tags, uint16(def.MaxRegisters), def.MaxGap), nil
+}
+
+// groupFields coalesces fields into as few reads as possible.  Fields are
+// read together if the read stays within maxRegisters, and if the addresses
+// between them, which are read but not used, are at most maxGap.
+func groupFields(slaveID byte, registerType string, fields []requestField, tags map[string]string, maxRegisters uint16, maxGap int) []request {
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].address < fields[j].address })
+
+	var requests []request
+	var current *request
+	for _, field := range fields {
+		start := int(field.address)
+		end := start + int(field.length)
+		if current != nil {
+			currentEnd := int(current.address) + int(current.length)
+			if start-currentEnd <= maxGap && end-int(current.address) <= int(maxRegisters) {
+				if end > currentEnd {
+					current.length = uint16(end - int(current.address))
+				}
+				current.fields = append(current.fields, field)
+				continue
+			}
+		}
+
+		requests = append(requests, request{
+			slaveID:      slaveID,
+			registerType: registerType,
+			address:      field.address,
+			length:       field.length,
+			fields:       []requestField{field},
+			tags:         tags,
+		})
+		current = &requests[len(requests)-1]
+	}
+	return requests
+}
+
+// gatherRequests reads the requests of each slave device.  A failed read
+// skips the remaining requests of its slave device; the other devices are
+// still read.
+func (m *Modbus) gatherRequests(acc telegraf.Accumulator) error {
+	grouper := metric.NewSeriesGrouper()
+
+	// The fields of a slave device share the time of its first read.
+	timestamps := make(map[byte]time.Time)
+	failed := make(map[byte]bool)
+	reconnect := false
+	for _, r := range m.requests {
+		if failed[r.slaveID] {
+			continue
+		}
+
+		timestamp, ok := timestamps[r.slaveID]
+		if !ok {
+			timestamp = time.Now()
+			timestamps[r.slaveID] = timestamp
+		}
+		values, err := m.readRequest(r)
+		if err != nil {
+			acc.AddError(fmt.Errorf("slave %d: reading %d %s(s) at address %d: %v", r.slaveID, r.length, r.registerType, r.address, err))
+			failed[r.slaveID] = true
+			if _, ok := err.(*mb.ModbusError); !ok {
+				reconnect = true
+			}
+			continue
+		}
+
+		tags := map[string]string{
+			"name": m.Name,
+			"type": r.registerType,
+		}
+		for k, v := range r.tags {
+			tags[k] = v
+		}
+		for _, field := range r.fields {
+			grouper.Add(field.measurement, tags, timestamp, field.name, r.value(field, values))
+		}
+	}
+
+	for _, metric := range grouper.Metrics() {
+		acc.AddMetric(metric)
+	}
+
+	// Errors other than exception responses of the device may be caused by
+	// a broken connection, reconnect on the next gather.
+	if reconnect {
+		disconnect(m)
+		m.isConnected = false
+	}
+	return nil
+}
+
+// readRequest reads the coils or registers of the request, retrying while the
+// slave device is busy.
+func (m *Modbus) readRequest(r request) ([]byte, error) {
+	m.setSlaveID(r.slaveID)
+
+	for retry := 0; ; retry++ {
+		values, err := readRegisterValues(m, r.registerType, registerRange{r.address, r.length})
+		if err != nil {
+			mberr, ok := err.(*mb.ModbusError)
+			if ok && mberr.ExceptionCode == mb.ExceptionCodeServerDeviceBusy && retry < m.Retries {
+				log.Printf("I! [inputs.modbus] device %d busy! Retrying %d more time(s)...", r.slaveID, m.Retries-retry)
+				time.Sleep(m.RetriesWaitTime.Duration)
+				continue
+			}
+			return nil, err
+		}
+
+		expected := int(r.length) * 2
+		if r.registerType == cCoils || r.registerType == cDiscreteInputs {
+			expected = (int(r.length) + 7) / 8
+		}
+		if len(values) < expected {
+			return nil, fmt.Errorf("short response of %d bytes, expected %d", len(values), expected)
+		}
+		return values, nil
+	}
+}
+
+func (m *Modbus) setSlaveID(id byte) {
+	switch {
+	case m.tcpHandler != nil:
+		m.tcpHandler.SlaveId = id
+	case m.rtuHandler != nil:
+		m.rtuHandler.SlaveId = id
+	case m.asciiHandler != nil:
+		m.asciiHandler.SlaveId = id
+	}
+}
+
+// value decodes the value of the field from the response to the request.
+func (r request) value(f requestField, values []byte) interface{} {
+	offset := int(f.address - r.address)
+	if r.registerType == cCoils || r.registerType == cDiscreteInputs {
+		return uint64(values[offset/8] >> uint(offset%8) & 0x01)
+	}
+
+	b := reorderBytes(f.byteOrder, values[2*offset:2*(offset+int(f.length))])
+
+	var v interface{}
+	switch f.dataType {
+	case "BIT":
+		return uint64(binary.BigEndian.Uint16(b) >> f.bit & 0x01)
+	case "INT16":
+		v = int64(int16(binary.BigEndian.Uint16(b)))
+	case "UINT16":
+		v = uint64(binary.BigEndian.Uint16(b))
+	case "INT32":
+		v = int64(int32(binary.BigEndian.Uint32(b)))
+	case "UINT32":
+		v = uint64(binary.BigEndian.Uint32(b))
+	case "INT64":
+		v = int64(binary.BigEndian.Uint64(b))
+	case "UINT64":
+		v = binary.BigEndian.Uint64(b)
+	case "FLOAT32":
+		v = float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case "FLOAT64":
+		v = math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+
+	if f.scale == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case int64:
+		return float64(v) * f.scale
+	case uint64:
+		return float64(v) * f.scale
+	case float64:
+		return v * f.scale
+	}
+	return v
+}
+
+// reorderBytes returns the bytes of consecutive registers in big endian
+// order.  The byte order names the order of the bytes of a 32-bit value;
+// values of other sizes follow the same pattern:
+//
+//	ABCD - big endian
+//	DCBA - little endian
+//	BADC - big endian with swapped bytes within each register
+//	CDAB - little endian registers with big endian bytes (word swap)
+func reorderBytes(byteOrder string, b []byte) []byte {
+	out := make([]byte, len(b))
+	n := len(b)
+	switch byteOrder {
+	case "DCBA":
+		for i := range b {
+			out[i] = b[n-1-i]
+		}
+	case "BADC":
+		for i := 0; i < n; i += 2 {
+			out[i], out[i+1] = b[i+1], b[i]
+		}
+	case "CDAB":
+		for i := 0; i < n; i += 2 {
+			out[i], out[i+1] = b[n-2-i], b[n-1-i]
+		}
+	default:
+		copy(out, b)
+	}
+	return out
+}
@@ -0,0 +1,295 @@
+package modbus
+
+import (
+	"testing"
+	"time"
+
+	m "github.com/goburrow/modbus"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/tbrandon/mbserver"
+)
+
+func TestRequestGrouping(t *testing.T) {
+	fields := []requestFieldDefinition{
+		{Name: "alarm", Address: 3, DataType: "BIT", Bit: 7},
+		{Name: "voltage", Address: 0, DataType: "INT16"},
+		{Name: "energy", Address: 1, DataType: "FLOAT32"},
+		{Name: "running", Address: 3, DataType: "BIT"},
+		{Name: "counter", Address: 10, DataType: "UINT32"},
+	}
+
+	tests := []struct {
+		name         string
+		maxRegisters int
+		maxGap       int
+		expected     [][2]uint16
+	}{
+		{
+			name:     "consecutive",
+			expected: [][2]uint16{{0, 4}, {10, 2}},
+		},
+		{
+			name:     "gap",
+			maxGap:   6,
+			expected: [][2]uint16{{0, 12}},
+		},
+		{
+			name:         "max registers",
+			maxRegisters: 3,
+			expected:     [][2]uint16{{0, 3}, {3, 1}, {10, 2}},
+		},
+		{
+			name:         "max registers and gap",
+			maxRegisters: 10,
+			maxGap:       10,
+			expected:     [][2]uint16{{0, 4}, {10, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, err := newRequests(requestDefinition{
+				SlaveID:      1,
+				MaxRegisters: tt.maxRegisters,
+				MaxGap:       tt.maxGap,
+				Fields:       fields,
+			})
+			require.NoError(t, err)
+
+			var actual [][2]uint16
+			var count int
+			for _, r := range requests {
+				actual = append(actual, [2]uint16{r.address, r.length})
+				count += len(r.fields)
+				require.Equal(t, cHoldingRegisters, r.registerType)
+				for _, f := range r.fields {
+					require.True(t, f.address >= r.address && f.address+f.length <= r.address+r.length)
+				}
+			}
+			require.Equal(t, tt.expected, actual)
+			require.Equal(t, len(fields), count)
+		})
+	}
+}
+
+func TestRequestInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		def  requestDefinition
+	}{
+		{
+			name: "slave id",
+			def:  requestDefinition{SlaveID: 256, Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "register type",
+			def:  requestDefinition{RegisterType: "holding_register", Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "byte order",
+			def:  requestDefinition{ByteOrder: "AB", Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "max registers",
+			def:  requestDefinition{MaxRegisters: 126, Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "field exceeds max registers",
+			def:  requestDefinition{MaxRegisters: 1, Fields: []requestFieldDefinition{{Name: "a", DataType: "INT32"}}},
+		},
+		{
+			name: "max gap",
+			def:  requestDefinition{MaxGap: -1, Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "no fields",
+			def:  requestDefinition{},
+		},
+		{
+			name: "duplicate name",
+			def:  requestDefinition{Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}, {Name: "a", Address: 1, DataType: "INT16"}}},
+		},
+		{
+			name: "data type",
+			def:  requestDefinition{Fields: []requestFieldDefinition{{Name: "a", DataType: "FLOAT32-IEEE"}}},
+		},
+		{
+			name: "bit",
+			def:  requestDefinition{Fields: []requestFieldDefinition{{Name: "a", DataType: "BIT", Bit: 16}}},
+		},
+		{
+			name: "bit without type bit",
+			def:  requestDefinition{Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16", Bit: 1}}},
+		},
+		{
+			name: "coil type",
+			def:  requestDefinition{RegisterType: "coil", Fields: []requestFieldDefinition{{Name: "a", DataType: "INT16"}}},
+		},
+		{
+			name: "address",
+			def:  requestDefinition{Fields: []requestFieldDefinition{{Name: "a", Address: 65535, DataType: "INT32"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRequests(tt.def)
+			require.Error(t, err)
+		})
+	}
+
+	modbus := Modbus{
+		Name:              "TestRequestInvalid",
+		ConfigurationType: "request",
+		Coils:             []fieldContainer{{Name: "coil", Address: []uint16{0}}},
+	}
+	require.Error(t, modbus.Init())
+}
+
+func TestRequestValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		byteOrder string
+		dataType  string
+		bit       uint8
+		scale     float64
+		raw       []byte
+		expected  interface{}
+	}{
+		{name: "int16", byteOrder: "ABCD", dataType: "INT16", raw: []byte{0xFF, 0xFE}, expected: int64(-2)},
+		{name: "int16 little endian", byteOrder: "DCBA", dataType: "INT16", raw: []byte{0xFE, 0xFF}, expected: int64(-2)},
+		{name: "uint16 scaled", byteOrder: "ABCD", dataType: "UINT16", scale: 0.1, raw: []byte{0x08, 0x98}, expected: float64(220)},
+		{name: "uint32", byteOrder: "ABCD", dataType: "UINT32", raw: []byte{0x01, 0x02, 0x03, 0x04}, expected: uint64(0x01020304)},
+		{name: "uint32 little endian", byteOrder: "DCBA", dataType: "UINT32", raw: []byte{0x04, 0x03, 0x02, 0x01}, expected: uint64(0x01020304)},
+		{name: "uint32 byte swap", byteOrder: "BADC", dataType: "UINT32", raw: []byte{0x02, 0x01, 0x04, 0x03}, expected: uint64(0x01020304)},
+		{name: "uint32 word swap", byteOrder: "CDAB", dataType: "UINT32", raw: []byte{0x03, 0x04, 0x01, 0x02}, expected: uint64(0x01020304)},
+		{name: "int32", byteOrder: "ABCD", dataType: "INT32", raw: []byte{0xFF, 0xFF, 0xFF, 0xFF}, expected: int64(-1)},
+		{name: "float32", byteOrder: "ABCD", dataType: "FLOAT32", raw: []byte{0x40, 0x49, 0x0F, 0xDB}, expected: float64(float32(3.1415927))},
+		{name: "uint64 word swap", byteOrder: "CDAB", dataType: "UINT64", raw: []byte{0x07, 0x08, 0x05, 0x06, 0x03, 0x04, 0x01, 0x02}, expected: uint64(0x0102030405060708)},
+		{name: "int64", byteOrder: "ABCD", dataType: "INT64", raw: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE}, expected: int64(-2)},
+		{name: "float64", byteOrder: "ABCD", dataType: "FLOAT64", scale: 2, raw: []byte{0x3F, 0xF8, 0, 0, 0, 0, 0, 0}, expected: float64(3)},
+		{name: "bit set", byteOrder: "ABCD", dataType: "BIT", bit: 9, raw: []byte{0x02, 0x00}, expected: uint64(1)},
+		{name: "bit unset", byteOrder: "ABCD", dataType: "BIT", bit: 1, raw: []byte{0x02, 0x00}, expected: uint64(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, ok := registerCount(tt.dataType)
+			require.True(t, ok)
+
+			// Place the value behind a leading register to check the offset.
+			r := request{registerType: cHoldingRegisters, address: 10, length: length + 1}
+			f := requestField{address: 11, length: length, dataType: tt.dataType, bit: tt.bit, byteOrder: tt.byteOrder, scale: tt.scale}
+			require.Equal(t, tt.expected, r.value(f, append([]byte{0xAA, 0xAA}, tt.raw...)))
+		})
+	}
+
+	r := request{registerType: cCoils, address: 5, length: 10}
+	require.Equal(t, uint64(1), r.value(requestField{address: 14}, []byte{0x00, 0x02}))
+	require.Equal(t, uint64(0), r.value(requestField{address: 13}, []byte{0x00, 0x02}))
+}
+
+func TestRequestGather(t *testing.T) {
+	serv := mbserver.NewServer()
+	err := serv.ListenTCP("localhost:1502")
+	require.NoError(t, err)
+	defer serv.Close()
+
+	handler := m.NewTCPClientHandler("localhost:1502")
+	require.NoError(t, handler.Connect())
+	defer handler.Close()
+	client := m.NewClient(handler)
+	_, err = client.WriteMultipleRegisters(0, 6, []byte{0x08, 0x98, 0x40, 0x49, 0x0F, 0xDB, 0x00, 0x81, 0x00, 0x00, 0x00, 0x2A})
+	require.NoError(t, err)
+	_, err = client.WriteMultipleCoils(0, 3, []byte{0x05})
+	require.NoError(t, err)
+
+	// Slave 2 does not respond, count the reads of the other slaves.
+	reads := make(map[uint8]int)
+	serv.RegisterFunctionHandler(3,
+		func(s *mbserver.Server, frame mbserver.Framer) ([]byte, *mbserver.Exception) {
+			device := frame.(*mbserver.TCPFrame).Device
+			if device == 2 {
+				return []byte{}, &mbserver.GatewayTargetDeviceFailedtoRespond
+			}
+			reads[device]++
+			return mbserver.ReadHoldingRegisters(s, frame)
+		})
+
+	modbus := Modbus{
+		Name:              "TestRequestGather",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Requests: []requestDefinition{
+			{
+				SlaveID: 1,
+				Fields: []requestFieldDefinition{
+					{Name: "voltage", Address: 0, DataType: "UINT16", Scale: 0.1},
+					{Name: "pi", Address: 1, DataType: "FLOAT32"},
+					{Name: "running", Address: 3, DataType: "BIT", Bit: 0},
+					{Name: "alarm", Address: 3, DataType: "BIT", Bit: 7},
+					{Name: "fault", Address: 3, DataType: "BIT", Bit: 3},
+					{Name: "count", Address: 5, DataType: "INT16"},
+				},
+				Tags: map[string]string{"machine": "impresser"},
+			},
+			{
+				SlaveID: 2,
+				Fields: []requestFieldDefinition{
+					{Name: "voltage", Address: 0, DataType: "UINT16"},
+				},
+			},
+			{
+				SlaveID: 2,
+				Fields: []requestFieldDefinition{
+					{Name: "current", Address: 20, DataType: "UINT16"},
+				},
+			},
+			{
+				SlaveID:      3,
+				RegisterType: "coil",
+				Measurement:  "motor",
+				Fields: []requestFieldDefinition{
+					{Name: "run", Address: 0},
+					{Name: "jog", Address: 1},
+					{Name: "stop", Address: 2},
+				},
+			},
+		},
+	}
+	require.NoError(t, modbus.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, modbus.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("modbus",
+			map[string]string{"name": "TestRequestGather", "type": cHoldingRegisters, "slave_id": "1", "machine": "impresser"},
+			map[string]interface{}{
+				"voltage": float64(220),
+				"pi":      float64(float32(3.1415927)),
+				"running": uint64(1),
+				"alarm":   uint64(1),
+				"fault":   uint64(0),
+				"count":   int64(42),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("motor",
+			map[string]string{"name": "TestRequestGather", "type": cCoils, "slave_id": "3"},
+			map[string]interface{}{
+				"run":  uint64(1),
+				"jog":  uint64(0),
+				"stop": uint64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The fields of slave 1 are read with two requests because of the unused
+	// address 4, the failing slave 2 is only tried once.
+	require.Equal(t, map[uint8]int{1: 2}, reads)
+	require.Len(t, acc.Errors, 1)
+	require.Contains(t, acc.Errors[0].Error(), "slave 2")
+	require.True(t, modbus.isConnected)
+}
@@ -0,0 +1,401 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	metricName = "netflow"
+
+	versionNetflowV5 = 5
+	versionNetflowV9 = 9
+	versionIPFIX     = 10
+
+	netflowV5HeaderLength = 24
+	netflowV5RecordLength = 48
+	netflowV9HeaderLength = 20
+	ipfixHeaderLength     = 16
+	setHeaderLength       = 4
+
+	netflowV9TemplateSetID        = 0
+	netflowV9OptionsTemplateSetID = 1
+	ipfixTemplateSetID            = 2
+	ipfixOptionsTemplateSetID     = 3
+	minDataSetID                  = 256
+)
+
+var errShortPacket = errors.New("packet too short")
+
+// templateKey identifies a template of an exporter.  Template IDs are unique
+// per exporter and source ID (NetFlow v9) or observation domain (IPFIX).
+type templateKey struct {
+	exporter string
+	version  uint16
+	domain   uint32
+	id       uint16
+}
+
+type templateField struct {
+	id         uint16
+	length     uint16
+	enterprise uint32
+}
+
+type template struct {
+	fields []templateField
+	// options templates describe records about the exporter, not flows
+	options bool
+}
+
+// minRecordLength returns the minimum length of a record of the template.
+func (t *template) minRecordLength() int {
+	length := 0
+	for _, f := range t.fields {
+		if f.length == ieVariableLength {
+			length++
+		} else {
+			length += int(f.length)
+		}
+	}
+	return length
+}
+
+// Decoder decodes NetFlow v5, NetFlow v9 and IPFIX packets to metrics.  The
+// templates of NetFlow v9 and IPFIX are cached per exporter and source ID or
+// observation domain.
+type Decoder struct {
+	Log telegraf.Logger
+
+	sync.Mutex
+	templates map[templateKey]*template
+
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{
+		templates: make(map[templateKey]*template),
+		now:       time.Now,
+	}
+}
+
+// Decode decodes the packet received from the exporter.
+func (d *Decoder) Decode(exporter net.IP, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < 2 {
+		return nil, errShortPacket
+	}
+
+	version := binary.BigEndian.Uint16(buf)
+	switch version {
+	case versionNetflowV5:
+		return d.decodeV5(exporter, buf)
+	case versionNetflowV9:
+		return d.decodeV9(exporter, buf)
+	case versionIPFIX:
+		return d.decodeIPFIX(exporter, buf)
+	default:
+		return nil, fmt.Errorf("version %d not supported", version)
+	}
+}
+
+func (d *Decoder) decodeV5(exporter net.IP, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < netflowV5HeaderLength {
+		return nil, errShortPacket
+	}
+	count := int(binary.BigEndian.Uint16(buf[2:]))
+	if len(buf) < netflowV5HeaderLength+count*netflowV5RecordLength {
+		return nil, errShortPacket
+	}
+
+	tags := map[string]string{
+		"agent_address": exporter.String(),
+		"version":       strconv.Itoa(versionNetflowV5),
+		"engine_type":   strconv.Itoa(int(buf[20])),
+		"engine_id":     strconv.Itoa(int(buf[21])),
+	}
+	samplingRate := uint64(binary.BigEndian.Uint16(buf[22:]) & 0x3fff)
+
+	now := d.now()
+	metrics := make([]telegraf.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		rec := buf[netflowV5HeaderLength+i*netflowV5RecordLength:]
+		r := newFlowRecord(tags)
+		r.tags["src_ip"] = net.IP(rec[0:4]).String()
+		r.tags["dst_ip"] = net.IP(rec[4:8]).String()
+		r.tags["next_hop"] = net.IP(rec[8:12]).String()
+		r.tags["input_ifindex"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[12:])), 10)
+		r.tags["output_ifindex"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[14:])), 10)
+		r.fields["packets"] = uint64(binary.BigEndian.Uint32(rec[16:]))
+		r.fields["bytes"] = uint64(binary.BigEndian.Uint32(rec[20:]))
+		r.setTimes(uint64(binary.BigEndian.Uint32(rec[24:])), uint64(binary.BigEndian.Uint32(rec[28:])))
+		r.tags["src_port"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[32:])), 10)
+		r.tags["dst_port"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[34:])), 10)
+		r.fields["tcp_flags"] = uint64(rec[37])
+		r.tags["ip_protocol"] = strconv.FormatUint(uint64(rec[38]), 10)
+		r.setClassOfService(rec[39])
+		r.tags["src_as"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[40:])), 10)
+		r.tags["dst_as"] = strconv.FormatUint(uint64(binary.BigEndian.Uint16(rec[42:])), 10)
+		r.tags["src_mask_len"] = strconv.FormatUint(uint64(rec[44]), 10)
+		r.tags["dst_mask_len"] = strconv.FormatUint(uint64(rec[45]), 10)
+		if samplingRate > 0 {
+			r.fields["sampling_rate"] = samplingRate
+		}
+		r.finish()
+
+		m, err := metric.New(metricName, r.tags, r.fields, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+func (d *Decoder) decodeV9(exporter net.IP, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < netflowV9HeaderLength {
+		return nil, errShortPacket
+	}
+	domain := binary.BigEndian.Uint32(buf[16:])
+	return d.decodeSets(exporter, versionNetflowV9, domain, buf[netflowV9HeaderLength:])
+}
+
+func (d *Decoder) decodeIPFIX(exporter net.IP, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < ipfixHeaderLength {
+		return nil, errShortPacket
+	}
+	length := int(binary.BigEndian.Uint16(buf[2:]))
+	if length < ipfixHeaderLength || length > len(buf) {
+		return nil, fmt.Errorf("invalid message length %d", length)
+	}
+	domain := binary.BigEndian.Uint32(buf[12:])
+	return d.decodeSets(exporter, versionIPFIX, domain, buf[ipfixHeaderLength:length])
+}
+
+// decodeSets decodes the template, options template and data sets, called
+// flowsets in NetFlow v9, of a NetFlow v9 or IPFIX packet.
+func (d *Decoder) decodeSets(exporter net.IP, version uint16, domain uint32, buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	for len(buf) >= setHeaderLength {
+		id := binary.BigEndian.Uint16(buf)
+		length := int(binary.BigEndian.Uint16(buf[2:]))
+		if length < setHeaderLength || length > len(buf) {
+			return metrics, fmt.Errorf("invalid set length %d", length)
+		}
+		body := buf[setHeaderLength:length]
+		buf = buf[length:]
+
+		key := templateKey{
+			exporter: exporter.String(),
+			version:  version,
+			domain:   domain,
+		}
+
+		var err error
+		switch {
+		case version == versionNetflowV9 && id == netflowV9TemplateSetID:
+			err = d.decodeTemplates(key, body, false, false)
+		case version == versionNetflowV9 && id == netflowV9OptionsTemplateSetID:
+			err = d.decodeV9OptionsTemplates(key, body)
+		case version == versionIPFIX && id == ipfixTemplateSetID:
+			err = d.decodeTemplates(key, body, true, false)
+		case version == versionIPFIX && id == ipfixOptionsTemplateSetID:
+			err = d.decodeTemplates(key, body, true, true)
+		case id >= minDataSetID:
+			key.id = id
+			var ms []telegraf.Metric
+			ms, err = d.decodeData(key, body)
+			metrics = append(metrics, ms...)
+		default:
+			// reserved set IDs are ignored
+		}
+		if err != nil {
+			return metrics, err
+		}
+	}
+	return metrics, nil
+}
+
+// decodeTemplates decodes the records of a NetFlow v9 template set or of an
+// IPFIX template or options template set.
+func (d *Decoder) decodeTemplates(key templateKey, buf []byte, ipfix, options bool) error {
+	for len(buf) >= 4 {
+		id := binary.BigEndian.Uint16(buf)
+		count := int(binary.BigEndian.Uint16(buf[2:]))
+		buf = buf[4:]
+
+		if ipfix && count == 0 {
+			// template withdrawal
+			d.deleteTemplate(key, id)
+			continue
+		}
+		if id < minDataSetID {
+			// padding at the end of the set
+			return nil
+		}
+
+		if options {
+			// the scope field count is not needed to decode the records
+			if len(buf) < 2 {
+				return errShortPacket
+			}
+			buf = buf[2:]
+		}
+
+		tmpl := &template{options: options}
+		for i := 0; i < count; i++ {
+			if len(buf) < 4 {
+				return errShortPacket
+			}
+			f := templateField{
+				id:     binary.BigEndian.Uint16(buf),
+				length: binary.BigEndian.Uint16(buf[2:]),
+			}
+			buf = buf[4:]
+			if ipfix && f.id&0x8000 != 0 {
+				if len(buf) < 4 {
+					return errShortPacket
+				}
+				f.id &= 0x7fff
+				f.enterprise = binary.BigEndian.Uint32(buf)
+				buf = buf[4:]
+			}
+			tmpl.fields = append(tmpl.fields, f)
+		}
+		d.setTemplate(key, id, tmpl)
+	}
+	return nil
+}
+
+// decodeV9OptionsTemplates decodes the records of a NetFlow v9 options
+// template set.  The scope and option fields are given in bytes.
+func (d *Decoder) decodeV9OptionsTemplates(key templateKey, buf []byte) error {
+	for len(buf) >= 6 {
+		id := binary.BigEndian.Uint16(buf)
+		scopeLength := int(binary.BigEndian.Uint16(buf[2:]))
+		optionLength := int(binary.BigEndian.Uint16(buf[4:]))
+		buf = buf[6:]
+		if id < minDataSetID {
+			// padding at the end of the set
+			return nil
+		}
+		if len(buf) < scopeLength+optionLength || (scopeLength+optionLength)%4 != 0 {
+			return errShortPacket
+		}
+
+		tmpl := &template{options: true}
+		for i := 0; i < scopeLength+optionLength; i += 4 {
+			tmpl.fields = append(tmpl.fields, templateField{
+				id:     binary.BigEndian.Uint16(buf[i:]),
+				length: binary.BigEndian.Uint16(buf[i+2:]),
+			})
+		}
+		buf = buf[scopeLength+optionLength:]
+		d.setTemplate(key, id, tmpl)
+	}
+	return nil
+}
+
+func (d *Decoder) setTemplate(key templateKey, id uint16, tmpl *template) {
+	key.id = id
+	d.Lock()
+	d.templates[key] = tmpl
+	d.Unlock()
+}
+
+func (d *Decoder) deleteTemplate(key templateKey, id uint16) {
+	d.Lock()
+	defer d.Unlock()
+	if id == ipfixTemplateSetID || id == ipfixOptionsTemplateSetID {
+		// withdrawal of all templates or all options templates of the domain
+		options := id == ipfixOptionsTemplateSetID
+		for k, tmpl := range d.templates {
+			if k.exporter == key.exporter && k.version == key.version &&
+				k.domain == key.domain && tmpl.options == options {
+				delete(d.templates, k)
+			}
+		}
+		return
+	}
+	key.id = id
+	delete(d.templates, key)
+}
+
+// decodeData decodes the records of a data set with the cached template.
+func (d *Decoder) decodeData(key templateKey, buf []byte) ([]telegraf.Metric, error) {
+	d.Lock()
+	tmpl, ok := d.templates[key]
+	d.Unlock()
+	if !ok {
+		if d.Log != nil {
+			d.Log.Debugf("Dropping data set of %s, template %d of domain %d is unknown",
+				key.exporter, key.id, key.domain)
+		}
+		return nil, nil
+	}
+	if tmpl.options {
+		return nil, nil
+	}
+
+	minLength := tmpl.minRecordLength()
+	if minLength == 0 {
+		return nil, nil
+	}
+
+	tags := map[string]string{
+		"agent_address": key.exporter,
+		"version":       strconv.Itoa(int(key.version)),
+		"source_id":     strconv.FormatUint(uint64(key.domain), 10),
+	}
+
+	now := d.now()
+	var metrics []telegraf.Metric
+	// the remaining bytes are padding if shorter than a record
+	for len(buf) >= minLength {
+		r := newFlowRecord(tags)
+		for _, f := range tmpl.fields {
+			length := int(f.length)
+			if f.length == ieVariableLength {
+				if len(buf) < 1 {
+					return metrics, errShortPacket
+				}
+				length = int(buf[0])
+				buf = buf[1:]
+				if length == 255 {
+					if len(buf) < 2 {
+						return metrics, errShortPacket
+					}
+					length = int(binary.BigEndian.Uint16(buf))
+					buf = buf[2:]
+				}
+			}
+			if len(buf) < length {
+				return metrics, errShortPacket
+			}
+			if f.enterprise == 0 {
+				r.addValue(f.id, buf[:length])
+			}
+			buf = buf[length:]
+		}
+		r.finish()
+		if len(r.fields) == 0 {
+			continue
+		}
+
+		m, err := metric.New(metricName, r.tags, r.fields, now)
+		if err != nil {
+			return metrics, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
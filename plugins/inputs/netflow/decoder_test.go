@@ -0,0 +1,352 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var exporter = net.ParseIP("192.168.1.2")
+
+func newTestDecoder() *Decoder {
+	d := NewDecoder()
+	d.Log = testutil.Logger{}
+	d.now = func() time.Time { return time.Unix(0, 0) }
+	return d
+}
+
+// packet builds a packet of big endian values.
+type packet []byte
+
+func (p packet) u8(v uint8) packet   { return append(p, v) }
+func (p packet) u16(v uint16) packet { return append(p, byte(v>>8), byte(v)) }
+func (p packet) u32(v uint32) packet {
+	return append(p, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+func (p packet) u64(v uint64) packet {
+	return p.u32(uint32(v >> 32)).u32(uint32(v))
+}
+func (p packet) ip(s string) packet {
+	ip := net.ParseIP(s)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return append(p, ip...)
+}
+func (p packet) bytes(b ...byte) packet { return append(p, b...) }
+
+// set wraps the body in a set header.
+func set(id uint16, body packet) packet {
+	return packet{}.u16(id).u16(uint16(len(body) + setHeaderLength)).bytes(body...)
+}
+
+func netflowV9(sourceID uint32, sets ...packet) packet {
+	p := packet{}.u16(versionNetflowV9).u16(uint16(len(sets))).
+		u32(1000).u32(1584473704).u32(1).u32(sourceID)
+	for _, s := range sets {
+		p = append(p, s...)
+	}
+	return p
+}
+
+func ipfix(domain uint32, sets ...packet) packet {
+	var body packet
+	for _, s := range sets {
+		body = append(body, s...)
+	}
+	p := packet{}.u16(versionIPFIX).u16(uint16(ipfixHeaderLength + len(body))).
+		u32(1584473704).u32(1).u32(domain)
+	return append(p, body...)
+}
+
+func TestDecodeNetflowV5(t *testing.T) {
+	buf := packet{}.u16(versionNetflowV5).u16(1).
+		u32(1000).u32(1584473704).u32(0).u32(42).
+		u8(1).u8(2).u16(0x4000 | 100)
+	buf = buf.ip("10.0.0.1").ip("10.0.0.2").ip("10.0.0.254").
+		u16(3).u16(4).
+		u32(10).u32(1500).
+		u32(5000).u32(6500).
+		u16(443).u16(40042).
+		u8(0).u8(0x12).u8(6).u8(27<<2 | 1).
+		u16(64512).u16(64513).
+		u8(24).u8(16).u16(0)
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"agent_address":  "192.168.1.2",
+				"version":        "5",
+				"engine_type":    "1",
+				"engine_id":      "2",
+				"src_ip":         "10.0.0.1",
+				"dst_ip":         "10.0.0.2",
+				"next_hop":       "10.0.0.254",
+				"input_ifindex":  "3",
+				"output_ifindex": "4",
+				"src_port":       "443",
+				"dst_port":       "40042",
+				"ip_protocol":    "6",
+				"ip_dscp":        "27",
+				"ip_ecn":         "1",
+				"src_as":         "64512",
+				"dst_as":         "64513",
+				"src_mask_len":   "24",
+				"dst_mask_len":   "16",
+			},
+			map[string]interface{}{
+				"packets":          uint64(10),
+				"bytes":            uint64(1500),
+				"tcp_flags":        uint64(0x12),
+				"sampling_rate":    uint64(100),
+				"flow_duration_ms": uint64(1500),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestDecodeNetflowV5Short(t *testing.T) {
+	buf := packet{}.u16(versionNetflowV5).u16(2).
+		u32(0).u32(0).u32(0).u32(0).u32(0)
+
+	d := newTestDecoder()
+	_, err := d.Decode(exporter, buf)
+	require.Equal(t, errShortPacket, err)
+}
+
+func TestDecodeNetflowV9(t *testing.T) {
+	tmpl := packet{}.u16(256).u16(7).
+		u16(8).u16(4).  // src_ip
+		u16(12).u16(4). // dst_ip
+		u16(7).u16(2).  // src_port
+		u16(11).u16(2). // dst_port
+		u16(4).u16(1).  // ip_protocol
+		u16(1).u16(4).  // bytes
+		u16(61).u16(1)  // flow direction
+	data := packet{}.
+		ip("10.0.0.1").ip("10.0.0.2").u16(53).u16(5353).u8(17).u32(120).u8(1).
+		ip("10.0.0.3").ip("10.0.0.4").u16(80).u16(8080).u8(6).u32(4000).u8(0).
+		bytes(0, 0) // padding
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, netflowV9(7, set(netflowV9TemplateSetID, tmpl), set(256, data)))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"agent_address":  "192.168.1.2",
+				"version":        "9",
+				"source_id":      "7",
+				"src_ip":         "10.0.0.1",
+				"dst_ip":         "10.0.0.2",
+				"src_port":       "53",
+				"dst_port":       "5353",
+				"ip_protocol":    "17",
+				"flow_direction": "egress",
+			},
+			map[string]interface{}{
+				"bytes": uint64(120),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"agent_address":  "192.168.1.2",
+				"version":        "9",
+				"source_id":      "7",
+				"src_ip":         "10.0.0.3",
+				"dst_ip":         "10.0.0.4",
+				"src_port":       "80",
+				"dst_port":       "8080",
+				"ip_protocol":    "6",
+				"flow_direction": "ingress",
+			},
+			map[string]interface{}{
+				"bytes": uint64(4000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// The template is cached for later packets of the same source ID.
+	metrics, err = d.Decode(exporter, netflowV9(7, set(256, data)))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestDecodeNetflowV9TemplateScope(t *testing.T) {
+	tmpl := packet{}.u16(256).u16(1).u16(1).u16(4)
+	data := packet{}.u32(100)
+
+	d := newTestDecoder()
+	_, err := d.Decode(exporter, netflowV9(1, set(netflowV9TemplateSetID, tmpl)))
+	require.NoError(t, err)
+
+	// Templates are not shared between source IDs and exporters.
+	metrics, err := d.Decode(exporter, netflowV9(2, set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+
+	metrics, err = d.Decode(net.ParseIP("192.168.1.3"), netflowV9(1, set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+
+	metrics, err = d.Decode(exporter, netflowV9(1, set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+}
+
+func TestDecodeNetflowV9OptionsTemplate(t *testing.T) {
+	// The sampling rate of the exporter is sent as options data, which is
+	// not turned into metrics.
+	tmpl := packet{}.u16(257).u16(4).u16(4).
+		u16(1).u16(4). // scope system
+		u16(34).u16(4) // sampling interval
+	data := packet{}.u32(0).u32(100)
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, netflowV9(1,
+		set(netflowV9OptionsTemplateSetID, tmpl), set(257, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	tmpl := packet{}.u16(300).u16(6).
+		u16(27).u16(16).                             // src_ip
+		u16(28).u16(16).                             // dst_ip
+		u16(2).u16(8).                               // packets
+		u16(0x8000 | 1).u16(ieVariableLength).u32(9) // enterprise field
+	tmpl = tmpl.
+		u16(152).u16(8). // flow start milliseconds
+		u16(153).u16(8)  // flow end milliseconds
+	data := packet{}.
+		ip("2001:db8::1").ip("2001:db8::2").u64(25).
+		u8(3).bytes(1, 2, 3).
+		u64(1584473704000).u64(1584473705250)
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, ipfix(3, set(ipfixTemplateSetID, tmpl), set(300, data)))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"agent_address": "192.168.1.2",
+				"version":       "10",
+				"source_id":     "3",
+				"src_ip":        "2001:db8::1",
+				"dst_ip":        "2001:db8::2",
+			},
+			map[string]interface{}{
+				"packets":          uint64(25),
+				"flow_duration_ms": uint64(1250),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestDecodeIPFIXWithdrawal(t *testing.T) {
+	tmpl := packet{}.u16(256).u16(1).u16(1).u16(4)
+	data := packet{}.u32(100)
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, ipfix(1, set(ipfixTemplateSetID, tmpl), set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	withdrawal := packet{}.u16(256).u16(0)
+	metrics, err = d.Decode(exporter, ipfix(1, set(ipfixTemplateSetID, withdrawal), set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+
+	// Withdrawal of all templates of the domain.
+	_, err = d.Decode(exporter, ipfix(1, set(ipfixTemplateSetID, tmpl)))
+	require.NoError(t, err)
+	withdrawal = packet{}.u16(ipfixTemplateSetID).u16(0)
+	metrics, err = d.Decode(exporter, ipfix(1, set(ipfixTemplateSetID, withdrawal), set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+}
+
+func TestDecodeIPFIXLongVariableLength(t *testing.T) {
+	tmpl := packet{}.u16(256).u16(2).
+		u16(82).u16(ieVariableLength). // interface name
+		u16(1).u16(4)
+	data := packet{}.u8(255).u16(300).bytes(make([]byte, 300)...).u32(42)
+
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, ipfix(1, set(ipfixTemplateSetID, tmpl), set(256, data)))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"bytes": uint64(42)}, metrics[0].Fields())
+}
+
+func TestDecodeUnknownTemplate(t *testing.T) {
+	d := newTestDecoder()
+	metrics, err := d.Decode(exporter, ipfix(1, set(256, packet{}.u32(100))))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{
+			name: "empty",
+			buf:  []byte{},
+		},
+		{
+			name: "unsupported version",
+			buf:  packet{}.u16(1).u16(0),
+		},
+		{
+			name: "short v9 header",
+			buf:  packet{}.u16(versionNetflowV9).u16(0),
+		},
+		{
+			name: "invalid ipfix length",
+			buf: func() []byte {
+				p := ipfix(1)
+				binary.BigEndian.PutUint16(p[2:], 100)
+				return p
+			}(),
+		},
+		{
+			name: "invalid set length",
+			buf:  netflowV9(1, packet{}.u16(256).u16(100)),
+		},
+		{
+			name: "truncated template",
+			buf:  ipfix(1, set(ipfixTemplateSetID, packet{}.u16(256).u16(2).u16(1).u16(4))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDecoder()
+			_, err := d.Decode(exporter, tt.buf)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+)
+
+// Information elements shared by NetFlow v9 and IPFIX, see
+// https://www.iana.org/assignments/ipfix/ipfix.xhtml
+const (
+	ieIPClassOfService             = 5
+	ieFlowEndSysUpTime             = 21
+	ieFlowStartSysUpTime           = 22
+	ieICMPTypeCodeIPv4             = 32
+	ieFlowDirection                = 61
+	ieICMPTypeCodeIPv6             = 139
+	ieFlowStartSeconds             = 150
+	ieFlowEndSeconds               = 151
+	ieFlowStartMilliseconds        = 152
+	ieFlowEndMilliseconds          = 153
+	ieFlowStartMicroseconds        = 154
+	ieFlowEndMicroseconds          = 155
+	ieFlowStartNanoseconds         = 156
+	ieFlowEndNanoseconds           = 157
+	ieVariableLength        uint16 = 65535
+)
+
+type fieldKind int
+
+const (
+	kindField fieldKind = iota // unsigned integer field
+	kindTag                    // unsigned integer tag
+	kindIP                     // IP address tag
+	kindMAC                    // MAC address tag
+)
+
+type fieldSpec struct {
+	name string
+	kind fieldKind
+}
+
+// fieldSpecs maps the information elements with a direct representation to
+// the tag or field of the metric, using the names of the sflow input.
+var fieldSpecs = map[uint16]fieldSpec{
+	1:  {"bytes", kindField},
+	2:  {"packets", kindField},
+	3:  {"flows", kindField},
+	4:  {"ip_protocol", kindTag},
+	6:  {"tcp_flags", kindField},
+	7:  {"src_port", kindTag},
+	8:  {"src_ip", kindIP},
+	9:  {"src_mask_len", kindTag},
+	10: {"input_ifindex", kindTag},
+	11: {"dst_port", kindTag},
+	12: {"dst_ip", kindIP},
+	13: {"dst_mask_len", kindTag},
+	14: {"output_ifindex", kindTag},
+	15: {"next_hop", kindIP},
+	16: {"src_as", kindTag},
+	17: {"dst_as", kindTag},
+	18: {"bgp_next_hop", kindIP},
+	27: {"src_ip", kindIP},
+	28: {"dst_ip", kindIP},
+	29: {"src_mask_len", kindTag},
+	30: {"dst_mask_len", kindTag},
+	31: {"ipv6_flow_label", kindField},
+	34: {"sampling_rate", kindField},
+	56: {"src_mac", kindMAC},
+	58: {"src_vlan", kindTag},
+	59: {"dst_vlan", kindTag},
+	60: {"ip_version", kindTag},
+	62: {"next_hop", kindIP},
+	63: {"bgp_next_hop", kindIP},
+	80: {"dst_mac", kindMAC},
+	85: {"total_bytes", kindField},
+	86: {"total_packets", kindField},
+}
+
+// flowRecord collects the tags and fields of a flow.
+type flowRecord struct {
+	tags   map[string]string
+	fields map[string]interface{}
+
+	// start and end of the flow, in milliseconds since the exporter booted
+	// or since the epoch
+	start, end       uint64
+	hasStart, hasEnd bool
+}
+
+func newFlowRecord(tags map[string]string) *flowRecord {
+	r := &flowRecord{
+		tags:   make(map[string]string, len(tags)+16),
+		fields: make(map[string]interface{}),
+	}
+	for k, v := range tags {
+		r.tags[k] = v
+	}
+	return r
+}
+
+// setClassOfService sets the DSCP and ECN of the type of service byte.
+func (r *flowRecord) setClassOfService(tos uint8) {
+	r.tags["ip_dscp"] = strconv.FormatUint(uint64(tos>>2), 10)
+	r.tags["ip_ecn"] = strconv.FormatUint(uint64(tos&0x03), 10)
+}
+
+// setTimes sets the start and end of the flow.
+func (r *flowRecord) setTimes(start, end uint64) {
+	r.start, r.hasStart = start, true
+	r.end, r.hasEnd = end, true
+}
+
+// addValue adds the value of the information element to the record.
+// Elements with an unknown representation are ignored.
+func (r *flowRecord) addValue(id uint16, value []byte) {
+	switch id {
+	case ieIPClassOfService:
+		if len(value) == 1 {
+			r.setClassOfService(value[0])
+		}
+		return
+	case ieICMPTypeCodeIPv4, ieICMPTypeCodeIPv6:
+		if len(value) == 2 {
+			r.fields["icmp_type"] = uint64(value[0])
+			r.fields["icmp_code"] = uint64(value[1])
+		}
+		return
+	case ieFlowDirection:
+		switch uintValue(value) {
+		case 0:
+			r.tags["flow_direction"] = "ingress"
+		case 1:
+			r.tags["flow_direction"] = "egress"
+		}
+		return
+	case ieFlowStartSysUpTime, ieFlowStartSeconds, ieFlowStartMilliseconds,
+		ieFlowStartMicroseconds, ieFlowStartNanoseconds:
+		r.start, r.hasStart = toMilliseconds(id, value), true
+		return
+	case ieFlowEndSysUpTime, ieFlowEndSeconds, ieFlowEndMilliseconds,
+		ieFlowEndMicroseconds, ieFlowEndNanoseconds:
+		r.end, r.hasEnd = toMilliseconds(id, value), true
+		return
+	}
+
+	spec, ok := fieldSpecs[id]
+	if !ok {
+		return
+	}
+	switch spec.kind {
+	case kindField:
+		r.fields[spec.name] = uintValue(value)
+	case kindTag:
+		r.tags[spec.name] = strconv.FormatUint(uintValue(value), 10)
+	case kindIP:
+		if len(value) == net.IPv4len || len(value) == net.IPv6len {
+			r.tags[spec.name] = net.IP(value).String()
+		}
+	case kindMAC:
+		if len(value) == 6 {
+			r.tags[spec.name] = net.HardwareAddr(value).String()
+		}
+	}
+}
+
+// finish adds the derived fields to the record.
+func (r *flowRecord) finish() {
+	if r.hasStart && r.hasEnd && r.end >= r.start {
+		r.fields["flow_duration_ms"] = r.end - r.start
+	}
+}
+
+// toMilliseconds converts the timestamp element to milliseconds.  The
+// microsecond and nanosecond elements use the NTP format.
+func toMilliseconds(id uint16, value []byte) uint64 {
+	switch id {
+	case ieFlowStartSeconds, ieFlowEndSeconds:
+		return uintValue(value) * 1000
+	case ieFlowStartMicroseconds, ieFlowEndMicroseconds,
+		ieFlowStartNanoseconds, ieFlowEndNanoseconds:
+		if len(value) != 8 {
+			return 0
+		}
+		seconds := uint64(binary.BigEndian.Uint32(value[:4]))
+		fraction := uint64(binary.BigEndian.Uint32(value[4:]))
+		return seconds*1000 + fraction*1000>>32
+	default:
+		return uintValue(value)
+	}
+}
+
+// uintValue decodes an unsigned integer of up to 8 bytes, the length may be
+// reduced compared to the length of the information element.
+func uintValue(value []byte) uint64 {
+	if len(value) > 8 {
+		value = value[len(value)-8:]
+	}
+	var v uint64
+	for _, b := range value {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package netflow
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Address to listen for NetFlow v5, NetFlow v9 and IPFIX packets.
+  ##   example: service_address = "udp://:2055"
+  ##            service_address = "udp4://:2055"
+  ##            service_address = "udp6://:2055"
+  service_address = "udp://:2055"
+
+  ## Set the size of the operating system's receive buffer.
+  ##   example: read_buffer_size = "64KiB"
+  # read_buffer_size = ""
+`
+
+const (
+	maxPacketSize = 64 * 1024
+)
+
+type NetFlow struct {
+	ServiceAddress string        `toml:"service_address"`
+	ReadBufferSize internal.Size `toml:"read_buffer_size"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	addr    net.Addr
+	decoder *Decoder
+	conn    *net.UDPConn
+	wg      sync.WaitGroup
+}
+
+// Description answers a description of this input plugin
+func (n *NetFlow) Description() string {
+	return "NetFlow v5, NetFlow v9 and IPFIX collector"
+}
+
+// SampleConfig answers a sample configuration
+func (n *NetFlow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NetFlow) Init() error {
+	n.decoder = NewDecoder()
+	n.decoder.Log = n.Log
+	return nil
+}
+
+// Start starts listening on the configured network for flow packets
+func (n *NetFlow) Start(acc telegraf.Accumulator) error {
+	u, err := url.Parse(n.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
+	conn, err := listenUDP(u.Scheme, u.Host)
+	if err != nil {
+		return err
+	}
+	n.conn = conn
+	n.addr = conn.LocalAddr()
+
+	if n.ReadBufferSize.Size > 0 {
+		conn.SetReadBuffer(int(n.ReadBufferSize.Size))
+	}
+
+	n.Log.Infof("Listening on %s://%s", n.addr.Network(), n.addr.String())
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.read(acc)
+	}()
+
+	return nil
+}
+
+// Gather is a NOOP for NetFlow as it receives packets asynchronously
+func (n *NetFlow) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (n *NetFlow) Stop() {
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.wg.Wait()
+}
+
+func (n *NetFlow) Address() net.Addr {
+	return n.addr
+}
+
+func (n *NetFlow) read(acc telegraf.Accumulator) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		count, src, err := n.conn.ReadFromUDP(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				acc.AddError(err)
+			}
+			break
+		}
+
+		metrics, err := n.decoder.Decode(src.IP, buf[:count])
+		for _, m := range metrics {
+			acc.AddMetric(m)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("unable to parse packet from %s: %v", src.IP, err))
+		}
+	}
+}
+
+func listenUDP(network string, address string) (*net.UDPConn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+		addr, err := net.ResolveUDPAddr(network, address)
+		if err != nil {
+			return nil, err
+		}
+		return net.ListenUDP(network, addr)
+	default:
+		return nil, fmt.Errorf("unsupported network type: %s", network)
+	}
+}
+
+func init() {
+	inputs.Add("netflow", func() telegraf.Input {
+		return &NetFlow{}
+	})
+}
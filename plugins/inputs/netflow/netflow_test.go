@@ -0,0 +1,57 @@
+package netflow
+
+import (
+	"net"
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetFlow(t *testing.T) {
+	netflow := &NetFlow{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	err := netflow.Init()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	err = netflow.Start(&acc)
+	require.NoError(t, err)
+	defer netflow.Stop()
+
+	client, err := net.Dial(netflow.Address().Network(), netflow.Address().String())
+	require.NoError(t, err)
+
+	tmpl := packet{}.u16(256).u16(3).
+		u16(8).u16(4).
+		u16(12).u16(4).
+		u16(1).u16(4)
+	data := packet{}.ip("10.0.0.1").ip("10.0.0.2").u32(1500)
+	client.Write(netflowV9(1, set(netflowV9TemplateSetID, tmpl), set(256, data)))
+
+	acc.Wait(1)
+
+	m := acc.GetTelegrafMetrics()[0]
+	require.Equal(t, "netflow", m.Name())
+	require.Equal(t, map[string]string{
+		"agent_address": "127.0.0.1",
+		"version":       "9",
+		"source_id":     "1",
+		"src_ip":        "10.0.0.1",
+		"dst_ip":        "10.0.0.2",
+	}, m.Tags())
+	require.Equal(t, map[string]interface{}{"bytes": uint64(1500)}, m.Fields())
+}
+
+func TestNetFlowUnsupportedNetwork(t *testing.T) {
+	netflow := &NetFlow{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, netflow.Init())
+
+	var acc testutil.Accumulator
+	require.Error(t, netflow.Start(&acc))
+}
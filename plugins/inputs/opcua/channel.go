@@ -0,0 +1,582 @@
+package opcua
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// This file implements the OPC UA connection protocol and secure
+// conversation, see OPC UA Part 6, 6.7 and 7.1.
+
+const (
+	msgHello = "HEL"
+	msgAck   = "ACK"
+	msgError = "ERR"
+	msgOpen  = "OPN"
+	msgClose = "CLO"
+	msgData  = "MSG"
+)
+
+const (
+	chunkFinal        = 'F'
+	chunkIntermediate = 'C'
+	chunkAbort        = 'A'
+)
+
+const (
+	headerSize         = 8
+	sequenceHeaderSize = 8
+
+	// Buffer sizes announced in the Hello and Acknowledge messages
+	bufferSize     = 65535
+	maxMessageSize = 16 * 1024 * 1024
+	maxChunkCount  = 0
+)
+
+// Request types of OpenSecureChannel requests
+const (
+	tokenIssue uint32 = 0
+	tokenRenew uint32 = 1
+)
+
+// secureChannel sends and receives messages over a connection.  It is used by
+// both ends of the channel; local refers to the end using it and remote to
+// the peer.
+type secureChannel struct {
+	conn net.Conn
+
+	policy *securityPolicy
+	mode   uint32
+
+	localCert  *certificate
+	remoteCert []byte
+	remoteKey  *rsa.PublicKey
+
+	channelID uint32
+	tokenID   uint32
+	created   time.Time
+	lifetime  time.Duration
+
+	localKeys  *symmetricKeys
+	remoteKeys map[uint32]*symmetricKeys
+
+	sequenceNumber uint32
+	requestID      uint32
+
+	// Negotiated size of the chunks sent
+	sendBufferSize uint32
+}
+
+func newSecureChannel(conn net.Conn, policy *securityPolicy, mode uint32, localCert *certificate, remoteCert []byte) (*secureChannel, error) {
+	c := &secureChannel{
+		conn:           conn,
+		policy:         policy,
+		mode:           mode,
+		localCert:      localCert,
+		remoteKeys:     make(map[uint32]*symmetricKeys),
+		sendBufferSize: bufferSize,
+	}
+	if policy != nil && policy.secure() {
+		if localCert == nil {
+			return nil, errors.New("certificate required for secure channel")
+		}
+		if err := c.setRemoteCertificate(remoteCert); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *secureChannel) setRemoteCertificate(der []byte) error {
+	key, err := publicKey(der)
+	if err != nil {
+		return fmt.Errorf("invalid certificate of peer: %v", err)
+	}
+	c.remoteCert = der
+	c.remoteKey = key
+	return nil
+}
+
+// setToken sets the security token of the channel and derives its keys from
+// the nonces.  Keys of earlier tokens are kept for messages in transit.
+func (c *secureChannel) setToken(tokenID uint32, lifetime time.Duration, localNonce, remoteNonce []byte) {
+	c.tokenID = tokenID
+	c.created = time.Now()
+	c.lifetime = lifetime
+	c.localKeys = c.policy.deriveKeys(remoteNonce, localNonce)
+	c.remoteKeys[tokenID] = c.policy.deriveKeys(localNonce, remoteNonce)
+}
+
+// expiring returns true if the token should be renewed, after 75% of its
+// lifetime.
+func (c *secureChannel) expiring() bool {
+	return c.lifetime > 0 && time.Since(c.created) > c.lifetime*3/4
+}
+
+func (c *secureChannel) nextSequenceNumber() uint32 {
+	c.sequenceNumber++
+	return c.sequenceNumber
+}
+
+func (c *secureChannel) nextRequestID() uint32 {
+	c.requestID++
+	return c.requestID
+}
+
+func (c *secureChannel) Close() error {
+	return c.conn.Close()
+}
+
+// writeMessage writes a message of the connection protocol.
+func writeMessage(w io.Writer, msgType string, chunkType byte, body []byte) error {
+	b := make([]byte, headerSize, headerSize+len(body))
+	copy(b, msgType)
+	b[3] = chunkType
+	binary.LittleEndian.PutUint32(b[4:], uint32(headerSize+len(body)))
+	_, err := w.Write(append(b, body...))
+	return err
+}
+
+// readMessage reads a message of the connection protocol and returns the
+// complete message including its header.
+func readMessage(r io.Reader) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size < headerSize || size > maxMessageSize {
+		return nil, fmt.Errorf("invalid message size %d", size)
+	}
+	b := make([]byte, size)
+	copy(b, header)
+	if _, err := io.ReadFull(r, b[headerSize:]); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// errorMessage decodes the body of an Error message.
+func errorMessage(body []byte) error {
+	d := newDecoder(body)
+	status := statusCode(d.uint32())
+	reason := d.string()
+	if d.err != nil {
+		return fmt.Errorf("invalid error message: %v", d.err)
+	}
+	if reason != "" {
+		return fmt.Errorf("%v: %s", status, reason)
+	}
+	return status
+}
+
+// hello exchanges the Hello and Acknowledge messages opening a connection.
+func (c *secureChannel) hello(endpointURL string) error {
+	var e encoder
+	e.uint32(0) // protocol version
+	e.uint32(bufferSize)
+	e.uint32(bufferSize)
+	e.uint32(maxMessageSize)
+	e.uint32(maxChunkCount)
+	e.string(endpointURL)
+	if err := writeMessage(c.conn, msgHello, chunkFinal, e.bytes()); err != nil {
+		return err
+	}
+
+	b, err := readMessage(c.conn)
+	if err != nil {
+		return err
+	}
+	switch string(b[:3]) {
+	case msgAck:
+	case msgError:
+		return errorMessage(b[headerSize:])
+	default:
+		return fmt.Errorf("unexpected %q message", b[:3])
+	}
+
+	d := newDecoder(b[headerSize:])
+	d.uint32() // protocol version
+	receiveBufferSize := d.uint32()
+	d.uint32() // send buffer size
+	d.uint32() // max message size
+	d.uint32() // max chunk count
+	if d.err != nil {
+		return fmt.Errorf("invalid acknowledge message: %v", d.err)
+	}
+	if receiveBufferSize < 8192 {
+		return fmt.Errorf("receive buffer size %d of server too small", receiveBufferSize)
+	}
+	if receiveBufferSize < c.sendBufferSize {
+		c.sendBufferSize = receiveBufferSize
+	}
+	return nil
+}
+
+// sendOpen sends an OpenSecureChannel message, secured with the asymmetric
+// algorithms of the policy.
+func (c *secureChannel) sendOpen(requestID uint32, body []byte) error {
+	var e encoder
+	e.uint32(c.channelID)
+	e.string(c.policy.uri)
+	if c.policy.secure() {
+		e.byteString(c.localCert.der)
+		e.byteString(thumbprint(c.remoteCert))
+	} else {
+		e.byteString(nil)
+		e.byteString(nil)
+	}
+	securityHeader := e.bytes()
+
+	var p encoder
+	p.uint32(c.nextSequenceNumber())
+	p.uint32(requestID)
+	plain := append(p.bytes(), body...)
+
+	if !c.policy.secure() {
+		return writeMessage(c.conn, msgOpen, chunkFinal, append(securityHeader, plain...))
+	}
+
+	sigSize := c.localCert.key.Size()
+	blockSize := c.policy.asymmetricPlainBlockSize(c.remoteKey)
+	plain = append(plain, padding(len(plain), sigSize, blockSize, c.remoteKey.Size() > 256)...)
+
+	// The signature covers the header of the final message size.
+	encryptedSize := (len(plain) + sigSize) / blockSize * c.remoteKey.Size()
+	message := make([]byte, headerSize, headerSize+len(securityHeader)+encryptedSize)
+	copy(message, msgOpen)
+	message[3] = chunkFinal
+	binary.LittleEndian.PutUint32(message[4:], uint32(headerSize+len(securityHeader)+encryptedSize))
+	message = append(message, securityHeader...)
+
+	signature, err := c.policy.asymmetricSign(c.localCert.key, append(message, plain...))
+	if err != nil {
+		return err
+	}
+	encrypted, err := c.policy.asymmetricEncrypt(c.remoteKey, append(plain, signature...))
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(append(message, encrypted...))
+	return err
+}
+
+// send sends a message secured with the symmetric algorithms of the current
+// token, split into chunks of the negotiated buffer size.
+func (c *secureChannel) send(msgType string, requestID uint32, body []byte) error {
+	sign := c.mode == modeSign || c.mode == modeSignAndEncrypt
+	encrypt := c.mode == modeSignAndEncrypt
+	sigSize := 0
+	if sign {
+		sigSize = c.policy.symmetricSignatureSize()
+	}
+
+	// Largest body of a chunk; encrypted chunks need at least one padding
+	// byte.
+	maxBody := int(c.sendBufferSize) - headerSize - 8 - sequenceHeaderSize - sigSize
+	if encrypt {
+		maxBody = (int(c.sendBufferSize)-headerSize-8)/16*16 - sequenceHeaderSize - sigSize - 1
+	}
+
+	for {
+		n := len(body)
+		chunkType := byte(chunkFinal)
+		if n > maxBody {
+			n = maxBody
+			chunkType = chunkIntermediate
+		}
+		if err := c.sendChunk(msgType, chunkType, requestID, body[:n], sigSize, encrypt); err != nil {
+			return err
+		}
+		body = body[n:]
+		if chunkType == chunkFinal {
+			return nil
+		}
+	}
+}
+
+func (c *secureChannel) sendChunk(msgType string, chunkType byte, requestID uint32, body []byte, sigSize int, encrypt bool) error {
+	message := make([]byte, headerSize+8+sequenceHeaderSize, headerSize+8+sequenceHeaderSize+len(body)+sigSize+16)
+	copy(message, msgType)
+	message[3] = chunkType
+	binary.LittleEndian.PutUint32(message[8:], c.channelID)
+	binary.LittleEndian.PutUint32(message[12:], c.tokenID)
+	binary.LittleEndian.PutUint32(message[16:], c.nextSequenceNumber())
+	binary.LittleEndian.PutUint32(message[20:], requestID)
+	message = append(message, body...)
+	if encrypt {
+		message = append(message, padding(len(message)-16, sigSize, 16, false)...)
+	}
+	binary.LittleEndian.PutUint32(message[4:], uint32(len(message)+sigSize))
+
+	if sigSize > 0 {
+		message = append(message, c.policy.symmetricSign(c.localKeys, message)...)
+	}
+	if encrypt {
+		if err := c.policy.symmetricEncrypt(c.localKeys, message[16:]); err != nil {
+			return err
+		}
+	}
+	_, err := c.conn.Write(message)
+	return err
+}
+
+// receive reads the chunks of the next message and returns its type, request
+// id and body.
+func (c *secureChannel) receive() (string, uint32, []byte, error) {
+	var body []byte
+	for {
+		b, err := readMessage(c.conn)
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		msgType := string(b[:3])
+		var requestID uint32
+		var chunk []byte
+		switch msgType {
+		case msgError:
+			return "", 0, nil, errorMessage(b[headerSize:])
+		case msgOpen:
+			requestID, chunk, err = c.openChunk(b)
+		case msgData, msgClose:
+			requestID, chunk, err = c.chunk(b)
+		default:
+			return "", 0, nil, fmt.Errorf("unexpected %q message", msgType)
+		}
+		if err != nil {
+			return "", 0, nil, err
+		}
+
+		switch b[3] {
+		case chunkFinal:
+			return msgType, requestID, append(body, chunk...), nil
+		case chunkIntermediate:
+			body = append(body, chunk...)
+			if len(body) > maxMessageSize {
+				return "", 0, nil, errors.New("message too large")
+			}
+		case chunkAbort:
+			return "", 0, nil, fmt.Errorf("message aborted: %v", errorMessage(chunk))
+		default:
+			return "", 0, nil, fmt.Errorf("invalid chunk type %q", b[3])
+		}
+	}
+}
+
+// openChunk decodes an OpenSecureChannel chunk and returns its request id
+// and body.
+func (c *secureChannel) openChunk(b []byte) (uint32, []byte, error) {
+	d := newDecoder(b[headerSize:])
+	channelID := d.uint32()
+	policyURI := d.string()
+	senderCert := d.byteString()
+	d.byteString() // receiver certificate thumbprint
+	if d.err != nil {
+		return 0, nil, fmt.Errorf("invalid security header: %v", d.err)
+	}
+	if c.channelID != 0 && channelID != c.channelID {
+		return 0, nil, statusBadSecureChannelIDInvalid
+	}
+
+	policy := policyByURI(policyURI)
+	if policy == nil || (c.policy != nil && policy != c.policy) {
+		return 0, nil, statusBadSecurityPolicyRejected
+	}
+	c.policy = policy
+
+	offset := headerSize + d.pos
+	plain := b[offset:]
+	if policy.secure() {
+		if c.localCert == nil {
+			return 0, nil, statusBadSecurityPolicyRejected
+		}
+		if err := c.setRemoteCertificate(senderCert); err != nil {
+			return 0, nil, err
+		}
+
+		var err error
+		plain, err = policy.asymmetricDecrypt(c.localCert.key, plain)
+		if err != nil {
+			return 0, nil, fmt.Errorf("decrypting message: %v", err)
+		}
+
+		sigSize := c.remoteKey.Size()
+		if len(plain) < sigSize+sequenceHeaderSize {
+			return 0, nil, errShortBuffer
+		}
+		signed := append(append([]byte{}, b[:offset]...), plain[:len(plain)-sigSize]...)
+		if err := policy.asymmetricVerify(c.remoteKey, signed, plain[len(plain)-sigSize:]); err != nil {
+			return 0, nil, fmt.Errorf("verifying message: %v", err)
+		}
+
+		end, err := unpad(plain[:len(plain)-sigSize], c.localCert.key.Size() > 256)
+		if err != nil {
+			return 0, nil, err
+		}
+		plain = plain[:end]
+	}
+
+	if len(plain) < sequenceHeaderSize {
+		return 0, nil, errShortBuffer
+	}
+	return binary.LittleEndian.Uint32(plain[4:]), plain[sequenceHeaderSize:], nil
+}
+
+// chunk decodes a chunk secured with the symmetric algorithms and returns its
+// request id and body.
+func (c *secureChannel) chunk(b []byte) (uint32, []byte, error) {
+	if len(b) < headerSize+8+sequenceHeaderSize {
+		return 0, nil, errShortBuffer
+	}
+	channelID := binary.LittleEndian.Uint32(b[8:])
+	tokenID := binary.LittleEndian.Uint32(b[12:])
+	if channelID != c.channelID {
+		return 0, nil, statusBadSecureChannelIDInvalid
+	}
+	keys, ok := c.remoteKeys[tokenID]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown security token %d", tokenID)
+	}
+
+	end := len(b)
+	if c.mode == modeSignAndEncrypt {
+		if err := c.policy.symmetricDecrypt(keys, b[16:]); err != nil {
+			return 0, nil, fmt.Errorf("decrypting message: %v", err)
+		}
+	}
+	if c.mode == modeSign || c.mode == modeSignAndEncrypt {
+		sigSize := c.policy.symmetricSignatureSize()
+		if end < 16+sequenceHeaderSize+sigSize {
+			return 0, nil, errShortBuffer
+		}
+		end -= sigSize
+		if err := c.policy.symmetricVerify(keys, b[:end], b[end:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	if c.mode == modeSignAndEncrypt {
+		n, err := unpad(b[16:end], false)
+		if err != nil {
+			return 0, nil, err
+		}
+		end = 16 + n
+	}
+	if end < 16+sequenceHeaderSize {
+		return 0, nil, errShortBuffer
+	}
+	return binary.LittleEndian.Uint32(b[20:]), b[16+sequenceHeaderSize : end], nil
+}
+
+// request sends a message and returns the body of the response, skipping
+// responses to other requests.
+func (c *secureChannel) request(body []byte, timeout time.Duration) ([]byte, error) {
+	requestID := c.nextRequestID()
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if err := c.send(msgData, requestID, body); err != nil {
+		return nil, err
+	}
+
+	for {
+		msgType, id, response, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if msgType == msgData && id == requestID {
+			return response, nil
+		}
+	}
+}
+
+// openRequest sends an OpenSecureChannel request and returns the body of the
+// response.
+func (c *secureChannel) openRequest(body []byte, timeout time.Duration) ([]byte, error) {
+	requestID := c.nextRequestID()
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if err := c.sendOpen(requestID, body); err != nil {
+		return nil, err
+	}
+
+	for {
+		msgType, id, response, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if msgType == msgOpen && id == requestID {
+			return response, nil
+		}
+	}
+}
+
+// open issues or renews the security token of the channel.
+func (c *secureChannel) open(requestType uint32, lifetime, timeout time.Duration) error {
+	localNonce, err := c.policy.nonce()
+	if err != nil {
+		return err
+	}
+	if !c.policy.secure() {
+		localNonce = nil
+	}
+
+	var e encoder
+	e.nodeID(numericID(0, idOpenSecureChannelRequest))
+	e.requestHeader(nodeID{}, 0, timeout)
+	e.uint32(0) // client protocol version
+	e.uint32(requestType)
+	e.uint32(c.mode)
+	e.byteString(localNonce)
+	e.uint32(uint32(lifetime / time.Millisecond))
+
+	body, err := c.openRequest(e.bytes(), timeout)
+	if err != nil {
+		return err
+	}
+
+	d, err := responseBody(body, idOpenSecureChannelResponse)
+	if err != nil {
+		return err
+	}
+	d.uint32() // server protocol version
+	channelID := d.uint32()
+	tokenID := d.uint32()
+	d.dateTime()
+	revisedLifetime := time.Duration(d.uint32()) * time.Millisecond
+	remoteNonce := d.byteString()
+	if d.err != nil {
+		return fmt.Errorf("invalid OpenSecureChannel response: %v", d.err)
+	}
+	if c.policy.secure() && len(remoteNonce) != c.policy.nonceLength {
+		return fmt.Errorf("invalid server nonce length %d", len(remoteNonce))
+	}
+
+	c.channelID = channelID
+	c.setToken(tokenID, revisedLifetime, localNonce, remoteNonce)
+	return nil
+}
+
+// close sends a CloseSecureChannel request, which has no response.
+func (c *secureChannel) close(timeout time.Duration) error {
+	var e encoder
+	e.nodeID(numericID(0, idCloseSecureChannelRequest))
+	e.requestHeader(nodeID{}, 0, timeout)
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return c.send(msgClose, c.nextRequestID(), e.bytes())
+}
+
+// sameCertificate compares two DER encoded certificates or chains by their
+// leaf certificate.
+func sameCertificate(a, b []byte) bool {
+	return bytes.Equal(leafCertificate(a), leafCertificate(b))
+}
@@ -0,0 +1,615 @@
+package opcua
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Lifetime requested for the security tokens of secure channels
+const tokenLifetime = time.Hour
+
+// Authentication methods
+const (
+	authAnonymous   = "Anonymous"
+	authUserName    = "UserName"
+	authCertificate = "Certificate"
+)
+
+var authTokenTypes = map[string]uint32{
+	authAnonymous:   tokenAnonymous,
+	authUserName:    tokenUserName,
+	authCertificate: tokenCertificate,
+}
+
+// clientConfig holds the settings of a connection to a server.
+type clientConfig struct {
+	endpoint string
+
+	// Name of the security policy and mode, or "auto" to choose the most
+	// secure endpoint
+	securityPolicy string
+	securityMode   string
+
+	// Application certificate, also used by certificate authentication
+	cert *certificate
+
+	// Trusted server certificates and certificate authorities
+	trusted            *x509.CertPool
+	insecureSkipVerify bool
+
+	authMethod string
+	username   string
+	password   string
+
+	// Send the password without encryption if neither the user token policy
+	// nor the channel encrypt it
+	allowPlaintextPassword bool
+
+	connectTimeout time.Duration
+	requestTimeout time.Duration
+}
+
+// client is a session with a server.  It is not safe for concurrent use.
+type client struct {
+	config  *clientConfig
+	channel *secureChannel
+
+	authToken     nodeID
+	requestHandle uint32
+}
+
+// connect opens a secure channel to the server and activates a session on
+// it.
+func connect(config *clientConfig) (*client, error) {
+	endpoints, err := getEndpoints(config)
+	if err != nil {
+		return nil, fmt.Errorf("getting endpoints: %v", err)
+	}
+	endpoint, tokenPolicy, err := selectEndpoint(config, endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := policyByURI(endpoint.securityPolicyURI)
+	if config.authMethod == authUserName && !config.allowPlaintextPassword && plaintextPassword(policy, endpoint.securityMode, tokenPolicy) {
+		return nil, errors.New("the endpoint does not encrypt the password; set allow_plaintext_password to send it in plaintext")
+	}
+	if !config.insecureSkipVerify && usesServerCertificate(config, policy, tokenPolicy) {
+		if err := verifyCertificate(endpoint.serverCertificate, config.trusted, time.Now()); err != nil {
+			return nil, fmt.Errorf("verifying server certificate: %v", err)
+		}
+	}
+
+	channel, err := openChannel(config, policy, endpoint.securityMode, endpoint.serverCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{config: config, channel: channel}
+	if err := c.createSession(endpoint, tokenPolicy, endpoints); err != nil {
+		c.closeChannel()
+		return nil, err
+	}
+	return c, nil
+}
+
+// usesServerCertificate reports whether the server certificate of the
+// endpoint is used, either by the security policy of the channel or to
+// encrypt the password of the user token.
+func usesServerCertificate(config *clientConfig, policy *securityPolicy, tokenPolicy userTokenPolicy) bool {
+	if policy.secure() {
+		return true
+	}
+	return config.authMethod == authUserName && tokenSecurityPolicy(policy, tokenPolicy).secure()
+}
+
+// plaintextPassword reports whether the password of the user token is sent
+// unencrypted, as neither the user token policy nor the channel encrypt it.
+func plaintextPassword(policy *securityPolicy, mode uint32, tokenPolicy userTokenPolicy) bool {
+	return !tokenSecurityPolicy(policy, tokenPolicy).secure() && mode != modeSignAndEncrypt
+}
+
+// tokenSecurityPolicy returns the security policy of the user token policy,
+// which defaults to the policy of the channel.
+func tokenSecurityPolicy(policy *securityPolicy, tokenPolicy userTokenPolicy) *securityPolicy {
+	if tokenPolicy.securityPolicyURI != "" {
+		return policyByURI(tokenPolicy.securityPolicyURI)
+	}
+	return policy
+}
+
+// openChannel connects to the server and opens a secure channel.
+func openChannel(config *clientConfig, policy *securityPolicy, mode uint32, serverCert []byte) (*secureChannel, error) {
+	u, err := url.Parse(config.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "opc.tcp" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4840")
+	}
+
+	conn, err := net.DialTimeout("tcp", host, config.connectTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := newSecureChannel(conn, policy, mode, config.cert, serverCert)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(config.connectTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := channel.hello(config.endpoint); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("opening connection: %v", err)
+	}
+	if err := channel.open(tokenIssue, tokenLifetime, config.requestTimeout); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("opening secure channel: %v", err)
+	}
+	return channel, nil
+}
+
+// getEndpoints returns the endpoints of the server, discovered over an
+// unsecured channel.
+func getEndpoints(config *clientConfig) ([]endpointDescription, error) {
+	channel, err := openChannel(config, policyNone, modeNone, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer channel.Close()
+
+	var e encoder
+	e.nodeID(numericID(0, idGetEndpointsRequest))
+	e.requestHeader(nodeID{}, 1, config.requestTimeout)
+	e.string(config.endpoint)
+	e.strings(nil) // locale ids
+	e.strings(nil) // profile uris
+
+	body, err := channel.request(e.bytes(), config.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	d, err := responseBody(body, idGetEndpointsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []endpointDescription
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		endpoints = append(endpoints, d.endpointDescription())
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid GetEndpoints response: %v", d.err)
+	}
+
+	// The channel is closed without waiting for the server.
+	channel.close(config.requestTimeout) //nolint:errcheck
+	return endpoints, nil
+}
+
+// selectEndpoint returns the endpoint matching the configured security policy
+// and mode, and its user token policy of the authentication method.  With
+// "auto" settings the most secure matching endpoint is chosen.
+func selectEndpoint(config *clientConfig, endpoints []endpointDescription) (endpointDescription, userTokenPolicy, error) {
+	tokenType := authTokenTypes[config.authMethod]
+
+	var best endpointDescription
+	var bestToken userTokenPolicy
+	bestScore := -1
+	for _, endpoint := range endpoints {
+		policy := policyByURI(endpoint.securityPolicyURI)
+		if policy == nil {
+			continue
+		}
+		if config.securityPolicy != "auto" && policy.name != config.securityPolicy {
+			continue
+		}
+		if config.securityMode != "auto" && endpoint.securityMode != securityModes[config.securityMode] {
+			continue
+		}
+		if endpoint.securityMode < modeNone || endpoint.securityMode > modeSignAndEncrypt {
+			continue
+		}
+		if policy.secure() && config.cert == nil {
+			continue
+		}
+
+		var token userTokenPolicy
+		found := false
+		for _, t := range endpoint.userTokens {
+			if t.tokenType == tokenType && (t.securityPolicyURI == "" || policyByURI(t.securityPolicyURI) != nil) {
+				token = t
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		score := int(endpoint.securityMode)
+		for i, p := range securityPolicies {
+			if p == policy {
+				score += 4 * i
+			}
+		}
+		if score > bestScore {
+			best, bestToken, bestScore = endpoint, token, score
+		}
+	}
+
+	if bestScore < 0 {
+		return best, bestToken, fmt.Errorf("no endpoint with security policy %q, security mode %q and authentication method %q",
+			config.securityPolicy, config.securityMode, config.authMethod)
+	}
+	return best, bestToken, nil
+}
+
+// request sends a service request and returns a decoder of the response,
+// positioned after its header.  The security token of the channel is
+// renewed before it expires.
+func (c *client) request(typeID, responseTypeID uint32, timeout time.Duration, body func(e *encoder)) (*decoder, error) {
+	if c.channel.expiring() {
+		if err := c.channel.open(tokenRenew, tokenLifetime, c.config.requestTimeout); err != nil {
+			return nil, fmt.Errorf("renewing security token: %v", err)
+		}
+	}
+
+	c.requestHandle++
+	var e encoder
+	e.nodeID(numericID(0, typeID))
+	e.requestHeader(c.authToken, c.requestHandle, timeout)
+	body(&e)
+
+	response, err := c.channel.request(e.bytes(), timeout)
+	if err != nil {
+		return nil, err
+	}
+	return responseBody(response, responseTypeID)
+}
+
+// createSession creates and activates a session on the endpoint.  The
+// endpoints returned by the server over the channel have to match the
+// discovered endpoints, which were received over an unsecured channel and
+// could have been modified to downgrade the security.
+func (c *client) createSession(endpoint endpointDescription, tokenPolicy userTokenPolicy, discovered []endpointDescription) error {
+	policy := c.channel.policy
+	clientNonce, err := policyBasic256Sha256.nonce()
+	if err != nil {
+		return err
+	}
+
+	var clientCert []byte
+	applicationURI := "urn:telegraf:opcua"
+	if c.config.cert != nil {
+		clientCert = c.config.cert.der
+		if c.config.cert.uri != "" {
+			applicationURI = c.config.cert.uri
+		}
+	}
+
+	d, err := c.request(idCreateSessionRequest, idCreateSessionResponse, c.config.requestTimeout, func(e *encoder) {
+		e.applicationDescription(applicationDescription{
+			uri:     applicationURI,
+			product: "urn:influxdata:telegraf",
+			name:    "Telegraf",
+			kind:    1,
+		})
+		e.string(endpoint.server.uri)
+		e.string(c.config.endpoint)
+		e.string("telegraf")
+		e.byteString(clientNonce)
+		e.byteString(clientCert)
+		e.float64(float64(time.Hour / time.Millisecond))
+		e.uint32(maxMessageSize)
+	})
+	if err != nil {
+		return fmt.Errorf("creating session: %v", err)
+	}
+
+	d.nodeID() // session id
+	authToken := d.nodeID()
+	d.float64() // revised session timeout
+	serverNonce := d.byteString()
+	serverCert := d.byteString()
+	var endpoints []endpointDescription
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		endpoints = append(endpoints, d.endpointDescription())
+	}
+	n = d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		d.byteString()
+		d.byteString()
+	}
+	serverSignature := d.signatureData()
+	if d.err != nil {
+		return fmt.Errorf("invalid CreateSession response: %v", d.err)
+	}
+
+	if !sameEndpoints(endpoints, discovered) {
+		return errors.New("endpoints of the session differ from the discovered endpoints")
+	}
+	if policy.secure() {
+		if !sameCertificate(serverCert, endpoint.serverCertificate) {
+			return errors.New("server certificate differs from the one of the endpoint")
+		}
+		data := append(append([]byte{}, clientCert...), clientNonce...)
+		if err := policy.asymmetricVerify(c.channel.remoteKey, data, serverSignature.signature); err != nil {
+			return fmt.Errorf("verifying server signature: %v", err)
+		}
+	}
+
+	c.authToken = authToken
+	if err := c.activateSession(endpoint, tokenPolicy, serverNonce); err != nil {
+		return fmt.Errorf("activating session: %v", err)
+	}
+	return nil
+}
+
+// sameEndpoints reports whether the endpoints offer the same security
+// policies, security modes and user token policies, in any order.
+func sameEndpoints(a, b []endpointDescription) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keys := func(endpoints []endpointDescription) []string {
+		k := make([]string, 0, len(endpoints))
+		for _, e := range endpoints {
+			key := fmt.Sprintf("%s|%d", e.securityPolicyURI, e.securityMode)
+			for _, t := range e.userTokens {
+				key += fmt.Sprintf("|%s,%d,%s", t.policyID, t.tokenType, t.securityPolicyURI)
+			}
+			k = append(k, key)
+		}
+		sort.Strings(k)
+		return k
+	}
+	ka, kb := keys(a), keys(b)
+	for i := range ka {
+		if ka[i] != kb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// activateSession activates the session with the identity token of the
+// authentication method.
+func (c *client) activateSession(endpoint endpointDescription, tokenPolicy userTokenPolicy, serverNonce []byte) error {
+	policy := c.channel.policy
+	serverData := append(append([]byte{}, leafCertificate(endpoint.serverCertificate)...), serverNonce...)
+
+	var clientSignature signatureData
+	if policy.secure() {
+		signature, err := policy.asymmetricSign(c.config.cert.key, serverData)
+		if err != nil {
+			return err
+		}
+		clientSignature = signatureData{algorithm: policy.signatureURI, signature: signature}
+	}
+
+	tokenSecurity := tokenSecurityPolicy(policy, tokenPolicy)
+
+	var tokenID uint32
+	var token func(e *encoder)
+	var tokenSignature signatureData
+	switch c.config.authMethod {
+	case authAnonymous:
+		tokenID = idAnonymousIdentityToken
+		token = func(e *encoder) {
+			e.string(tokenPolicy.policyID)
+		}
+	case authUserName:
+		password := []byte(c.config.password)
+		algorithm := ""
+		if tokenSecurity.secure() {
+			key, err := publicKey(endpoint.serverCertificate)
+			if err != nil {
+				return err
+			}
+			var plain encoder
+			plain.uint32(uint32(len(password) + len(serverNonce)))
+			plain.buf = append(plain.buf, password...)
+			plain.buf = append(plain.buf, serverNonce...)
+			password, err = tokenSecurity.asymmetricEncrypt(key, plain.bytes())
+			if err != nil {
+				return err
+			}
+			algorithm = tokenSecurity.encryptionURI
+		}
+		tokenID = idUserNameIdentityToken
+		token = func(e *encoder) {
+			e.string(tokenPolicy.policyID)
+			e.string(c.config.username)
+			e.byteString(password)
+			e.string(algorithm)
+		}
+	case authCertificate:
+		// The token is signed even on unsecured channels.
+		if !tokenSecurity.secure() {
+			tokenSecurity = policyBasic256Sha256
+		}
+		signature, err := tokenSecurity.asymmetricSign(c.config.cert.key, serverData)
+		if err != nil {
+			return err
+		}
+		tokenSignature = signatureData{algorithm: tokenSecurity.signatureURI, signature: signature}
+		tokenID = idX509IdentityToken
+		token = func(e *encoder) {
+			e.string(tokenPolicy.policyID)
+			e.byteString(c.config.cert.der)
+		}
+	default:
+		return fmt.Errorf("invalid authentication method %q", c.config.authMethod)
+	}
+
+	d, err := c.request(idActivateSessionRequest, idActivateSessionResponse, c.config.requestTimeout, func(e *encoder) {
+		e.signatureData(clientSignature)
+		e.int32(-1)    // client software certificates
+		e.strings(nil) // locale ids
+		e.extensionObject(tokenID, token)
+		e.signatureData(tokenSignature)
+	})
+	if err != nil {
+		return err
+	}
+	d.byteString() // server nonce
+	return d.err
+}
+
+// read reads the values of the nodes.
+func (c *client) read(nodes []nodeID) ([]dataValue, []error, error) {
+	d, err := c.request(idReadRequest, idReadResponse, c.config.requestTimeout, func(e *encoder) {
+		e.float64(0) // max age
+		e.uint32(timestampsBoth)
+		e.int32(int32(len(nodes)))
+		for _, id := range nodes {
+			e.readValueID(id)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := d.arrayLength()
+	if d.err == nil && n != len(nodes) {
+		return nil, nil, fmt.Errorf("read returned %d values for %d nodes", n, len(nodes))
+	}
+	values := make([]dataValue, 0, len(nodes))
+	errs := make([]error, 0, len(nodes))
+	for i := 0; i < n && d.err == nil; i++ {
+		v, err := d.dataValue()
+		values = append(values, v)
+		errs = append(errs, err)
+	}
+	if d.err != nil {
+		return nil, nil, fmt.Errorf("invalid Read response: %v", d.err)
+	}
+	return values, errs, nil
+}
+
+// subscribe creates a subscription publishing at the interval, with
+// monitored items of the nodes.  The client handle of each item is the
+// index of its node.
+func (c *client) subscribe(nodes []nodeID, interval time.Duration) (uint32, error) {
+	d, err := c.request(idCreateSubscriptionRequest, idCreateSubscriptionResponse, c.config.requestTimeout, func(e *encoder) {
+		e.float64(float64(interval) / float64(time.Millisecond))
+		e.uint32(30) // lifetime count
+		e.uint32(10) // max keep alive count
+		e.uint32(0)  // max notifications per publish
+		e.boolean(true)
+		e.uint8(0) // priority
+	})
+	if err != nil {
+		return 0, fmt.Errorf("creating subscription: %v", err)
+	}
+	subscriptionID := d.uint32()
+	if d.err != nil {
+		return 0, fmt.Errorf("invalid CreateSubscription response: %v", d.err)
+	}
+
+	d, err = c.request(idCreateMonitoredItemsRequest, idCreateMonitoredItemsResponse, c.config.requestTimeout, func(e *encoder) {
+		e.uint32(subscriptionID)
+		e.uint32(timestampsBoth)
+		e.int32(int32(len(nodes)))
+		for i, id := range nodes {
+			e.readValueID(id)
+			e.uint32(monitoringReporting)
+			e.uint32(uint32(i))
+			e.float64(float64(interval) / float64(time.Millisecond))
+			e.extensionObject(0, nil) // filter
+			e.uint32(1)               // queue size
+			e.boolean(true)           // discard oldest
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("creating monitored items: %v", err)
+	}
+
+	n := d.arrayLength()
+	if d.err == nil && n != len(nodes) {
+		return 0, fmt.Errorf("created %d monitored items for %d nodes", n, len(nodes))
+	}
+	for i := 0; i < n && d.err == nil; i++ {
+		status := statusCode(d.uint32())
+		d.uint32()  // monitored item id
+		d.float64() // revised sampling interval
+		d.uint32()  // revised queue size
+		d.extensionObject()
+		if status.isBad() {
+			return 0, fmt.Errorf("creating monitored item for node %v: %v", nodes[i], status)
+		}
+	}
+	if d.err != nil {
+		return 0, fmt.Errorf("invalid CreateMonitoredItems response: %v", d.err)
+	}
+	return subscriptionID, nil
+}
+
+// publish acknowledges the previous notification message of the subscription
+// and waits for the next one, returning its sequence number and data change
+// notifications.  Keep-alive messages return no notifications.
+func (c *client) publish(subscriptionID, ack uint32, timeout time.Duration) (uint32, []notification, error) {
+	d, err := c.request(idPublishRequest, idPublishResponse, timeout, func(e *encoder) {
+		if ack == 0 {
+			e.int32(0)
+			return
+		}
+		e.int32(1)
+		e.uint32(subscriptionID)
+		e.uint32(ack)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if id := d.uint32(); d.err == nil && id != subscriptionID {
+		return 0, nil, fmt.Errorf("notification of unknown subscription %d", id)
+	}
+	d.uint32s() // available sequence numbers
+	d.boolean() // more notifications
+	sequenceNumber, notifications, err := d.notificationMessage()
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid Publish response: %v", err)
+	}
+	if len(notifications) == 0 {
+		// Keep-alive messages are not acknowledged.
+		return 0, nil, nil
+	}
+	return sequenceNumber, notifications, nil
+}
+
+// interrupt aborts a request waiting for its response.
+func (c *client) interrupt() {
+	c.channel.conn.SetReadDeadline(time.Now()) //nolint:errcheck
+}
+
+// close closes the session, deleting its subscriptions, and the secure
+// channel.
+func (c *client) close() error {
+	_, err := c.request(idCloseSessionRequest, idCloseSessionResponse, c.config.requestTimeout, func(e *encoder) {
+		e.boolean(true)
+	})
+	c.closeChannel()
+	return err
+}
+
+func (c *client) closeChannel() {
+	c.channel.close(c.config.requestTimeout) //nolint:errcheck
+	c.channel.Close()
+}
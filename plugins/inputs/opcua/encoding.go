@@ -0,0 +1,843 @@
+package opcua
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements the OPC UA binary encoding of the built-in types, see
+// OPC UA Part 6, 5.2.
+
+var errShortBuffer = errors.New("unexpected end of message")
+
+// encoder appends values in binary encoding to a buffer.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) bytes() []byte {
+	return e.buf
+}
+
+func (e *encoder) boolean(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.buf = append(e.buf, byte(v), byte(v>>8))
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf = append(e.buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (e *encoder) int32(v int32) {
+	e.uint32(uint32(v))
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.uint32(uint32(v))
+	e.uint32(uint32(v >> 32))
+}
+
+func (e *encoder) int64(v int64) {
+	e.uint64(uint64(v))
+}
+
+func (e *encoder) float64(v float64) {
+	e.uint64(math.Float64bits(v))
+}
+
+// string encodes a string; the empty string is encoded as null string.
+func (e *encoder) string(v string) {
+	if v == "" {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// byteString encodes a byte string; nil is encoded as null byte string.
+func (e *encoder) byteString(v []byte) {
+	if v == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) strings(v []string) {
+	if v == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(v)))
+	for _, s := range v {
+		e.string(s)
+	}
+}
+
+func (e *encoder) dateTime(v time.Time) {
+	e.int64(toDateTime(v))
+}
+
+// nodeID encodes a node id; the zero value is encoded as the null node id.
+func (e *encoder) nodeID(v nodeID) {
+	switch v.kind {
+	case 0, idNumeric:
+		switch {
+		case v.namespace == 0 && v.numeric <= math.MaxUint8:
+			e.uint8(0x00)
+			e.uint8(uint8(v.numeric))
+		case v.namespace <= math.MaxUint8 && v.numeric <= math.MaxUint16:
+			e.uint8(0x01)
+			e.uint8(uint8(v.namespace))
+			e.uint16(uint16(v.numeric))
+		default:
+			e.uint8(0x02)
+			e.uint16(v.namespace)
+			e.uint32(v.numeric)
+		}
+	case idString:
+		e.uint8(0x03)
+		e.uint16(v.namespace)
+		e.string(v.text)
+	case idGUID:
+		e.uint8(0x04)
+		e.uint16(v.namespace)
+		e.buf = append(e.buf, v.text...)
+	case idOpaque:
+		e.uint8(0x05)
+		e.uint16(v.namespace)
+		e.byteString([]byte(v.text))
+	}
+}
+
+func (e *encoder) qualifiedName(namespace uint16, name string) {
+	e.uint16(namespace)
+	e.string(name)
+}
+
+func (e *encoder) localizedText(text string) {
+	if text == "" {
+		e.uint8(0x00)
+		return
+	}
+	e.uint8(0x02)
+	e.string(text)
+}
+
+// extensionObject encodes a structure of the given binary encoding id as
+// extension object.
+func (e *encoder) extensionObject(typeID uint32, body func(e *encoder)) {
+	e.nodeID(numericID(0, typeID))
+	if body == nil {
+		e.uint8(0x00)
+		return
+	}
+	e.uint8(0x01)
+	var b encoder
+	body(&b)
+	e.byteString(b.bytes())
+}
+
+// diagnosticInfo encodes an empty diagnostic info.
+func (e *encoder) diagnosticInfo() {
+	e.uint8(0x00)
+}
+
+// variant encodes a scalar value of one of the types returned by
+// decoder.variant.
+func (e *encoder) variant(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.uint8(0)
+	case bool:
+		e.uint8(typeBoolean)
+		e.boolean(v)
+	case int8:
+		e.uint8(typeSByte)
+		e.uint8(uint8(v))
+	case uint8:
+		e.uint8(typeByte)
+		e.uint8(v)
+	case int16:
+		e.uint8(typeInt16)
+		e.uint16(uint16(v))
+	case uint16:
+		e.uint8(typeUInt16)
+		e.uint16(v)
+	case int32:
+		e.uint8(typeInt32)
+		e.int32(v)
+	case uint32:
+		e.uint8(typeUInt32)
+		e.uint32(v)
+	case int64:
+		e.uint8(typeInt64)
+		e.int64(v)
+	case uint64:
+		e.uint8(typeUInt64)
+		e.uint64(v)
+	case float32:
+		e.uint8(typeFloat)
+		e.uint32(math.Float32bits(v))
+	case float64:
+		e.uint8(typeDouble)
+		e.float64(v)
+	case string:
+		e.uint8(typeString)
+		e.int32(int32(len(v)))
+		e.buf = append(e.buf, v...)
+	case time.Time:
+		e.uint8(typeDateTime)
+		e.dateTime(v)
+	case []byte:
+		e.uint8(typeByteString)
+		e.byteString(v)
+	case statusCode:
+		e.uint8(typeStatusCode)
+		e.uint32(uint32(v))
+	default:
+		return fmt.Errorf("cannot encode %T as variant", v)
+	}
+	return nil
+}
+
+// decoder reads values in binary encoding from a buffer.  The first error
+// is kept and stops decoding; later reads return zero values.
+type decoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func newDecoder(b []byte) *decoder {
+	return &decoder{buf: b}
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.pos+n > len(d.buf) {
+		d.err = errShortBuffer
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) fail(format string, a ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, a...)
+	}
+}
+
+func (d *decoder) boolean() bool {
+	return d.uint8() != 0
+}
+
+func (d *decoder) uint8() uint8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (d *decoder) int32() int32 {
+	return int32(d.uint32())
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (d *decoder) int64() int64 {
+	return int64(d.uint64())
+}
+
+func (d *decoder) float64() float64 {
+	return math.Float64frombits(d.uint64())
+}
+
+// arrayLength reads the length of an array, or -1 for null arrays.
+func (d *decoder) arrayLength() int {
+	n := int(d.int32())
+	if n < -1 || n > len(d.buf)-d.pos {
+		// Each element takes at least one byte.
+		d.fail("invalid array length %d", n)
+		return -1
+	}
+	return n
+}
+
+func (d *decoder) byteString() []byte {
+	n := d.arrayLength()
+	if n < 0 {
+		return nil
+	}
+	b := d.next(n)
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+func (d *decoder) string() string {
+	return string(d.byteString())
+}
+
+func (d *decoder) strings() []string {
+	n := d.arrayLength()
+	if n < 0 {
+		return nil
+	}
+	v := make([]string, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		v = append(v, d.string())
+	}
+	return v
+}
+
+func (d *decoder) uint32s() []uint32 {
+	n := d.arrayLength()
+	if n < 0 {
+		return nil
+	}
+	v := make([]uint32, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		v = append(v, d.uint32())
+	}
+	return v
+}
+
+func (d *decoder) dateTime() time.Time {
+	return fromDateTime(d.int64())
+}
+
+func (d *decoder) nodeID() nodeID {
+	mask := d.uint8()
+	switch mask & 0x3f {
+	case 0x00:
+		return numericID(0, uint32(d.uint8()))
+	case 0x01:
+		ns := uint16(d.uint8())
+		return numericID(ns, uint32(d.uint16()))
+	case 0x02:
+		ns := d.uint16()
+		return numericID(ns, d.uint32())
+	case 0x03:
+		ns := d.uint16()
+		return nodeID{namespace: ns, kind: idString, text: d.string()}
+	case 0x04:
+		ns := d.uint16()
+		return nodeID{namespace: ns, kind: idGUID, text: string(d.next(16))}
+	case 0x05:
+		ns := d.uint16()
+		return nodeID{namespace: ns, kind: idOpaque, text: string(d.byteString())}
+	default:
+		d.fail("invalid node id encoding 0x%02x", mask)
+		return nodeID{}
+	}
+}
+
+// expandedNodeID reads an expanded node id, dropping the namespace uri and
+// server index.
+func (d *decoder) expandedNodeID() nodeID {
+	mask := d.buf[d.pos:]
+	if len(mask) == 0 {
+		d.err = errShortBuffer
+		return nodeID{}
+	}
+	flags := mask[0]
+	id := d.nodeID()
+	if flags&0x80 != 0 {
+		d.string()
+	}
+	if flags&0x40 != 0 {
+		d.uint32()
+	}
+	return id
+}
+
+func (d *decoder) qualifiedName() string {
+	d.uint16()
+	return d.string()
+}
+
+func (d *decoder) localizedText() string {
+	mask := d.uint8()
+	if mask&0x01 != 0 {
+		d.string()
+	}
+	if mask&0x02 != 0 {
+		return d.string()
+	}
+	return ""
+}
+
+// extensionObject reads an extension object and returns its type id and
+// binary body.
+func (d *decoder) extensionObject() (uint32, []byte) {
+	id := d.nodeID()
+	switch d.uint8() {
+	case 0x00:
+		return id.numeric, nil
+	case 0x01, 0x02:
+		return id.numeric, d.byteString()
+	default:
+		d.fail("invalid extension object encoding")
+		return 0, nil
+	}
+}
+
+func (d *decoder) diagnosticInfo() {
+	mask := d.uint8()
+	for _, bit := range []uint8{0x01, 0x02, 0x08, 0x04} {
+		if mask&bit != 0 {
+			d.int32()
+		}
+	}
+	if mask&0x10 != 0 {
+		d.string()
+	}
+	if mask&0x20 != 0 {
+		d.uint32()
+	}
+	if mask&0x40 != 0 {
+		d.diagnosticInfo()
+	}
+}
+
+func (d *decoder) diagnosticInfos() {
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		d.diagnosticInfo()
+	}
+}
+
+// Built-in type ids of variants
+const (
+	typeBoolean         = 1
+	typeSByte           = 2
+	typeByte            = 3
+	typeInt16           = 4
+	typeUInt16          = 5
+	typeInt32           = 6
+	typeUInt32          = 7
+	typeInt64           = 8
+	typeUInt64          = 9
+	typeFloat           = 10
+	typeDouble          = 11
+	typeString          = 12
+	typeDateTime        = 13
+	typeGUID            = 14
+	typeByteString      = 15
+	typeXMLElement      = 16
+	typeNodeID          = 17
+	typeExpandedNodeID  = 18
+	typeStatusCode      = 19
+	typeQualifiedName   = 20
+	typeLocalizedText   = 21
+	typeExtensionObject = 22
+	typeDataValue       = 23
+	typeVariant         = 24
+	typeDiagnosticInfo  = 25
+)
+
+// errArray is returned for variants holding arrays or matrices.
+var errArray = errors.New("array values are not supported")
+
+// variant reads a variant.  Scalars are returned as Go values; arrays are
+// skipped and returned as errArray.
+func (d *decoder) variant() (interface{}, error) {
+	mask := d.uint8()
+	typ := mask & 0x3f
+	if mask&0x80 == 0 {
+		v := d.scalar(typ)
+		return v, d.err
+	}
+
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		d.scalar(typ)
+	}
+	if mask&0x40 != 0 {
+		dims := d.arrayLength()
+		for i := 0; i < dims && d.err == nil; i++ {
+			d.int32()
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return nil, errArray
+}
+
+func (d *decoder) scalar(typ uint8) interface{} {
+	switch typ {
+	case 0:
+		return nil
+	case typeBoolean:
+		return d.boolean()
+	case typeSByte:
+		return int8(d.uint8())
+	case typeByte:
+		return d.uint8()
+	case typeInt16:
+		return int16(d.uint16())
+	case typeUInt16:
+		return d.uint16()
+	case typeInt32:
+		return d.int32()
+	case typeUInt32:
+		return d.uint32()
+	case typeInt64:
+		return d.int64()
+	case typeUInt64:
+		return d.uint64()
+	case typeFloat:
+		return math.Float32frombits(d.uint32())
+	case typeDouble:
+		return d.float64()
+	case typeString, typeXMLElement:
+		return d.string()
+	case typeDateTime:
+		return d.dateTime()
+	case typeGUID:
+		return formatGUID(d.next(16))
+	case typeByteString:
+		return d.byteString()
+	case typeNodeID:
+		return d.nodeID().String()
+	case typeExpandedNodeID:
+		return d.expandedNodeID().String()
+	case typeStatusCode:
+		return statusCode(d.uint32())
+	case typeQualifiedName:
+		return d.qualifiedName()
+	case typeLocalizedText:
+		return d.localizedText()
+	case typeExtensionObject:
+		d.extensionObject()
+		return nil
+	case typeDataValue:
+		v, _ := d.dataValue()
+		return v.value
+	case typeVariant:
+		v, _ := d.variant()
+		return v
+	case typeDiagnosticInfo:
+		d.diagnosticInfo()
+		return nil
+	default:
+		d.fail("invalid variant type %d", typ)
+		return nil
+	}
+}
+
+// dataValue is the value of a node with its status and timestamps.
+type dataValue struct {
+	value           interface{}
+	status          statusCode
+	sourceTimestamp time.Time
+	serverTimestamp time.Time
+}
+
+// dataValue reads a data value; an error decoding the value itself, such as
+// errArray, is returned separately from decoding errors.
+func (d *decoder) dataValue() (dataValue, error) {
+	var v dataValue
+	var err error
+	mask := d.uint8()
+	if mask&0x01 != 0 {
+		v.value, err = d.variant()
+	}
+	if mask&0x02 != 0 {
+		v.status = statusCode(d.uint32())
+	}
+	if mask&0x04 != 0 {
+		v.sourceTimestamp = d.dateTime()
+	}
+	if mask&0x10 != 0 {
+		d.uint16()
+	}
+	if mask&0x08 != 0 {
+		v.serverTimestamp = d.dateTime()
+	}
+	if mask&0x20 != 0 {
+		d.uint16()
+	}
+	if d.err != nil {
+		return v, d.err
+	}
+	return v, err
+}
+
+func (e *encoder) dataValue(v dataValue) error {
+	var mask uint8 = 0x01
+	if v.status != 0 {
+		mask |= 0x02
+	}
+	if !v.sourceTimestamp.IsZero() {
+		mask |= 0x04
+	}
+	if !v.serverTimestamp.IsZero() {
+		mask |= 0x08
+	}
+	e.uint8(mask)
+	if err := e.variant(v.value); err != nil {
+		return err
+	}
+	if mask&0x02 != 0 {
+		e.uint32(uint32(v.status))
+	}
+	if mask&0x04 != 0 {
+		e.dateTime(v.sourceTimestamp)
+	}
+	if mask&0x08 != 0 {
+		e.dateTime(v.serverTimestamp)
+	}
+	return nil
+}
+
+// epoch is the origin of DateTime values, counted in 100 nanosecond
+// intervals.
+var epoch = time.Date(1601, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const epochOffset = 116444736000000000 // 100ns intervals from epoch to 1970
+
+func toDateTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()/100 + epochOffset
+}
+
+func fromDateTime(v int64) time.Time {
+	if v <= 0 || v == math.MaxInt64 {
+		return time.Time{}
+	}
+	return time.Unix(0, (v-epochOffset)*100).UTC()
+}
+
+// Node id types
+const (
+	idNumeric = 'i'
+	idString  = 's'
+	idGUID    = 'g'
+	idOpaque  = 'b'
+)
+
+// nodeID identifies a node of the address space of a server.  The
+// identifier is numeric, or held in text: the string, the 16 bytes of a
+// GUID in binary encoding, or the bytes of an opaque identifier.
+type nodeID struct {
+	namespace uint16
+	kind      byte
+	numeric   uint32
+	text      string
+}
+
+func numericID(namespace uint16, id uint32) nodeID {
+	return nodeID{namespace: namespace, kind: idNumeric, numeric: id}
+}
+
+// newNodeID creates a node id from the namespace index, the identifier type,
+// one of "i", "s", "g" or "b", and the identifier.  GUIDs are given in their
+// usual string form, opaque identifiers base64 encoded.
+func newNodeID(namespace uint16, idType string, identifier string) (nodeID, error) {
+	switch idType {
+	case "i":
+		v, err := strconv.ParseUint(identifier, 10, 32)
+		if err != nil {
+			return nodeID{}, fmt.Errorf("invalid numeric identifier %q", identifier)
+		}
+		return numericID(namespace, uint32(v)), nil
+	case "s":
+		if identifier == "" {
+			return nodeID{}, fmt.Errorf("empty string identifier")
+		}
+		return nodeID{namespace: namespace, kind: idString, text: identifier}, nil
+	case "g":
+		b, err := parseGUID(identifier)
+		if err != nil {
+			return nodeID{}, err
+		}
+		return nodeID{namespace: namespace, kind: idGUID, text: string(b)}, nil
+	case "b":
+		b, err := base64.StdEncoding.DecodeString(identifier)
+		if err != nil || len(b) == 0 {
+			return nodeID{}, fmt.Errorf("invalid opaque identifier %q", identifier)
+		}
+		return nodeID{namespace: namespace, kind: idOpaque, text: string(b)}, nil
+	default:
+		return nodeID{}, fmt.Errorf("invalid identifier type %q", idType)
+	}
+}
+
+// String returns the node id in the notation of OPC UA Part 6, 5.3.1.10,
+// e.g. "ns=2;s=Temperature".
+func (id nodeID) String() string {
+	var v string
+	switch id.kind {
+	case idNumeric:
+		v = strconv.FormatUint(uint64(id.numeric), 10)
+	case idString:
+		v = id.text
+	case idGUID:
+		v = formatGUID([]byte(id.text))
+	case idOpaque:
+		v = base64.StdEncoding.EncodeToString([]byte(id.text))
+	}
+	if id.namespace == 0 {
+		return fmt.Sprintf("%c=%s", id.kind, v)
+	}
+	return fmt.Sprintf("ns=%d;%c=%s", id.namespace, id.kind, v)
+}
+
+// parseGUID converts a GUID of the form "72962B91-FA75-4AE6-8D28-B404DC7DAF63"
+// into its binary encoding.
+func parseGUID(s string) ([]byte, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return nil, fmt.Errorf("invalid guid %q", s)
+	}
+	raw, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid guid %q", s)
+	}
+
+	// Data1, Data2 and Data3 are little endian, Data4 is a byte array.
+	b := make([]byte, 16)
+	b[0], b[1], b[2], b[3] = raw[3], raw[2], raw[1], raw[0]
+	b[4], b[5] = raw[5], raw[4]
+	b[6], b[7] = raw[7], raw[6]
+	copy(b[8:], raw[8:])
+	return b, nil
+}
+
+func formatGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+		binary.LittleEndian.Uint32(b[0:]),
+		binary.LittleEndian.Uint16(b[4:]),
+		binary.LittleEndian.Uint16(b[6:]),
+		b[8:10], b[10:])
+}
+
+// statusCode is the result of a service or operation.
+type statusCode uint32
+
+const (
+	statusGood                      statusCode = 0x00000000
+	statusBadUnexpectedError        statusCode = 0x80010000
+	statusBadInternalError          statusCode = 0x80020000
+	statusBadCommunicationError     statusCode = 0x80050000
+	statusBadEncodingError          statusCode = 0x80060000
+	statusBadDecodingError          statusCode = 0x80070000
+	statusBadTimeout                statusCode = 0x800A0000
+	statusBadServiceUnsupported     statusCode = 0x800B0000
+	statusBadTooManyOperations      statusCode = 0x80100000
+	statusBadCertificateInvalid     statusCode = 0x80120000
+	statusBadSecurityChecksFailed   statusCode = 0x80130000
+	statusBadUserAccessDenied       statusCode = 0x801F0000
+	statusBadIdentityTokenInvalid   statusCode = 0x80200000
+	statusBadIdentityTokenRejected  statusCode = 0x80210000
+	statusBadSecureChannelIDInvalid statusCode = 0x80220000
+	statusBadSessionIDInvalid       statusCode = 0x80250000
+	statusBadSessionClosed          statusCode = 0x80260000
+	statusBadSessionNotActivated    statusCode = 0x80270000
+	statusBadSubscriptionIDInvalid  statusCode = 0x80280000
+	statusBadNodeIDInvalid          statusCode = 0x80330000
+	statusBadNodeIDUnknown          statusCode = 0x80340000
+	statusBadAttributeIDInvalid     statusCode = 0x80350000
+	statusBadNotReadable            statusCode = 0x803A0000
+	statusBadSecurityPolicyRejected statusCode = 0x80550000
+	statusBadNoSubscription         statusCode = 0x80790000
+	statusBadTCPMessageTypeInvalid  statusCode = 0x807E0000
+	statusBadTCPEndpointURLInvalid  statusCode = 0x80830000
+)
+
+var statusNames = map[statusCode]string{
+	statusGood:                      "Good",
+	statusBadUnexpectedError:        "BadUnexpectedError",
+	statusBadInternalError:          "BadInternalError",
+	statusBadCommunicationError:     "BadCommunicationError",
+	statusBadEncodingError:          "BadEncodingError",
+	statusBadDecodingError:          "BadDecodingError",
+	statusBadTimeout:                "BadTimeout",
+	statusBadServiceUnsupported:     "BadServiceUnsupported",
+	statusBadTooManyOperations:      "BadTooManyOperations",
+	statusBadCertificateInvalid:     "BadCertificateInvalid",
+	statusBadSecurityChecksFailed:   "BadSecurityChecksFailed",
+	statusBadUserAccessDenied:       "BadUserAccessDenied",
+	statusBadIdentityTokenInvalid:   "BadIdentityTokenInvalid",
+	statusBadIdentityTokenRejected:  "BadIdentityTokenRejected",
+	statusBadSecureChannelIDInvalid: "BadSecureChannelIdInvalid",
+	statusBadSessionIDInvalid:       "BadSessionIdInvalid",
+	statusBadSessionClosed:          "BadSessionClosed",
+	statusBadSessionNotActivated:    "BadSessionNotActivated",
+	statusBadSubscriptionIDInvalid:  "BadSubscriptionIdInvalid",
+	statusBadNodeIDInvalid:          "BadNodeIdInvalid",
+	statusBadNodeIDUnknown:          "BadNodeIdUnknown",
+	statusBadAttributeIDInvalid:     "BadAttributeIdInvalid",
+	statusBadNotReadable:            "BadNotReadable",
+	statusBadSecurityPolicyRejected: "BadSecurityPolicyRejected",
+	statusBadNoSubscription:         "BadNoSubscription",
+	statusBadTCPMessageTypeInvalid:  "BadTcpMessageTypeInvalid",
+	statusBadTCPEndpointURLInvalid:  "BadTcpEndpointUrlInvalid",
+}
+
+func (s statusCode) isBad() bool {
+	return s&0x80000000 != 0
+}
+
+func (s statusCode) Error() string {
+	if name, ok := statusNames[s]; ok {
+		return fmt.Sprintf("%s (0x%08X)", name, uint32(s))
+	}
+	return fmt.Sprintf("status 0x%08X", uint32(s))
+}
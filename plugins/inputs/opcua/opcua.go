@@ -0,0 +1,444 @@
+package opcua
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Metric name
+  # name = "opcua"
+
+  ## OPC UA endpoint URL
+  endpoint = "opc.tcp://localhost:4840"
+
+  ## Maximum time allowed to establish a connection to the endpoint.
+  # connect_timeout = "10s"
+
+  ## Maximum time allowed for a request over the established connection.
+  # request_timeout = "5s"
+
+  ## Security policy, one of "None", "Basic128Rsa15", "Basic256",
+  ## "Basic256Sha256", "Aes128_Sha256_RsaOaep", or "auto" to use the most
+  ## secure policy offered by the server.
+  # security_policy = "auto"
+
+  ## Security mode, one of "None", "Sign", "SignAndEncrypt", or "auto" to use
+  ## the most secure mode offered by the server.
+  # security_mode = "auto"
+
+  ## Path to the application certificate and its private key in PEM format.
+  ## If not set, a self-signed certificate is generated on startup.
+  # certificate = "/etc/telegraf/cert.pem"
+  # private_key = "/etc/telegraf/key.pem"
+
+  ## Trusted server certificates, or certificates of the CAs issuing them, in
+  ## PEM or DER format.  The server certificate, including its validity
+  ## period, is verified if it is used by the security policy or to encrypt
+  ## the password.
+  # server_certificates = ["/etc/telegraf/server.der"]
+
+  ## Use the server certificate without verifying it.
+  # insecure_skip_verify = false
+
+  ## Authentication method, one of "Anonymous", "UserName" or "Certificate".
+  ## Certificate authentication uses the application certificate.
+  # auth_method = "Anonymous"
+
+  ## Credentials of the "UserName" authentication method.
+  # username = ""
+  # password = ""
+
+  ## Send the password in plaintext if the endpoint encrypts it neither with
+  ## the user token policy nor with the "SignAndEncrypt" security mode.
+  # allow_plaintext_password = false
+
+  ## Nodes to read; each node is a field of the metric with the node name.
+  ##   name            - field name
+  ##   namespace       - namespace index of the node id
+  ##   identifier_type - "i" numeric, "s" string, "g" GUID or "b" opaque
+  ##                     (base64 encoded)
+  ##   identifier      - identifier of the node id
+  ##   tags            - tags added to the metric of the node, as list of
+  ##                     [key, value] pairs
+  nodes = [
+    {name="temperature", namespace=2, identifier_type="s", identifier="Boiler.Temperature"},
+    {name="pressure", namespace=2, identifier_type="i", identifier="1042", tags=[["line", "1"]]},
+  ]
+
+  ## Interval of a subscription publishing changed values of the nodes.  If
+  ## set, values are received as they change instead of being read each
+  ## interval.
+  # subscription_interval = "0s"
+
+  ## Time of the metrics, one of:
+  ##   gather - time of the read, or of the receipt of a notification
+  ##   source - source timestamp of the value
+  ##   server - server timestamp of the value
+  # timestamp = "gather"
+`
+
+// OpcUA reads the values of nodes of an OPC UA server.
+type OpcUA struct {
+	MetricName             string            `toml:"name"`
+	Endpoint               string            `toml:"endpoint"`
+	ConnectTimeout         internal.Duration `toml:"connect_timeout"`
+	RequestTimeout         internal.Duration `toml:"request_timeout"`
+	SecurityPolicy         string            `toml:"security_policy"`
+	SecurityMode           string            `toml:"security_mode"`
+	Certificate            string            `toml:"certificate"`
+	PrivateKey             string            `toml:"private_key"`
+	ServerCertificates     []string          `toml:"server_certificates"`
+	InsecureSkipVerify     bool              `toml:"insecure_skip_verify"`
+	AuthMethod             string            `toml:"auth_method"`
+	Username               string            `toml:"username"`
+	Password               string            `toml:"password"`
+	AllowPlaintextPassword bool              `toml:"allow_plaintext_password"`
+	Nodes                  []Node            `toml:"nodes"`
+	SubscriptionInterval   internal.Duration `toml:"subscription_interval"`
+	Timestamp              string            `toml:"timestamp"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	config  *clientConfig
+	nodeIDs []nodeID
+	client  *client
+
+	// Subscription
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Node is a node whose value is a field.
+type Node struct {
+	Name           string     `toml:"name"`
+	Namespace      uint16     `toml:"namespace"`
+	IdentifierType string     `toml:"identifier_type"`
+	Identifier     string     `toml:"identifier"`
+	Tags           [][]string `toml:"tags"`
+
+	tags map[string]string
+}
+
+func (o *OpcUA) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpcUA) Description() string {
+	return "Retrieve data from OPC UA servers"
+}
+
+func (o *OpcUA) Init() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+
+	if o.SecurityPolicy != "auto" && policyByName(o.SecurityPolicy) == nil {
+		return fmt.Errorf("invalid security policy %q", o.SecurityPolicy)
+	}
+	if _, ok := securityModes[o.SecurityMode]; !ok && o.SecurityMode != "auto" {
+		return fmt.Errorf("invalid security mode %q", o.SecurityMode)
+	}
+	if (o.SecurityPolicy == "None") != (o.SecurityMode == "None") && o.SecurityPolicy != "auto" && o.SecurityMode != "auto" {
+		return fmt.Errorf("security policy %q cannot be used with security mode %q", o.SecurityPolicy, o.SecurityMode)
+	}
+
+	if _, ok := authTokenTypes[o.AuthMethod]; !ok {
+		return fmt.Errorf("invalid authentication method %q", o.AuthMethod)
+	}
+	if o.AuthMethod == authUserName && o.Username == "" {
+		return fmt.Errorf("username is required by authentication method %q", o.AuthMethod)
+	}
+	if o.AuthMethod == authUserName && o.AllowPlaintextPassword {
+		o.Log.Warn("The password is sent in plaintext if the endpoint does not encrypt it")
+	}
+
+	switch o.Timestamp {
+	case "gather", "source", "server":
+	default:
+		return fmt.Errorf("invalid timestamp %q", o.Timestamp)
+	}
+
+	if len(o.Nodes) == 0 {
+		return fmt.Errorf("no nodes configured")
+	}
+	names := make(map[string]bool, len(o.Nodes))
+	o.nodeIDs = make([]nodeID, 0, len(o.Nodes))
+	for i := range o.Nodes {
+		node := &o.Nodes[i]
+		if node.Name == "" {
+			return fmt.Errorf("empty name of node %q", node.Identifier)
+		}
+		if names[node.Name] {
+			return fmt.Errorf("duplicate node name %q", node.Name)
+		}
+		names[node.Name] = true
+
+		id, err := newNodeID(node.Namespace, node.IdentifierType, node.Identifier)
+		if err != nil {
+			return fmt.Errorf("node %q: %v", node.Name, err)
+		}
+		o.nodeIDs = append(o.nodeIDs, id)
+
+		node.tags = map[string]string{"id": id.String()}
+		for _, tag := range node.Tags {
+			if len(tag) != 2 || tag[0] == "" {
+				return fmt.Errorf("node %q: tag %q is not a [key, value] pair", node.Name, tag)
+			}
+			node.tags[tag[0]] = tag[1]
+		}
+	}
+
+	var cert *certificate
+	var err error
+	switch {
+	case o.Certificate != "" || o.PrivateKey != "":
+		cert, err = loadCertificate(o.Certificate, o.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("loading certificate: %v", err)
+		}
+	case o.SecurityPolicy != "None" || o.AuthMethod == authCertificate:
+		cert, err = generateCertificate("urn:telegraf:opcua:"+internal.RandomString(8), 365*24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("generating certificate: %v", err)
+		}
+	}
+
+	trusted, err := loadCertPool(o.ServerCertificates)
+	if err != nil {
+		return fmt.Errorf("loading server certificates: %v", err)
+	}
+
+	o.config = &clientConfig{
+		endpoint:               o.Endpoint,
+		securityPolicy:         o.SecurityPolicy,
+		securityMode:           o.SecurityMode,
+		cert:                   cert,
+		trusted:                trusted,
+		insecureSkipVerify:     o.InsecureSkipVerify,
+		authMethod:             o.AuthMethod,
+		username:               o.Username,
+		password:               o.Password,
+		allowPlaintextPassword: o.AllowPlaintextPassword,
+		connectTimeout:         o.ConnectTimeout.Duration,
+		requestTimeout:         o.RequestTimeout.Duration,
+	}
+	return nil
+}
+
+func (o *OpcUA) Start(acc telegraf.Accumulator) error {
+	if o.SubscriptionInterval.Duration <= 0 {
+		return nil
+	}
+
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		o.subscribe(acc)
+	}()
+	return nil
+}
+
+func (o *OpcUA) Stop() {
+	if o.cancel != nil {
+		o.cancel()
+		o.mu.Lock()
+		if o.client != nil {
+			o.client.interrupt()
+		}
+		o.mu.Unlock()
+		o.wg.Wait()
+	}
+
+	if o.client != nil {
+		if err := o.client.close(); err != nil {
+			o.Log.Debugf("Closing session: %v", err)
+		}
+		o.client = nil
+	}
+}
+
+func (o *OpcUA) Gather(acc telegraf.Accumulator) error {
+	if o.SubscriptionInterval.Duration > 0 {
+		return nil
+	}
+
+	if o.client == nil {
+		c, err := connect(o.config)
+		if err != nil {
+			return fmt.Errorf("connecting to %q: %v", o.Endpoint, err)
+		}
+		o.client = c
+	}
+
+	values, errs, err := o.client.read(o.nodeIDs)
+	if err != nil {
+		// Reconnect with the next gather.
+		o.client.closeChannel()
+		o.client = nil
+		return fmt.Errorf("reading nodes: %v", err)
+	}
+
+	now := time.Now()
+	for i, value := range values {
+		o.addValue(acc, i, value, errs[i], now)
+	}
+	return nil
+}
+
+// subscribe receives the values of the nodes from a subscription until the
+// plugin is stopped, reconnecting on errors.
+func (o *OpcUA) subscribe(acc telegraf.Accumulator) {
+	for {
+		err := o.receive(acc)
+		if o.ctx.Err() != nil {
+			return
+		}
+		acc.AddError(err)
+
+		o.mu.Lock()
+		if o.client != nil {
+			o.client.closeChannel()
+			o.client = nil
+		}
+		o.mu.Unlock()
+
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-time.After(o.SubscriptionInterval.Duration):
+		}
+	}
+}
+
+// receive creates the subscription and adds the values of its notifications
+// until an error occurs or the plugin is stopped.
+func (o *OpcUA) receive(acc telegraf.Accumulator) error {
+	c, err := connect(o.config)
+	if err != nil {
+		return fmt.Errorf("connecting to %q: %v", o.Endpoint, err)
+	}
+	o.mu.Lock()
+	o.client = c
+	stopped := o.ctx.Err() != nil
+	o.mu.Unlock()
+	if stopped {
+		return nil
+	}
+
+	subscriptionID, err := c.subscribe(o.nodeIDs, o.SubscriptionInterval.Duration)
+	if err != nil {
+		return err
+	}
+
+	// Keep-alive messages are sent after ten publishing intervals without
+	// notifications.
+	timeout := 10*o.SubscriptionInterval.Duration + o.RequestTimeout.Duration
+	var ack uint32
+	for o.ctx.Err() == nil {
+		var notifications []notification
+		ack, notifications, err = c.publish(subscriptionID, ack, timeout)
+		if err != nil {
+			return fmt.Errorf("receiving notifications: %v", err)
+		}
+
+		now := time.Now()
+		for _, n := range notifications {
+			if int(n.handle) >= len(o.Nodes) {
+				acc.AddError(fmt.Errorf("notification of unknown monitored item %d", n.handle))
+				continue
+			}
+			o.addValue(acc, int(n.handle), n.value, n.err, now)
+		}
+	}
+	return nil
+}
+
+// addValue adds the metric of a value of the i-th node.
+func (o *OpcUA) addValue(acc telegraf.Accumulator, i int, value dataValue, err error, now time.Time) {
+	node := o.Nodes[i]
+	if err != nil {
+		acc.AddError(fmt.Errorf("value of node %q: %v", node.Name, err))
+		return
+	}
+	if value.status.isBad() {
+		acc.AddError(fmt.Errorf("status of node %q: %v", node.Name, value.status))
+		return
+	}
+
+	v := fieldValue(value.value)
+	if v == nil {
+		return
+	}
+
+	t := now
+	switch o.Timestamp {
+	case "source":
+		if !value.sourceTimestamp.IsZero() {
+			t = value.sourceTimestamp
+		} else if !value.serverTimestamp.IsZero() {
+			t = value.serverTimestamp
+		}
+	case "server":
+		if !value.serverTimestamp.IsZero() {
+			t = value.serverTimestamp
+		}
+	}
+
+	acc.AddFields(o.MetricName, map[string]interface{}{node.Name: v}, node.tags, t)
+}
+
+// fieldValue converts the value of a variant to a field value; values of
+// types without field representation return nil.
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool, string, int64, uint64, float64:
+		return v
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case statusCode:
+		return uint64(v)
+	default:
+		return nil
+	}
+}
+
+func init() {
+	inputs.Add("opcua", func() telegraf.Input {
+		return &OpcUA{
+			MetricName:     "opcua",
+			ConnectTimeout: internal.Duration{Duration: 10 * time.Second},
+			RequestTimeout: internal.Duration{Duration: 5 * time.Second},
+			SecurityPolicy: "auto",
+			SecurityMode:   "auto",
+			AuthMethod:     authAnonymous,
+			Timestamp:      "gather",
+		}
+	})
+}
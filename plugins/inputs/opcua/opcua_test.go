@@ -0,0 +1,672 @@
+package opcua
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	temperature = nodeID{namespace: 2, kind: idString, text: "Boiler.Temperature"}
+	pressure    = numericID(2, 1042)
+	running     = numericID(3, 7)
+	serial      = nodeID{namespace: 2, kind: idString, text: "Boiler.Serial"}
+	broken      = nodeID{namespace: 2, kind: idString, text: "Boiler.Broken"}
+)
+
+var sourceTime = time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func setValues(s *testServer) {
+	s.setValue(temperature, dataValue{value: float64(83.5), sourceTimestamp: sourceTime, serverTimestamp: sourceTime.Add(time.Second)})
+	s.setValue(pressure, dataValue{value: int32(-12)})
+	s.setValue(running, dataValue{value: true})
+	s.setValue(serial, dataValue{value: "SN-" + strings.Repeat("0", 100000)})
+	s.setValue(broken, dataValue{value: uint16(0), status: statusBadNotReadable})
+}
+
+func newPlugin(endpoint string) *OpcUA {
+	o := inputs.Inputs["opcua"]().(*OpcUA)
+	o.Endpoint = endpoint
+	o.Log = testutil.Logger{}
+	o.Nodes = []Node{
+		{Name: "temperature", Namespace: 2, IdentifierType: "s", Identifier: "Boiler.Temperature"},
+		{Name: "pressure", Namespace: 2, IdentifierType: "i", Identifier: "1042", Tags: [][]string{{"line", "1"}}},
+		{Name: "running", Namespace: 3, IdentifierType: "i", Identifier: "7"},
+		{Name: "serial", Namespace: 2, IdentifierType: "s", Identifier: "Boiler.Serial"},
+	}
+	return o
+}
+
+func expectedMetrics(now time.Time) []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric("opcua",
+			map[string]string{"id": "ns=2;s=Boiler.Temperature"},
+			map[string]interface{}{"temperature": float64(83.5)},
+			now),
+		testutil.MustMetric("opcua",
+			map[string]string{"id": "ns=2;i=1042", "line": "1"},
+			map[string]interface{}{"pressure": int64(-12)},
+			now),
+		testutil.MustMetric("opcua",
+			map[string]string{"id": "ns=3;i=7"},
+			map[string]interface{}{"running": true},
+			now),
+		testutil.MustMetric("opcua",
+			map[string]string{"id": "ns=2;s=Boiler.Serial"},
+			map[string]interface{}{"serial": "SN-" + strings.Repeat("0", 100000)},
+			now),
+	}
+}
+
+// writeCertificate writes the certificate and key in PEM format and returns
+// the file names.
+func writeCertificate(t *testing.T, dir string, cert *certificate) (string, string) {
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(cert.key)}), 0600))
+	return certFile, keyFile
+}
+
+func TestGather(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyNone, modeNone})
+	defer s.Close()
+	setValues(s)
+
+	o := newPlugin(s.url)
+	o.SecurityPolicy = "None"
+	o.Nodes = append(o.Nodes,
+		Node{Name: "broken", Namespace: 2, IdentifierType: "s", Identifier: "Boiler.Broken"},
+		Node{Name: "unknown", Namespace: 2, IdentifierType: "s", Identifier: "Boiler.Unknown"},
+	)
+	require.NoError(t, o.Init())
+	defer o.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, o.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expectedMetrics(time.Unix(0, 0)), acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	require.Len(t, acc.Errors, 2)
+	require.Contains(t, acc.Errors[0].Error(), `status of node "broken": BadNotReadable`)
+	require.Contains(t, acc.Errors[1].Error(), `status of node "unknown": BadNodeIdUnknown`)
+
+	// The session is kept between gathers.
+	acc.ClearMetrics()
+	require.NoError(t, o.Gather(&acc))
+	require.Equal(t, uint64(4), acc.NMetrics())
+	require.Equal(t, 2, s.sessions) // discovery and session channels
+}
+
+func TestGatherSecure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opcua")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	clientCert, err := generateCertificate("urn:telegraf:test:client", time.Hour)
+	require.NoError(t, err)
+	certFile, keyFile := writeCertificate(t, dir, clientCert)
+
+	tests := []struct {
+		name       string
+		policy     string
+		mode       string
+		authMethod string
+		endpoints  []testEndpoint
+		expected   testEndpoint
+	}{
+		{
+			name:       "Basic256Sha256 encrypted with username",
+			policy:     "Basic256Sha256",
+			mode:       "SignAndEncrypt",
+			authMethod: "UserName",
+			endpoints:  []testEndpoint{{policyBasic256Sha256, modeSignAndEncrypt}},
+			expected:   testEndpoint{policyBasic256Sha256, modeSignAndEncrypt},
+		},
+		{
+			name:       "Basic256 signed with certificate",
+			policy:     "Basic256",
+			mode:       "Sign",
+			authMethod: "Certificate",
+			endpoints:  []testEndpoint{{policyBasic256, modeSign}, {policyBasic256, modeSignAndEncrypt}},
+			expected:   testEndpoint{policyBasic256, modeSign},
+		},
+		{
+			name:       "Basic128Rsa15 encrypted",
+			policy:     "Basic128Rsa15",
+			mode:       "SignAndEncrypt",
+			authMethod: "Anonymous",
+			endpoints:  []testEndpoint{{policyBasic128Rsa15, modeSignAndEncrypt}},
+			expected:   testEndpoint{policyBasic128Rsa15, modeSignAndEncrypt},
+		},
+		{
+			name:       "Aes128_Sha256_RsaOaep encrypted",
+			policy:     "Aes128_Sha256_RsaOaep",
+			mode:       "SignAndEncrypt",
+			authMethod: "Anonymous",
+			endpoints:  []testEndpoint{{policyAes128Sha256RsaOaep, modeSignAndEncrypt}},
+			expected:   testEndpoint{policyAes128Sha256RsaOaep, modeSignAndEncrypt},
+		},
+		{
+			name:       "None with username",
+			policy:     "None",
+			mode:       "None",
+			authMethod: "UserName",
+			endpoints:  []testEndpoint{{policyNone, modeNone}},
+			expected:   testEndpoint{policyNone, modeNone},
+		},
+		{
+			name:       "auto",
+			policy:     "auto",
+			mode:       "auto",
+			authMethod: "Anonymous",
+			endpoints: []testEndpoint{
+				{policyNone, modeNone},
+				{policyBasic128Rsa15, modeSignAndEncrypt},
+				{policyBasic256Sha256, modeSign},
+				{policyBasic256Sha256, modeSignAndEncrypt},
+				{policyBasic256, modeSignAndEncrypt},
+			},
+			expected: testEndpoint{policyBasic256Sha256, modeSignAndEncrypt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.endpoints...)
+			defer s.Close()
+			setValues(s)
+			s.anonymous = tt.authMethod == "Anonymous"
+			s.users["operator"] = "secret"
+			s.userCerts = [][]byte{clientCert.der}
+
+			o := newPlugin(s.url)
+			o.SecurityPolicy = tt.policy
+			o.SecurityMode = tt.mode
+			o.AuthMethod = tt.authMethod
+			o.Username = "operator"
+			o.Password = "secret"
+			o.Certificate = certFile
+			o.PrivateKey = keyFile
+			o.ServerCertificates = []string{s.certFile}
+			require.NoError(t, o.Init())
+			defer o.Stop()
+
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(o.Gather))
+			testutil.RequireMetricsEqual(t, expectedMetrics(time.Unix(0, 0)), acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+			require.Equal(t, tt.expected.policy, o.client.channel.policy)
+			require.Equal(t, tt.expected.mode, o.client.channel.mode)
+		})
+	}
+}
+
+func TestGatherRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		mode     string
+		username string
+		password string
+		expected string
+	}{
+		{
+			name:     "wrong password",
+			policy:   "auto",
+			mode:     "auto",
+			username: "operator",
+			password: "wrong",
+			expected: "BadUserAccessDenied",
+		},
+		{
+			name:     "unknown user",
+			policy:   "auto",
+			mode:     "auto",
+			username: "nobody",
+			password: "secret",
+			expected: "BadUserAccessDenied",
+		},
+		{
+			name:     "security mode not offered",
+			policy:   "Basic256Sha256",
+			mode:     "Sign",
+			username: "operator",
+			password: "secret",
+			expected: `no endpoint with security policy "Basic256Sha256", security mode "Sign"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, testEndpoint{policyBasic256Sha256, modeSignAndEncrypt})
+			defer s.Close()
+			setValues(s)
+			s.anonymous = false
+			s.users["operator"] = "secret"
+
+			o := newPlugin(s.url)
+			o.SecurityPolicy = tt.policy
+			o.SecurityMode = tt.mode
+			o.AuthMethod = "UserName"
+			o.Username = tt.username
+			o.Password = tt.password
+			o.ServerCertificates = []string{s.certFile}
+			require.NoError(t, o.Init())
+			defer o.Stop()
+
+			var acc testutil.Accumulator
+			err := o.Gather(&acc)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+			require.Equal(t, uint64(0), acc.NMetrics())
+		})
+	}
+}
+
+func TestDowngradedEndpoints(t *testing.T) {
+	s := newTestServer(t,
+		testEndpoint{policyNone, modeNone},
+		testEndpoint{policyBasic256Sha256, modeSignAndEncrypt},
+	)
+	defer s.Close()
+	setValues(s)
+	s.discovered = []testEndpoint{{policyNone, modeNone}}
+
+	o := newPlugin(s.url)
+	o.ServerCertificates = []string{s.certFile}
+	require.NoError(t, o.Init())
+	defer o.Stop()
+
+	var acc testutil.Accumulator
+	err := o.Gather(&acc)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "endpoints of the session differ from the discovered endpoints")
+	require.Equal(t, uint64(0), acc.NMetrics())
+}
+
+func TestPlaintextPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint testEndpoint
+		allow    bool
+		expected string
+	}{
+		{
+			name:     "unsecured channel",
+			endpoint: testEndpoint{policyNone, modeNone},
+			expected: "the endpoint does not encrypt the password",
+		},
+		{
+			name:     "signed channel",
+			endpoint: testEndpoint{policyBasic256Sha256, modeSign},
+			expected: "the endpoint does not encrypt the password",
+		},
+		{
+			name:     "allowed",
+			endpoint: testEndpoint{policyNone, modeNone},
+			allow:    true,
+		},
+		{
+			name:     "encrypted channel",
+			endpoint: testEndpoint{policyBasic256Sha256, modeSignAndEncrypt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.endpoint)
+			defer s.Close()
+			setValues(s)
+			s.anonymous = false
+			s.plaintextPassword = true
+			s.users["operator"] = "secret"
+
+			o := newPlugin(s.url)
+			o.AuthMethod = "UserName"
+			o.Username = "operator"
+			o.Password = "secret"
+			o.AllowPlaintextPassword = tt.allow
+			o.ServerCertificates = []string{s.certFile}
+			require.NoError(t, o.Init())
+			defer o.Stop()
+
+			var acc testutil.Accumulator
+			err := o.Gather(&acc)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				require.Equal(t, uint64(4), acc.NMetrics())
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+			require.Equal(t, uint64(0), acc.NMetrics())
+		})
+	}
+}
+
+func TestServerCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opcua")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	expired, err := generateCertificate("urn:telegraf:test:server", -time.Minute)
+	require.NoError(t, err)
+	expiredFile, _ := writeCertificate(t, dir, expired)
+
+	tests := []struct {
+		name     string
+		modify   func(o *OpcUA, s *testServer)
+		expected string
+	}{
+		{
+			name:     "untrusted",
+			modify:   func(o *OpcUA, s *testServer) {},
+			expected: "verifying server certificate: x509: certificate signed by unknown authority",
+		},
+		{
+			name: "untrusted for password encryption",
+			modify: func(o *OpcUA, s *testServer) {
+				s.endpoints = []testEndpoint{{policyNone, modeNone}}
+				o.AuthMethod = "UserName"
+				o.Username = "operator"
+				o.Password = "secret"
+			},
+			expected: "verifying server certificate: x509: certificate signed by unknown authority",
+		},
+		{
+			name: "expired",
+			modify: func(o *OpcUA, s *testServer) {
+				s.cert = expired
+				o.ServerCertificates = []string{expiredFile}
+			},
+			expected: "verifying server certificate: x509: certificate has expired or is not yet valid",
+		},
+		{
+			name: "other certificate",
+			modify: func(o *OpcUA, s *testServer) {
+				o.ServerCertificates = []string{expiredFile}
+			},
+			expected: "verifying server certificate: x509: certificate signed by unknown authority",
+		},
+		{
+			name: "trusted",
+			modify: func(o *OpcUA, s *testServer) {
+				o.ServerCertificates = []string{expiredFile, s.certFile}
+			},
+		},
+		{
+			name: "not verified",
+			modify: func(o *OpcUA, s *testServer) {
+				s.cert = expired
+				o.InsecureSkipVerify = true
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, testEndpoint{policyBasic256Sha256, modeSignAndEncrypt})
+			defer s.Close()
+			setValues(s)
+			s.users["operator"] = "secret"
+
+			o := newPlugin(s.url)
+			tt.modify(o, s)
+			require.NoError(t, o.Init())
+			defer o.Stop()
+
+			var acc testutil.Accumulator
+			err := o.Gather(&acc)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				require.Equal(t, uint64(4), acc.NMetrics())
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+			require.Equal(t, uint64(0), acc.NMetrics())
+		})
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyNone, modeNone})
+	defer s.Close()
+	setValues(s)
+
+	tests := []struct {
+		timestamp string
+		expected  time.Time
+	}{
+		{timestamp: "source", expected: sourceTime},
+		{timestamp: "server", expected: sourceTime.Add(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timestamp, func(t *testing.T) {
+			o := newPlugin(s.url)
+			o.Nodes = o.Nodes[:1]
+			o.Timestamp = tt.timestamp
+			require.NoError(t, o.Init())
+			defer o.Stop()
+
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(o.Gather))
+			testutil.RequireMetricsEqual(t, expectedMetrics(tt.expected)[:1], acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestTokenRenewal(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyBasic256Sha256, modeSignAndEncrypt})
+	defer s.Close()
+	setValues(s)
+	s.lifetime = 200 * time.Millisecond
+
+	o := newPlugin(s.url)
+	o.ServerCertificates = []string{s.certFile}
+	require.NoError(t, o.Init())
+	defer o.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(o.Gather))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, acc.GatherError(o.Gather))
+	require.Equal(t, uint64(8), acc.NMetrics())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Equal(t, 1, s.renewals)
+}
+
+func TestReconnect(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyNone, modeNone})
+	setValues(s)
+
+	o := newPlugin(s.url)
+	require.NoError(t, o.Init())
+	defer o.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(o.Gather))
+
+	// Restart the server on the same address.
+	s.Close()
+	require.Error(t, o.Gather(&acc))
+	require.Error(t, o.Gather(&acc))
+
+	restarted := newTestServerAt(t, strings.TrimPrefix(s.url, "opc.tcp://"), testEndpoint{policyNone, modeNone})
+	defer restarted.Close()
+	setValues(restarted)
+
+	acc.ClearMetrics()
+	require.NoError(t, o.Gather(&acc))
+	require.Equal(t, uint64(4), acc.NMetrics())
+}
+
+func TestSubscription(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyBasic256Sha256, modeSignAndEncrypt})
+	defer s.Close()
+	setValues(s)
+
+	o := newPlugin(s.url)
+	o.ServerCertificates = []string{s.certFile}
+	o.SubscriptionInterval.Duration = 50 * time.Millisecond
+	require.NoError(t, o.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, o.Start(&acc))
+	require.NoError(t, o.Gather(&acc))
+	acc.Wait(8)
+	o.Stop()
+
+	require.Empty(t, acc.Errors)
+	metrics := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expectedMetrics(time.Unix(0, 0)), metrics[:4], testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestSubscriptionUnknownNode(t *testing.T) {
+	s := newTestServer(t, testEndpoint{policyNone, modeNone})
+	defer s.Close()
+	setValues(s)
+
+	o := newPlugin(s.url)
+	o.Nodes = append(o.Nodes, Node{Name: "unknown", Namespace: 2, IdentifierType: "s", Identifier: "Boiler.Unknown"})
+	o.SubscriptionInterval.Duration = 50 * time.Millisecond
+	require.NoError(t, o.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, o.Start(&acc))
+	acc.WaitError(1)
+	o.Stop()
+
+	require.Contains(t, acc.Errors[0].Error(), `creating monitored item for node ns=2;s=Boiler.Unknown: BadNodeIdUnknown`)
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(o *OpcUA)
+		expected string
+	}{
+		{
+			name:     "no endpoint",
+			modify:   func(o *OpcUA) { o.Endpoint = "" },
+			expected: "endpoint is required",
+		},
+		{
+			name:     "invalid security policy",
+			modify:   func(o *OpcUA) { o.SecurityPolicy = "Basic512" },
+			expected: `invalid security policy "Basic512"`,
+		},
+		{
+			name:     "invalid security mode",
+			modify:   func(o *OpcUA) { o.SecurityMode = "Encrypt" },
+			expected: `invalid security mode "Encrypt"`,
+		},
+		{
+			name: "secure policy without security mode",
+			modify: func(o *OpcUA) {
+				o.SecurityPolicy = "Basic256Sha256"
+				o.SecurityMode = "None"
+			},
+			expected: `security policy "Basic256Sha256" cannot be used with security mode "None"`,
+		},
+		{
+			name:     "invalid authentication method",
+			modify:   func(o *OpcUA) { o.AuthMethod = "Token" },
+			expected: `invalid authentication method "Token"`,
+		},
+		{
+			name:     "missing username",
+			modify:   func(o *OpcUA) { o.AuthMethod = "UserName" },
+			expected: `username is required`,
+		},
+		{
+			name:     "invalid timestamp",
+			modify:   func(o *OpcUA) { o.Timestamp = "now" },
+			expected: `invalid timestamp "now"`,
+		},
+		{
+			name:     "no nodes",
+			modify:   func(o *OpcUA) { o.Nodes = nil },
+			expected: "no nodes configured",
+		},
+		{
+			name:     "duplicate node name",
+			modify:   func(o *OpcUA) { o.Nodes[1].Name = "temperature" },
+			expected: `duplicate node name "temperature"`,
+		},
+		{
+			name:     "invalid tag",
+			modify:   func(o *OpcUA) { o.Nodes[1].Tags = [][]string{{"line"}} },
+			expected: `node "pressure": tag ["line"] is not a [key, value] pair`,
+		},
+		{
+			name:     "invalid identifier type",
+			modify:   func(o *OpcUA) { o.Nodes[0].IdentifierType = "x" },
+			expected: `node "temperature": invalid identifier type "x"`,
+		},
+		{
+			name:     "invalid numeric identifier",
+			modify:   func(o *OpcUA) { o.Nodes[1].Identifier = "Boiler.Pressure" },
+			expected: `node "pressure": invalid numeric identifier "Boiler.Pressure"`,
+		},
+		{
+			name:     "missing certificate",
+			modify:   func(o *OpcUA) { o.Certificate = "/nonexistent/cert.pem" },
+			expected: "loading certificate",
+		},
+		{
+			name:     "missing server certificate",
+			modify:   func(o *OpcUA) { o.ServerCertificates = []string{"/nonexistent/server.der"} },
+			expected: "loading server certificates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newPlugin("opc.tcp://localhost:4840")
+			o.SecurityPolicy = "None"
+			tt.modify(o)
+			err := o.Init()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	tests := []struct {
+		namespace  uint16
+		idType     string
+		identifier string
+		expected   string
+	}{
+		{0, "i", "2258", "i=2258"},
+		{2, "i", "70000", "ns=2;i=70000"},
+		{300, "i", "1", "ns=300;i=1"},
+		{2, "s", "Boiler.Temperature", "ns=2;s=Boiler.Temperature"},
+		{1, "g", "72962B91-FA75-4AE6-8D28-B404DC7DAF63", "ns=1;g=72962B91-FA75-4AE6-8D28-B404DC7DAF63"},
+		{1, "b", "M/RbKBsRVkePCePcx24oRA==", "ns=1;b=M/RbKBsRVkePCePcx24oRA=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			id, err := newNodeID(tt.namespace, tt.idType, tt.identifier)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, id.String())
+
+			// Encoding round trip
+			var e encoder
+			e.nodeID(id)
+			d := newDecoder(e.bytes())
+			require.Equal(t, id, d.nodeID())
+			require.NoError(t, d.err)
+			require.Equal(t, len(e.bytes()), d.pos)
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package opcua
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"os"
+	"time"
+
+	// Hash functions of the security policies
+	_ "crypto/sha256"
+)
+
+// Message security modes
+const (
+	modeInvalid        uint32 = 0
+	modeNone           uint32 = 1
+	modeSign           uint32 = 2
+	modeSignAndEncrypt uint32 = 3
+)
+
+var securityModes = map[string]uint32{
+	"None":           modeNone,
+	"Sign":           modeSign,
+	"SignAndEncrypt": modeSignAndEncrypt,
+}
+
+const (
+	uriRSASHA1   = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	uriRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	uriRSAOAEP   = "http://www.w3.org/2001/04/xmlenc#rsa-oaep"
+	uriRSA15     = "http://www.w3.org/2001/04/xmlenc#rsa-1_5"
+)
+
+// securityPolicy holds the algorithms of a security policy, see OPC UA Part 7.
+type securityPolicy struct {
+	name string
+	uri  string
+
+	// HMAC and key derivation
+	symmetricHash crypto.Hash
+	// RSA PKCS #1 v1.5 signatures
+	asymmetricHash crypto.Hash
+	// RSA-OAEP with SHA1, or RSA PKCS #1 v1.5 encryption
+	oaep bool
+
+	signingKeyLength    int
+	encryptingKeyLength int
+	nonceLength         int
+
+	signatureURI  string
+	encryptionURI string
+}
+
+var (
+	policyNone = &securityPolicy{
+		name: "None",
+		uri:  "http://opcfoundation.org/UA/SecurityPolicy#None",
+	}
+	policyBasic128Rsa15 = &securityPolicy{
+		name:                "Basic128Rsa15",
+		uri:                 "http://opcfoundation.org/UA/SecurityPolicy#Basic128Rsa15",
+		symmetricHash:       crypto.SHA1,
+		asymmetricHash:      crypto.SHA1,
+		oaep:                false,
+		signingKeyLength:    16,
+		encryptingKeyLength: 16,
+		nonceLength:         16,
+		signatureURI:        uriRSASHA1,
+		encryptionURI:       uriRSA15,
+	}
+	policyBasic256 = &securityPolicy{
+		name:                "Basic256",
+		uri:                 "http://opcfoundation.org/UA/SecurityPolicy#Basic256",
+		symmetricHash:       crypto.SHA1,
+		asymmetricHash:      crypto.SHA1,
+		oaep:                true,
+		signingKeyLength:    24,
+		encryptingKeyLength: 32,
+		nonceLength:         32,
+		signatureURI:        uriRSASHA1,
+		encryptionURI:       uriRSAOAEP,
+	}
+	policyBasic256Sha256 = &securityPolicy{
+		name:                "Basic256Sha256",
+		uri:                 "http://opcfoundation.org/UA/SecurityPolicy#Basic256Sha256",
+		symmetricHash:       crypto.SHA256,
+		asymmetricHash:      crypto.SHA256,
+		oaep:                true,
+		signingKeyLength:    32,
+		encryptingKeyLength: 32,
+		nonceLength:         32,
+		signatureURI:        uriRSASHA256,
+		encryptionURI:       uriRSAOAEP,
+	}
+	policyAes128Sha256RsaOaep = &securityPolicy{
+		name:                "Aes128_Sha256_RsaOaep",
+		uri:                 "http://opcfoundation.org/UA/SecurityPolicy#Aes128_Sha256_RsaOaep",
+		symmetricHash:       crypto.SHA256,
+		asymmetricHash:      crypto.SHA256,
+		oaep:                true,
+		signingKeyLength:    32,
+		encryptingKeyLength: 16,
+		nonceLength:         32,
+		signatureURI:        uriRSASHA256,
+		encryptionURI:       uriRSAOAEP,
+	}
+)
+
+// securityPolicies are the supported policies, from the least to the most
+// preferred one.
+var securityPolicies = []*securityPolicy{
+	policyNone,
+	policyBasic128Rsa15,
+	policyBasic256,
+	policyAes128Sha256RsaOaep,
+	policyBasic256Sha256,
+}
+
+func policyByName(name string) *securityPolicy {
+	for _, p := range securityPolicies {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func policyByURI(uri string) *securityPolicy {
+	for _, p := range securityPolicies {
+		if p.uri == uri {
+			return p
+		}
+	}
+	return nil
+}
+
+func (p *securityPolicy) secure() bool {
+	return p != policyNone
+}
+
+// symmetricKeys are the keys derived from the nonces of a security token.
+type symmetricKeys struct {
+	signing    []byte
+	encrypting []byte
+	iv         []byte
+}
+
+// deriveKeys derives the keys used by the sender of the seed, see OPC UA
+// Part 6, 6.7.5.
+func (p *securityPolicy) deriveKeys(secret, seed []byte) *symmetricKeys {
+	if !p.secure() {
+		return &symmetricKeys{}
+	}
+	b := pHash(p.symmetricHash, secret, seed, p.signingKeyLength+p.encryptingKeyLength+aes.BlockSize)
+	return &symmetricKeys{
+		signing:    b[:p.signingKeyLength],
+		encrypting: b[p.signingKeyLength : p.signingKeyLength+p.encryptingKeyLength],
+		iv:         b[p.signingKeyLength+p.encryptingKeyLength:],
+	}
+}
+
+// pHash is the P_hash function of RFC 5246, 5.
+func pHash(h crypto.Hash, secret, seed []byte, length int) []byte {
+	var out []byte
+	a := seed
+	for len(out) < length {
+		mac := hmac.New(h.New, secret)
+		mac.Write(a)
+		a = mac.Sum(nil)
+
+		mac = hmac.New(h.New, secret)
+		mac.Write(a)
+		mac.Write(seed)
+		out = mac.Sum(out)
+	}
+	return out[:length]
+}
+
+func (p *securityPolicy) symmetricSignatureSize() int {
+	if !p.secure() {
+		return 0
+	}
+	return p.symmetricHash.Size()
+}
+
+func (p *securityPolicy) symmetricSign(keys *symmetricKeys, data []byte) []byte {
+	mac := hmac.New(p.symmetricHash.New, keys.signing)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func (p *securityPolicy) symmetricVerify(keys *symmetricKeys, data, signature []byte) error {
+	if !hmac.Equal(p.symmetricSign(keys, data), signature) {
+		return errors.New("invalid message signature")
+	}
+	return nil
+}
+
+func (p *securityPolicy) symmetricEncrypt(keys *symmetricKeys, b []byte) error {
+	block, err := aes.NewCipher(keys.encrypting)
+	if err != nil {
+		return err
+	}
+	if len(b)%aes.BlockSize != 0 {
+		return errors.New("message is not a multiple of the block size")
+	}
+	cipher.NewCBCEncrypter(block, keys.iv).CryptBlocks(b, b)
+	return nil
+}
+
+func (p *securityPolicy) symmetricDecrypt(keys *symmetricKeys, b []byte) error {
+	block, err := aes.NewCipher(keys.encrypting)
+	if err != nil {
+		return err
+	}
+	if len(b)%aes.BlockSize != 0 {
+		return errors.New("message is not a multiple of the block size")
+	}
+	cipher.NewCBCDecrypter(block, keys.iv).CryptBlocks(b, b)
+	return nil
+}
+
+func (p *securityPolicy) asymmetricSign(key *rsa.PrivateKey, data []byte) ([]byte, error) {
+	h := p.asymmetricHash.New()
+	h.Write(data)
+	return rsa.SignPKCS1v15(rand.Reader, key, p.asymmetricHash, h.Sum(nil))
+}
+
+func (p *securityPolicy) asymmetricVerify(key *rsa.PublicKey, data, signature []byte) error {
+	h := p.asymmetricHash.New()
+	h.Write(data)
+	return rsa.VerifyPKCS1v15(key, p.asymmetricHash, h.Sum(nil), signature)
+}
+
+// asymmetricPlainBlockSize returns the size of the plaintext encrypted into a
+// single block of the key size.
+func (p *securityPolicy) asymmetricPlainBlockSize(key *rsa.PublicKey) int {
+	if p.oaep {
+		return key.Size() - 2*sha1.Size - 2
+	}
+	return key.Size() - 11
+}
+
+func (p *securityPolicy) asymmetricEncrypt(key *rsa.PublicKey, plain []byte) ([]byte, error) {
+	size := p.asymmetricPlainBlockSize(key)
+	var out []byte
+	for len(plain) > 0 {
+		n := size
+		if n > len(plain) {
+			n = len(plain)
+		}
+
+		var block []byte
+		var err error
+		if p.oaep {
+			block, err = rsa.EncryptOAEP(sha1.New(), rand.Reader, key, plain[:n], nil)
+		} else {
+			block, err = rsa.EncryptPKCS1v15(rand.Reader, key, plain[:n])
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, block...)
+		plain = plain[n:]
+	}
+	return out, nil
+}
+
+func (p *securityPolicy) asymmetricDecrypt(key *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	size := key.Size()
+	if len(ciphertext)%size != 0 {
+		return nil, errors.New("message is not a multiple of the key size")
+	}
+
+	var out []byte
+	for ; len(ciphertext) > 0; ciphertext = ciphertext[size:] {
+		var block []byte
+		var err error
+		if p.oaep {
+			block, err = rsa.DecryptOAEP(sha1.New(), rand.Reader, key, ciphertext[:size], nil)
+		} else {
+			block, err = rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext[:size])
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, block...)
+	}
+	return out, nil
+}
+
+func (p *securityPolicy) nonce() ([]byte, error) {
+	n := p.nonceLength
+	if n == 0 {
+		n = 32
+	}
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// padding returns the padding of a message of plainLen bytes followed by a
+// signature of sigLen bytes, to fill the plaintext blocks of blockSize bytes.
+// The extra padding byte is used by asymmetric encryption with keys larger
+// than 2048 bits.
+func padding(plainLen, sigLen, blockSize int, extra bool) []byte {
+	overhead := 1
+	if extra {
+		overhead = 2
+	}
+	n := (blockSize - (plainLen+sigLen+overhead)%blockSize) % blockSize
+	p := make([]byte, n+overhead)
+	for i := 0; i <= n; i++ {
+		p[i] = byte(n)
+	}
+	if extra {
+		p[n+1] = byte(n >> 8)
+	}
+	return p
+}
+
+// unpad returns the length of the message followed by padding in b.
+func unpad(b []byte, extra bool) (int, error) {
+	end := len(b)
+	n := 0
+	if extra {
+		if end < 1 {
+			return 0, errors.New("invalid padding")
+		}
+		n = int(b[end-1]) << 8
+		end--
+	}
+	if end < 1 {
+		return 0, errors.New("invalid padding")
+	}
+	n |= int(b[end-1])
+	end -= n + 1
+	if end < 0 {
+		return 0, errors.New("invalid padding")
+	}
+	return end, nil
+}
+
+// certificate is an application or user certificate with its private key.
+type certificate struct {
+	der []byte
+	key *rsa.PrivateKey
+	uri string
+}
+
+func loadCertificate(certFile, keyFile string) (*certificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key of %q is not a RSA key", certFile)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	c := &certificate{der: pair.Certificate[0], key: key}
+	if len(cert.URIs) > 0 {
+		c.uri = cert.URIs[0].String()
+	}
+	return c, nil
+}
+
+// generateCertificate creates a self-signed application certificate.
+func generateCertificate(applicationURI string, validity time.Duration) (*certificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := url.Parse(applicationURI)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	// Backdated to allow for clock differences to the server
+	now := time.Now()
+	notBefore := now.Add(-time.Hour)
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"Telegraf"},
+			CommonName:   "Telegraf OPC UA Client",
+		},
+		NotBefore:             notBefore,
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		URIs:                  []*url.URL{uri},
+	}
+	if host != "" {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate{der: der, key: key, uri: applicationURI}, nil
+}
+
+// loadCertPool returns a pool of the certificates of the files, which are in
+// PEM or DER format.
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var certs []*x509.Certificate
+		block, rest := pem.Decode(b)
+		if block == nil {
+			certs, err = x509.ParseCertificates(b)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %q: %v", file, err)
+			}
+		}
+		for ; block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %q: %v", file, err)
+			}
+			certs = append(certs, cert)
+		}
+		if len(certs) == 0 {
+			return nil, fmt.Errorf("no certificate in %q", file)
+		}
+
+		for _, cert := range certs {
+			pool.AddCert(cert)
+		}
+	}
+	return pool, nil
+}
+
+// verifyCertificate verifies that a DER encoded certificate, which may be
+// followed by the certificates of its chain, is valid at the time and is
+// either trusted itself or issued by a trusted certificate.
+func verifyCertificate(der []byte, trusted *x509.CertPool, now time.Time) error {
+	certs, err := x509.ParseCertificates(der)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return errors.New("no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         trusted,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// publicKey returns the RSA public key of a DER encoded certificate; the
+// certificate may be followed by the certificates of its chain.
+func publicKey(der []byte) (*rsa.PublicKey, error) {
+	certs, err := x509.ParseCertificates(der)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate")
+	}
+	key, ok := certs[0].PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("certificate has no RSA key")
+	}
+	return key, nil
+}
+
+// leafCertificate returns the first certificate of a chain.
+func leafCertificate(der []byte) []byte {
+	certs, err := x509.ParseCertificates(der)
+	if err != nil || len(certs) == 0 {
+		return der
+	}
+	return certs[0].Raw
+}
+
+func thumbprint(der []byte) []byte {
+	sum := sha1.Sum(leafCertificate(der))
+	return sum[:]
+}
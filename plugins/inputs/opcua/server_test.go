@@ -0,0 +1,639 @@
+package opcua
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testEndpoint is a security policy and mode offered by the test server.
+type testEndpoint struct {
+	policy *securityPolicy
+	mode   uint32
+}
+
+// testServer is a simulated OPC UA server serving the values of nodes.
+type testServer struct {
+	listener net.Listener
+	url      string
+	cert     *certificate
+	dir      string
+	certFile string
+
+	endpoints []testEndpoint
+	anonymous bool
+
+	// Endpoints returned by GetEndpoints instead of endpoints, simulating a
+	// modification of the unsecured discovery
+	discovered []testEndpoint
+
+	// Accept passwords without encryption
+	plaintextPassword bool
+	users     map[string]string
+	userCerts [][]byte
+	lifetime  time.Duration
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	values   map[string]dataValue
+	sessions int
+	renewals int
+	wg       sync.WaitGroup
+}
+
+func newTestServer(t *testing.T, endpoints ...testEndpoint) *testServer {
+	return newTestServerAt(t, "127.0.0.1:0", endpoints...)
+}
+
+// newTestServerAt starts a test server listening on the address.
+func newTestServerAt(t *testing.T, address string, endpoints ...testEndpoint) *testServer {
+	cert, err := generateCertificate("urn:telegraf:test:server", time.Hour)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "opcua-server")
+	require.NoError(t, err)
+	certFile, _ := writeCertificate(t, dir, cert)
+
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+
+	s := &testServer{
+		listener:  listener,
+		url:       "opc.tcp://" + listener.Addr().String(),
+		cert:      cert,
+		dir:       dir,
+		certFile:  certFile,
+		endpoints: endpoints,
+		anonymous: true,
+		users:     make(map[string]string),
+		lifetime:  time.Hour,
+		conns:     make(map[net.Conn]bool),
+		values:    make(map[string]dataValue),
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = true
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer conn.Close()
+				s.serve(conn) //nolint:errcheck
+			}()
+		}
+	}()
+	return s
+}
+
+// Close stops the server, closing its connections.
+func (s *testServer) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	os.RemoveAll(s.dir)
+}
+
+func (s *testServer) setValue(id nodeID, value dataValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[id.String()] = value
+}
+
+func (s *testServer) value(id nodeID) dataValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[id.String()]
+	if !ok {
+		return dataValue{status: statusBadNodeIDUnknown}
+	}
+	return v
+}
+
+// session is the state of a connection to the test server.
+type session struct {
+	channel *secureChannel
+
+	authToken    nodeID
+	serverNonce  []byte
+	clientCert   []byte
+	activated    bool
+	subscription uint32
+	publishing   time.Duration
+	items        map[uint32]nodeID
+}
+
+// serve handles the messages of a connection.
+func (s *testServer) serve(conn net.Conn) error {
+	b, err := readMessage(conn)
+	if err != nil {
+		return err
+	}
+	if string(b[:3]) != msgHello {
+		return writeMessage(conn, msgError, chunkFinal, errorBody(statusBadTCPMessageTypeInvalid))
+	}
+	var ack encoder
+	ack.uint32(0)
+	ack.uint32(bufferSize)
+	ack.uint32(bufferSize)
+	ack.uint32(maxMessageSize)
+	ack.uint32(0)
+	if err := writeMessage(conn, msgAck, chunkFinal, ack.bytes()); err != nil {
+		return err
+	}
+
+	ss := &session{
+		channel: &secureChannel{
+			conn:           conn,
+			localCert:      s.cert,
+			remoteKeys:     make(map[uint32]*symmetricKeys),
+			sendBufferSize: bufferSize,
+		},
+		items: make(map[uint32]nodeID),
+	}
+	for {
+		msgType, requestID, body, err := ss.channel.receive()
+		if err != nil {
+			return err
+		}
+
+		switch msgType {
+		case msgOpen:
+			err = s.openSecureChannel(ss, requestID, body)
+		case msgClose:
+			return nil
+		case msgData:
+			err = s.handle(ss, requestID, body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func errorBody(status statusCode) []byte {
+	var e encoder
+	e.uint32(uint32(status))
+	e.string("")
+	return e.bytes()
+}
+
+// decodeRequest reads the type and header of a request and returns the
+// authentication token and request handle.
+func decodeRequest(d *decoder) (uint32, nodeID, uint32) {
+	typeID := d.nodeID().numeric
+	authToken := d.nodeID()
+	d.dateTime()
+	handle := d.uint32()
+	d.uint32()
+	d.string()
+	d.uint32()
+	d.extensionObject()
+	return typeID, authToken, handle
+}
+
+func responseHeader(e *encoder, typeID uint32, handle uint32, status statusCode) {
+	e.nodeID(numericID(0, typeID))
+	e.dateTime(time.Now())
+	e.uint32(handle)
+	e.uint32(uint32(status))
+	e.diagnosticInfo()
+	e.strings(nil)
+	e.extensionObject(0, nil)
+}
+
+func (s *testServer) offers(policy *securityPolicy, mode uint32) bool {
+	if policy == policyNone && mode == modeNone {
+		// Used for discovery
+		return true
+	}
+	for _, ep := range s.endpoints {
+		if ep.policy == policy && ep.mode == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testServer) openSecureChannel(ss *session, requestID uint32, body []byte) error {
+	d := newDecoder(body)
+	typeID, _, handle := decodeRequest(d)
+	d.uint32() // protocol version
+	requestType := d.uint32()
+	mode := d.uint32()
+	clientNonce := d.byteString()
+	d.uint32() // requested lifetime
+	if d.err != nil || typeID != idOpenSecureChannelRequest {
+		return fmt.Errorf("invalid OpenSecureChannel request: %v", d.err)
+	}
+
+	c := ss.channel
+	if !s.offers(c.policy, mode) {
+		return writeMessage(c.conn, msgError, chunkFinal, errorBody(statusBadSecurityPolicyRejected))
+	}
+	c.mode = mode
+
+	s.mu.Lock()
+	if requestType == tokenIssue {
+		s.sessions++
+		c.channelID = uint32(s.sessions)
+	} else {
+		s.renewals++
+	}
+	s.mu.Unlock()
+
+	serverNonce, err := c.policy.nonce()
+	if err != nil {
+		return err
+	}
+	if !c.policy.secure() {
+		serverNonce = nil
+	}
+	c.setToken(c.tokenID+1, s.lifetime, serverNonce, clientNonce)
+
+	var e encoder
+	responseHeader(&e, idOpenSecureChannelResponse, handle, statusGood)
+	e.uint32(0)
+	e.uint32(c.channelID)
+	e.uint32(c.tokenID)
+	e.dateTime(time.Now())
+	e.uint32(uint32(s.lifetime / time.Millisecond))
+	e.byteString(serverNonce)
+	return c.sendOpen(requestID, e.bytes())
+}
+
+func (s *testServer) handle(ss *session, requestID uint32, body []byte) error {
+	d := newDecoder(body)
+	typeID, authToken, handle := decodeRequest(d)
+	if d.err != nil {
+		return d.err
+	}
+
+	var e encoder
+	var status statusCode
+	switch typeID {
+	case idGetEndpointsRequest:
+		status = s.getEndpoints(&e, handle)
+	case idCreateSessionRequest:
+		status = s.createSession(ss, d, &e, handle)
+	default:
+		if authToken != ss.authToken {
+			status = statusBadSessionIDInvalid
+			break
+		}
+		switch typeID {
+		case idActivateSessionRequest:
+			status = s.activateSession(ss, d, &e, handle)
+		case idCloseSessionRequest:
+			ss.activated = false
+			responseHeader(&e, idCloseSessionResponse, handle, statusGood)
+		default:
+			if !ss.activated {
+				status = statusBadSessionNotActivated
+				break
+			}
+			switch typeID {
+			case idReadRequest:
+				status = s.read(d, &e, handle)
+			case idCreateSubscriptionRequest:
+				status = s.createSubscription(ss, d, &e, handle)
+			case idCreateMonitoredItemsRequest:
+				status = s.createMonitoredItems(ss, d, &e, handle)
+			case idPublishRequest:
+				status = s.publish(ss, d, &e, handle)
+			default:
+				status = statusBadServiceUnsupported
+			}
+		}
+	}
+
+	if status != statusGood {
+		e = encoder{}
+		responseHeader(&e, idServiceFault, handle, status)
+	}
+	return ss.channel.send(msgData, requestID, e.bytes())
+}
+
+func (s *testServer) endpointDescriptions(offered []testEndpoint) []endpointDescription {
+	var tokens []userTokenPolicy
+	if s.anonymous {
+		tokens = append(tokens, userTokenPolicy{policyID: "anonymous", tokenType: tokenAnonymous})
+	}
+	passwordPolicy := policyBasic256Sha256
+	if s.plaintextPassword {
+		passwordPolicy = policyNone
+	}
+	tokens = append(tokens,
+		userTokenPolicy{policyID: "username", tokenType: tokenUserName, securityPolicyURI: passwordPolicy.uri},
+		userTokenPolicy{policyID: "certificate", tokenType: tokenCertificate, securityPolicyURI: policyBasic256Sha256.uri},
+	)
+
+	var endpoints []endpointDescription
+	for _, ep := range offered {
+		endpoints = append(endpoints, endpointDescription{
+			url:               s.url,
+			server:            applicationDescription{uri: s.cert.uri, name: "Test Server"},
+			serverCertificate: s.cert.der,
+			securityMode:      ep.mode,
+			securityPolicyURI: ep.policy.uri,
+			userTokens:        tokens,
+		})
+	}
+	return endpoints
+}
+
+func (s *testServer) getEndpoints(e *encoder, handle uint32) statusCode {
+	responseHeader(e, idGetEndpointsResponse, handle, statusGood)
+	offered := s.endpoints
+	if s.discovered != nil {
+		offered = s.discovered
+	}
+	endpoints := s.endpointDescriptions(offered)
+	e.int32(int32(len(endpoints)))
+	for _, ep := range endpoints {
+		e.endpointDescription(ep)
+	}
+	return statusGood
+}
+
+func (s *testServer) createSession(ss *session, d *decoder, e *encoder, handle uint32) statusCode {
+	d.applicationDescription()
+	d.string() // server uri
+	d.string() // endpoint url
+	d.string() // session name
+	clientNonce := d.byteString()
+	clientCert := d.byteString()
+	d.float64()
+	d.uint32()
+	if d.err != nil {
+		return statusBadDecodingError
+	}
+
+	c := ss.channel
+	if c.policy.secure() && !bytes.Equal(clientCert, c.remoteCert) {
+		return statusBadCertificateInvalid
+	}
+
+	serverNonce, err := policyBasic256Sha256.nonce()
+	if err != nil {
+		return statusBadInternalError
+	}
+	var signature signatureData
+	if c.policy.secure() {
+		b, err := c.policy.asymmetricSign(s.cert.key, append(append([]byte{}, clientCert...), clientNonce...))
+		if err != nil {
+			return statusBadInternalError
+		}
+		signature = signatureData{algorithm: c.policy.signatureURI, signature: b}
+	}
+
+	ss.authToken = numericID(1, c.channelID*1000)
+	ss.serverNonce = serverNonce
+	ss.clientCert = clientCert
+
+	responseHeader(e, idCreateSessionResponse, handle, statusGood)
+	e.nodeID(numericID(1, c.channelID))
+	e.nodeID(ss.authToken)
+	e.float64(float64(time.Hour / time.Millisecond))
+	e.byteString(serverNonce)
+	e.byteString(s.cert.der)
+	endpoints := s.endpointDescriptions(s.endpoints)
+	e.int32(int32(len(endpoints)))
+	for _, ep := range endpoints {
+		e.endpointDescription(ep)
+	}
+	e.int32(0)
+	e.signatureData(signature)
+	e.uint32(maxMessageSize)
+	return statusGood
+}
+
+func (s *testServer) activateSession(ss *session, d *decoder, e *encoder, handle uint32) statusCode {
+	clientSignature := d.signatureData()
+	d.arrayLength()
+	d.strings()
+	tokenID, token := d.extensionObject()
+	tokenSignature := d.signatureData()
+	if d.err != nil {
+		return statusBadDecodingError
+	}
+
+	c := ss.channel
+	serverData := append(append([]byte{}, s.cert.der...), ss.serverNonce...)
+	if c.policy.secure() {
+		if err := c.policy.asymmetricVerify(c.remoteKey, serverData, clientSignature.signature); err != nil {
+			return statusBadSecurityChecksFailed
+		}
+	}
+
+	td := newDecoder(token)
+	switch tokenID {
+	case idAnonymousIdentityToken:
+		if !s.anonymous {
+			return statusBadIdentityTokenRejected
+		}
+	case idUserNameIdentityToken:
+		td.string()
+		username := td.string()
+		password := td.byteString()
+		algorithm := td.string()
+		if td.err != nil {
+			return statusBadIdentityTokenInvalid
+		}
+		if s.plaintextPassword {
+			expected, ok := s.users[username]
+			if algorithm != "" || !ok || string(password) != expected {
+				return statusBadUserAccessDenied
+			}
+			break
+		}
+		if algorithm != policyBasic256Sha256.encryptionURI {
+			return statusBadIdentityTokenInvalid
+		}
+		plain, err := policyBasic256Sha256.asymmetricDecrypt(s.cert.key, password)
+		if err != nil || len(plain) < 4+len(ss.serverNonce) {
+			return statusBadIdentityTokenInvalid
+		}
+		pd := newDecoder(plain)
+		n := int(pd.uint32())
+		secret := pd.next(n)
+		if secret == nil || !bytes.HasSuffix(secret, ss.serverNonce) {
+			return statusBadIdentityTokenInvalid
+		}
+		expected, ok := s.users[username]
+		if !ok || string(secret[:len(secret)-len(ss.serverNonce)]) != expected {
+			return statusBadUserAccessDenied
+		}
+	case idX509IdentityToken:
+		td.string()
+		cert := td.byteString()
+		trusted := false
+		for _, c := range s.userCerts {
+			trusted = trusted || bytes.Equal(c, cert)
+		}
+		if !trusted {
+			return statusBadIdentityTokenRejected
+		}
+		key, err := publicKey(cert)
+		if err != nil {
+			return statusBadIdentityTokenInvalid
+		}
+		if tokenSignature.algorithm != policyBasic256Sha256.signatureURI {
+			return statusBadIdentityTokenInvalid
+		}
+		if err := policyBasic256Sha256.asymmetricVerify(key, serverData, tokenSignature.signature); err != nil {
+			return statusBadIdentityTokenRejected
+		}
+	default:
+		return statusBadIdentityTokenInvalid
+	}
+
+	ss.activated = true
+	responseHeader(e, idActivateSessionResponse, handle, statusGood)
+	e.byteString(ss.serverNonce)
+	e.int32(0)
+	e.int32(0)
+	return statusGood
+}
+
+// decodeReadValueID reads a read value id and returns its node id.
+func decodeReadValueID(d *decoder) nodeID {
+	id := d.nodeID()
+	d.uint32()
+	d.string()
+	d.qualifiedName()
+	return id
+}
+
+func (s *testServer) read(d *decoder, e *encoder, handle uint32) statusCode {
+	d.float64()
+	d.uint32()
+	n := d.arrayLength()
+	var ids []nodeID
+	for i := 0; i < n && d.err == nil; i++ {
+		ids = append(ids, decodeReadValueID(d))
+	}
+	if d.err != nil {
+		return statusBadDecodingError
+	}
+
+	responseHeader(e, idReadResponse, handle, statusGood)
+	e.int32(int32(len(ids)))
+	for _, id := range ids {
+		if err := e.dataValue(s.value(id)); err != nil {
+			return statusBadEncodingError
+		}
+	}
+	e.int32(0)
+	return statusGood
+}
+
+func (s *testServer) createSubscription(ss *session, d *decoder, e *encoder, handle uint32) statusCode {
+	interval := d.float64()
+	if d.err != nil {
+		return statusBadDecodingError
+	}
+	ss.subscription = 7
+	ss.publishing = time.Duration(interval * float64(time.Millisecond))
+
+	responseHeader(e, idCreateSubscriptionResponse, handle, statusGood)
+	e.uint32(ss.subscription)
+	e.float64(interval)
+	e.uint32(30)
+	e.uint32(10)
+	return statusGood
+}
+
+func (s *testServer) createMonitoredItems(ss *session, d *decoder, e *encoder, handle uint32) statusCode {
+	if d.uint32() != ss.subscription {
+		return statusBadSubscriptionIDInvalid
+	}
+	d.uint32()
+	n := d.arrayLength()
+	type item struct {
+		id     nodeID
+		handle uint32
+	}
+	var items []item
+	for i := 0; i < n && d.err == nil; i++ {
+		id := decodeReadValueID(d)
+		d.uint32()
+		h := d.uint32()
+		d.float64()
+		d.extensionObject()
+		d.uint32()
+		d.boolean()
+		items = append(items, item{id: id, handle: h})
+	}
+	if d.err != nil {
+		return statusBadDecodingError
+	}
+
+	responseHeader(e, idCreateMonitoredItemsResponse, handle, statusGood)
+	e.int32(int32(len(items)))
+	for i, it := range items {
+		status := statusGood
+		if s.value(it.id).status == statusBadNodeIDUnknown {
+			status = statusBadNodeIDUnknown
+		} else {
+			ss.items[it.handle] = it.id
+		}
+		e.uint32(uint32(status))
+		e.uint32(uint32(i + 1))
+		e.float64(0)
+		e.uint32(1)
+		e.extensionObject(0, nil)
+	}
+	e.int32(0)
+	return statusGood
+}
+
+// publish waits for the publishing interval and returns the values of all
+// monitored items.
+func (s *testServer) publish(ss *session, d *decoder, e *encoder, handle uint32) statusCode {
+	if ss.subscription == 0 {
+		return statusBadNoSubscription
+	}
+	time.Sleep(ss.publishing)
+
+	var items encoder
+	items.int32(int32(len(ss.items)))
+	for h, id := range ss.items {
+		items.uint32(h)
+		if err := items.dataValue(s.value(id)); err != nil {
+			return statusBadEncodingError
+		}
+	}
+	items.int32(0)
+
+	responseHeader(e, idPublishResponse, handle, statusGood)
+	e.uint32(ss.subscription)
+	e.int32(0)
+	e.boolean(false)
+	e.uint32(1)
+	e.dateTime(time.Now())
+	e.int32(1)
+	e.extensionObject(idDataChangeNotification, func(b *encoder) {
+		b.buf = append(b.buf, items.bytes()...)
+	})
+	e.int32(0)
+	e.int32(0)
+	return statusGood
+}
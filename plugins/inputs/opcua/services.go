@@ -0,0 +1,266 @@
+package opcua
+
+import (
+	"fmt"
+	"time"
+)
+
+// This file implements the encoding of the services used by the client, see
+// OPC UA Part 4, 5 and 7.
+
+// Binary encoding ids of the service requests and responses, see
+// NodeIds.csv of the OPC UA specification.
+const (
+	idServiceFault                 = 397
+	idGetEndpointsRequest          = 428
+	idGetEndpointsResponse         = 431
+	idOpenSecureChannelRequest     = 446
+	idOpenSecureChannelResponse    = 449
+	idCloseSecureChannelRequest    = 452
+	idCreateSessionRequest         = 461
+	idCreateSessionResponse        = 464
+	idActivateSessionRequest       = 467
+	idActivateSessionResponse      = 470
+	idCloseSessionRequest          = 473
+	idCloseSessionResponse         = 476
+	idReadRequest                  = 631
+	idReadResponse                 = 634
+	idCreateMonitoredItemsRequest  = 751
+	idCreateMonitoredItemsResponse = 754
+	idCreateSubscriptionRequest    = 787
+	idCreateSubscriptionResponse   = 790
+	idPublishRequest               = 826
+	idPublishResponse              = 829
+
+	idAnonymousIdentityToken   = 321
+	idUserNameIdentityToken    = 324
+	idX509IdentityToken        = 327
+	idDataChangeNotification   = 811
+	idStatusChangeNotification = 820
+)
+
+// User token types
+const (
+	tokenAnonymous   uint32 = 0
+	tokenUserName    uint32 = 1
+	tokenCertificate uint32 = 2
+)
+
+// Attribute ids
+const (
+	attributeValue uint32 = 13
+)
+
+// Timestamps to return by Read and monitored items
+const (
+	timestampsBoth uint32 = 2
+)
+
+// Monitoring modes
+const (
+	monitoringReporting uint32 = 2
+)
+
+// requestHeader encodes the header of a request.
+func (e *encoder) requestHeader(authToken nodeID, handle uint32, timeout time.Duration) {
+	e.nodeID(authToken)
+	e.dateTime(time.Now())
+	e.uint32(handle)
+	e.uint32(0)  // return diagnostics
+	e.string("") // audit entry id
+	e.uint32(uint32(timeout / time.Millisecond))
+	e.extensionObject(0, nil) // additional header
+}
+
+// responseHeader reads the header of a response and returns its service
+// result.
+func (d *decoder) responseHeader() statusCode {
+	d.dateTime()
+	d.uint32() // request handle
+	result := statusCode(d.uint32())
+	d.diagnosticInfo()
+	d.strings()
+	d.extensionObject()
+	return result
+}
+
+// responseBody checks the type and result of a response and returns a decoder
+// positioned after its header.
+func responseBody(b []byte, typeID uint32) (*decoder, error) {
+	d := newDecoder(b)
+	id := d.nodeID()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid response: %v", d.err)
+	}
+	if id.kind != idNumeric || (id.numeric != typeID && id.numeric != idServiceFault) {
+		return nil, fmt.Errorf("unexpected response type %v", id)
+	}
+
+	result := d.responseHeader()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid response header: %v", d.err)
+	}
+	if result.isBad() {
+		return nil, result
+	}
+	if id.numeric == idServiceFault {
+		return nil, statusBadUnexpectedError
+	}
+	return d, nil
+}
+
+// applicationDescription describes the client or server application.
+type applicationDescription struct {
+	uri     string
+	product string
+	name    string
+	// 0 server, 1 client
+	kind uint32
+}
+
+func (e *encoder) applicationDescription(v applicationDescription) {
+	e.string(v.uri)
+	e.string(v.product)
+	e.localizedText(v.name)
+	e.uint32(v.kind)
+	e.string("")   // gateway server uri
+	e.string("")   // discovery profile uri
+	e.strings(nil) // discovery urls
+}
+
+func (d *decoder) applicationDescription() applicationDescription {
+	var v applicationDescription
+	v.uri = d.string()
+	v.product = d.string()
+	v.name = d.localizedText()
+	v.kind = d.uint32()
+	d.string()
+	d.string()
+	d.strings()
+	return v
+}
+
+// userTokenPolicy describes a type of user identity tokens accepted by an
+// endpoint.
+type userTokenPolicy struct {
+	policyID  string
+	tokenType uint32
+	// Security policy used to encrypt or sign the token; the one of the
+	// channel if empty
+	securityPolicyURI string
+}
+
+// endpointDescription describes an endpoint of a server.
+type endpointDescription struct {
+	url               string
+	server            applicationDescription
+	serverCertificate []byte
+	securityMode      uint32
+	securityPolicyURI string
+	userTokens        []userTokenPolicy
+	securityLevel     uint8
+}
+
+func (e *encoder) endpointDescription(v endpointDescription) {
+	e.string(v.url)
+	e.applicationDescription(v.server)
+	e.byteString(v.serverCertificate)
+	e.uint32(v.securityMode)
+	e.string(v.securityPolicyURI)
+	e.int32(int32(len(v.userTokens)))
+	for _, t := range v.userTokens {
+		e.string(t.policyID)
+		e.uint32(t.tokenType)
+		e.string("") // issued token type
+		e.string("") // issuer endpoint url
+		e.string(t.securityPolicyURI)
+	}
+	e.string("http://opcfoundation.org/UA-Profile/Transport/uatcp-uasc-uabinary")
+	e.uint8(v.securityLevel)
+}
+
+func (d *decoder) endpointDescription() endpointDescription {
+	var v endpointDescription
+	v.url = d.string()
+	v.server = d.applicationDescription()
+	v.serverCertificate = d.byteString()
+	v.securityMode = d.uint32()
+	v.securityPolicyURI = d.string()
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		var t userTokenPolicy
+		t.policyID = d.string()
+		t.tokenType = d.uint32()
+		d.string()
+		d.string()
+		t.securityPolicyURI = d.string()
+		v.userTokens = append(v.userTokens, t)
+	}
+	d.string() // transport profile uri
+	v.securityLevel = d.uint8()
+	return v
+}
+
+// signatureData is the signature of a client or server proving possession of
+// the private key of its certificate.
+type signatureData struct {
+	algorithm string
+	signature []byte
+}
+
+func (e *encoder) signatureData(v signatureData) {
+	e.string(v.algorithm)
+	e.byteString(v.signature)
+}
+
+func (d *decoder) signatureData() signatureData {
+	return signatureData{algorithm: d.string(), signature: d.byteString()}
+}
+
+// readValueID identifies the value attribute of a node to read or monitor.
+func (e *encoder) readValueID(id nodeID) {
+	e.nodeID(id)
+	e.uint32(attributeValue)
+	e.string("")           // index range
+	e.qualifiedName(0, "") // data encoding
+}
+
+// notification is the value of a monitored item reported by Publish.
+type notification struct {
+	handle uint32
+	value  dataValue
+	err    error
+}
+
+// notificationMessage reads the notification message of a Publish response
+// and returns its sequence number and the data change notifications.
+func (d *decoder) notificationMessage() (uint32, []notification, error) {
+	sequenceNumber := d.uint32()
+	d.dateTime() // publish time
+
+	var notifications []notification
+	n := d.arrayLength()
+	for i := 0; i < n && d.err == nil; i++ {
+		typeID, body := d.extensionObject()
+		switch typeID {
+		case idDataChangeNotification:
+			nd := newDecoder(body)
+			items := nd.arrayLength()
+			for j := 0; j < items && nd.err == nil; j++ {
+				handle := nd.uint32()
+				value, err := nd.dataValue()
+				notifications = append(notifications, notification{handle: handle, value: value, err: err})
+			}
+			if nd.err != nil {
+				return 0, nil, fmt.Errorf("invalid data change notification: %v", nd.err)
+			}
+		case idStatusChangeNotification:
+			nd := newDecoder(body)
+			status := statusCode(nd.uint32())
+			if status.isBad() {
+				return 0, nil, fmt.Errorf("subscription status changed: %v", status)
+			}
+		}
+	}
+	return sequenceNumber, notifications, d.err
+}
@@ -0,0 +1,151 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	commonpb "github.com/influxdata/telegraf/plugins/common/otlp/common/v1"
+	metricspb "github.com/influxdata/telegraf/plugins/common/otlp/metrics/v1"
+)
+
+// convert converts OTLP metrics into telegraf metrics using the same layout
+// as the prometheus input: the measurement is named after the OTLP metric,
+// gauges and sums use a "gauge" or "counter" field and histograms and
+// summaries use "sum" and "count" fields along with a field for each bucket
+// or quantile.  Resource and data point attributes become tags.
+func convert(resourceMetrics []*metricspb.ResourceMetrics) []telegraf.Metric {
+	now := time.Now()
+
+	var metrics []telegraf.Metric
+	for _, rm := range resourceMetrics {
+		resourceTags := make(map[string]string)
+		addTags(resourceTags, rm.GetResource().GetAttributes())
+
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			for _, m := range ilm.Metrics {
+				c := &metricConverter{name: m.Name, resourceTags: resourceTags, now: now}
+				switch data := m.Data.(type) {
+				case *metricspb.Metric_Gauge:
+					c.numbers(data.Gauge.DataPoints, "gauge", telegraf.Gauge)
+				case *metricspb.Metric_Sum:
+					if data.Sum.IsMonotonic {
+						c.numbers(data.Sum.DataPoints, "counter", telegraf.Counter)
+					} else {
+						c.numbers(data.Sum.DataPoints, "gauge", telegraf.Gauge)
+					}
+				case *metricspb.Metric_Histogram:
+					c.histograms(data.Histogram.DataPoints)
+				case *metricspb.Metric_Summary:
+					c.summaries(data.Summary.DataPoints)
+				}
+				metrics = append(metrics, c.metrics...)
+			}
+		}
+	}
+	return metrics
+}
+
+type metricConverter struct {
+	name         string
+	resourceTags map[string]string
+	now          time.Time
+
+	metrics []telegraf.Metric
+}
+
+func (c *metricConverter) add(attrs []*commonpb.KeyValue, fields map[string]interface{}, ts uint64, tp telegraf.ValueType) {
+	if len(fields) == 0 {
+		return
+	}
+
+	tags := make(map[string]string, len(c.resourceTags)+len(attrs))
+	for k, v := range c.resourceTags {
+		tags[k] = v
+	}
+	addTags(tags, attrs)
+
+	t := c.now
+	if ts > 0 {
+		t = time.Unix(0, int64(ts))
+	}
+
+	m, err := metric.New(c.name, tags, fields, t, tp)
+	if err != nil {
+		return
+	}
+	c.metrics = append(c.metrics, m)
+}
+
+func (c *metricConverter) numbers(points []*metricspb.NumberDataPoint, field string, tp telegraf.ValueType) {
+	for _, dp := range points {
+		var value interface{}
+		switch v := dp.Value.(type) {
+		case *metricspb.NumberDataPoint_AsInt:
+			value = v.AsInt
+		case *metricspb.NumberDataPoint_AsDouble:
+			if math.IsNaN(v.AsDouble) {
+				continue
+			}
+			value = v.AsDouble
+		default:
+			continue
+		}
+		c.add(dp.Attributes, map[string]interface{}{field: value}, dp.TimeUnixNano, tp)
+	}
+}
+
+func (c *metricConverter) histograms(points []*metricspb.HistogramDataPoint) {
+	for _, dp := range points {
+		fields := map[string]interface{}{
+			"count": float64(dp.Count),
+			"sum":   dp.Sum,
+		}
+
+		// OTLP uses per bucket counts while the fields hold cumulative counts
+		var cumulative uint64
+		for i, count := range dp.BucketCounts {
+			cumulative += count
+			if i < len(dp.ExplicitBounds) {
+				fields[fmt.Sprint(dp.ExplicitBounds[i])] = float64(cumulative)
+			} else {
+				fields["+Inf"] = float64(cumulative)
+			}
+		}
+		c.add(dp.Attributes, fields, dp.TimeUnixNano, telegraf.Histogram)
+	}
+}
+
+func (c *metricConverter) summaries(points []*metricspb.SummaryDataPoint) {
+	for _, dp := range points {
+		fields := map[string]interface{}{
+			"count": float64(dp.Count),
+			"sum":   dp.Sum,
+		}
+		for _, q := range dp.QuantileValues {
+			if !math.IsNaN(q.Value) {
+				fields[fmt.Sprint(q.Quantile)] = q.Value
+			}
+		}
+		c.add(dp.Attributes, fields, dp.TimeUnixNano, telegraf.Summary)
+	}
+}
+
+// addTags adds the attributes with a scalar value as tags.
+func addTags(tags map[string]string, attrs []*commonpb.KeyValue) {
+	for _, attr := range attrs {
+		switch v := attr.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			tags[attr.Key] = v.StringValue
+		case *commonpb.AnyValue_BoolValue:
+			tags[attr.Key] = strconv.FormatBool(v.BoolValue)
+		case *commonpb.AnyValue_IntValue:
+			tags[attr.Key] = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_DoubleValue:
+			tags[attr.Key] = strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		}
+	}
+}
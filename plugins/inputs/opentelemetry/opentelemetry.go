@@ -0,0 +1,118 @@
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	internaltls "github.com/influxdata/telegraf/internal/tls"
+	colmetricspb "github.com/influxdata/telegraf/plugins/common/otlp/collector/metrics/v1"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	// Register the gzip decompressor used by most OTLP exporters
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+const sampleConfig = `
+  ## Address and port to listen on for OTLP over gRPC.
+  service_address = "0.0.0.0:4317"
+
+  ## Maximum size of a received message in bytes, zero uses the gRPC default.
+  # max_msg_size = 0
+
+  ## Enable TLS
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Enable TLS client authentication and define allowed CA certificates.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+`
+
+type OpenTelemetry struct {
+	ServiceAddress string `toml:"service_address"`
+	MaxMsgSize     int    `toml:"max_msg_size"`
+	internaltls.ServerConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	acc        telegraf.Accumulator
+	grpcServer *grpc.Server
+	listener   net.Listener
+	wg         sync.WaitGroup
+}
+
+func (o *OpenTelemetry) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpenTelemetry) Description() string {
+	return "Receive metrics from OpenTelemetry exporters using OTLP over gRPC"
+}
+
+func (o *OpenTelemetry) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
+	o.acc = acc
+
+	var opts []grpc.ServerOption
+	tlsConfig, err := o.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	} else if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if o.MaxMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.MaxMsgSize))
+	}
+
+	o.listener, err = net.Listen("tcp", o.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
+	o.grpcServer = grpc.NewServer(opts...)
+	colmetricspb.RegisterMetricsServiceServer(o.grpcServer, o)
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		if err := o.grpcServer.Serve(o.listener); err != nil {
+			o.acc.AddError(fmt.Errorf("serving OTLP failed: %v", err))
+		}
+	}()
+	o.Log.Infof("Listening on %s", o.listener.Addr())
+	return nil
+}
+
+func (o *OpenTelemetry) Stop() {
+	if o.grpcServer != nil {
+		o.grpcServer.Stop()
+	}
+	o.wg.Wait()
+}
+
+// Address returns the address the receiver is listening on.
+func (o *OpenTelemetry) Address() net.Addr {
+	return o.listener.Addr()
+}
+
+// Export implements the OTLP metrics service.
+func (o *OpenTelemetry) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	for _, m := range convert(req.ResourceMetrics) {
+		o.acc.AddMetric(m)
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func init() {
+	inputs.Add("opentelemetry", func() telegraf.Input {
+		return &OpenTelemetry{
+			ServiceAddress: "0.0.0.0:4317",
+		}
+	})
+}
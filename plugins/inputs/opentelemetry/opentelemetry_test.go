@@ -0,0 +1,167 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	colmetricspb "github.com/influxdata/telegraf/plugins/common/otlp/collector/metrics/v1"
+	commonpb "github.com/influxdata/telegraf/plugins/common/otlp/common/v1"
+	metricspb "github.com/influxdata/telegraf/plugins/common/otlp/metrics/v1"
+	resourcepb "github.com/influxdata/telegraf/plugins/common/otlp/resource/v1"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func TestExport(t *testing.T) {
+	plugin := &OpenTelemetry{
+		ServiceAddress: "127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	defer plugin.Stop()
+
+	conn, err := grpc.Dial(plugin.Address().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{stringAttribute("host.name", "a")},
+				},
+				InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{
+					{
+						Metrics: []*metricspb.Metric{
+							{
+								Name: "system_cpu_utilization",
+								Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+									DataPoints: []*metricspb.NumberDataPoint{
+										{
+											Attributes: []*commonpb.KeyValue{
+												stringAttribute("cpu", "0"),
+												{Key: "online", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}},
+											},
+											TimeUnixNano: 10,
+											Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 0.5},
+										},
+									},
+								}},
+							},
+							{
+								Name: "http_requests",
+								Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+									IsMonotonic: true,
+									DataPoints: []*metricspb.NumberDataPoint{
+										{TimeUnixNano: 10, Value: &metricspb.NumberDataPoint_AsInt{AsInt: 42}},
+									},
+								}},
+							},
+							{
+								Name: "queue_size",
+								Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+									DataPoints: []*metricspb.NumberDataPoint{
+										{TimeUnixNano: 10, Value: &metricspb.NumberDataPoint_AsInt{AsInt: -3}},
+									},
+								}},
+							},
+							{
+								Name: "http_duration",
+								Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+									DataPoints: []*metricspb.HistogramDataPoint{
+										{
+											TimeUnixNano:   10,
+											Count:          8,
+											Sum:            4.5,
+											ExplicitBounds: []float64{0.1, 0.5, 1},
+											BucketCounts:   []uint64{2, 3, 2, 1},
+										},
+									},
+								}},
+							},
+							{
+								Name: "rpc_latency",
+								Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{
+									DataPoints: []*metricspb.SummaryDataPoint{
+										{
+											TimeUnixNano: 10,
+											Count:        4,
+											Sum:          2,
+											QuantileValues: []*metricspb.SummaryDataPoint_ValueAtQuantile{
+												{Quantile: 0.5, Value: 0.4},
+												{Quantile: 0.99, Value: 0.9},
+											},
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	client := colmetricspb.NewMetricsServiceClient(conn)
+	_, err = client.Export(context.Background(), req)
+	require.NoError(t, err)
+
+	ts := time.Unix(0, 10)
+	expected := []telegraf.Metric{
+		testutil.MustMetric("system_cpu_utilization",
+			map[string]string{"host.name": "a", "cpu": "0", "online": "true"},
+			map[string]interface{}{"gauge": 0.5},
+			ts,
+			telegraf.Gauge,
+		),
+		testutil.MustMetric("http_requests",
+			map[string]string{"host.name": "a"},
+			map[string]interface{}{"counter": int64(42)},
+			ts,
+			telegraf.Counter,
+		),
+		testutil.MustMetric("queue_size",
+			map[string]string{"host.name": "a"},
+			map[string]interface{}{"gauge": int64(-3)},
+			ts,
+			telegraf.Gauge,
+		),
+		testutil.MustMetric("http_duration",
+			map[string]string{"host.name": "a"},
+			map[string]interface{}{
+				"0.1":   float64(2),
+				"0.5":   float64(5),
+				"1":     float64(7),
+				"+Inf":  float64(8),
+				"count": float64(8),
+				"sum":   4.5,
+			},
+			ts,
+			telegraf.Histogram,
+		),
+		testutil.MustMetric("rpc_latency",
+			map[string]string{"host.name": "a"},
+			map[string]interface{}{
+				"0.5":   0.4,
+				"0.99":  0.9,
+				"count": float64(4),
+				"sum":   float64(2),
+			},
+			ts,
+			telegraf.Summary,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/wlog"
 	"github.com/soniah/gosnmp"
@@ -31,6 +32,11 @@ const sampleConfig = `
   ## Timeout for each request.
   # timeout = "5s"
 
+  ## Paths of MIB files, or directories of MIB files, to resolve OIDs with
+  ## in-process.  When unset, or when an OID is not found in these MIBs, the
+  ## net-snmp tools are used.
+  # path = ["/usr/share/snmp/mibs"]
+
   ## SNMP version; can be 1, 2, or 3.
   # version = 2
 
@@ -68,6 +74,9 @@ const sampleConfig = `
 // execCommand is so tests can mock out exec.Command usage.
 var execCommand = exec.Command
 
+// mibs is so tests can use their own MIB modules.
+var mibs = snmp.DefaultMibs()
+
 // execCmd executes the specified command, returning the STDOUT content.
 // If command exits with error status, the output is captured into the returned error.
 func execCmd(arg0 string, args ...string) ([]byte, error) {
@@ -124,6 +133,9 @@ type Snmp struct {
 	EngineBoots  uint32 `toml:"-"`
 	EngineTime   uint32 `toml:"-"`
 
+	// Path of MIB files or directories to resolve OIDs with in-process.
+	Path []string `toml:"path"`
+
 	Tables []Table `toml:"table"`
 
 	// Name & Fields are the elements of a Table.
@@ -132,6 +144,8 @@ type Snmp struct {
 	Name   string  // deprecated in 1.14; use name_override
 	Fields []Field `toml:"field"`
 
+	Log telegraf.Logger `toml:"-"`
+
 	connectionCache []snmpConnection
 	initialized     bool
 }
@@ -141,6 +155,12 @@ func (s *Snmp) init() error {
 		return nil
 	}
 
+	if len(s.Path) > 0 {
+		if err := mibs.LoadPath(s.Path, s.Log); err != nil {
+			return err
+		}
+	}
+
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
 
 	for i := range s.Tables {
@@ -203,8 +223,8 @@ func (t *Table) init() error {
 }
 
 // initBuild initializes the table if it has an OID configured. If so, the
-// net-snmp tools will be used to look up the OID and auto-populate the table's
-// fields.
+// loaded MIBs or the net-snmp tools will be used to look up the OID and
+// auto-populate the table's fields.
 func (t *Table) initBuild() error {
 	if t.Oid == "" {
 		return nil
@@ -253,6 +273,7 @@ type Field struct {
 	//  "int" will conver the value into an integer.
 	//  "hwaddr" will convert a 6-byte string to a MAC address.
 	//  "ipaddr" will convert the value to an IPv4 or IPv6 address.
+	//  "datetime" will convert a DateAndTime string to a RFC3339 timestamp.
 	Conversion string
 
 	initialized bool
@@ -276,8 +297,6 @@ func (f *Field) init() error {
 		f.Conversion = conversion
 	}
 
-	//TODO use textual convention conversion from the MIB
-
 	f.initialized = true
 	return nil
 }
@@ -731,6 +750,7 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 //  "int" will convert the value into an integer.
 //  "hwaddr" will convert the value into a MAC address.
 //  "ipaddr" will convert the value into into an IP address.
+//  "datetime" will convert a DateAndTime value into a RFC3339 timestamp.
 //  "" will convert a byte slice into a string.
 func fieldConvert(conv string, v interface{}) (interface{}, error) {
 	if conv == "" {
@@ -845,6 +865,21 @@ func fieldConvert(conv string, v interface{}) (interface{}, error) {
 		return v, nil
 	}
 
+	if conv == "datetime" {
+		var bs []byte
+
+		switch vt := v.(type) {
+		case string:
+			bs = []byte(vt)
+		case []byte:
+			bs = vt
+		default:
+			return nil, fmt.Errorf("invalid type (%T) for datetime conversion", v)
+		}
+
+		return snmp.DateAndTime(bs)
+	}
+
 	return nil, fmt.Errorf("invalid conversion type '%s'", conv)
 }
 
@@ -879,6 +914,13 @@ func snmpTable(oid string) (mibName string, oidNum string, oidText string, field
 }
 
 func snmpTableCall(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error) {
+	if mibs.Loaded() {
+		mibName, oidNum, oidText, fields, err = snmpTableMibs(oid)
+		if err == nil {
+			return mibName, oidNum, oidText, fields, nil
+		}
+	}
+
 	mibName, oidNum, oidText, _, err = SnmpTranslate(oid)
 	if err != nil {
 		return "", "", "", nil, Errorf(err, "translating")
@@ -937,6 +979,39 @@ func snmpTableCall(oid string) (mibName string, oidNum string, oidText string, f
 	return mibName, oidNum, oidText, fields, err
 }
 
+// snmpTableMibs resolves the table with the loaded MIBs.  The entry is the
+// only child of the table, the columns are the children of the entry and the
+// objects of its INDEX are tags.
+func snmpTableMibs(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error) {
+	t, err := mibs.Translate(oid)
+	if err != nil {
+		return "", "", "", nil, err
+	}
+
+	entries := t.Node.Children()
+	if len(entries) != 1 {
+		return "", "", "", nil, fmt.Errorf("%s is not a table", oid)
+	}
+	entry := entries[0]
+
+	tags := make(map[string]bool, len(entry.Index))
+	for _, index := range entry.Index {
+		tags[index] = true
+	}
+
+	for _, col := range entry.Children() {
+		if col.Name == "" {
+			continue
+		}
+		fields = append(fields, Field{Name: col.Name, Oid: col.Module + "::" + col.Name, IsTag: tags[col.Name]})
+	}
+	if len(fields) == 0 {
+		return "", "", "", nil, fmt.Errorf("could not find any columns in table")
+	}
+
+	return t.Module, t.OID, t.Name, fields, nil
+}
+
 type snmpTranslateCache struct {
 	mibName    string
 	oidNum     string
@@ -998,6 +1073,12 @@ func SnmpTranslateClear() {
 }
 
 func snmpTranslateCall(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	if mibs.Loaded() {
+		if t, err := mibs.Translate(oid); err == nil {
+			return t.Module, t.OID, t.Name, snmp.NodeConversion(t.Node), nil
+		}
+	}
+
 	var out []byte
 	if strings.ContainsAny(oid, ":abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
 		out, err = execCmd("snmptranslate", "-Td", "-Ob", oid)
@@ -1039,11 +1120,10 @@ func snmpTranslateCall(oid string) (mibName string, oidNum string, oidText strin
 
 		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
 			tc := strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION ")
-			switch tc {
-			case "MacAddress", "PhysAddress":
-				conversion = "hwaddr"
-			case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
-				conversion = "ipaddr"
+			// DateAndTime values are only converted with the MIBs loaded
+			// from path, snmptranslate lookups keep the raw value.
+			if conv := snmp.Conversion(tc); conv != "datetime" {
+				conversion = conv
 			}
 		} else if strings.HasPrefix(line, "::= { ") {
 			objs := strings.TrimPrefix(line, "::= { ")
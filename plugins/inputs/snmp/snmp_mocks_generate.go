@@ -35,6 +35,7 @@ var mockedCommands = [][]string{
 	{"snmptranslate", "-Td", "-Ob", "IF-MIB::ifPhysAddress.1"},
 	{"snmptranslate", "-Td", "-Ob", "BRIDGE-MIB::dot1dTpFdbAddress.1"},
 	{"snmptranslate", "-Td", "-Ob", "TCP-MIB::tcpConnectionLocalAddress.1"},
+	{"snmptranslate", "-Td", "-Ob", "HOST-RESOURCES-MIB::hrSystemDate.0"},
 	{"snmptranslate", "-Td", "TEST::testTable.1"},
 	{"snmptable", "-Ch", "-Cl", "-c", "public", "127.0.0.1", "TEST::testTable"},
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/influxdata/toml"
@@ -120,6 +121,8 @@ func TestFieldInit(t *testing.T) {
 		{"IF-MIB::ifPhysAddress.1", "", "none", ".1.3.6.1.2.1.2.2.1.6.1", "ifPhysAddress.1", "none"},
 		{"BRIDGE-MIB::dot1dTpFdbAddress.1", "", "", ".1.3.6.1.2.1.17.4.3.1.1.1", "dot1dTpFdbAddress.1", "hwaddr"},
 		{"TCP-MIB::tcpConnectionLocalAddress.1", "", "", ".1.3.6.1.2.1.6.19.1.2.1", "tcpConnectionLocalAddress.1", "ipaddr"},
+		// DateAndTime is only converted with the MIBs loaded by the plugin.
+		{"HOST-RESOURCES-MIB::hrSystemDate.0", "", "", ".1.3.6.1.2.1.25.1.2.0", "hrSystemDate.0", ""},
 	}
 
 	for _, txl := range translations {
@@ -230,6 +233,54 @@ func TestSnmpInit_noTranslate(t *testing.T) {
 	assert.Equal(t, false, s.Tables[0].Fields[2].IsTag)
 }
 
+func TestSnmpInit_mibs(t *testing.T) {
+	// override execCommand so it returns exec.ErrNotFound
+	defer func(ec func(string, ...string) *exec.Cmd) { execCommand = ec }(execCommand)
+	execCommand = func(_ string, _ ...string) *exec.Cmd {
+		return exec.Command("snmptranslateExecErrNotFound")
+	}
+	defer func(m *snmp.Mibs) { mibs = m }(mibs)
+	mibs = snmp.NewMibs()
+	snmpTranslateCaches = nil
+	snmpTableCaches = nil
+	defer func() {
+		snmpTranslateCaches = nil
+		snmpTableCaches = nil
+	}()
+
+	s := &Snmp{
+		Path: []string{"testdata/test.mib"},
+		Tables: []Table{
+			{Oid: "TEST::testTable"},
+		},
+		Fields: []Field{
+			{Oid: "TEST::hostname"},
+			{Oid: ".1.0.0.0.1.1.0"},
+			{Oid: ".1.1.1.1", Name: "one"},
+			// not defined by the loaded MIBs, left to snmptranslate
+			{Oid: ".1.3.6.1.2.1.1.3.0"},
+		},
+		Log: testutil.Logger{},
+	}
+
+	err := s.init()
+	require.NoError(t, err)
+
+	require.Equal(t, "testTable", s.Tables[0].Name)
+	require.Equal(t, []Field{
+		{Oid: ".1.0.0.0.1.1", Name: "server", IsTag: true, initialized: true},
+		{Oid: ".1.0.0.0.1.2", Name: "connections", initialized: true},
+		{Oid: ".1.0.0.0.1.3", Name: "latency", initialized: true},
+		{Oid: ".1.0.0.0.1.4", Name: "description", initialized: true},
+	}, s.Tables[0].Fields)
+	require.Equal(t, []Field{
+		{Oid: ".1.0.0.1.1", Name: "hostname", initialized: true},
+		{Oid: ".1.0.0.0.1.1.0", Name: "server.0", initialized: true},
+		{Oid: ".1.1.1.1", Name: "one", initialized: true},
+		{Oid: ".1.3.6.1.2.1.1.3.0", Name: ".1.3.6.1.2.1.1.3.0", initialized: true},
+	}, s.Fields)
+}
+
 func TestGetSNMPConnection_v2(t *testing.T) {
 	s := &Snmp{
 		Agents:    []string{"1.2.3.4:567", "1.2.3.4", "udp://127.0.0.1"},
@@ -713,6 +764,8 @@ func TestFieldConvert(t *testing.T) {
 		{[]byte("abcd"), "ipaddr", "97.98.99.100"},
 		{"abcd", "ipaddr", "97.98.99.100"},
 		{[]byte("abcdefghijklmnop"), "ipaddr", "6162:6364:6566:6768:696a:6b6c:6d6e:6f70"},
+		{[]byte{0x07, 0xe4, 6, 1, 13, 30, 15, 5}, "datetime", "2020-06-01T13:30:15.5Z"},
+		{string([]byte{0x07, 0xe4, 6, 1, 13, 30, 15, 0, '-', 4, 30}), "datetime", "2020-06-01T13:30:15-04:30"},
 	}
 
 	for _, tc := range testTable {
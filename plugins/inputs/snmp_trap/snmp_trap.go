@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/soniah/gosnmp"
@@ -23,9 +24,13 @@ var defaultTimeout = internal.Duration{Duration: time.Second * 5}
 type handler func(*gosnmp.SnmpPacket, *net.UDPAddr)
 type execer func(internal.Duration, string, ...string) ([]byte, error)
 
+// mibs is so tests can use their own MIB modules.
+var mibs = snmp.DefaultMibs()
+
 type mibEntry struct {
-	mibName string
-	oidText string
+	mibName    string
+	oidText    string
+	conversion string
 }
 
 type SnmpTrap struct {
@@ -44,10 +49,22 @@ type SnmpTrap struct {
 	PrivProtocol string `toml:"priv_protocol"`
 	PrivPassword string `toml:"priv_password"`
 
+	// Users are the SNMPv3 USM users in addition to the one above.
+	Users []User `toml:"user"`
+	// EngineID is the SNMPv3 engine ID of the receiver as hex string.
+	EngineID string `toml:"engine_id"`
+
+	// Path of MIB files or directories to translate OIDs with in-process.
+	Path []string `toml:"path"`
+
 	acc      telegraf.Accumulator
-	listener *gosnmp.TrapListener
+	conn     *net.UDPConn
+	wg       sync.WaitGroup
+	handler  handler
 	timeFunc func() time.Time
-	errCh    chan error
+
+	engine *engine
+	users  map[string]*usmUser
 
 	makeHandlerWrapper func(handler) handler
 
@@ -68,10 +85,20 @@ var sampleConfig = `
   ## 1024.  See README.md for details
   ##
   # service_address = "udp://:162"
+  ##
+  ## Paths of MIB files, or directories of MIB files, to translate OIDs with
+  ## in-process.  OIDs not found in these MIBs are translated with
+  ## snmptranslate.
+  # path = ["/usr/share/snmp/mibs"]
+  ##
   ## Timeout running snmptranslate command
   # timeout = "5s"
-  ## Snmp version, defaults to 2c
+  ## Snmp version, defaults to 2c.  With version 3 only SNMPv3 messages are
+  ## accepted, SNMPv1 and SNMPv2c messages are rejected.
   # version = "2c"
+  ## SNMPv3 engine ID of the receiver as hex string, used to acknowledge
+  ## inform requests.  Defaults to a random engine ID chosen at startup.
+  # engine_id = ""
   ## SNMPv3 authentication and encryption options.
   ##
   ## Security Name.
@@ -86,6 +113,15 @@ var sampleConfig = `
   # priv_protocol = ""
   ## Privacy password used for encrypted messages.
   # priv_password = ""
+
+  ## Additional SNMPv3 users; their options are the ones above.
+  # [[inputs.snmp_trap.user]]
+  #   sec_name = "otheruser"
+  #   sec_level = "authPriv"
+  #   auth_protocol = "SHA"
+  #   auth_password = "pass"
+  #   priv_protocol = "AES"
+  #   priv_password = "pass"
 `
 
 func (s *SnmpTrap) SampleConfig() string {
@@ -125,93 +161,51 @@ func realExecCmd(Timeout internal.Duration, arg0 string, args ...string) ([]byte
 func (s *SnmpTrap) Init() error {
 	s.cache = map[string]mibEntry{}
 	s.execCmd = realExecCmd
+
+	if len(s.Path) > 0 {
+		if err := mibs.LoadPath(s.Path, s.Log); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 	s.acc = acc
-	s.listener = gosnmp.NewTrapListener()
-	s.listener.OnNewTrap = makeTrapHandler(s)
-	s.listener.Params = gosnmp.Default
-
-	switch s.Version {
-	case "3":
-		s.listener.Params.Version = gosnmp.Version3
-	case "2c":
-		s.listener.Params.Version = gosnmp.Version2c
-	case "1":
-		s.listener.Params.Version = gosnmp.Version1
-	default:
-		s.listener.Params.Version = gosnmp.Version2c
-	}
-
-	if s.listener.Params.Version == gosnmp.Version3 {
-		s.listener.Params.SecurityModel = gosnmp.UserSecurityModel
-
-		switch strings.ToLower(s.SecLevel) {
-		case "noauthnopriv", "":
-			s.listener.Params.MsgFlags = gosnmp.NoAuthNoPriv
-		case "authnopriv":
-			s.listener.Params.MsgFlags = gosnmp.AuthNoPriv
-		case "authpriv":
-			s.listener.Params.MsgFlags = gosnmp.AuthPriv
-		default:
-			return fmt.Errorf("unknown security level '%s'", s.SecLevel)
-		}
 
-		var authenticationProtocol gosnmp.SnmpV3AuthProtocol
-		switch strings.ToLower(s.AuthProtocol) {
-		case "md5":
-			authenticationProtocol = gosnmp.MD5
-		case "sha":
-			authenticationProtocol = gosnmp.SHA
-		//case "sha224":
-		//	authenticationProtocol = gosnmp.SHA224
-		//case "sha256":
-		//	authenticationProtocol = gosnmp.SHA256
-		//case "sha384":
-		//	authenticationProtocol = gosnmp.SHA384
-		//case "sha512":
-		//	authenticationProtocol = gosnmp.SHA512
-		case "":
-			authenticationProtocol = gosnmp.NoAuth
-		default:
-			return fmt.Errorf("unknown authentication protocol '%s'", s.AuthProtocol)
-		}
+	var err error
+	s.engine, err = newEngine(s.EngineID, s.timeFunc)
+	if err != nil {
+		return err
+	}
 
-		var privacyProtocol gosnmp.SnmpV3PrivProtocol
-		switch strings.ToLower(s.PrivProtocol) {
-		case "aes":
-			privacyProtocol = gosnmp.AES
-		case "des":
-			privacyProtocol = gosnmp.DES
-		case "aes192":
-			privacyProtocol = gosnmp.AES192
-		case "aes192c":
-			privacyProtocol = gosnmp.AES192C
-		case "aes256":
-			privacyProtocol = gosnmp.AES256
-		case "aes256c":
-			privacyProtocol = gosnmp.AES256C
-		case "":
-			privacyProtocol = gosnmp.NoPriv
-		default:
-			return fmt.Errorf("unknown privacy protocol '%s'", s.PrivProtocol)
+	users := s.Users
+	if s.Version == "3" {
+		users = append([]User{{
+			SecLevel:     s.SecLevel,
+			SecName:      s.SecName,
+			AuthProtocol: s.AuthProtocol,
+			AuthPassword: s.AuthPassword,
+			PrivProtocol: s.PrivProtocol,
+			PrivPassword: s.PrivPassword,
+		}}, users...)
+	}
+	s.users = make(map[string]*usmUser, len(users))
+	for _, u := range users {
+		user, err := newUSMUser(u)
+		if err != nil {
+			return err
 		}
-
-		s.listener.Params.SecurityParameters = &gosnmp.UsmSecurityParameters{
-			UserName:                 s.SecName,
-			PrivacyProtocol:          privacyProtocol,
-			PrivacyPassphrase:        s.PrivPassword,
-			AuthenticationPassphrase: s.AuthPassword,
-			AuthenticationProtocol:   authenticationProtocol,
+		if _, ok := s.users[user.name]; ok {
+			return fmt.Errorf("duplicate user '%s'", user.name)
 		}
-
+		s.users[user.name] = user
 	}
 
+	s.handler = makeTrapHandler(s)
 	// wrap the handler, used in unit tests
 	if nil != s.makeHandlerWrapper {
-		s.listener.OnNewTrap = s.makeHandlerWrapper(s.listener.OnNewTrap)
+		s.handler = s.makeHandlerWrapper(s.handler)
 	}
 
 	split := strings.SplitN(s.ServiceAddress, "://", 2)
@@ -222,36 +216,65 @@ func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 	protocol := split[0]
 	addr := split[1]
 
-	// gosnmp.TrapListener currently supports udp only.  For forward
+	// Notifications are received on udp only.  For forward
 	// compatibility, require udp in the service address
 	if protocol != "udp" {
 		return fmt.Errorf("unknown protocol '%s' in '%s'", protocol, s.ServiceAddress)
 	}
 
-	// If (*TrapListener).Listen immediately returns an error we need
-	// to return it from this function.  Use a channel to get it here
-	// from the goroutine.  Buffer one in case Listen returns after
-	// Listening but before our Close is called.
-	s.errCh = make(chan error, 1)
-	go func() {
-		s.errCh <- s.listener.Listen(addr)
-	}()
-
-	select {
-	case <-s.listener.Listening():
-		s.Log.Infof("Listening on %s", s.ServiceAddress)
-	case err := <-s.errCh:
+	udpAddr, err := net.ResolveUDPAddr(protocol, addr)
+	if err != nil {
 		return err
 	}
+	s.conn, err = net.ListenUDP(protocol, udpAddr)
+	if err != nil {
+		return err
+	}
+	s.Log.Infof("Listening on %s", s.ServiceAddress)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.read()
+	}()
 
 	return nil
 }
 
 func (s *SnmpTrap) Stop() {
-	s.listener.Close()
-	err := <-s.errCh
-	if nil != err {
-		s.Log.Errorf("Error stopping trap listener %v", err)
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.wg.Wait()
+}
+
+func (s *SnmpTrap) read() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				s.Log.Errorf("Error reading notification: %v", err)
+			}
+			return
+		}
+
+		packet, err := s.unmarshal(buf[:n], addr)
+		if err != nil {
+			s.Log.Errorf("Error receiving notification from %s: %v", addr.IP, err)
+			continue
+		}
+		if packet == nil {
+			continue
+		}
+
+		if packet.PDUType == gosnmp.InformRequest {
+			if err := s.acknowledge(packet, addr); err != nil {
+				s.Log.Errorf("Error acknowledging inform request from %s: %v", addr.IP, err)
+			}
+		}
+
+		s.handler(packet, addr)
 	}
 }
 
@@ -305,11 +328,6 @@ func makeTrapHandler(s *SnmpTrap) handler {
 
 			var value interface{}
 
-			// todo: format the pdu value based on its snmp type and
-			// the mib's textual convention.  The snmp input plugin
-			// only handles textual convention for ip and mac
-			// addresses
-
 			switch v.Type {
 			case gosnmp.ObjectIdentifier:
 				val, ok := v.Value.(string)
@@ -334,8 +352,6 @@ func makeTrapHandler(s *SnmpTrap) handler {
 					setTrapOid(tags, val, e)
 					continue
 				}
-			default:
-				value = v.Value
 			}
 
 			e, err := s.lookup(v.Name)
@@ -344,6 +360,13 @@ func makeTrapHandler(s *SnmpTrap) handler {
 				return
 			}
 
+			if v.Type != gosnmp.ObjectIdentifier {
+				value = fieldValue(v, e.conversion)
+			}
+			if value == nil {
+				continue
+			}
+
 			name := e.oidText
 
 			fields[name] = value
@@ -368,7 +391,19 @@ func (s *SnmpTrap) lookup(oid string) (e mibEntry, err error) {
 	defer s.cacheLock.Unlock()
 	var ok bool
 	if e, ok = s.cache[oid]; !ok {
-		// cache miss.  exec snmptranslate
+		// cache miss.  translate with the loaded MIBs, then exec
+		// snmptranslate
+		if mibs.Loaded() {
+			if t, err := mibs.Translate(oid); err == nil {
+				e = mibEntry{
+					mibName:    t.Module,
+					oidText:    t.Name,
+					conversion: snmp.NodeConversion(t.Node),
+				}
+				s.cache[oid] = e
+				return e, nil
+			}
+		}
 		e, err = s.snmptranslate(oid)
 		if err == nil {
 			s.cache[oid] = e
@@ -412,5 +447,52 @@ func (s *SnmpTrap) snmptranslate(oid string) (e mibEntry, err error) {
 	}
 	e.mibName = e.oidText[:i]
 	e.oidText = e.oidText[i+2:]
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
+			e.conversion = snmp.Conversion(strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION "))
+			break
+		}
+	}
 	return e, nil
 }
+
+// fieldValue returns the field value of a variable, converted by its type
+// and the conversion of its textual convention, nil if the variable has no
+// value.
+func fieldValue(v gosnmp.SnmpPDU, conversion string) interface{} {
+	switch v.Type {
+	case gosnmp.OctetString:
+		bs, ok := v.Value.([]byte)
+		if !ok {
+			return v.Value
+		}
+		switch conversion {
+		case "hwaddr":
+			return net.HardwareAddr(bs).String()
+		case "ipaddr":
+			if len(bs) == 4 || len(bs) == 16 {
+				return net.IP(bs).String()
+			}
+		case "datetime":
+			if t, err := snmp.DateAndTime(bs); err == nil {
+				return t
+			}
+		}
+		return string(bs)
+	case gosnmp.Integer:
+		if i, ok := v.Value.(int); ok {
+			return int64(i)
+		}
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32, gosnmp.Counter64:
+		return gosnmp.ToBigInt(v.Value).Uint64()
+	case gosnmp.OpaqueFloat:
+		if f, ok := v.Value.(float32); ok {
+			return float64(f)
+		}
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return nil
+	}
+	return v.Value
+}
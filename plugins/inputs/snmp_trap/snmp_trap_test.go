@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -25,8 +26,8 @@ func TestLoad(t *testing.T) {
 	s.load(
 		".1.3.6.1.6.3.1.1.5.1",
 		mibEntry{
-			"SNMPv2-MIB",
-			"coldStart",
+			mibName: "SNMPv2-MIB",
+			oidText: "coldStart",
 		},
 	)
 
@@ -201,22 +202,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -283,15 +284,15 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					".1.2.3.4.5",
 					mibEntry{
-						"valueMIB",
-						"valueOID",
+						mibName: "valueMIB",
+						oidText: "valueOID",
 					},
 				},
 				{
 					".1.2.3.0.55",
 					mibEntry{
-						"enterpriseMIB",
-						"enterpriseOID",
+						mibName: "enterpriseMIB",
+						oidText: "enterpriseOID",
 					},
 				},
 			},
@@ -336,15 +337,15 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					".1.2.3.4.5",
 					mibEntry{
-						"valueMIB",
-						"valueOID",
+						mibName: "valueMIB",
+						oidText: "valueOID",
 					},
 				},
 				{
 					".1.3.6.1.6.3.1.1.5.1",
 					mibEntry{
-						"coldStartMIB",
-						"coldStartOID",
+						mibName: "coldStartMIB",
+						oidText: "coldStartOID",
 					},
 				},
 			},
@@ -393,22 +394,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -457,22 +458,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -520,22 +521,22 @@ func TestReceiveTrap(t *testing.T) {
 					{
 						oid: ".1.3.6.1.6.3.1.1.4.1.0",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"snmpTrapOID.0",
+							mibName: "SNMPv2-MIB",
+							oidText: "snmpTrapOID.0",
 						},
 					},
 					{
 						oid: ".1.3.6.1.6.3.1.1.5.1",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"coldStart",
+							mibName: "SNMPv2-MIB",
+							oidText: "coldStart",
 						},
 					},
 					{
 						oid: ".1.3.6.1.2.1.1.3.0",
 						e: mibEntry{
-							"UNUSED_MIB_NAME",
-							"sysUpTimeInstance",
+							mibName: "UNUSED_MIB_NAME",
+							oidText: "sysUpTimeInstance",
 						},
 					},
 				},
@@ -582,22 +583,22 @@ func TestReceiveTrap(t *testing.T) {
 					{
 						oid: ".1.3.6.1.6.3.1.1.4.1.0",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"snmpTrapOID.0",
+							mibName: "SNMPv2-MIB",
+							oidText: "snmpTrapOID.0",
 						},
 					},
 					{
 						oid: ".1.3.6.1.6.3.1.1.5.1",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"coldStart",
+							mibName: "SNMPv2-MIB",
+							oidText: "coldStart",
 						},
 					},
 					{
 						oid: ".1.3.6.1.2.1.1.3.0",
 						e: mibEntry{
-							"UNUSED_MIB_NAME",
-							"sysUpTimeInstance",
+							mibName: "UNUSED_MIB_NAME",
+							oidText: "sysUpTimeInstance",
 						},
 					},
 				},
@@ -644,22 +645,22 @@ func TestReceiveTrap(t *testing.T) {
 					{
 						oid: ".1.3.6.1.6.3.1.1.4.1.0",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"snmpTrapOID.0",
+							mibName: "SNMPv2-MIB",
+							oidText: "snmpTrapOID.0",
 						},
 					},
 					{
 						oid: ".1.3.6.1.6.3.1.1.5.1",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"coldStart",
+							mibName: "SNMPv2-MIB",
+							oidText: "coldStart",
 						},
 					},
 					{
 						oid: ".1.3.6.1.2.1.1.3.0",
 						e: mibEntry{
-							"UNUSED_MIB_NAME",
-							"sysUpTimeInstance",
+							mibName: "UNUSED_MIB_NAME",
+							oidText: "sysUpTimeInstance",
 						},
 					},
 				},
@@ -706,22 +707,22 @@ func TestReceiveTrap(t *testing.T) {
 					{
 						oid: ".1.3.6.1.6.3.1.1.4.1.0",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"snmpTrapOID.0",
+							mibName: "SNMPv2-MIB",
+							oidText: "snmpTrapOID.0",
 						},
 					},
 					{
 						oid: ".1.3.6.1.6.3.1.1.5.1",
 						e: mibEntry{
-							"SNMPv2-MIB",
-							"coldStart",
+							mibName: "SNMPv2-MIB",
+							oidText: "coldStart",
 						},
 					},
 					{
 						oid: ".1.3.6.1.2.1.1.3.0",
 						e: mibEntry{
-							"UNUSED_MIB_NAME",
-							"sysUpTimeInstance",
+							mibName: "UNUSED_MIB_NAME",
+							oidText: "sysUpTimeInstance",
 						},
 					},
 				},
@@ -768,22 +769,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -830,22 +831,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -894,22 +895,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -958,22 +959,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -1022,22 +1023,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -1086,22 +1087,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -1150,22 +1151,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -1214,22 +1215,22 @@ func TestReceiveTrap(t *testing.T) {
 				{
 					oid: ".1.3.6.1.6.3.1.1.4.1.0",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"snmpTrapOID.0",
+						mibName: "SNMPv2-MIB",
+						oidText: "snmpTrapOID.0",
 					},
 				},
 				{
 					oid: ".1.3.6.1.6.3.1.1.5.1",
 					e: mibEntry{
-						"SNMPv2-MIB",
-						"coldStart",
+						mibName: "SNMPv2-MIB",
+						oidText: "coldStart",
 					},
 				},
 				{
 					oid: ".1.3.6.1.2.1.1.3.0",
 					e: mibEntry{
-						"UNUSED_MIB_NAME",
-						"sysUpTimeInstance",
+						mibName: "UNUSED_MIB_NAME",
+						oidText: "sysUpTimeInstance",
 					},
 				},
 			},
@@ -1287,6 +1288,10 @@ func TestReceiveTrap(t *testing.T) {
 				PrivPassword: tt.privPass,
 			}
 			require.Nil(t, s.Init())
+
+			// Don't look up oid with snmptranslate.
+			s.execCmd = fakeExecCmd
+
 			var acc testutil.Accumulator
 			require.Nil(t, s.Start(&acc))
 			defer s.Stop()
@@ -1297,9 +1302,6 @@ func TestReceiveTrap(t *testing.T) {
 				s.load(entry.oid, entry.e)
 			}
 
-			// Don't look up oid with snmptranslate.
-			s.execCmd = fakeExecCmd
-
 			// Send the trap
 			sendTrap(t, port, now, tt.trap, tt.version, tt.secLevel, tt.secName, tt.authProto, tt.authPass, tt.privProto, tt.privPass, tt.contextName, tt.engineID)
 
@@ -1318,3 +1320,114 @@ func TestReceiveTrap(t *testing.T) {
 	}
 
 }
+
+func TestReceiveTrapMIB(t *testing.T) {
+	defer func(m *snmp.Mibs) { mibs = m }(mibs)
+	mibs = snmp.NewMibs()
+
+	const port = 12399
+	fakeTime := time.Unix(456456456, 456)
+
+	received := make(chan int)
+	s := &SnmpTrap{
+		ServiceAddress: "udp://:" + strconv.Itoa(port),
+		makeHandlerWrapper: func(f handler) handler {
+			return func(p *gosnmp.SnmpPacket, a *net.UDPAddr) {
+				f(p, a)
+				received <- 0
+			}
+		},
+		timeFunc: func() time.Time {
+			return fakeTime
+		},
+		Path:    []string{"testdata"},
+		Log:     testutil.Logger{},
+		Version: "2c",
+	}
+	require.NoError(t, s.Init())
+
+	// defined by SNMPv2-MIB, which is not among the test MIBs, so these are
+	// translated by snmptranslate
+	translated := map[string]string{
+		".1.3.6.1.6.3.1.1.4.1.0": "SNMPv2-MIB::snmpTrapOID.0",
+		".1.3.6.1.2.1.1.3.0":     "SNMPv2-MIB::sysUpTimeInstance",
+	}
+	// Init sets execCmd, replace it before the listener is started
+	s.execCmd = func(timeout internal.Duration, cmd string, args ...string) ([]byte, error) {
+		if out, ok := translated[args[len(args)-1]]; ok && cmd == "snmptranslate" {
+			return []byte(out + "\n"), nil
+		}
+		return fakeExecCmd(timeout, cmd, args...)
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	defer s.Stop()
+
+	trap := gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(42)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.99999.0.1"},
+			{Name: ".1.3.6.1.4.1.99999.1.1.0", Type: gosnmp.OctetString, Value: "eth0"},
+			{Name: ".1.3.6.1.4.1.99999.1.2.0", Type: gosnmp.OctetString, Value: []byte{0, 0x1b, 0x21, 0x3c, 0x4d, 0x5e}},
+			{Name: ".1.3.6.1.4.1.99999.1.3.0", Type: gosnmp.OctetString, Value: []byte{0x07, 0xe4, 6, 1, 13, 30, 15, 0, '+', 2, 0}},
+			{Name: ".1.3.6.1.4.1.99999.1.4.0", Type: gosnmp.Counter32, Value: uint32(7)},
+		},
+	}
+	sendTrap(t, port, 42, trap, gosnmp.Version2c, "", "", "", "", "", "", "", "")
+
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for trap to be received")
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"snmp_trap",
+			map[string]string{
+				"oid":     ".1.3.6.1.4.1.99999.0.1",
+				"name":    "testNotification",
+				"mib":     "TEST-TRAP-MIB",
+				"version": "2c",
+				"source":  "127.0.0.1",
+			},
+			map[string]interface{}{
+				"sysUpTimeInstance": uint64(42),
+				"testName.0":        "eth0",
+				"testAddress.0":     "00:1b:21:3c:4d:5e",
+				"testChanged.0":     "2020-06-01T13:30:15+02:00",
+				"testCount.0":       uint64(7),
+			},
+			fakeTime,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestFieldValue(t *testing.T) {
+	tests := []struct {
+		pdu        gosnmp.SnmpPDU
+		conversion string
+		expected   interface{}
+	}{
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("foo")}, "", "foo"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("abcdef")}, "hwaddr", "61:62:63:64:65:66"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{10, 0, 0, 1}}, "ipaddr", "10.0.0.1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("abc")}, "ipaddr", "abc"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x07, 0xe4, 6, 1, 13, 30, 15, 5}}, "datetime", "2020-06-01T13:30:15.5Z"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -3}, "", int64(-3)},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter32, Value: uint(3)}, "", uint64(3)},
+		{gosnmp.SnmpPDU{Type: gosnmp.Gauge32, Value: uint(3)}, "", uint64(3)},
+		{gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(3)}, "", uint64(3)},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1 << 40)}, "", uint64(1 << 40)},
+		{gosnmp.SnmpPDU{Type: gosnmp.OpaqueFloat, Value: float32(0.5)}, "", float64(0.5)},
+		{gosnmp.SnmpPDU{Type: gosnmp.IPAddress, Value: "10.0.0.1"}, "", "10.0.0.1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Null}, "", nil},
+		{gosnmp.SnmpPDU{Type: gosnmp.NoSuchObject}, "", nil},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, fieldValue(tt.pdu, tt.conversion), "%v %v", tt.pdu.Type, tt.pdu.Value)
+	}
+}
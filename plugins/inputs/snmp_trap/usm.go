@@ -0,0 +1,437 @@
+package snmp_trap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/soniah/gosnmp"
+)
+
+// maxMessageSize is the largest SNMP message received, the maximum size of
+// an UDP datagram.
+const maxMessageSize = 65535
+
+// usmStatsUnknownEngineIDs is the OID of the counter reported to senders
+// using an engine ID other than the one of the receiver.
+const usmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+
+// discard is the logger of gosnmp, its debug output is not useful to users.
+var discard = log.New(ioutil.Discard, "", 0)
+
+// User holds the configuration of a SNMPv3 USM user.
+type User struct {
+	// Values: "noAuthNoPriv", "authNoPriv", "authPriv"
+	SecLevel string `toml:"sec_level"`
+	SecName  string `toml:"sec_name"`
+	// Values: "MD5", "SHA", "". Default: ""
+	AuthProtocol string `toml:"auth_protocol"`
+	AuthPassword string `toml:"auth_password"`
+	// Values: "DES", "AES", "AES192", "AES192C", "AES256", "AES256C", "".
+	// Default: ""
+	PrivProtocol string `toml:"priv_protocol"`
+	PrivPassword string `toml:"priv_password"`
+}
+
+type usmUser struct {
+	name     string
+	flags    gosnmp.SnmpV3MsgFlags
+	auth     gosnmp.SnmpV3AuthProtocol
+	authPass string
+	priv     gosnmp.SnmpV3PrivProtocol
+	privPass string
+}
+
+func newUSMUser(u User) (*usmUser, error) {
+	user := &usmUser{
+		name:     u.SecName,
+		authPass: u.AuthPassword,
+		privPass: u.PrivPassword,
+	}
+
+	switch strings.ToLower(u.SecLevel) {
+	case "noauthnopriv", "":
+		user.flags = gosnmp.NoAuthNoPriv
+	case "authnopriv":
+		user.flags = gosnmp.AuthNoPriv
+	case "authpriv":
+		user.flags = gosnmp.AuthPriv
+	default:
+		return nil, fmt.Errorf("unknown security level '%s'", u.SecLevel)
+	}
+
+	switch strings.ToLower(u.AuthProtocol) {
+	case "md5":
+		user.auth = gosnmp.MD5
+	case "sha":
+		user.auth = gosnmp.SHA
+	case "":
+		user.auth = gosnmp.NoAuth
+	default:
+		return nil, fmt.Errorf("unknown authentication protocol '%s'", u.AuthProtocol)
+	}
+
+	switch strings.ToLower(u.PrivProtocol) {
+	case "aes":
+		user.priv = gosnmp.AES
+	case "des":
+		user.priv = gosnmp.DES
+	case "aes192":
+		user.priv = gosnmp.AES192
+	case "aes192c":
+		user.priv = gosnmp.AES192C
+	case "aes256":
+		user.priv = gosnmp.AES256
+	case "aes256c":
+		user.priv = gosnmp.AES256C
+	case "":
+		user.priv = gosnmp.NoPriv
+	default:
+		return nil, fmt.Errorf("unknown privacy protocol '%s'", u.PrivProtocol)
+	}
+
+	if user.flags&gosnmp.AuthNoPriv != 0 && user.auth == gosnmp.NoAuth {
+		return nil, fmt.Errorf("user '%s': security level '%s' requires an authentication protocol", u.SecName, u.SecLevel)
+	}
+	if user.flags&gosnmp.AuthPriv == gosnmp.AuthPriv && user.priv == gosnmp.NoPriv {
+		return nil, fmt.Errorf("user '%s': security level '%s' requires a privacy protocol", u.SecName, u.SecLevel)
+	}
+
+	return user, nil
+}
+
+// params returns the parameters to decode the messages of the user.  The
+// keys are localized to the engine ID of each message, so they are created
+// anew for every message.
+func (u *usmUser) params() *gosnmp.GoSNMP {
+	return &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      u.flags,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 u.name,
+			AuthenticationProtocol:   u.auth,
+			AuthenticationPassphrase: u.authPass,
+			PrivacyProtocol:          u.priv,
+			PrivacyPassphrase:        u.privPass,
+			Logger:                   discard,
+		},
+		Logger: discard,
+	}
+}
+
+// engine is the SNMPv3 engine of the receiver, the authoritative engine of
+// inform requests.
+type engine struct {
+	id               string
+	boots            uint32
+	start            time.Time
+	now              func() time.Time
+	unknownEngineIDs uint32
+}
+
+// newEngine returns the engine with the hex encoded ID, or a random ID if
+// empty.
+func newEngine(id string, now func() time.Time) (*engine, error) {
+	e := &engine{
+		boots: 1,
+		now:   now,
+	}
+	if e.now == nil {
+		e.now = time.Now
+	}
+	e.start = e.now()
+
+	if id != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid engine ID '%s': %v", id, err)
+		}
+		if len(b) < 5 || len(b) > 32 {
+			return nil, fmt.Errorf("invalid engine ID '%s': length must be between 5 and 32 bytes", id)
+		}
+		e.id = string(b)
+		return e, nil
+	}
+
+	// RFC 3411 format: enterprise number with the high bit set, format 5
+	// (administratively assigned octets) and random octets
+	b := make([]byte, 13)
+	copy(b, []byte{0x80, 0x00, 0x00, 0x00, 0x05})
+	if _, err := rand.Read(b[5:]); err != nil {
+		return nil, err
+	}
+	e.id = string(b)
+	return e, nil
+}
+
+func (e *engine) time() uint32 {
+	return uint32(e.now().Sub(e.start) / time.Second)
+}
+
+// unmarshal decodes a notification received from addr.  SNMPv3 messages are
+// authenticated and decrypted with the parameters of their user, SNMPv1 and
+// SNMPv2c messages are rejected if the receiver is configured for SNMPv3.  A
+// nil packet without error is returned for messages answered here, i.e.
+// engine ID discovery.
+func (s *SnmpTrap) unmarshal(msg []byte, addr *net.UDPAddr) (*gosnmp.SnmpPacket, error) {
+	h, err := parseHeader(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.version != gosnmp.Version3 {
+		if s.Version == "3" {
+			return nil, fmt.Errorf("SNMPv%s message rejected, only SNMPv3 is accepted", h.version)
+		}
+
+		params := &gosnmp.GoSNMP{Version: h.version, Logger: discard}
+		packet := params.UnmarshalTrap(msg)
+		if packet == nil {
+			return nil, errors.New("invalid message")
+		}
+		return packet, nil
+	}
+
+	if h.engineID == "" {
+		// discovery of the engine ID, by senders of inform requests
+		if h.flags&gosnmp.Reportable == 0 {
+			return nil, nil
+		}
+		params := &gosnmp.GoSNMP{
+			Version:            gosnmp.Version3,
+			SecurityModel:      gosnmp.UserSecurityModel,
+			SecurityParameters: &gosnmp.UsmSecurityParameters{Logger: discard},
+			Logger:             discard,
+		}
+		packet := params.UnmarshalTrap(msg)
+		if packet == nil {
+			return nil, errors.New("invalid discovery message")
+		}
+		return nil, s.report(h.msgID, packet.RequestID, addr)
+	}
+
+	if len(s.users) == 0 {
+		return nil, errors.New("no SNMPv3 user configured")
+	}
+	user, ok := s.users[h.userName]
+	if !ok {
+		return nil, fmt.Errorf("unknown user '%s'", h.userName)
+	}
+	if h.flags&gosnmp.AuthPriv < user.flags {
+		return nil, fmt.Errorf("security level of user '%s' is too low", h.userName)
+	}
+
+	packet := user.params().UnmarshalTrap(msg)
+	if packet == nil {
+		return nil, fmt.Errorf("authentication or decryption failed for user '%s'", h.userName)
+	}
+
+	// the receiver is the authoritative engine of inform requests, senders
+	// using another engine ID need to discover it again
+	if packet.PDUType == gosnmp.InformRequest && h.engineID != s.engine.id {
+		return nil, s.report(h.msgID, packet.RequestID, addr)
+	}
+	return packet, nil
+}
+
+// acknowledge sends the response to an inform request, echoing its variable
+// bindings.
+func (s *SnmpTrap) acknowledge(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) error {
+	response := &gosnmp.SnmpPacket{
+		Version:   packet.Version,
+		Community: packet.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: packet.RequestID,
+		Variables: packet.Variables,
+	}
+
+	if packet.Version == gosnmp.Version3 {
+		sp, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if !ok {
+			return errors.New("unsupported security model")
+		}
+		params := &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    sp.AuthoritativeEngineID,
+			AuthoritativeEngineBoots: s.engine.boots,
+			AuthoritativeEngineTime:  s.engine.time(),
+			UserName:                 sp.UserName,
+			AuthenticationProtocol:   sp.AuthenticationProtocol,
+			PrivacyProtocol:          sp.PrivacyProtocol,
+			SecretKey:                sp.SecretKey,
+			PrivacyKey:               sp.PrivacyKey,
+			Logger:                   discard,
+		}
+		if packet.MsgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+			params.PrivacyParameters = make([]byte, 8)
+			if _, err := rand.Read(params.PrivacyParameters); err != nil {
+				return err
+			}
+		}
+
+		response.MsgID = packet.MsgID
+		response.MsgFlags = packet.MsgFlags &^ gosnmp.Reportable
+		response.SecurityModel = gosnmp.UserSecurityModel
+		response.SecurityParameters = params
+		response.ContextEngineID = packet.ContextEngineID
+		response.ContextName = packet.ContextName
+	}
+
+	msg, err := response.MarshalMsg()
+	if err != nil {
+		// not all types of values can be encoded, the variable
+		// bindings of the response are informational only
+		response.Variables = nil
+		if msg, err = response.MarshalMsg(); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.conn.WriteToUDP(msg, addr)
+	return err
+}
+
+// report sends the engine ID of the receiver to a SNMPv3 sender, as response
+// to an engine ID discovery request.
+func (s *SnmpTrap) report(msgID, requestID uint32, addr *net.UDPAddr) error {
+	count := atomic.AddUint32(&s.engine.unknownEngineIDs, 1)
+	report := &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgID:         msgID,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    s.engine.id,
+			AuthoritativeEngineBoots: s.engine.boots,
+			AuthoritativeEngineTime:  s.engine.time(),
+			Logger:                   discard,
+		},
+		ContextEngineID: s.engine.id,
+		PDUType:         gosnmp.Report,
+		RequestID:       requestID,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: usmStatsUnknownEngineIDs, Type: gosnmp.Counter32, Value: count},
+		},
+	}
+
+	msg, err := report.MarshalMsg()
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.WriteToUDP(msg, addr)
+	return err
+}
+
+// header is the part of a message needed to choose how to decode it.
+type header struct {
+	version  gosnmp.SnmpVersion
+	msgID    uint32
+	flags    gosnmp.SnmpV3MsgFlags
+	engineID string
+	userName string
+}
+
+// parseHeader parses the version of a message, and for SNMPv3 messages the
+// message ID, flags, authoritative engine ID and user name of the USM
+// security parameters.
+func parseHeader(msg []byte) (*header, error) {
+	errInvalid := errors.New("invalid message")
+
+	tag, msg, _, err := berNext(msg)
+	if err != nil || tag != byte(gosnmp.Sequence) {
+		return nil, errInvalid
+	}
+	tag, version, msg, err := berNext(msg)
+	if err != nil || tag != byte(gosnmp.Integer) || len(version) != 1 {
+		return nil, errInvalid
+	}
+	h := &header{version: gosnmp.SnmpVersion(version[0])}
+	if h.version != gosnmp.Version3 {
+		return h, nil
+	}
+
+	// msgGlobalData: msgID, msgMaxSize, msgFlags, msgSecurityModel
+	tag, global, msg, err := berNext(msg)
+	if err != nil || tag != byte(gosnmp.Sequence) {
+		return nil, errInvalid
+	}
+	tag, msgID, global, err := berNext(global)
+	if err != nil || tag != byte(gosnmp.Integer) || len(msgID) > 5 {
+		return nil, errInvalid
+	}
+	for _, b := range msgID {
+		h.msgID = h.msgID<<8 | uint32(b)
+	}
+	if _, _, global, err = berNext(global); err != nil {
+		return nil, errInvalid
+	}
+	tag, flags, global, err := berNext(global)
+	if err != nil || tag != byte(gosnmp.OctetString) || len(flags) != 1 {
+		return nil, errInvalid
+	}
+	h.flags = gosnmp.SnmpV3MsgFlags(flags[0])
+	tag, model, _, err := berNext(global)
+	if err != nil || tag != byte(gosnmp.Integer) || len(model) != 1 || gosnmp.SnmpV3SecurityModel(model[0]) != gosnmp.UserSecurityModel {
+		return nil, errors.New("unsupported security model")
+	}
+
+	// msgSecurityParameters: engine ID, boots, time, user name, ...
+	tag, params, _, err := berNext(msg)
+	if err != nil || tag != byte(gosnmp.OctetString) {
+		return nil, errInvalid
+	}
+	tag, params, _, err = berNext(params)
+	if err != nil || tag != byte(gosnmp.Sequence) {
+		return nil, errInvalid
+	}
+	tag, engineID, params, err := berNext(params)
+	if err != nil || tag != byte(gosnmp.OctetString) {
+		return nil, errInvalid
+	}
+	h.engineID = string(engineID)
+	for i := 0; i < 2; i++ {
+		if _, _, params, err = berNext(params); err != nil {
+			return nil, errInvalid
+		}
+	}
+	tag, userName, _, err := berNext(params)
+	if err != nil || tag != byte(gosnmp.OctetString) {
+		return nil, errInvalid
+	}
+	h.userName = string(userName)
+
+	return h, nil
+}
+
+// berNext splits the first BER encoded element off b, returning its tag,
+// its contents and the remaining bytes.
+func berNext(b []byte) (tag byte, value []byte, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errors.New("truncated")
+	}
+	tag = b[0]
+	length := int(b[1])
+	b = b[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 || len(b) < n {
+			return 0, nil, nil, errors.New("invalid length")
+		}
+		length = 0
+		for _, c := range b[:n] {
+			length = length<<8 | int(c)
+		}
+		b = b[n:]
+	}
+	if len(b) < length {
+		return 0, nil, nil, errors.New("truncated")
+	}
+	return tag, b[:length], b[length:], nil
+}
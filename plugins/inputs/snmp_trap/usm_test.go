@@ -0,0 +1,301 @@
+package snmp_trap
+
+import (
+	"crypto/sha1"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+// localizedSHAKey returns the authentication key of the password localized
+// to the engine ID, as described in RFC 3414 A.2.2.
+func localizedSHAKey(password, engineID string) []byte {
+	h := sha1.New()
+	for i := 0; i < 1048576; i++ {
+		h.Write([]byte{password[i%len(password)]})
+	}
+	ku := h.Sum(nil)
+
+	h.Reset()
+	h.Write(ku)
+	h.Write([]byte(engineID))
+	h.Write(ku)
+	return h.Sum(nil)
+}
+
+// v3Message returns a SNMPv3 message of the user, authenticated with SHA if
+// the password is set.
+func v3Message(t *testing.T, pduType gosnmp.PDUType, engineID, user, password string) []byte {
+	flags := gosnmp.NoAuthNoPriv
+	sp := &gosnmp.UsmSecurityParameters{
+		AuthoritativeEngineID:    engineID,
+		AuthoritativeEngineBoots: 1,
+		AuthoritativeEngineTime:  1,
+		UserName:                 user,
+		Logger:                   discard,
+	}
+	if password != "" {
+		flags = gosnmp.AuthNoPriv
+		sp.AuthenticationProtocol = gosnmp.SHA
+		sp.SecretKey = localizedSHAKey(password, engineID)
+	}
+
+	packet := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgID:              7,
+		MsgFlags:           flags | gosnmp.Reportable,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: sp,
+		ContextEngineID:    engineID,
+		PDUType:            pduType,
+		RequestID:          42,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+		},
+	}
+	msg, err := packet.MarshalMsg()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestUnmarshalUsers(t *testing.T) {
+	s := &SnmpTrap{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Version:        "3",
+		SecName:        "alice",
+		SecLevel:       "authNoPriv",
+		AuthProtocol:   "SHA",
+		AuthPassword:   "alicepass",
+		Users: []User{
+			{SecName: "bob", SecLevel: "authNoPriv", AuthProtocol: "SHA", AuthPassword: "bobpass"},
+			{SecName: "carol"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	defer s.Stop()
+
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		err      string
+	}{
+		{name: "first user", user: "alice", password: "alicepass"},
+		{name: "second user", user: "bob", password: "bobpass"},
+		{name: "no authentication", user: "carol"},
+		{name: "wrong password", user: "bob", password: "alicepass", err: "authentication or decryption failed for user 'bob'"},
+		{name: "unauthenticated", user: "bob", err: "security level of user 'bob' is too low"},
+		{name: "unknown user", user: "dave", err: "unknown user 'dave'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, err := s.unmarshal(v3Message(t, gosnmp.SNMPv2Trap, "sender", tt.user, tt.password), addr)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, gosnmp.SNMPv2Trap, packet.PDUType)
+			require.Len(t, packet.Variables, 2)
+		})
+	}
+
+	trap := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		PDUType:   gosnmp.SNMPv2Trap,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+		},
+	}
+	msg, err := trap.MarshalMsg()
+	require.NoError(t, err)
+	_, err = s.unmarshal(msg, addr)
+	require.EqualError(t, err, "SNMPv2c message rejected, only SNMPv3 is accepted")
+}
+
+func TestUserConfig(t *testing.T) {
+	_, err := newUSMUser(User{SecName: "a", SecLevel: "authPriv", AuthProtocol: "SHA"})
+	require.EqualError(t, err, "user 'a': security level 'authPriv' requires a privacy protocol")
+	_, err = newUSMUser(User{SecName: "a", SecLevel: "authNoPriv"})
+	require.EqualError(t, err, "user 'a': security level 'authNoPriv' requires an authentication protocol")
+	_, err = newUSMUser(User{SecName: "a", AuthProtocol: "SHA512"})
+	require.EqualError(t, err, "unknown authentication protocol 'SHA512'")
+
+	s := &SnmpTrap{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Users:          []User{{SecName: "a"}, {SecName: "a"}},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+	require.EqualError(t, s.Start(&testutil.Accumulator{}), "duplicate user 'a'")
+}
+
+func TestEngineID(t *testing.T) {
+	e, err := newEngine("0x80001f8880abcdef", nil)
+	require.NoError(t, err)
+	require.Equal(t, "\x80\x00\x1f\x88\x80\xab\xcd\xef", e.id)
+
+	_, err = newEngine("8000", nil)
+	require.Error(t, err)
+
+	e, err = newEngine("", nil)
+	require.NoError(t, err)
+	require.Len(t, e.id, 13)
+}
+
+func TestParseHeader(t *testing.T) {
+	h, err := parseHeader(v3Message(t, gosnmp.InformRequest, "engine", "user", ""))
+	require.NoError(t, err)
+	require.Equal(t, &header{
+		version:  gosnmp.Version3,
+		msgID:    7,
+		flags:    gosnmp.NoAuthNoPriv | gosnmp.Reportable,
+		engineID: "engine",
+		userName: "user",
+	}, h)
+
+	for _, msg := range [][]byte{nil, {0x30}, {0x30, 0x03, 0x02, 0x01}, {0x04, 0x00}} {
+		_, err := parseHeader(msg)
+		require.Error(t, err)
+	}
+}
+
+// exchange sends the message to the port and returns the response.
+func exchange(t *testing.T, port int, msg []byte) []byte {
+	conn, err := net.Dial("udp", "127.0.0.1:"+strconv.Itoa(port))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(msg)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, maxMessageSize)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func startInformReceiver(t *testing.T, port int, version string, received chan int) (*SnmpTrap, *testutil.Accumulator) {
+	s := &SnmpTrap{
+		ServiceAddress: "udp://:" + strconv.Itoa(port),
+		makeHandlerWrapper: func(f handler) handler {
+			return func(p *gosnmp.SnmpPacket, a *net.UDPAddr) {
+				f(p, a)
+				received <- 0
+			}
+		},
+		timeFunc:     time.Now,
+		Log:          testutil.Logger{},
+		Version:      version,
+		SecName:      "user",
+		SecLevel:     "authNoPriv",
+		AuthProtocol: "SHA",
+		AuthPassword: "password",
+	}
+	require.NoError(t, s.Init())
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+
+	s.load(".1.3.6.1.6.3.1.1.4.1.0", mibEntry{mibName: "SNMPv2-MIB", oidText: "snmpTrapOID.0"})
+	s.load(".1.3.6.1.6.3.1.1.5.1", mibEntry{mibName: "SNMPv2-MIB", oidText: "coldStart"})
+	s.load(".1.3.6.1.2.1.1.3.0", mibEntry{mibName: "SNMPv2-MIB", oidText: "sysUpTimeInstance"})
+	s.execCmd = fakeExecCmd
+	return s, acc
+}
+
+func TestInform_v2c(t *testing.T) {
+	const port = 12399
+	received := make(chan int, 1)
+	s, acc := startInformReceiver(t, port, "2c", received)
+	defer s.Stop()
+
+	inform := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		PDUType:   gosnmp.InformRequest,
+		RequestID: 42,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+		},
+	}
+	msg, err := inform.MarshalMsg()
+	require.NoError(t, err)
+
+	params := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Logger: discard}
+	response := params.UnmarshalTrap(exchange(t, port, msg))
+	require.NotNil(t, response)
+	require.Equal(t, gosnmp.GetResponse, response.PDUType)
+	require.Equal(t, uint32(42), response.RequestID)
+	require.Equal(t, "public", response.Community)
+	require.Len(t, response.Variables, 2)
+
+	<-received
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "coldStart", acc.Metrics[0].Tags["name"])
+}
+
+func TestInform_v3(t *testing.T) {
+	const port = 12399
+	received := make(chan int, 1)
+	s, acc := startInformReceiver(t, port, "3", received)
+	defer s.Stop()
+
+	// discover the engine ID of the receiver
+	discovery := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgID:              1,
+		MsgFlags:           gosnmp.NoAuthNoPriv | gosnmp.Reportable,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{Logger: discard},
+		PDUType:            gosnmp.GetRequest,
+		RequestID:          1,
+	}
+	msg, err := discovery.MarshalMsg()
+	require.NoError(t, err)
+
+	params := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{Logger: discard},
+		Logger:             discard,
+	}
+	report := params.UnmarshalTrap(exchange(t, port, msg))
+	require.NotNil(t, report)
+	require.Equal(t, gosnmp.Report, report.PDUType)
+	require.Equal(t, uint32(1), report.RequestID)
+	require.Equal(t, usmStatsUnknownEngineIDs, report.Variables[0].Name)
+	engineID := report.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID
+	require.Equal(t, s.engine.id, engineID)
+
+	// an inform request sent to another engine is reported
+	report = params.UnmarshalTrap(exchange(t, port, v3Message(t, gosnmp.InformRequest, "other", "user", "password")))
+	require.NotNil(t, report)
+	require.Equal(t, gosnmp.Report, report.PDUType)
+	require.Equal(t, uint32(42), report.RequestID)
+
+	// the inform request is acknowledged with an authenticated response
+	user, err := newUSMUser(User{SecName: "user", SecLevel: "authNoPriv", AuthProtocol: "SHA", AuthPassword: "password"})
+	require.NoError(t, err)
+	response := user.params().UnmarshalTrap(exchange(t, port, v3Message(t, gosnmp.InformRequest, engineID, "user", "password")))
+	require.NotNil(t, response)
+	require.Equal(t, gosnmp.GetResponse, response.PDUType)
+	require.Equal(t, uint32(42), response.RequestID)
+	require.Equal(t, uint32(7), response.MsgID)
+
+	<-received
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "coldStart", acc.Metrics[0].Tags["name"])
+	require.Equal(t, "3", acc.Metrics[0].Tags["version"])
+}
@@ -0,0 +1,433 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/stdlib"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Database driver, one of:
+  ##   mysql     - MySQL, MariaDB and ClickHouse (MySQL interface)
+  ##   pgx       - PostgreSQL
+  ##   sqlserver - Microsoft SQL Server
+  ##   sqlite3   - SQLite, only available in builds with cgo enabled
+  driver = "mysql"
+
+  ## Data source name passed to the driver.
+  ##   mysql:     see https://github.com/go-sql-driver/mysql#dsn-data-source-name
+  ##   pgx:       see https://godoc.org/github.com/jackc/pgx#ParseDSN
+  ##   sqlserver: see https://github.com/denisenkom/go-mssqldb#connection-parameters-and-dsn
+  ##   sqlite3:   see https://github.com/mattn/go-sqlite3#connection-string
+  data_source_name = "telegraf:password@tcp(localhost:3306)/"
+
+  ## Timeout for each query.
+  # timeout = "5s"
+
+  ## Connection pool settings; zero keeps the default of the database/sql
+  ## package.  A negative max_idle_connections keeps no idle connections.
+  # max_open_connections = 0
+  # max_idle_connections = 0
+  # connection_max_lifetime = "0s"
+
+  ## Queries to run each interval, each producing one metric per row.
+  [[inputs.sql.query]]
+    ## Query to run, or file with the query to run.
+    query = "SELECT host, state, COUNT(*) AS connections FROM processes GROUP BY host, state"
+    # query_script = "/etc/telegraf/query.sql"
+
+    ## Measurement name of the metrics.
+    # measurement = "sql"
+
+    ## Columns used as tags.
+    tag_columns = ["host", "state"]
+
+    ## Columns used as fields; by default all columns which are neither tag
+    ## nor time columns.
+    # field_columns = []
+
+    ## Conversion of field columns to one of the types "integer", "unsigned",
+    ## "float", "boolean" or "string".  Other columns keep the type returned
+    ## by the driver.
+    # field_types = {connections = "integer"}
+
+    ## Column with the time of the metric, and its format if the driver
+    ## does not return a time: one of "unix", "unix_ms", "unix_us",
+    ## "unix_ns", or a Go time layout.  Without time column the metric time
+    ## is the gather time.
+    # time_column = ""
+    # time_format = "unix"
+`
+
+type SQL struct {
+	Driver                string            `toml:"driver"`
+	DataSourceName        string            `toml:"data_source_name"`
+	Timeout               internal.Duration `toml:"timeout"`
+	MaxOpenConnections    int               `toml:"max_open_connections"`
+	MaxIdleConnections    int               `toml:"max_idle_connections"`
+	ConnectionMaxLifetime internal.Duration `toml:"connection_max_lifetime"`
+	Queries               []Query           `toml:"query"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	db *sql.DB
+}
+
+// Query is a query producing one metric per row.
+type Query struct {
+	Query        string            `toml:"query"`
+	Script       string            `toml:"query_script"`
+	Measurement  string            `toml:"measurement"`
+	TagColumns   []string          `toml:"tag_columns"`
+	FieldColumns []string          `toml:"field_columns"`
+	FieldTypes   map[string]string `toml:"field_types"`
+	TimeColumn   string            `toml:"time_column"`
+	TimeFormat   string            `toml:"time_format"`
+
+	tags   map[string]bool
+	fields map[string]bool
+}
+
+func (s *SQL) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SQL) Description() string {
+	return "Read metrics from SQL queries on any database/sql driver"
+}
+
+func (s *SQL) Init() error {
+	known := sql.Drivers()
+	i := sort.SearchStrings(known, s.Driver)
+	if i == len(known) || known[i] != s.Driver {
+		return fmt.Errorf("unsupported driver %q", s.Driver)
+	}
+
+	if len(s.Queries) == 0 {
+		return fmt.Errorf("no queries configured")
+	}
+
+	for i := range s.Queries {
+		q := &s.Queries[i]
+		if q.Query == "" {
+			if q.Script == "" {
+				return fmt.Errorf("query %d: either query or query_script is required", i+1)
+			}
+			b, err := ioutil.ReadFile(q.Script)
+			if err != nil {
+				return fmt.Errorf("query %d: %v", i+1, err)
+			}
+			q.Query = string(b)
+		}
+		if q.Measurement == "" {
+			q.Measurement = "sql"
+		}
+		if q.TimeFormat == "" {
+			q.TimeFormat = "unix"
+		}
+
+		for column, typ := range q.FieldTypes {
+			switch typ {
+			case "integer", "unsigned", "float", "boolean", "string":
+			default:
+				return fmt.Errorf("query %d: unknown type %q of column %q", i+1, typ, column)
+			}
+		}
+
+		q.tags = make(map[string]bool, len(q.TagColumns))
+		for _, column := range q.TagColumns {
+			q.tags[column] = true
+		}
+		q.fields = make(map[string]bool, len(q.FieldColumns))
+		for _, column := range q.FieldColumns {
+			q.fields[column] = true
+		}
+	}
+	return nil
+}
+
+func (s *SQL) Start(_ telegraf.Accumulator) error {
+	db, err := sql.Open(s.Driver, s.DataSourceName)
+	if err != nil {
+		return err
+	}
+
+	db.SetMaxOpenConns(s.MaxOpenConnections)
+	if s.MaxIdleConnections != 0 {
+		db.SetMaxIdleConns(s.MaxIdleConnections)
+	}
+	db.SetConnMaxLifetime(s.ConnectionMaxLifetime.Duration)
+
+	s.db = db
+	return nil
+}
+
+func (s *SQL) Stop() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+func (s *SQL) Gather(acc telegraf.Accumulator) error {
+	for i := range s.Queries {
+		if err := s.gatherQuery(acc, &s.Queries[i]); err != nil {
+			acc.AddError(fmt.Errorf("query %d: %v", i+1, err))
+		}
+	}
+	return nil
+}
+
+func (s *SQL) gatherQuery(acc telegraf.Accumulator, q *Query) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout.Duration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, q.Query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		found[column] = true
+	}
+	for _, column := range q.TagColumns {
+		if !found[column] {
+			return fmt.Errorf("tag column %q not found", column)
+		}
+	}
+	for _, column := range q.FieldColumns {
+		if !found[column] {
+			return fmt.Errorf("field column %q not found", column)
+		}
+	}
+	if q.TimeColumn != "" && !found[q.TimeColumn] {
+		return fmt.Errorf("time column %q not found", q.TimeColumn)
+	}
+
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		tags := make(map[string]string)
+		fields := make(map[string]interface{})
+		var tm []time.Time
+		for i, column := range columns {
+			value := normalize(values[i])
+			if value == nil {
+				continue
+			}
+
+			switch {
+			case column == q.TimeColumn:
+				t, err := parseTime(q.TimeFormat, value)
+				if err != nil {
+					return fmt.Errorf("parsing time column %q: %v", column, err)
+				}
+				tm = append(tm, t)
+			case q.tags[column]:
+				tag, _ := convert(value, "string")
+				tags[column] = tag.(string)
+			case len(q.fields) == 0 || q.fields[column]:
+				typ, ok := q.FieldTypes[column]
+				if !ok {
+					if _, isTime := value.(time.Time); !isTime {
+						fields[column] = value
+						continue
+					}
+					typ = "string"
+				}
+				converted, ok := convert(value, typ)
+				if !ok {
+					return fmt.Errorf("cannot convert %v of column %q to %s", value, column, typ)
+				}
+				fields[column] = converted
+			}
+		}
+
+		if len(fields) == 0 {
+			s.Log.Debugf("Skipping row without fields of query %q", q.Query)
+			continue
+		}
+		acc.AddFields(q.Measurement, fields, tags, tm...)
+	}
+	return rows.Err()
+}
+
+// normalize converts a value returned by a driver to a field type, or nil for
+// NULL values.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return string(v)
+	case string, int64, uint64, float64, bool, time.Time:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseTime(format string, value interface{}) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	if v, ok := value.(uint64); ok {
+		value = strconv.FormatUint(v, 10)
+	}
+	return internal.ParseTimestamp(format, value, "")
+}
+
+// convert converts a normalized value to the given type.  Times are
+// converted to strings in RFC3339 format, or to numbers as unix time in
+// nanoseconds.
+func convert(value interface{}, typ string) (interface{}, bool) {
+	if t, ok := value.(time.Time); ok {
+		if typ == "string" {
+			return t.Format(time.RFC3339Nano), true
+		}
+		value = t.UnixNano()
+	}
+
+	switch typ {
+	case "integer":
+		switch v := value.(type) {
+		case int64:
+			return v, true
+		case uint64:
+			if v > math.MaxInt64 {
+				return nil, false
+			}
+			return int64(v), true
+		case float64:
+			return int64(v), true
+		case bool:
+			if v {
+				return int64(1), true
+			}
+			return int64(0), true
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, true
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return int64(f), true
+			}
+		}
+	case "unsigned":
+		switch v := value.(type) {
+		case int64:
+			if v < 0 {
+				return nil, false
+			}
+			return uint64(v), true
+		case uint64:
+			return v, true
+		case float64:
+			if v < 0 {
+				return nil, false
+			}
+			return uint64(v), true
+		case bool:
+			if v {
+				return uint64(1), true
+			}
+			return uint64(0), true
+		case string:
+			if u, err := strconv.ParseUint(v, 10, 64); err == nil {
+				return u, true
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+				return uint64(f), true
+			}
+		}
+	case "float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), true
+		case uint64:
+			return float64(v), true
+		case float64:
+			return v, true
+		case bool:
+			if v {
+				return 1.0, true
+			}
+			return 0.0, true
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, true
+			}
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case int64:
+			return v != 0, true
+		case uint64:
+			return v != 0, true
+		case float64:
+			return v != 0, true
+		case bool:
+			return v, true
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, true
+			}
+		}
+	case "string":
+		return fmt.Sprint(value), true
+	}
+	return nil, false
+}
+
+func init() {
+	inputs.Add("sql", func() telegraf.Input {
+		return &SQL{
+			Timeout: internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-sql")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "query.sql")
+	require.NoError(t, ioutil.WriteFile(script, []byte("SELECT 1 AS one"), 0644))
+
+	plugin := &SQL{
+		Driver:  "mysql",
+		Queries: []Query{{Script: script}},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "SELECT 1 AS one", plugin.Queries[0].Query)
+	require.Equal(t, "sql", plugin.Queries[0].Measurement)
+
+	tests := []struct {
+		name   string
+		plugin *SQL
+	}{
+		{
+			name:   "unknown driver",
+			plugin: &SQL{Driver: "oracle", Queries: []Query{{Query: "SELECT 1"}}},
+		},
+		{
+			name:   "no queries",
+			plugin: &SQL{Driver: "mysql"},
+		},
+		{
+			name:   "no query",
+			plugin: &SQL{Driver: "mysql", Queries: []Query{{Measurement: "foo"}}},
+		},
+		{
+			name:   "missing script",
+			plugin: &SQL{Driver: "mysql", Queries: []Query{{Script: filepath.Join(dir, "missing.sql")}}},
+		},
+		{
+			name: "unknown type",
+			plugin: &SQL{Driver: "mysql", Queries: []Query{{
+				Query:      "SELECT 1 AS one",
+				FieldTypes: map[string]string{"one": "int"},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.plugin.Init())
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		typ      string
+		expected interface{}
+		ok       bool
+	}{
+		{value: "42", typ: "integer", expected: int64(42), ok: true},
+		{value: "4.2", typ: "integer", expected: int64(4), ok: true},
+		{value: uint64(42), typ: "integer", expected: int64(42), ok: true},
+		{value: "foo", typ: "integer", ok: false},
+		{value: int64(-1), typ: "unsigned", ok: false},
+		{value: "42", typ: "unsigned", expected: uint64(42), ok: true},
+		{value: int64(1), typ: "float", expected: 1.0, ok: true},
+		{value: "1.5", typ: "float", expected: 1.5, ok: true},
+		{value: int64(0), typ: "boolean", expected: false, ok: true},
+		{value: "true", typ: "boolean", expected: true, ok: true},
+		{value: 1.5, typ: "string", expected: "1.5", ok: true},
+		{value: time.Unix(1, 0), typ: "integer", expected: int64(1e9), ok: true},
+		{value: time.Unix(1, 0).UTC(), typ: "string", expected: "1970-01-01T00:00:01Z", ok: true},
+	}
+	for _, tt := range tests {
+		actual, ok := convert(tt.value, tt.typ)
+		require.Equal(t, tt.ok, ok, "%v to %s", tt.value, tt.typ)
+		require.Equal(t, tt.expected, actual, "%v to %s", tt.value, tt.typ)
+	}
+}
@@ -0,0 +1,8 @@
+// +build cgo
+
+package sql
+
+import (
+	// The SQLite driver requires cgo.
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,211 @@
+// +build cgo
+
+package sql
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// createDatabase creates an SQLite database with the processes table, and
+// returns its data source name and a function removing it.
+func createDatabase(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "telegraf-sql")
+	require.NoError(t, err)
+	dsn := filepath.Join(dir, "metrics.db")
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE processes (host TEXT, state TEXT, count INTEGER, ratio TEXT, updated INTEGER, started DATETIME)",
+		"INSERT INTO processes VALUES ('a', 'running', 3, '0.5', 1000, '2020-01-02 03:04:05')",
+		"INSERT INTO processes VALUES ('a', 'sleeping', 10, '0.25', 2000, '2020-01-02 03:04:06')",
+		"INSERT INTO processes VALUES ('b', 'running', NULL, NULL, 3000, NULL)",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	return dsn, func() { os.RemoveAll(dir) }
+}
+
+func TestSQLiteGather(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    Query
+		expected []telegraf.Metric
+	}{
+		{
+			name: "tags and fields",
+			query: Query{
+				Query:      "SELECT host, state, count, ratio FROM processes WHERE count IS NOT NULL ORDER BY host, state",
+				TagColumns: []string{"host", "state"},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a", "state": "running"},
+					map[string]interface{}{"count": int64(3), "ratio": "0.5"},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a", "state": "sleeping"},
+					map[string]interface{}{"count": int64(10), "ratio": "0.25"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "field columns and types",
+			query: Query{
+				Query:        "SELECT host, count, ratio, updated FROM processes ORDER BY host, state",
+				Measurement:  "processes",
+				TagColumns:   []string{"host"},
+				FieldColumns: []string{"count", "ratio"},
+				FieldTypes:   map[string]string{"ratio": "float", "count": "unsigned"},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("processes",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"count": uint64(3), "ratio": 0.5},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("processes",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"count": uint64(10), "ratio": 0.25},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "time field",
+			query: Query{
+				Query:      "SELECT host, started FROM processes WHERE started IS NOT NULL ORDER BY started",
+				TagColumns: []string{"host"},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"started": "2020-01-02T03:04:05Z"},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"started": "2020-01-02T03:04:06Z"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "unix time column",
+			query: Query{
+				Query:      "SELECT host, state, updated, count FROM processes ORDER BY host, state",
+				TagColumns: []string{"host", "state"},
+				TimeColumn: "updated",
+				TimeFormat: "unix_ms",
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a", "state": "running"},
+					map[string]interface{}{"count": int64(3)},
+					time.Unix(1, 0),
+				),
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a", "state": "sleeping"},
+					map[string]interface{}{"count": int64(10)},
+					time.Unix(2, 0),
+				),
+			},
+		},
+		{
+			name: "datetime time column",
+			query: Query{
+				Query:      "SELECT host, started, count FROM processes WHERE started IS NOT NULL ORDER BY started",
+				TagColumns: []string{"host"},
+				TimeColumn: "started",
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"count": int64(3)},
+					time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				),
+				testutil.MustMetric("sql",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"count": int64(10)},
+					time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, cleanup := createDatabase(t)
+			defer cleanup()
+
+			plugin := &SQL{
+				Driver:         "sqlite3",
+				DataSourceName: dsn,
+				Timeout:        internal.Duration{Duration: 5 * time.Second},
+				Queries:        []Query{tt.query},
+				Log:            testutil.Logger{},
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			require.NoError(t, acc.GatherError(plugin.Gather))
+
+			opts := []cmp.Option{testutil.SortMetrics()}
+			if tt.query.TimeColumn == "" {
+				opts = append(opts, testutil.IgnoreTime())
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), opts...)
+		})
+	}
+}
+
+func TestSQLiteGatherErrors(t *testing.T) {
+	dsn, cleanup := createDatabase(t)
+	defer cleanup()
+
+	plugin := &SQL{
+		Driver:         "sqlite3",
+		DataSourceName: dsn,
+		Timeout:        internal.Duration{Duration: 5 * time.Second},
+		Queries: []Query{
+			{Query: "SELECT host, count FROM processes", TagColumns: []string{"hostname"}},
+			{Query: "SELECT host, ratio FROM processes WHERE ratio IS NOT NULL", FieldTypes: map[string]string{"host": "integer"}},
+			{Query: "SELECT count FROM missing"},
+			{Query: "SELECT count FROM processes WHERE count = 3"},
+		},
+		Log: testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 3)
+	require.Contains(t, acc.Errors[0].Error(), `tag column "hostname" not found`)
+	require.Contains(t, acc.Errors[1].Error(), `cannot convert a of column "host" to integer`)
+	require.Contains(t, acc.Errors[2].Error(), "no such table")
+
+	// The errors do not prevent other queries from being gathered.
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(3), acc.Metrics[0].Fields["count"])
+}
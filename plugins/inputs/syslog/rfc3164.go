@@ -0,0 +1,239 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/go-syslog/v2"
+	"github.com/influxdata/go-syslog/v2/rfc5424"
+)
+
+// Supported syslog message formats.
+const (
+	standardRFC5424 = "RFC5424"
+	standardRFC3164 = "RFC3164"
+	standardAuto    = "AUTO"
+)
+
+// rfc3164Message is a RFC3164 (BSD) syslog message.  It reuses the RFC5424
+// message for the common parts, RFC3164 messages have no version and the
+// timestamp is kept with its full precision.
+type rfc3164Message struct {
+	*rfc5424.SyslogMessage
+	timestamp *time.Time
+}
+
+// Valid tells whether the message has a priority, the only mandatory part.
+func (m *rfc3164Message) Valid() bool {
+	return m.Priority() != nil
+}
+
+// Timestamp returns the timestamp or nil when not set.
+func (m *rfc3164Message) Timestamp() *time.Time {
+	return m.timestamp
+}
+
+// rfc3164Parser parses RFC3164 syslog messages of the form
+// "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG".  An RFC3339 timestamp is
+// accepted in place of the BSD timestamp, the hostname and the tag are
+// optional.
+type rfc3164Parser struct {
+	bestEffort bool
+	// now returns the current time, used to complete the year of the BSD
+	// timestamp.
+	now func() time.Time
+}
+
+func newRFC3164Parser(now func() time.Time, bestEffort bool) syslog.Machine {
+	return &rfc3164Parser{
+		bestEffort: bestEffort,
+		now:        now,
+	}
+}
+
+func (p *rfc3164Parser) WithBestEffort() {
+	p.bestEffort = true
+}
+
+func (p *rfc3164Parser) HasBestEffort() bool {
+	return p.bestEffort
+}
+
+// Parse parses the message.  In best effort mode a message with an invalid
+// timestamp is returned along with the error, its content is used as message.
+func (p *rfc3164Parser) Parse(input []byte) (syslog.Message, error) {
+	priority, rest, err := parsePriority(input)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &rfc3164Message{SyslogMessage: &rfc5424.SyslogMessage{}}
+	msg.SetPriority(priority)
+
+	timestamp, rest, err := parseBSDTimestamp(rest, p.now())
+	if err != nil {
+		if !p.bestEffort {
+			return nil, err
+		}
+		if rest != "" {
+			msg.SetMessage(rest)
+		}
+		return msg, err
+	}
+	msg.timestamp = &timestamp
+
+	if _, _, _, ok := parseTag(rest); !ok {
+		var hostname string
+		hostname, rest = nextToken(rest)
+		if hostname != "" {
+			msg.SetHostname(hostname)
+		}
+	}
+
+	if appname, procid, remainder, ok := parseTag(rest); ok {
+		msg.SetAppname(appname)
+		if procid != "" {
+			msg.SetProcID(procid)
+		}
+		rest = remainder
+	}
+
+	if rest != "" {
+		msg.SetMessage(rest)
+	}
+	return msg, nil
+}
+
+// parsePriority parses the "<PRI>" part of the message.
+func parsePriority(input []byte) (uint8, string, error) {
+	if len(input) == 0 || input[0] != '<' {
+		return 0, "", fmt.Errorf("expecting a priority value within angle brackets")
+	}
+	end := bytes.IndexByte(input, '>')
+	if end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("expecting a priority value within angle brackets")
+	}
+	priority, err := strconv.ParseUint(string(input[1:end]), 10, 8)
+	if err != nil || priority > 191 {
+		return 0, "", fmt.Errorf("expecting a priority value in the range 1-191 or equal to 0")
+	}
+	return uint8(priority), string(input[end+1:]), nil
+}
+
+// parseBSDTimestamp parses the "Mmm dd hh:mm:ss" timestamp, or an RFC3339
+// timestamp as sent by some daemons, and returns the remainder of the message.
+// The BSD timestamp has no year and no timezone, the time is taken as UTC in
+// the year of now, or the year before if it would be more than a day ahead.
+func parseBSDTimestamp(input string, now time.Time) (time.Time, string, error) {
+	for _, length := range []int{len(time.Stamp), len(time.Stamp) - 1} {
+		if len(input) < length || (len(input) > length && input[length] != ' ') {
+			continue
+		}
+		t, err := time.Parse(time.Stamp, input[:length])
+		if err != nil {
+			continue
+		}
+
+		now = now.UTC()
+		year := now.Year()
+		if time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).After(now.Add(24 * time.Hour)) {
+			year--
+		}
+		t = time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		return t, strings.TrimPrefix(input[length:], " "), nil
+	}
+
+	token, rest := nextToken(input)
+	if t, err := time.Parse(time.RFC3339Nano, token); err == nil {
+		return t, rest, nil
+	}
+	return time.Time{}, input, fmt.Errorf("expecting a timestamp in the form 'Mmm dd hh:mm:ss' or RFC3339")
+}
+
+// parseTag parses the "TAG[PID]: " part of the message and returns the
+// remainder.
+func parseTag(input string) (string, string, string, bool) {
+	end := strings.IndexAny(input, "[: ")
+	if end <= 0 {
+		return "", "", "", false
+	}
+	tag, rest := input[:end], input[end:]
+
+	var procid string
+	if rest[0] == '[' {
+		pidEnd := strings.IndexByte(rest, ']')
+		if pidEnd < 0 {
+			return "", "", "", false
+		}
+		procid, rest = rest[1:pidEnd], rest[pidEnd+1:]
+	}
+
+	// The tag is terminated by a colon followed by a space or by the end of
+	// the message, this tells it apart from an IPv6 hostname.
+	if !strings.HasPrefix(rest, ":") || (len(rest) > 1 && rest[1] != ' ') {
+		return "", "", "", false
+	}
+	return tag, procid, strings.TrimPrefix(rest[1:], " "), true
+}
+
+// nextToken returns the text up to the next space and the text after it.
+func nextToken(input string) (string, string) {
+	i := strings.IndexByte(input, ' ')
+	if i < 0 {
+		return input, ""
+	}
+	return input[:i], input[i+1:]
+}
+
+// autoParser parses RFC5424 and RFC3164 messages, telling them apart by the
+// version following the priority.
+type autoParser struct {
+	rfc5424 syslog.Machine
+	rfc3164 syslog.Machine
+}
+
+func newAutoParser(now func() time.Time, bestEffort bool) syslog.Machine {
+	p := &autoParser{
+		rfc5424: rfc5424.NewParser(),
+		rfc3164: newRFC3164Parser(now, false),
+	}
+	if bestEffort {
+		p.WithBestEffort()
+	}
+	return p
+}
+
+func (p *autoParser) WithBestEffort() {
+	p.rfc5424.WithBestEffort()
+	p.rfc3164.WithBestEffort()
+}
+
+func (p *autoParser) HasBestEffort() bool {
+	return p.rfc5424.HasBestEffort()
+}
+
+func (p *autoParser) Parse(input []byte) (syslog.Message, error) {
+	if isRFC5424(input) {
+		return p.rfc5424.Parse(input)
+	}
+	return p.rfc3164.Parse(input)
+}
+
+// isRFC5424 tells whether the priority is followed by a version and a space,
+// which is never the case for RFC3164 messages.
+func isRFC5424(input []byte) bool {
+	end := bytes.IndexByte(input, '>')
+	if end < 0 {
+		return false
+	}
+	version := input[end+1:]
+
+	digits := 0
+	for digits < len(version) && digits < 3 && version[digits] >= '0' && version[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && version[0] != '0' && digits < len(version) && version[digits] == ' '
+}
@@ -0,0 +1,367 @@
+package syslog
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC3164Parse(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		bestEffort bool
+		tags       map[string]string
+		fields     map[string]interface{}
+		werr       bool
+	}{
+		{
+			name:  "complete",
+			input: "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8",
+			tags: map[string]string{
+				"severity": "crit",
+				"facility": "auth",
+				"hostname": "mymachine",
+				"appname":  "su",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 2,
+				"facility_code": 4,
+				"timestamp":     time.Date(2019, time.October, 11, 22, 14, 15, 0, time.UTC).UnixNano(),
+				"procid":        "123",
+				"message":       "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name:  "single digit day",
+			input: "<13>Jun  1 00:00:01 host app: hello",
+			tags: map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"hostname": "host",
+				"appname":  "app",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 5,
+				"facility_code": 1,
+				"timestamp":     time.Date(2020, time.June, 1, 0, 0, 1, 0, time.UTC).UnixNano(),
+				"message":       "hello",
+			},
+		},
+		{
+			name:  "rfc3339 timestamp",
+			input: "<13>2020-05-31T12:00:00.5+02:00 host app: hello",
+			tags: map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"hostname": "host",
+				"appname":  "app",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 5,
+				"facility_code": 1,
+				"timestamp":     time.Date(2020, time.May, 31, 10, 0, 0, 500000000, time.UTC).UnixNano(),
+				"message":       "hello",
+			},
+		},
+		{
+			name:  "no hostname",
+			input: "<13>May 31 12:00:00 app[42]: hello world",
+			tags: map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"appname":  "app",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 5,
+				"facility_code": 1,
+				"timestamp":     time.Date(2020, time.May, 31, 12, 0, 0, 0, time.UTC).UnixNano(),
+				"procid":        "42",
+				"message":       "hello world",
+			},
+		},
+		{
+			name:  "ipv6 hostname without tag",
+			input: "<13>May 31 12:00:00 fe80::1 link up",
+			tags: map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"hostname": "fe80::1",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 5,
+				"facility_code": 1,
+				"timestamp":     time.Date(2020, time.May, 31, 12, 0, 0, 0, time.UTC).UnixNano(),
+				"message":       "link up",
+			},
+		},
+		{
+			name:  "missing priority",
+			input: "May 31 12:00:00 host app: hello",
+			werr:  true,
+		},
+		{
+			name:       "missing priority best effort",
+			input:      "May 31 12:00:00 host app: hello",
+			bestEffort: true,
+			werr:       true,
+		},
+		{
+			name:  "invalid timestamp",
+			input: "<13>yesterday host app: hello",
+			werr:  true,
+		},
+		{
+			name:       "invalid timestamp best effort",
+			input:      "<13>yesterday host app: hello",
+			bestEffort: true,
+			tags: map[string]string{
+				"severity": "notice",
+				"facility": "user",
+			},
+			fields: map[string]interface{}{
+				"severity_code": 5,
+				"facility_code": 1,
+				"message":       "yesterday host app: hello",
+			},
+			werr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRFC3164Parser(now, tt.bestEffort)
+			msg, err := p.Parse([]byte(tt.input))
+			if tt.werr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.tags == nil {
+				require.Nil(t, msg)
+				return
+			}
+			require.True(t, msg.Valid())
+			require.Equal(t, tt.tags, tags(msg))
+			require.Equal(t, tt.fields, fields(msg, &Syslog{Separator: "_"}))
+		})
+	}
+}
+
+func TestRFC3164TimestampYear(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 0, 0, 30, 0, time.UTC)
+
+	// A message sent just before the new year is taken from the last year.
+	ts, _, err := parseBSDTimestamp("Dec 31 23:59:59 host", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2020, time.December, 31, 23, 59, 59, 0, time.UTC), ts)
+
+	// Clocks slightly ahead do not move the message to the last year.
+	ts, _, err = parseBSDTimestamp("Jan  1 01:00:00 host", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2021, time.January, 1, 1, 0, 0, 0, time.UTC), ts)
+}
+
+func TestAutoParse(t *testing.T) {
+	p := newAutoParser(func() time.Time { return defaultTime }, false)
+
+	msg, err := p.Parse([]byte("<1>1 - - - - - - A"))
+	require.NoError(t, err)
+	require.Equal(t, uint16(1), msg.Version())
+
+	msg, err = p.Parse([]byte("<1>Dec 31 23:59:59 host app: A"))
+	require.NoError(t, err)
+	require.Equal(t, uint16(0), msg.Version())
+	require.Equal(t, "app", *msg.Appname())
+}
+
+func TestUnknownSyslogStandard(t *testing.T) {
+	receiver := &Syslog{
+		Address:        "udp://" + address,
+		SyslogStandard: "RFC1234",
+	}
+	require.EqualError(t, receiver.Start(&testutil.Accumulator{}), "unknown syslog standard 'RFC1234'")
+}
+
+// rfc3164Metric returns the metric of the message "<13>Dec 31 23:59:59 host
+// app[1]: <message>" received at defaultTime plus the given nanoseconds.
+func rfc3164Metric(message string, ns int64) telegraf.Metric {
+	return testutil.MustMetric(
+		"syslog",
+		map[string]string{
+			"severity": "notice",
+			"facility": "user",
+			"hostname": "host",
+			"appname":  "app",
+		},
+		map[string]interface{}{
+			"severity_code": 5,
+			"facility_code": 1,
+			"timestamp":     time.Unix(-1, 0).UnixNano(),
+			"procid":        "1",
+			"message":       message,
+		},
+		defaultTime.Add(time.Duration(ns)),
+	)
+}
+
+func testRFC3164Packet(t *testing.T, protocol string, address string) {
+	receiver := newUDPSyslogReceiver(protocol+"://"+address, false)
+	receiver.SyslogStandard = "RFC3164"
+	acc := &testutil.Accumulator{}
+	require.NoError(t, receiver.Start(acc))
+	defer receiver.Stop()
+
+	conn, err := net.Dial(protocol, address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("<13>Dec 31 23:59:59 host app[1]: A"))
+	require.NoError(t, err)
+	conn.Close()
+
+	acc.Wait(1)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{rfc3164Metric("A", 0)}, acc.GetTelegrafMetrics())
+}
+
+func TestRFC3164_udp(t *testing.T) {
+	testRFC3164Packet(t, "udp", address)
+}
+
+func TestRFC3164_unixgram(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	sock := filepath.Join(tmpdir, "syslog.TestRFC3164_unixgram.sock")
+	testRFC3164Packet(t, "unixgram", sock)
+}
+
+func TestRFC3164Stream(t *testing.T) {
+	tests := []struct {
+		name       string
+		framing    framing.Framing
+		standard   string
+		bestEffort bool
+		data       string
+		want       []telegraf.Metric
+		werr       int
+	}{
+		{
+			name:     "octet counting",
+			framing:  framing.OctetCounting,
+			standard: "RFC3164",
+			data:     "34 <13>Dec 31 23:59:59 host app[1]: A35 <13>Dec 31 23:59:59 host app[1]: BC",
+			want:     []telegraf.Metric{rfc3164Metric("A", 0), rfc3164Metric("BC", 1)},
+		},
+		{
+			name:     "octet counting stops at error",
+			framing:  framing.OctetCounting,
+			standard: "RFC3164",
+			data:     "15 <13>yesterday A34 <13>Dec 31 23:59:59 host app[1]: B",
+			werr:     1,
+		},
+		{
+			name:       "octet counting best effort",
+			framing:    framing.OctetCounting,
+			standard:   "RFC3164",
+			bestEffort: true,
+			data:       "6 <13>A 34 <13>Dec 31 23:59:59 host app[1]: B",
+			want: []telegraf.Metric{
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "notice",
+						"facility": "user",
+					},
+					map[string]interface{}{
+						"severity_code": 5,
+						"facility_code": 1,
+						"message":       "A",
+					},
+					defaultTime,
+				),
+				rfc3164Metric("B", 1),
+			},
+			werr: 1,
+		},
+		{
+			name:     "octet counting invalid length",
+			framing:  framing.OctetCounting,
+			standard: "RFC3164",
+			data:     "x <13>Dec 31 23:59:59 host app[1]: A",
+			werr:     1,
+		},
+		{
+			name:     "non-transparent",
+			framing:  framing.NonTransparent,
+			standard: "RFC3164",
+			data:     "<13>Dec 31 23:59:59 host app[1]: A\n\n<13>Dec 31 23:59:59 host app[1]: B",
+			want:     []telegraf.Metric{rfc3164Metric("A", 0), rfc3164Metric("B", 1)},
+		},
+		{
+			name:     "non-transparent continues after error",
+			framing:  framing.NonTransparent,
+			standard: "RFC3164",
+			data:     "garbage\n<13>Dec 31 23:59:59 host app[1]: B\n",
+			want:     []telegraf.Metric{rfc3164Metric("B", 0)},
+			werr:     1,
+		},
+		{
+			name:     "auto",
+			framing:  framing.NonTransparent,
+			standard: "auto",
+			data:     "<13>Dec 31 23:59:59 host app[1]: A\n<13>1 - host app 1 - - B\n",
+			want: []telegraf.Metric{
+				rfc3164Metric("A", 0),
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{
+						"severity": "notice",
+						"facility": "user",
+						"hostname": "host",
+						"appname":  "app",
+					},
+					map[string]interface{}{
+						"version":       uint16(1),
+						"severity_code": 5,
+						"facility_code": 1,
+						"procid":        "1",
+						"message":       "B",
+					},
+					defaultTime.Add(time.Nanosecond),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newTCPSyslogReceiver("tcp://"+address, nil, 0, tt.bestEffort, tt.framing)
+			receiver.SyslogStandard = tt.standard
+			acc := &testutil.Accumulator{}
+			require.NoError(t, receiver.Start(acc))
+			defer receiver.Stop()
+
+			conn, err := net.Dial("tcp", address)
+			require.NoError(t, err)
+			_, err = conn.Write([]byte(tt.data))
+			require.NoError(t, err)
+			conn.Close()
+
+			acc.Wait(len(tt.want))
+			acc.WaitError(tt.werr)
+			require.Len(t, acc.Errors, tt.werr)
+			testutil.RequireMetricsEqual(t, tt.want, acc.GetTelegrafMetrics())
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/influxdata/go-syslog/v2"
+	"github.com/influxdata/go-syslog/v2/nontransparent"
+	framing "github.com/influxdata/telegraf/internal/syslog"
+)
+
+// maxMessageLength is the maximum length of a message of a stream.
+const maxMessageLength = 64 * 1024
+
+// streamParser splits a stream by octet counting or non-transparent framing
+// and parses the messages with the given machine.  It is used for the message
+// formats the go-syslog stream parsers, which only support RFC5424, do not
+// handle.
+type streamParser struct {
+	machine syslog.Machine
+	framing framing.Framing
+	trailer byte
+	emit    syslog.ParserListener
+}
+
+func newStreamParser(machine syslog.Machine, f framing.Framing, t nontransparent.TrailerType) (*streamParser, error) {
+	trailer, err := t.Value()
+	if err != nil {
+		return nil, err
+	}
+	return &streamParser{
+		machine: machine,
+		framing: f,
+		trailer: byte(trailer),
+		emit:    func(*syslog.Result) {},
+	}, nil
+}
+
+func (p *streamParser) WithListener(f syslog.ParserListener) {
+	p.emit = f
+}
+
+func (p *streamParser) WithBestEffort() {
+	p.machine.WithBestEffort()
+}
+
+func (p *streamParser) HasBestEffort() bool {
+	return p.machine.HasBestEffort()
+}
+
+// Parse parses the messages of the reader until it is exhausted.  With octet
+// counting framing parsing stops at the first error unless in best effort
+// mode, with non-transparent framing the next message is parsed.
+func (p *streamParser) Parse(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxMessageLength)
+	if p.framing == framing.OctetCounting {
+		scanner.Split(splitOctetCounting)
+	} else {
+		scanner.Split(p.splitNonTransparent)
+	}
+
+	for scanner.Scan() {
+		message, err := p.machine.Parse(scanner.Bytes())
+		p.emit(&syslog.Result{
+			Message: message,
+			Error:   err,
+		})
+		if err != nil && p.framing == framing.OctetCounting && !p.HasBestEffort() {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		p.emit(&syslog.Result{Error: err})
+	}
+}
+
+// splitOctetCounting splits messages framed as "MSG-LEN SP SYSLOG-MSG", see
+// RFC6587#section-3.4.1.
+func splitOctetCounting(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	space := bytes.IndexByte(data, ' ')
+	if space < 0 {
+		if atEOF || len(data) > len(strconv.Itoa(maxMessageLength)) {
+			return 0, nil, fmt.Errorf("found %q, expecting a message length followed by a space", data)
+		}
+		return 0, nil, nil
+	}
+
+	length, err := strconv.Atoi(string(data[:space]))
+	if err != nil || length <= 0 || length > maxMessageLength {
+		return 0, nil, fmt.Errorf("found %q, expecting a message length", data[:space])
+	}
+
+	end := space + 1 + length
+	if len(data) < end {
+		if atEOF {
+			return 0, nil, fmt.Errorf("found %d octets, expecting a message containing %d octets",
+				len(data)-space-1, length)
+		}
+		return 0, nil, nil
+	}
+	return end, data[space+1 : end], nil
+}
+
+// splitNonTransparent splits messages terminated by the trailer, see
+// RFC6587#section-3.4.2.  The remaining data at the end of the stream is
+// taken as the last message.
+func (p *streamParser) splitNonTransparent(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	i := bytes.IndexByte(data, p.trailer)
+	switch {
+	case i == 0:
+		// skip empty messages
+		return 1, nil, nil
+	case i > 0:
+		return i + 1, data[:i], nil
+	case atEOF:
+		return len(data), data, nil
+	default:
+		return 0, nil, nil
+	}
+}
@@ -37,6 +37,7 @@ type Syslog struct {
 	Trailer         nontransparent.TrailerType
 	BestEffort      bool
 	Separator       string `toml:"sdparam_separator"`
+	SyslogStandard  string `toml:"syslog_standard"`
 
 	now      func() time.Time
 	lastTime time.Time
@@ -91,6 +92,11 @@ var sampleConfig = `
   ## Must be one of "LF", or "NUL".
   # trailer = "LF"
 
+  ## The syslog message format (default = "RFC5424").
+  ## Must be one of "RFC5424", "RFC3164", or "auto".
+  ## With "auto" the format is detected for each message.
+  # syslog_standard = "RFC5424"
+
   ## Whether to parse in best effort mode or not (default = false).
   ## By default best effort parsing is off.
   # best_effort = false
@@ -110,7 +116,7 @@ func (s *Syslog) SampleConfig() string {
 
 // Description returns the plugin description
 func (s *Syslog) Description() string {
-	return "Accepts syslog messages following RFC5424 format with transports as per RFC5426, RFC5425, or RFC6587"
+	return "Accepts syslog messages following RFC5424 or RFC3164 format with transports as per RFC5426, RFC5425, or RFC6587"
 }
 
 // Gather ...
@@ -123,6 +129,12 @@ func (s *Syslog) Start(acc telegraf.Accumulator) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	switch strings.ToUpper(s.SyslogStandard) {
+	case "", standardRFC5424, standardRFC3164, standardAuto:
+	default:
+		return fmt.Errorf("unknown syslog standard '%s'", s.SyslogStandard)
+	}
+
 	scheme, host, err := getAddressParts(s.Address)
 	if err != nil {
 		return err
@@ -218,12 +230,7 @@ func getAddressParts(a string) (string, string, error) {
 func (s *Syslog) listenPacket(acc telegraf.Accumulator) {
 	defer s.wg.Done()
 	b := make([]byte, ipMaxPacketSize)
-	var p syslog.Machine
-	if s.BestEffort {
-		p = rfc5424.NewParser(rfc5424.WithBestEffort())
-	} else {
-		p = rfc5424.NewParser()
-	}
+	p := s.newMachine()
 	for {
 		n, _, err := s.udpListener.ReadFrom(b)
 		if err != nil {
@@ -296,8 +303,6 @@ func (s *Syslog) handle(conn net.Conn, acc telegraf.Accumulator) {
 		conn.Close()
 	}()
 
-	var p syslog.Parser
-
 	emit := func(r *syslog.Result) {
 		s.store(*r, acc)
 		if s.ReadTimeout != nil && s.ReadTimeout.Duration > 0 {
@@ -305,10 +310,45 @@ func (s *Syslog) handle(conn net.Conn, acc telegraf.Accumulator) {
 		}
 	}
 
-	// Create parser options
-	opts := []syslog.ParserOption{
-		syslog.WithListener(emit),
+	p, err := s.newParser()
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	p.WithListener(emit)
+	p.Parse(conn)
+
+	if s.ReadTimeout != nil && s.ReadTimeout.Duration > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.ReadTimeout.Duration))
+	}
+}
+
+// newMachine returns the parser of single messages for the configured
+// syslog standard.
+func (s *Syslog) newMachine() syslog.Machine {
+	switch strings.ToUpper(s.SyslogStandard) {
+	case standardRFC3164:
+		return newRFC3164Parser(s.now, s.BestEffort)
+	case standardAuto:
+		return newAutoParser(s.now, s.BestEffort)
 	}
+
+	if s.BestEffort {
+		return rfc5424.NewParser(rfc5424.WithBestEffort())
+	}
+	return rfc5424.NewParser()
+}
+
+// newParser returns the parser of streams for the configured syslog standard
+// and transport framing.
+func (s *Syslog) newParser() (syslog.Parser, error) {
+	switch strings.ToUpper(s.SyslogStandard) {
+	case standardRFC3164, standardAuto:
+		return newStreamParser(s.newMachine(), s.Framing, s.Trailer)
+	}
+
+	// Create parser options
+	var opts []syslog.ParserOption
 	if s.BestEffort {
 		opts = append(opts, syslog.WithBestEffort())
 	}
@@ -316,18 +356,11 @@ func (s *Syslog) handle(conn net.Conn, acc telegraf.Accumulator) {
 	// Select the parser to use depending on transport framing
 	if s.Framing == framing.OctetCounting {
 		// Octet counting transparent framing
-		p = octetcounting.NewParser(opts...)
-	} else {
-		// Non-transparent framing
-		opts = append(opts, nontransparent.WithTrailer(s.Trailer))
-		p = nontransparent.NewParser(opts...)
-	}
-
-	p.Parse(conn)
-
-	if s.ReadTimeout != nil && s.ReadTimeout.Duration > 0 {
-		conn.SetReadDeadline(time.Now().Add(s.ReadTimeout.Duration))
+		return octetcounting.NewParser(opts...), nil
 	}
+	// Non-transparent framing
+	opts = append(opts, nontransparent.WithTrailer(s.Trailer))
+	return nontransparent.NewParser(opts...), nil
 }
 
 func (s *Syslog) setKeepAlive(c *net.TCPConn) error {
@@ -373,8 +406,11 @@ func tags(msg syslog.Message) map[string]string {
 
 func fields(msg syslog.Message, s *Syslog) map[string]interface{} {
 	// Not checking assuming a minimally valid message
-	flds := map[string]interface{}{
-		"version": msg.Version(),
+	flds := map[string]interface{}{}
+
+	// RFC3164 messages have no version
+	if msg.Version() > 0 {
+		flds["version"] = msg.Version()
 	}
 	flds["severity_code"] = int(*msg.Severity())
 	flds["facility_code"] = int(*msg.Facility())
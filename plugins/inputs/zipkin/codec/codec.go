@@ -30,6 +30,12 @@ type Span interface {
 	Duration() time.Duration
 }
 
+// EndpointSpan is implemented by spans which carry the endpoint of the
+// service recording them, rather than endpoints of their annotations.
+type EndpointSpan interface {
+	Endpoint() Endpoint
+}
+
 // Annotation represents an event that explains latency with a timestamp.
 type Annotation interface {
 	Timestamp() time.Time
@@ -73,6 +79,9 @@ func NewTrace(spans []Span) (trace.Trace, error) {
 			return nil, err
 		}
 		endpoint := serviceEndpoint(span.Annotations(), bin)
+		if s, ok := span.(EndpointSpan); ok {
+			endpoint = s.Endpoint()
+		}
 		id, err := span.SpanID()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,255 @@
+package jaeger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec/jsonV1"
+)
+
+// JSON decodes spans in the JSON format of the Jaeger query API, either a
+// single trace or a response with traces in its "data" member.
+type JSON struct{}
+
+// Decode unmarshals and validates the JSON body
+func (j *JSON) Decode(octets []byte) ([]codec.Span, error) {
+	var doc document
+	if err := json.Unmarshal(octets, &doc); err != nil {
+		return nil, err
+	}
+
+	traces := doc.Data
+	if len(doc.Spans) > 0 {
+		traces = append(traces, doc.traceData)
+	}
+
+	var res []codec.Span
+	for i := range traces {
+		t := &traces[i]
+		for j := range t.Spans {
+			s := &t.Spans[j]
+			if s.Process == nil {
+				p, ok := t.Processes[s.ProcessID]
+				if !ok {
+					return nil, fmt.Errorf("Unknown process %q of span %s", s.ProcessID, s.ID)
+				}
+				s.Process = &p
+			}
+			if err := s.Validate(); err != nil {
+				return nil, err
+			}
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+type document struct {
+	Data []traceData `json:"data"`
+	traceData
+}
+
+type traceData struct {
+	Spans     []span             `json:"spans"`
+	Processes map[string]process `json:"processes"`
+}
+
+type span struct {
+	TraceID       string      `json:"traceID"`
+	ID            string      `json:"spanID"`
+	ParentID      string      `json:"parentSpanID,omitempty"`
+	OperationName string      `json:"operationName"`
+	References    []reference `json:"references"`
+	StartTime     int64       `json:"startTime"`
+	Dur           int64       `json:"duration"`
+	Tags          []keyValue  `json:"tags"`
+	Logs          []log       `json:"logs"`
+	ProcessID     string      `json:"processID"`
+	Process       *process    `json:"process,omitempty"`
+}
+
+type reference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type keyValue struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type log struct {
+	Time   int64      `json:"timestamp"`
+	Fields []keyValue `json:"fields"`
+}
+
+type process struct {
+	ServiceName string     `json:"serviceName"`
+	Tags        []keyValue `json:"tags"`
+}
+
+func (s *span) Validate() error {
+	if _, err := s.Trace(); err != nil {
+		return err
+	}
+	if _, err := s.SpanID(); err != nil {
+		return err
+	}
+	_, err := s.Parent()
+	return err
+}
+
+func (s *span) Trace() (string, error) {
+	if s.TraceID == "" {
+		return "", fmt.Errorf("Trace ID cannot be null")
+	}
+	return jsonV1.TraceIDFromString(s.TraceID)
+}
+
+func (s *span) SpanID() (string, error) {
+	if s.ID == "" {
+		return "", fmt.Errorf("Span ID cannot be null")
+	}
+	return jsonV1.IDFromString(s.ID)
+}
+
+// Parent returns the span referenced as CHILD_OF, or else as FOLLOWS_FROM.
+func (s *span) Parent() (string, error) {
+	id := s.ParentID
+	for _, r := range s.References {
+		if r.RefType == "CHILD_OF" {
+			id = r.SpanID
+			break
+		}
+		if id == "" && r.RefType == "FOLLOWS_FROM" {
+			id = r.SpanID
+		}
+	}
+	if id == "" {
+		return "", nil
+	}
+	return jsonV1.IDFromString(id)
+}
+
+func (s *span) Name() string {
+	return s.OperationName
+}
+
+// Annotations returns the logs of the span.
+func (s *span) Annotations() []codec.Annotation {
+	res := make([]codec.Annotation, len(s.Logs))
+	for i := range s.Logs {
+		res[i] = &annotation{log: &s.Logs[i], endpoint: s.Endpoint()}
+	}
+	return res
+}
+
+// BinaryAnnotations returns the tags of the span.
+func (s *span) BinaryAnnotations() ([]codec.BinaryAnnotation, error) {
+	res := make([]codec.BinaryAnnotation, len(s.Tags))
+	for i := range s.Tags {
+		res[i] = &binaryAnnotation{kv: &s.Tags[i], endpoint: s.Endpoint()}
+	}
+	return res, nil
+}
+
+func (s *span) Timestamp() time.Time {
+	if s.StartTime == 0 {
+		return time.Time{}
+	}
+	return codec.MicroToTime(s.StartTime)
+}
+
+func (s *span) Duration() time.Duration {
+	return time.Duration(s.Dur) * time.Microsecond
+}
+
+// Endpoint returns the process of the span.
+func (s *span) Endpoint() codec.Endpoint {
+	return endpoint{process: s.Process}
+}
+
+type annotation struct {
+	log      *log
+	endpoint codec.Endpoint
+}
+
+func (a *annotation) Timestamp() time.Time {
+	return codec.MicroToTime(a.log.Time)
+}
+
+// Value returns the "event" field of the log, or its "message" field, or
+// else all fields as key=value pairs.
+func (a *annotation) Value() string {
+	for _, key := range []string{"event", "message"} {
+		for _, f := range a.log.Fields {
+			if f.Key == key {
+				return f.String()
+			}
+		}
+	}
+
+	pairs := make([]string, len(a.log.Fields))
+	for i, f := range a.log.Fields {
+		pairs[i] = f.Key + "=" + f.String()
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (a *annotation) Host() codec.Endpoint {
+	return a.endpoint
+}
+
+type binaryAnnotation struct {
+	kv       *keyValue
+	endpoint codec.Endpoint
+}
+
+func (b *binaryAnnotation) Key() string {
+	return b.kv.Key
+}
+
+func (b *binaryAnnotation) Value() string {
+	return b.kv.String()
+}
+
+func (b *binaryAnnotation) Host() codec.Endpoint {
+	return b.endpoint
+}
+
+// String returns the value of strings, and the JSON text of other values.
+func (kv *keyValue) String() string {
+	var s string
+	if err := json.Unmarshal(kv.Value, &s); err == nil {
+		return s
+	}
+	return string(kv.Value)
+}
+
+type endpoint struct {
+	process *process
+}
+
+// Host returns the "ip" tag of the process, or else its "hostname" tag.
+func (e endpoint) Host() string {
+	for _, key := range []string{"ip", "hostname"} {
+		for _, t := range e.process.Tags {
+			if t.Key == key {
+				return t.String()
+			}
+		}
+	}
+	return (&codec.DefaultEndpoint{}).Host()
+}
+
+func (e endpoint) Name() string {
+	if e.process.ServiceName == "" {
+		return codec.DefaultServiceName
+	}
+	return e.process.ServiceName
+}
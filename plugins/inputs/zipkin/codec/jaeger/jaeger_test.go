@@ -0,0 +1,120 @@
+package jaeger
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON_Decode(t *testing.T) {
+	octets, err := ioutil.ReadFile("../../testdata/jaeger/hotrod.json")
+	require.NoError(t, err)
+
+	spans, err := (&JSON{}).Decode(octets)
+	require.NoError(t, err)
+
+	got, err := codec.NewTrace(spans)
+	require.NoError(t, err)
+
+	want := trace.Trace{
+		{
+			ID:          "eee19b7ec3c1b174",
+			TraceID:     "5b8efff798038103d269b633813fc60c",
+			Name:        "HTTP GET /dispatch",
+			ParentID:    "eee19b7ec3c1b174",
+			ServiceName: "frontend",
+			Timestamp:   time.Unix(0, 1585062150000000000).UTC(),
+			Duration:    120 * time.Millisecond,
+			Annotations: []trace.Annotation{
+				{
+					Timestamp:   time.Unix(0, 1585062150010000000).UTC(),
+					Value:       "dispatch started",
+					Host:        "10.0.0.1",
+					ServiceName: "frontend",
+				},
+			},
+			BinaryAnnotations: []trace.BinaryAnnotation{
+				{
+					Key:         "http.status_code",
+					Value:       "200",
+					Host:        "10.0.0.1",
+					ServiceName: "frontend",
+				},
+			},
+		},
+		{
+			ID:          "eee19b7ec3c1b173",
+			TraceID:     "5b8efff798038103d269b633813fc60c",
+			Name:        "FindDriverIDs",
+			ParentID:    "eee19b7ec3c1b174",
+			ServiceName: "redis",
+			Timestamp:   time.Unix(0, 1585062150020000000).UTC(),
+			Duration:    30 * time.Millisecond,
+			Annotations: []trace.Annotation{
+				{
+					Timestamp:   time.Unix(0, 1585062150040000000).UTC(),
+					Value:       "level=error retry=1",
+					Host:        "cache01",
+					ServiceName: "redis",
+				},
+			},
+			BinaryAnnotations: []trace.BinaryAnnotation{
+				{
+					Key:         "error",
+					Value:       "true",
+					Host:        "cache01",
+					ServiceName: "redis",
+				},
+			},
+		},
+	}
+	require.Equal(t, want, got)
+}
+
+func TestJSON_DecodeSingleTrace(t *testing.T) {
+	octets := []byte(`{
+		"traceID": "1",
+		"spans": [{"traceID": "1", "spanID": "2", "operationName": "get", "process": {"serviceName": "api"}}]
+	}`)
+
+	spans, err := (&JSON{}).Decode(octets)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	require.Equal(t, "get", spans[0].Name())
+	require.Equal(t, "api", spans[0].(codec.EndpointSpan).Endpoint().Name())
+	require.Equal(t, "0.0.0.0", spans[0].(codec.EndpointSpan).Endpoint().Host())
+}
+
+func TestJSON_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		octets string
+	}{
+		{
+			name:   "bad json",
+			octets: `{"data": [`,
+		},
+		{
+			name:   "unknown process",
+			octets: `{"data": [{"spans": [{"traceID": "1", "spanID": "2", "processID": "p1"}]}]}`,
+		},
+		{
+			name:   "missing span id",
+			octets: `{"data": [{"spans": [{"traceID": "1", "processID": "p1"}], "processes": {"p1": {}}}]}`,
+		},
+		{
+			name:   "invalid parent id",
+			octets: `{"data": [{"spans": [{"traceID": "1", "spanID": "2", "parentSpanID": "xyz", "processID": "p1"}], "processes": {"p1": {}}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&JSON{}).Decode([]byte(tt.octets))
+			require.Error(t, err)
+		})
+	}
+}
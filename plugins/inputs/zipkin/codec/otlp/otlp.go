@@ -0,0 +1,309 @@
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec/jsonV1"
+)
+
+// statusCodeError is the OpenTelemetry status code of failed spans
+const statusCodeError = 2
+
+// JSON decodes spans of an OpenTelemetry ExportTraceServiceRequest in the
+// JSON encoding of the OTLP/HTTP protocol.
+type JSON struct{}
+
+// Decode unmarshals and validates the JSON body
+func (j *JSON) Decode(octets []byte) ([]codec.Span, error) {
+	var req request
+	if err := json.Unmarshal(octets, &req); err != nil {
+		return nil, err
+	}
+
+	var res []codec.Span
+	for i := range req.ResourceSpans {
+		rs := &req.ResourceSpans[i]
+		ep := newEndpoint(rs.Resource.Attributes)
+		for _, scopes := range [][]scopeSpans{rs.ScopeSpans, rs.InstrumentationLibrarySpans} {
+			for _, scope := range scopes {
+				for k := range scope.Spans {
+					s := &scope.Spans[k]
+					s.endpoint = ep
+					if err := s.Validate(); err != nil {
+						return nil, err
+					}
+					res = append(res, s)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+type request struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource struct {
+		Attributes []keyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+	// InstrumentationLibrarySpans is the name of ScopeSpans before OTLP 0.15
+	InstrumentationLibrarySpans []scopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type scopeSpans struct {
+	Spans []span `json:"spans"`
+}
+
+type span struct {
+	TraceID    string     `json:"traceId"`
+	ID         string     `json:"spanId"`
+	ParentID   string     `json:"parentSpanId"`
+	SpanName   string     `json:"name"`
+	StartTime  unixNano   `json:"startTimeUnixNano"`
+	EndTime    unixNano   `json:"endTimeUnixNano"`
+	Attributes []keyValue `json:"attributes"`
+	Events     []event    `json:"events"`
+	Status     status     `json:"status"`
+	endpoint   codec.Endpoint
+}
+
+type event struct {
+	Time       unixNano   `json:"timeUnixNano"`
+	EventName  string     `json:"name"`
+	Attributes []keyValue `json:"attributes"`
+}
+
+type status struct {
+	Code    statusCode `json:"code"`
+	Message string     `json:"message"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string          `json:"stringValue"`
+	BoolValue   *bool            `json:"boolValue"`
+	IntValue    *json.RawMessage `json:"intValue"`
+	DoubleValue *float64         `json:"doubleValue"`
+}
+
+// String returns the scalar value of the attribute; arrays, maps and bytes
+// are not supported and yield an empty string.
+func (v anyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strings.Trim(string(*v.IntValue), `"`)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	}
+	return ""
+}
+
+// unixNano is a timestamp in nanoseconds, which protobuf JSON encodes as
+// string but some exporters send as number.
+type unixNano int64
+
+func (u *unixNano) UnmarshalJSON(b []byte) error {
+	n, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s: %v", b, err)
+	}
+	*u = unixNano(n)
+	return nil
+}
+
+// statusCode is an OpenTelemetry status code, either as number or as the
+// name of the enum value.
+type statusCode int
+
+func (c *statusCode) UnmarshalJSON(b []byte) error {
+	switch strings.Trim(string(b), `"`) {
+	case "STATUS_CODE_UNSET":
+		*c = 0
+	case "STATUS_CODE_OK":
+		*c = 1
+	case "STATUS_CODE_ERROR":
+		*c = statusCodeError
+	default:
+		n, err := strconv.Atoi(string(b))
+		if err != nil {
+			return fmt.Errorf("invalid status code %s", b)
+		}
+		*c = statusCode(n)
+	}
+	return nil
+}
+
+func (s *span) Validate() error {
+	if _, err := s.Trace(); err != nil {
+		return err
+	}
+	if _, err := s.SpanID(); err != nil {
+		return err
+	}
+	_, err := s.Parent()
+	return err
+}
+
+func (s *span) Trace() (string, error) {
+	if s.TraceID == "" {
+		return "", fmt.Errorf("Trace ID cannot be null")
+	}
+	return jsonV1.TraceIDFromString(s.TraceID)
+}
+
+func (s *span) SpanID() (string, error) {
+	if s.ID == "" {
+		return "", fmt.Errorf("Span ID cannot be null")
+	}
+	return jsonV1.IDFromString(s.ID)
+}
+
+func (s *span) Parent() (string, error) {
+	if s.ParentID == "" {
+		return "", nil
+	}
+	return jsonV1.IDFromString(s.ParentID)
+}
+
+func (s *span) Name() string {
+	return s.SpanName
+}
+
+// Annotations returns the events of the span.
+func (s *span) Annotations() []codec.Annotation {
+	res := make([]codec.Annotation, len(s.Events))
+	for i := range s.Events {
+		res[i] = &annotation{event: &s.Events[i], endpoint: s.endpoint}
+	}
+	return res
+}
+
+// BinaryAnnotations returns the attributes of the span, and an "error"
+// annotation if the status of the span is an error.
+func (s *span) BinaryAnnotations() ([]codec.BinaryAnnotation, error) {
+	res := make([]codec.BinaryAnnotation, 0, len(s.Attributes)+1)
+	for _, kv := range s.Attributes {
+		res = append(res, &binaryAnnotation{
+			key:      kv.Key,
+			value:    kv.Value.String(),
+			endpoint: s.endpoint,
+		})
+	}
+	if s.Status.Code == statusCodeError {
+		msg := s.Status.Message
+		if msg == "" {
+			msg = "true"
+		}
+		res = append(res, &binaryAnnotation{
+			key:      "error",
+			value:    msg,
+			endpoint: s.endpoint,
+		})
+	}
+	return res, nil
+}
+
+func (s *span) Timestamp() time.Time {
+	if s.StartTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(s.StartTime)).UTC()
+}
+
+func (s *span) Duration() time.Duration {
+	if s.EndTime < s.StartTime {
+		return 0
+	}
+	return time.Duration(s.EndTime - s.StartTime)
+}
+
+// Endpoint returns the resource of the span.
+func (s *span) Endpoint() codec.Endpoint {
+	return s.endpoint
+}
+
+type annotation struct {
+	event    *event
+	endpoint codec.Endpoint
+}
+
+func (a *annotation) Timestamp() time.Time {
+	return time.Unix(0, int64(a.event.Time)).UTC()
+}
+
+func (a *annotation) Value() string {
+	return a.event.EventName
+}
+
+func (a *annotation) Host() codec.Endpoint {
+	return a.endpoint
+}
+
+type binaryAnnotation struct {
+	key      string
+	value    string
+	endpoint codec.Endpoint
+}
+
+func (b *binaryAnnotation) Key() string {
+	return b.key
+}
+
+func (b *binaryAnnotation) Value() string {
+	return b.value
+}
+
+func (b *binaryAnnotation) Host() codec.Endpoint {
+	return b.endpoint
+}
+
+type endpoint struct {
+	host string
+	name string
+}
+
+// newEndpoint returns the endpoint given by the "service.name" and
+// "host.name" attributes of a resource.
+func newEndpoint(attributes []keyValue) *endpoint {
+	e := &endpoint{
+		host: (&codec.DefaultEndpoint{}).Host(),
+		name: codec.DefaultServiceName,
+	}
+	for _, kv := range attributes {
+		v := kv.Value.String()
+		if v == "" {
+			continue
+		}
+		switch kv.Key {
+		case "service.name":
+			e.name = v
+		case "host.name":
+			e.host = v
+		}
+	}
+	return e
+}
+
+func (e *endpoint) Host() string {
+	return e.host
+}
+
+func (e *endpoint) Name() string {
+	return e.name
+}
@@ -0,0 +1,111 @@
+package otlp
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON_Decode(t *testing.T) {
+	octets, err := ioutil.ReadFile("../../testdata/otlp/checkout.json")
+	require.NoError(t, err)
+
+	spans, err := (&JSON{}).Decode(octets)
+	require.NoError(t, err)
+
+	got, err := codec.NewTrace(spans)
+	require.NoError(t, err)
+
+	want := trace.Trace{
+		{
+			ID:          "eee19b7ec3c1b174",
+			TraceID:     "5b8efff798038103d269b633813fc60c",
+			Name:        "POST /checkout",
+			ParentID:    "eee19b7ec3c1b174",
+			ServiceName: "Checkout",
+			Timestamp:   time.Unix(0, 1585062150000000000).UTC(),
+			Duration:    250 * time.Millisecond,
+			Annotations: []trace.Annotation{
+				{
+					Timestamp:   time.Unix(0, 1585062150100000000).UTC(),
+					Value:       "payment declined",
+					Host:        "web01",
+					ServiceName: "Checkout",
+				},
+			},
+			BinaryAnnotations: []trace.BinaryAnnotation{
+				{
+					Key:         "http.status_code",
+					Value:       "500",
+					Host:        "web01",
+					ServiceName: "Checkout",
+				},
+				{
+					Key:         "error",
+					Value:       "payment declined",
+					Host:        "web01",
+					ServiceName: "Checkout",
+				},
+			},
+		},
+		{
+			ID:          "eee19b7ec3c1b175",
+			TraceID:     "5b8efff798038103d269b633813fc60c",
+			Name:        "charge",
+			ParentID:    "eee19b7ec3c1b174",
+			ServiceName: "Checkout",
+			Timestamp:   time.Unix(0, 1585062150050000000).UTC(),
+			Duration:    40 * time.Millisecond,
+			Annotations: []trace.Annotation{},
+			BinaryAnnotations: []trace.BinaryAnnotation{
+				{
+					Key:         "retry",
+					Value:       "false",
+					Host:        "web01",
+					ServiceName: "Checkout",
+				},
+				{
+					Key:         "amount",
+					Value:       "12.5",
+					Host:        "web01",
+					ServiceName: "Checkout",
+				},
+			},
+		},
+	}
+	require.Equal(t, want, got)
+}
+
+func TestJSON_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		octets string
+	}{
+		{
+			name:   "bad json",
+			octets: `{"resourceSpans": [`,
+		},
+		{
+			name:   "missing trace id",
+			octets: `{"resourceSpans": [{"scopeSpans": [{"spans": [{"spanId": "1"}]}]}]}`,
+		},
+		{
+			name:   "invalid timestamp",
+			octets: `{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "1", "spanId": "2", "startTimeUnixNano": "now"}]}]}]}`,
+		},
+		{
+			name:   "invalid status code",
+			octets: `{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "1", "spanId": "2", "status": {"code": "BROKEN"}}]}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&JSON{}).Decode([]byte(tt.octets))
+			require.Error(t, err)
+		})
+	}
+}
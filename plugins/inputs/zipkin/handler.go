@@ -18,6 +18,7 @@ import (
 // span data and sends it to the recorder
 type SpanHandler struct {
 	Path     string
+	decoder  codec.Decoder
 	recorder Recorder
 }
 
@@ -28,6 +29,15 @@ func NewSpanHandler(path string) *SpanHandler {
 	}
 }
 
+// NewDecoderSpanHandler returns a new server instance given path to handle,
+// which decodes all spans with decoder regardless of their Content-Type
+func NewDecoderSpanHandler(path string, decoder codec.Decoder) *SpanHandler {
+	return &SpanHandler{
+		Path:    path,
+		decoder: decoder,
+	}
+}
+
 func cors(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if origin := r.Header.Get("Origin"); origin != "" {
@@ -82,10 +92,14 @@ func (s *SpanHandler) Spans(w http.ResponseWriter, r *http.Request) {
 		defer body.Close()
 	}
 
-	decoder, err := ContentDecoder(r)
-	if err != nil {
-		s.recorder.Error(err)
-		w.WriteHeader(http.StatusUnsupportedMediaType)
+	decoder := s.decoder
+	if decoder == nil {
+		decoder, err = ContentDecoder(r)
+		if err != nil {
+			s.recorder.Error(err)
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
 	}
 
 	octets, err := ioutil.ReadAll(body)
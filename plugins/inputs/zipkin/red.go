@@ -0,0 +1,206 @@
+package zipkin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/caio/go-tdigest"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+)
+
+// REDMeasurement is the measurement of the request rate, error rate and
+// duration statistics aggregated from spans
+const REDMeasurement = "zipkin_red"
+
+// REDRecorder implements the Recorder interface; rather than storing every
+// span it aggregates the spans per service and span name, and adds their
+// request rate, error rate and duration (RED) statistics to the accumulator
+// on every Flush.  The memory used per service and span name is bounded: the
+// histogram buckets are counted as spans are recorded, and the percentiles
+// are estimated with a t-digest.
+type REDRecorder struct {
+	acc         telegraf.Accumulator
+	percentiles []float64
+	buckets     []time.Duration
+
+	mu    sync.Mutex
+	stats map[redKey]*redStats
+	since time.Time
+}
+
+type redKey struct {
+	service string
+	name    string
+}
+
+type redStats struct {
+	count   int64
+	errors  int64
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+	buckets []int64
+	digest  *tdigest.TDigest
+}
+
+func newREDStats(buckets int) (*redStats, error) {
+	digest, err := tdigest.New()
+	if err != nil {
+		return nil, err
+	}
+	return &redStats{
+		buckets: make([]int64, buckets),
+		digest:  digest,
+	}, nil
+}
+
+// NewREDRecorder returns a REDRecorder that adds the duration percentiles
+// and a histogram of the durations with the upper bounds of buckets to acc.
+func NewREDRecorder(acc telegraf.Accumulator, percentiles []float64, buckets []time.Duration) *REDRecorder {
+	return &REDRecorder{
+		acc:         acc,
+		percentiles: percentiles,
+		buckets:     buckets,
+		stats:       make(map[redKey]*redStats),
+		since:       time.Now(),
+	}
+}
+
+// Record adds the spans of the trace to the statistics of the interval.
+func (r *REDRecorder) Record(t trace.Trace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range t {
+		key := redKey{
+			service: formatName(s.ServiceName),
+			name:    formatName(s.Name),
+		}
+		stats, ok := r.stats[key]
+		if !ok {
+			var err error
+			stats, err = newREDStats(len(r.buckets))
+			if err != nil {
+				return err
+			}
+			r.stats[key] = stats
+		}
+
+		if stats.count == 0 || s.Duration < stats.min {
+			stats.min = s.Duration
+		}
+		if stats.count == 0 || s.Duration > stats.max {
+			stats.max = s.Duration
+		}
+		stats.count++
+		stats.sum += s.Duration
+		if isError(s) {
+			stats.errors++
+		}
+
+		// Only the first bucket with an upper bound of at least the duration
+		// is counted, the cumulative counts are computed on Flush.
+		i := sort.Search(len(r.buckets), func(i int) bool {
+			return r.buckets[i] >= s.Duration
+		})
+		if i < len(stats.buckets) {
+			stats.buckets[i]++
+		}
+		if err := stats.digest.Add(float64(s.Duration)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *REDRecorder) Error(err error) {
+	r.acc.AddError(err)
+}
+
+// Flush adds the statistics of the spans recorded since the last flush to
+// the accumulator, and starts a new interval at now.
+func (r *REDRecorder) Flush(now time.Time) {
+	r.mu.Lock()
+	stats := r.stats
+	elapsed := now.Sub(r.since).Seconds()
+	r.stats = make(map[redKey]*redStats)
+	r.since = now
+	r.mu.Unlock()
+
+	for key, s := range stats {
+		tags := map[string]string{
+			"service_name": key.service,
+			"name":         key.name,
+		}
+
+		fields := map[string]interface{}{
+			"requests":         s.count,
+			"errors":           s.errors,
+			"error_ratio":      float64(s.errors) / float64(s.count),
+			"duration_ns_min":  s.min.Nanoseconds(),
+			"duration_ns_max":  s.max.Nanoseconds(),
+			"duration_ns_mean": float64(s.sum.Nanoseconds()) / float64(s.count),
+		}
+		if elapsed > 0 {
+			fields["request_rate"] = float64(s.count) / elapsed
+			fields["error_rate"] = float64(s.errors) / elapsed
+		}
+		for _, p := range r.percentiles {
+			name := "duration_ns_p" + strconv.FormatFloat(p, 'f', -1, 64)
+			fields[name] = s.percentile(p).Nanoseconds()
+		}
+		r.acc.AddFields(REDMeasurement, fields, tags, now)
+
+		if len(r.buckets) == 0 {
+			continue
+		}
+		var cumulative int64
+		for i, bound := range r.buckets {
+			cumulative += s.buckets[i]
+			r.addBucket(tags, fmt.Sprint(bound.Nanoseconds()), cumulative, now)
+		}
+		r.addBucket(tags, "+Inf", s.count, now)
+	}
+}
+
+// addBucket adds the cumulative count of a histogram bucket with the upper
+// bound le.
+func (r *REDRecorder) addBucket(tags map[string]string, le string, count int64, now time.Time) {
+	bucketTags := map[string]string{"le": le}
+	for k, v := range tags {
+		bucketTags[k] = v
+	}
+	fields := map[string]interface{}{
+		"duration_ns_bucket": count,
+	}
+	r.acc.AddFields(REDMeasurement, fields, bucketTags, now)
+}
+
+// percentile returns the estimated percentile p of the durations, limited to
+// the observed minimum and maximum.
+func (s *redStats) percentile(p float64) time.Duration {
+	d := time.Duration(math.Round(s.digest.Quantile(p / 100)))
+	if d < s.min {
+		return s.min
+	}
+	if d > s.max {
+		return s.max
+	}
+	return d
+}
+
+// isError reports whether the span has an "error" binary annotation, which
+// zipkin, jaeger and the OpenTelemetry status use to mark failed requests.
+func isError(s trace.Span) bool {
+	for _, b := range s.BinaryAnnotations {
+		if b.Key == "error" && b.Value != "false" {
+			return true
+		}
+	}
+	return false
+}
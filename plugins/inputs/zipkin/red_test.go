@@ -0,0 +1,144 @@
+package zipkin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestREDRecorder(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	r := NewREDRecorder(acc, []float64{50, 99}, []time.Duration{10 * time.Millisecond, 100 * time.Millisecond})
+
+	start := time.Unix(1585062150, 0)
+	r.since = start
+
+	failed := []trace.BinaryAnnotation{{Key: "error", Value: "true"}}
+	succeeded := []trace.BinaryAnnotation{{Key: "error", Value: "false"}}
+	err := r.Record(trace.Trace{
+		{ServiceName: "Frontend", Name: "GET", Duration: 5 * time.Millisecond},
+		{ServiceName: "frontend", Name: "get", Duration: 20 * time.Millisecond, BinaryAnnotations: succeeded},
+		{ServiceName: "frontend", Name: "get", Duration: 30 * time.Millisecond},
+		{ServiceName: "frontend", Name: "get", Duration: 200 * time.Millisecond, BinaryAnnotations: failed},
+		{ServiceName: "redis", Name: "find", Duration: 1 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	now := start.Add(10 * time.Second)
+	r.Flush(now)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "frontend", "name": "get"},
+			map[string]interface{}{
+				"requests":         int64(4),
+				"errors":           int64(1),
+				"request_rate":     0.4,
+				"error_rate":       0.1,
+				"error_ratio":      0.25,
+				"duration_ns_min":  int64(5000000),
+				"duration_ns_max":  int64(200000000),
+				"duration_ns_mean": float64(63750000),
+				"duration_ns_p50":  int64(25000000),
+				"duration_ns_p99":  int64(194900000),
+			},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "frontend", "name": "get", "le": "10000000"},
+			map[string]interface{}{"duration_ns_bucket": int64(1)},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "frontend", "name": "get", "le": "100000000"},
+			map[string]interface{}{"duration_ns_bucket": int64(3)},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "frontend", "name": "get", "le": "+Inf"},
+			map[string]interface{}{"duration_ns_bucket": int64(4)},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "redis", "name": "find"},
+			map[string]interface{}{
+				"requests":         int64(1),
+				"errors":           int64(0),
+				"request_rate":     0.1,
+				"error_rate":       float64(0),
+				"error_ratio":      float64(0),
+				"duration_ns_min":  int64(1000000),
+				"duration_ns_max":  int64(1000000),
+				"duration_ns_mean": float64(1000000),
+				"duration_ns_p50":  int64(1000000),
+				"duration_ns_p99":  int64(1000000),
+			},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "redis", "name": "find", "le": "10000000"},
+			map[string]interface{}{"duration_ns_bucket": int64(1)},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "redis", "name": "find", "le": "100000000"},
+			map[string]interface{}{"duration_ns_bucket": int64(1)},
+			now,
+		),
+		testutil.MustMetric(
+			REDMeasurement,
+			map[string]string{"service_name": "redis", "name": "find", "le": "+Inf"},
+			map[string]interface{}{"duration_ns_bucket": int64(1)},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Keys without spans in the interval are not added
+	acc.ClearMetrics()
+	r.Flush(now.Add(10 * time.Second))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestREDRecorderManySpans(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	r := NewREDRecorder(acc, []float64{50, 99}, []time.Duration{time.Millisecond, 5 * time.Millisecond})
+
+	// Durations of 1µs to 10ms; the durations are not stored, so the
+	// percentiles are estimates.
+	for i := 1; i <= 10000; i++ {
+		err := r.Record(trace.Trace{
+			{ServiceName: "frontend", Name: "get", Duration: time.Duration(i) * time.Microsecond},
+		})
+		require.NoError(t, err)
+	}
+	r.Flush(time.Now())
+
+	buckets := make(map[string]int64)
+	var fields map[string]interface{}
+	for _, m := range acc.GetTelegrafMetrics() {
+		if le, ok := m.GetTag("le"); ok {
+			buckets[le] = m.Fields()["duration_ns_bucket"].(int64)
+			continue
+		}
+		fields = m.Fields()
+	}
+	require.Equal(t, map[string]int64{"1000000": 1000, "5000000": 5000, "+Inf": 10000}, buckets)
+	require.Equal(t, int64(10000), fields["requests"])
+	require.Equal(t, int64(1000), fields["duration_ns_min"])
+	require.Equal(t, int64(10000000), fields["duration_ns_max"])
+	require.Equal(t, 5000500.0, fields["duration_ns_mean"])
+	require.InDelta(t, 5000000, fields["duration_ns_p50"], 50000)
+	require.InDelta(t, 9900000, fields["duration_ns_p99"], 50000)
+}
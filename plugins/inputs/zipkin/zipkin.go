@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec/jaeger"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec/otlp"
 	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
 )
 
@@ -23,6 +26,21 @@ const (
 	// expect.
 	DefaultRoute = "/api/v1/spans"
 
+	// JaegerRoute is the route of traces in the JSON format of the jaeger
+	// query API; it is not enabled by default.
+	JaegerRoute = "/api/traces"
+
+	// OTLPRoute is the route OTLP/HTTP exporters send spans in the JSON
+	// encoding of the OpenTelemetry protocol to; it is not enabled by default.
+	OTLPRoute = "/v1/traces"
+
+	// ModeSpans records every span and annotation as a metric
+	ModeSpans = "spans"
+
+	// ModeRED aggregates the spans to request rate, error rate and duration
+	// statistics per service and span name
+	ModeRED = "red"
+
 	// DefaultShutdownTimeout is the max amount of time telegraf will wait
 	// for the plugin to shutdown
 	DefaultShutdownTimeout = 5
@@ -49,20 +67,45 @@ type Handler interface {
 const sampleConfig = `
   # path = "/api/v1/spans" # URL path for span data
   # port = 9411            # Port on which Telegraf listens
+
+  ## URL paths for spans in the JSON formats of jaeger and OpenTelemetry
+  ## (OTLP/HTTP), e.g. "/api/traces" and "/v1/traces"; an empty path disables
+  ## the format.
+  # jaeger_path = ""
+  # otlp_path = ""
+
+  ## Mode of the plugin; "spans" outputs a metric for every span and
+  ## annotation, "red" aggregates the spans of each interval to request rate,
+  ## error rate and duration statistics per service and span name.
+  # mode = "spans"
+
+  ## Percentiles of the span durations in "red" mode.
+  # percentiles = [50.0, 90.0, 99.0]
+
+  ## Upper bounds of the buckets of the span duration histogram in "red"
+  ## mode; an empty list disables the histogram.
+  # histogram_buckets = ["5ms", "10ms", "25ms", "50ms", "100ms", "250ms", "500ms", "1s", "2.5s", "5s", "10s"]
 `
 
 // Zipkin is a telegraf configuration structure for the zipkin input plugin,
 // but it also contains fields for the management of a separate, concurrent
 // zipkin http server
 type Zipkin struct {
-	ServiceAddress string
-	Port           int
-	Path           string
+	ServiceAddress   string
+	Port             int
+	Path             string
+	JaegerPath       string    `toml:"jaeger_path"`
+	OTLPPath         string    `toml:"otlp_path"`
+	Mode             string    `toml:"mode"`
+	Percentiles      []float64 `toml:"percentiles"`
+	HistogramBuckets []string  `toml:"histogram_buckets"`
 
 	Log telegraf.Logger
 
 	address   string
-	handler   Handler
+	buckets   []time.Duration
+	handlers  []Handler
+	red       *REDRecorder
 	server    *http.Server
 	waitGroup *sync.WaitGroup
 }
@@ -77,22 +120,82 @@ func (z Zipkin) SampleConfig() string {
 	return sampleConfig
 }
 
-// Gather is empty for the zipkin plugin; all gathering is done through
-// the separate goroutine launched in (*Zipkin).Start()
-func (z *Zipkin) Gather(acc telegraf.Accumulator) error { return nil }
+// Init validates the mode, percentiles and histogram buckets, and that the
+// paths of the span formats differ.
+func (z *Zipkin) Init() error {
+	switch z.Mode {
+	case "":
+		z.Mode = ModeSpans
+	case ModeSpans, ModeRED:
+	default:
+		return fmt.Errorf("invalid mode %q", z.Mode)
+	}
+
+	for _, p := range z.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile %v, must be in (0, 100]", p)
+		}
+	}
+
+	z.buckets = make([]time.Duration, 0, len(z.HistogramBuckets))
+	for _, b := range z.HistogramBuckets {
+		d, err := time.ParseDuration(b)
+		if err != nil {
+			return fmt.Errorf("invalid histogram bucket %q: %v", b, err)
+		}
+		if n := len(z.buckets); n > 0 && d <= z.buckets[n-1] {
+			return fmt.Errorf("histogram buckets must be in increasing order")
+		}
+		z.buckets = append(z.buckets, d)
+	}
+
+	paths := make(map[string]bool)
+	for _, path := range []string{z.Path, z.JaegerPath, z.OTLPPath} {
+		if path == "" {
+			continue
+		}
+		if paths[path] {
+			return fmt.Errorf("path %q is used for more than one span format", path)
+		}
+		paths[path] = true
+	}
+	return nil
+}
+
+// Gather adds the RED statistics of the spans received since the last
+// gather in "red" mode; all other gathering is done through the separate
+// goroutine launched in (*Zipkin).Start()
+func (z *Zipkin) Gather(acc telegraf.Accumulator) error {
+	if z.red != nil {
+		z.red.Flush(time.Now())
+	}
+	return nil
+}
 
 // Start launches a separate goroutine for collecting zipkin client http requests,
 // passing in a telegraf.Accumulator such that data can be collected.
 func (z *Zipkin) Start(acc telegraf.Accumulator) error {
-	z.handler = NewSpanHandler(z.Path)
+	z.handlers = []Handler{NewSpanHandler(z.Path)}
+	if z.JaegerPath != "" {
+		z.handlers = append(z.handlers, NewDecoderSpanHandler(z.JaegerPath, &jaeger.JSON{}))
+	}
+	if z.OTLPPath != "" {
+		z.handlers = append(z.handlers, NewDecoderSpanHandler(z.OTLPPath, &otlp.JSON{}))
+	}
 
 	var wg sync.WaitGroup
 	z.waitGroup = &wg
 
 	router := mux.NewRouter()
-	converter := NewLineProtocolConverter(acc)
-	if err := z.handler.Register(router, converter); err != nil {
-		return err
+	var recorder Recorder = NewLineProtocolConverter(acc)
+	if z.Mode == ModeRED {
+		z.red = NewREDRecorder(acc, z.Percentiles, z.buckets)
+		recorder = z.red
+	}
+	for _, handler := range z.handlers {
+		if err := handler.Register(router, recorder); err != nil {
+			return err
+		}
 	}
 
 	z.server = &http.Server{
@@ -108,8 +211,8 @@ func (z *Zipkin) Start(acc telegraf.Accumulator) error {
 	z.address = ln.Addr().String()
 	z.Log.Infof("Started the zipkin listener on %s", z.address)
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		defer wg.Done()
 
 		z.Listen(ln, acc)
@@ -146,8 +249,14 @@ func (z *Zipkin) Listen(ln net.Listener, acc telegraf.Accumulator) {
 func init() {
 	inputs.Add("zipkin", func() telegraf.Input {
 		return &Zipkin{
-			Path: DefaultRoute,
-			Port: DefaultPort,
+			Path:        DefaultRoute,
+			Port:        DefaultPort,
+			Mode:        ModeSpans,
+			Percentiles: []float64{50, 90, 99},
+			HistogramBuckets: []string{
+				"5ms", "10ms", "25ms", "50ms", "100ms", "250ms", "500ms",
+				"1s", "2.5s", "5s", "10s",
+			},
 		}
 	})
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
 )
 
 func TestZipkinPlugin(t *testing.T) {
@@ -636,13 +637,131 @@ func TestZipkinPlugin(t *testing.T) {
 	}
 }
 
+func TestZipkinPluginRED(t *testing.T) {
+	DefaultNetwork = "tcp4"
+
+	acc := &testutil.Accumulator{}
+	z := &Zipkin{
+		Log:              testutil.Logger{},
+		Path:             DefaultRoute,
+		JaegerPath:       JaegerRoute,
+		OTLPPath:         OTLPRoute,
+		Mode:             ModeRED,
+		Percentiles:      []float64{50},
+		HistogramBuckets: []string{"100ms"},
+	}
+	require.NoError(t, z.Init())
+	require.NoError(t, z.Start(acc))
+	defer z.Stop()
+
+	require.NoError(t, postData("testdata/jaeger/hotrod.json", z.address, JaegerRoute, "application/json"))
+	require.NoError(t, postData("testdata/otlp/checkout.json", z.address, OTLPRoute, "application/json"))
+	require.NoError(t, postData("testdata/threespans.dat", z.address, DefaultRoute, "application/x-thrift"))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	require.NoError(t, z.Gather(acc))
+
+	requests := make(map[string]int64)
+	errors := make(map[string]int64)
+	buckets := make(map[string]int64)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, REDMeasurement, m.Name())
+		key := m.Tags()["service_name"] + "/" + m.Tags()["name"]
+		if le, ok := m.GetTag("le"); ok {
+			buckets[key+"/"+le] = m.Fields()["duration_ns_bucket"].(int64)
+			continue
+		}
+		requests[key] = m.Fields()["requests"].(int64)
+		errors[key] = m.Fields()["errors"].(int64)
+	}
+
+	require.Equal(t, map[string]int64{
+		"frontend/http get /dispatch": 1,
+		"redis/finddriverids":         1,
+		"checkout/post /checkout":     1,
+		"checkout/charge":             1,
+		"trivial/child":               2,
+		"trivial/parent":              1,
+	}, requests)
+	require.Equal(t, map[string]int64{
+		"frontend/http get /dispatch": 0,
+		"redis/finddriverids":         1,
+		"checkout/post /checkout":     1,
+		"checkout/charge":             0,
+		"trivial/child":               0,
+		"trivial/parent":              0,
+	}, errors)
+	require.Equal(t, int64(0), buckets["checkout/post /checkout/100000000"])
+	require.Equal(t, int64(1), buckets["checkout/charge/100000000"])
+	require.Equal(t, int64(1), buckets["checkout/post /checkout/+Inf"])
+
+	// Only the spans received since the last gather are aggregated
+	acc.ClearMetrics()
+	require.NoError(t, z.Gather(acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestZipkinInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		zipkin  *Zipkin
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			zipkin: &Zipkin{Path: DefaultRoute, JaegerPath: JaegerRoute, OTLPPath: OTLPRoute},
+		},
+		{
+			name:    "invalid mode",
+			zipkin:  &Zipkin{Path: DefaultRoute, Mode: "traces"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid percentile",
+			zipkin:  &Zipkin{Path: DefaultRoute, Mode: ModeRED, Percentiles: []float64{0}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid bucket",
+			zipkin:  &Zipkin{Path: DefaultRoute, Mode: ModeRED, HistogramBuckets: []string{"1"}},
+			wantErr: true,
+		},
+		{
+			name:    "unordered buckets",
+			zipkin:  &Zipkin{Path: DefaultRoute, Mode: ModeRED, HistogramBuckets: []string{"1s", "10ms"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate path",
+			zipkin:  &Zipkin{Path: DefaultRoute, OTLPPath: DefaultRoute},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.zipkin.Init()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ModeSpans, tt.zipkin.Mode)
+		})
+	}
+}
+
 func postThriftData(datafile, address, contentType string) error {
+	return postData(datafile, address, DefaultRoute, contentType)
+}
+
+func postData(datafile, address, path, contentType string) error {
 	dat, err := ioutil.ReadFile(datafile)
 	if err != nil {
 		return fmt.Errorf("could not read from data file %s", datafile)
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/api/v1/spans", address), bytes.NewReader(dat))
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s%s", address, path), bytes.NewReader(dat))
 	if err != nil {
 		return fmt.Errorf("HTTP request creation failed")
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -47,6 +48,7 @@ type AMQP struct {
 	AuthMethod         string            `toml:"auth_method"`
 	RoutingTag         string            `toml:"routing_tag"`
 	RoutingKey         string            `toml:"routing_key"`
+	RoutingKeyTemplate string            `toml:"routing_key_template"`
 	DeliveryMode       string            `toml:"delivery_mode"`
 	Database           string            `toml:"database"`         // deprecated in 1.7; use headers
 	RetentionPolicy    string            `toml:"retention_policy"` // deprecated in 1.7; use headers
@@ -57,12 +59,13 @@ type AMQP struct {
 	ContentEncoding    string            `toml:"content_encoding"`
 	tls.ClientConfig
 
-	serializer   serializers.Serializer
-	connect      func(*ClientConfig) (Client, error)
-	client       Client
-	config       *ClientConfig
-	sentMessages int
-	encoder      internal.ContentEncoder
+	serializer         serializers.Serializer
+	connect            func(*ClientConfig) (Client, error)
+	client             Client
+	config             *ClientConfig
+	sentMessages       int
+	encoder            internal.ContentEncoder
+	routingKeyTemplate *templating.MetricTemplate
 }
 
 type Client interface {
@@ -119,6 +122,11 @@ var sampleConfig = `
   # routing_key = ""
   # routing_key = "telegraf"
 
+  ## Go template of the routing key, taking precedence over the routing_tag
+  ## and routing_key when rendering a non-empty key.  The template can use
+  ## {{.Name}}, {{.Tag "key"}}, {{.Tags}} and {{.Time}}.
+  # routing_key_template = 'telegraf.{{.Name}}.{{.Tag "host"}}'
+
   ## Delivery Mode controls if a published message is persistent.
   ##   One of "transient" or "persistent".
   # delivery_mode = "transient"
@@ -193,6 +201,13 @@ func (q *AMQP) Connect() error {
 		return err
 	}
 
+	if q.RoutingKeyTemplate != "" && q.routingKeyTemplate == nil {
+		q.routingKeyTemplate, err = templating.NewMetricTemplate(q.RoutingKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid routing_key_template: %v", err)
+		}
+	}
+
 	q.client, err = q.connect(q.config)
 	if err != nil {
 		return err
@@ -209,6 +224,14 @@ func (q *AMQP) Close() error {
 }
 
 func (q *AMQP) routingKey(metric telegraf.Metric) string {
+	if q.routingKeyTemplate != nil {
+		key, err := q.routingKeyTemplate.Render(metric)
+		if err != nil {
+			log.Printf("E! [outputs.amqp] Could not render routing key: %v", err)
+		} else if key != "" {
+			return key
+		}
+	}
 	if q.RoutingTag != "" {
 		key, ok := metric.GetTag(q.RoutingTag)
 		if ok {
@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/require"
 )
@@ -160,3 +163,53 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+func TestRoutingKeyTemplate(t *testing.T) {
+	published := make(map[string]string)
+	client := &MockClient{
+		PublishF: func(key string, body []byte) error {
+			published[key] += string(body)
+			return nil
+		},
+		CloseF: func() error {
+			return nil
+		},
+	}
+
+	q := &AMQP{
+		Brokers:            []string{DefaultURL},
+		ExchangeType:       DefaultExchangeType,
+		RoutingTag:         "host",
+		RoutingKey:         "telegraf",
+		RoutingKeyTemplate: `{{if .Tag "region"}}{{.Name}}.{{.Tag "region"}}{{end}}`,
+		connect: func(config *ClientConfig) (Client, error) {
+			return client, nil
+		},
+	}
+	q.SetSerializer(influx.NewSerializer())
+	require.NoError(t, q.Connect())
+
+	require.NoError(t, q.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"region": "eu"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{"region": "eu", "host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 4.0}, time.Unix(0, 0)),
+	}))
+
+	// an empty rendered key falls back to the routing tag and key
+	require.Equal(t, map[string]string{
+		"cpu.eu":   "cpu,region=eu value=1 0\n",
+		"mem.eu":   "mem,host=a,region=eu value=2 0\n",
+		"a":        "cpu,host=a value=3 0\n",
+		"telegraf": "cpu value=4 0\n",
+	}, published)
+}
+
+func TestInvalidRoutingKeyTemplate(t *testing.T) {
+	q := &AMQP{
+		Brokers:            []string{DefaultURL},
+		RoutingKeyTemplate: "{{.Name",
+		connect:            MockConnect,
+	}
+	require.Error(t, q.Connect())
+}
@@ -0,0 +1,577 @@
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	layoutWide   = "wide"
+	layoutNarrow = "narrow"
+)
+
+const sampleConfig = `
+  ## URL of the ClickHouse HTTP interface.
+  url = "http://127.0.0.1:8123"
+
+  ## Credentials for the ClickHouse server.
+  # username = "default"
+  # password = ""
+
+  ## Database holding the tables.
+  # database = "default"
+
+  ## HTTP request timeout.
+  # timeout = "5s"
+
+  ## Format of the inserted rows, "RowBinary" or "JSONEachRow".
+  # format = "RowBinary"
+
+  ## Table layout, one of:
+  ##   wide   - one table per measurement with a column for each tag and field
+  ##   narrow - a single table with one row per field and the columns
+  ##            timestamp, name, tags, field and value
+  # table_layout = "wide"
+
+  ## Wide layout: prefix prepended to the measurement to form the table name.
+  # table_prefix = ""
+
+  ## Narrow layout: name of the table.
+  # table = "telegraf"
+
+  ## Name of the column holding the metric timestamp.
+  # timestamp_column = "timestamp"
+
+  ## Create missing tables and, in the wide layout, add missing columns.
+  # create_tables = true
+
+  ## Table engine used when creating tables.
+  # engine = "MergeTree()"
+
+  ## Time to live of the rows in created tables, zero disables expiry.
+  # ttl = "0s"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+type ClickHouse struct {
+	URL             string            `toml:"url"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	Database        string            `toml:"database"`
+	Timeout         internal.Duration `toml:"timeout"`
+	Format          string            `toml:"format"`
+	TableLayout     string            `toml:"table_layout"`
+	TablePrefix     string            `toml:"table_prefix"`
+	Table           string            `toml:"table"`
+	TimestampColumn string            `toml:"timestamp_column"`
+	CreateTables    bool              `toml:"create_tables"`
+	Engine          string            `toml:"engine"`
+	TTL             internal.Duration `toml:"ttl"`
+	tls.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	client  *http.Client
+	encoder encoder
+
+	// tables caches the known columns and their types of each table.
+	tables map[string]map[string]string
+}
+
+// column is the definition of a table column.
+type column struct {
+	name   string
+	chType string
+	tag    bool
+}
+
+func (c *ClickHouse) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *ClickHouse) Description() string {
+	return "Save metrics to ClickHouse using the HTTP interface"
+}
+
+func (c *ClickHouse) Init() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	enc, ok := encoders[c.Format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", c.Format)
+	}
+	c.encoder = enc
+
+	switch c.TableLayout {
+	case layoutWide:
+	case layoutNarrow:
+		if c.Table == "" {
+			return fmt.Errorf("table is required for the narrow layout")
+		}
+	default:
+		return fmt.Errorf("unknown table_layout %q", c.TableLayout)
+	}
+	return nil
+}
+
+func (c *ClickHouse) Connect() error {
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: c.Timeout.Duration,
+	}
+	c.tables = make(map[string]map[string]string)
+	return nil
+}
+
+func (c *ClickHouse) Close() error {
+	return nil
+}
+
+func (c *ClickHouse) Write(metrics []telegraf.Metric) error {
+	if c.TableLayout == layoutNarrow {
+		if err := c.writeNarrow(metrics); err != nil {
+			return fmt.Errorf("writing to table %q: %v", c.Table, err)
+		}
+		return nil
+	}
+
+	// Group the metrics by table keeping the order of first appearance.
+	var order []string
+	batches := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		table := c.TablePrefix + m.Name()
+		if _, ok := batches[table]; !ok {
+			order = append(order, table)
+		}
+		batches[table] = append(batches[table], m)
+	}
+
+	for _, table := range order {
+		if err := c.writeWide(table, batches[table]); err != nil {
+			return fmt.Errorf("writing to table %q: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// writeWide inserts the metrics into a table with a column for each tag and
+// field.
+func (c *ClickHouse) writeWide(table string, metrics []telegraf.Metric) error {
+	columns := c.wideColumns(metrics)
+	known, err := c.ensureTable(table, columns, c.wideOrderBy(columns))
+	if err != nil {
+		return err
+	}
+
+	// Existing columns keep their type.
+	if err := setColumnTypes(columns, known); err != nil {
+		return err
+	}
+	index := make(map[string]int, len(columns))
+	for i := range columns {
+		index[columns[i].name] = i
+	}
+
+	rows := make([][]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		row := make([]interface{}, len(columns))
+		row[0] = m.Time()
+		for _, tag := range m.TagList() {
+			i, ok := index[tag.Key]
+			if !ok || !columns[i].tag {
+				continue
+			}
+			if v, ok := convert(tag.Value, columns[i].chType); ok {
+				row[i] = v
+			} else {
+				c.Log.Debugf("Cannot store tag %q in column of type %s", tag.Key, columns[i].chType)
+			}
+		}
+		for _, field := range m.FieldList() {
+			i, ok := index[field.Key]
+			if !ok || columns[i].tag {
+				continue
+			}
+			if v, ok := convert(field.Value, columns[i].chType); ok {
+				row[i] = v
+			} else {
+				c.Log.Debugf("Cannot store field %q of type %T in column of type %s",
+					field.Key, field.Value, columns[i].chType)
+			}
+		}
+		// Columns which are not nullable, such as the tag columns, use the
+		// default value of the type.
+		for i, col := range columns {
+			if row[i] == nil {
+				row[i] = defaultValue(col.chType)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return c.insert(table, columns, rows)
+}
+
+// wideColumns returns the timestamp column followed by the sorted tag and
+// field columns used by the metrics.  A field with the same key as a tag is
+// not stored.
+func (c *ClickHouse) wideColumns(metrics []telegraf.Metric) []column {
+	tags := make(map[string]bool)
+	fields := make(map[string]string)
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if tag.Key != c.TimestampColumn {
+				tags[tag.Key] = true
+			}
+		}
+		for _, field := range m.FieldList() {
+			if field.Key == c.TimestampColumn {
+				continue
+			}
+			if _, ok := fields[field.Key]; !ok {
+				fields[field.Key] = "Nullable(" + columnType(field.Value) + ")"
+			}
+		}
+	}
+
+	columns := []column{{name: c.TimestampColumn, chType: "DateTime64(9)"}}
+
+	tagColumns := make([]column, 0, len(tags))
+	for key := range tags {
+		tagColumns = append(tagColumns, column{name: key, chType: "LowCardinality(String)", tag: true})
+	}
+	sort.Slice(tagColumns, func(i, j int) bool { return tagColumns[i].name < tagColumns[j].name })
+
+	fieldColumns := make([]column, 0, len(fields))
+	for key, chType := range fields {
+		if tags[key] {
+			continue
+		}
+		fieldColumns = append(fieldColumns, column{name: key, chType: chType})
+	}
+	sort.Slice(fieldColumns, func(i, j int) bool { return fieldColumns[i].name < fieldColumns[j].name })
+
+	columns = append(columns, tagColumns...)
+	return append(columns, fieldColumns...)
+}
+
+// wideOrderBy returns the sorting key of a new table, the tag columns
+// followed by the timestamp.
+func (c *ClickHouse) wideOrderBy(columns []column) []string {
+	var orderBy []string
+	for _, col := range columns {
+		if col.tag {
+			orderBy = append(orderBy, col.name)
+		}
+	}
+	return append(orderBy, c.TimestampColumn)
+}
+
+// writeNarrow inserts a row for each numeric field into the single table.
+func (c *ClickHouse) writeNarrow(metrics []telegraf.Metric) error {
+	columns := []column{
+		{name: c.TimestampColumn, chType: "DateTime64(9)"},
+		{name: "name", chType: "LowCardinality(String)"},
+		{name: "tags", chType: "Map(String, String)"},
+		{name: "field", chType: "LowCardinality(String)"},
+		{name: "value", chType: "Float64"},
+	}
+	known, err := c.ensureTable(c.Table, columns, []string{"name", "field", c.TimestampColumn})
+	if err != nil {
+		return err
+	}
+	if err := setColumnTypes(columns, known); err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for _, m := range metrics {
+		tags := m.Tags()
+		for _, field := range m.FieldList() {
+			value, ok := convert(field.Value, columns[4].chType)
+			if !ok {
+				continue
+			}
+			rows = append(rows, []interface{}{m.Time(), m.Name(), tags, field.Key, value})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return c.insert(c.Table, columns, rows)
+}
+
+// setColumnTypes sets the types of the columns to the ones of the table.  It
+// fails if the first column, the timestamp, is not a DateTime or DateTime64
+// column, or if another column has a type the values cannot be written as.
+func setColumnTypes(columns []column, known map[string]string) error {
+	for i := range columns {
+		chType := known[columns[i].name]
+		if i == 0 {
+			if _, ok := timePrecision(chType); !ok {
+				return fmt.Errorf("timestamp column %q has type %s instead of DateTime or DateTime64",
+					columns[i].name, chType)
+			}
+		} else if !supportedType(chType) {
+			return fmt.Errorf("column %q has unsupported type %s", columns[i].name, chType)
+		}
+		columns[i].chType = chType
+	}
+	return nil
+}
+
+// ensureTable returns the column types of the table, creating the table and
+// adding missing columns if enabled.
+func (c *ClickHouse) ensureTable(table string, columns []column, orderBy []string) (map[string]string, error) {
+	known, ok := c.tables[table]
+	if !ok {
+		var err error
+		known, err = c.columns(table)
+		if err != nil {
+			return nil, fmt.Errorf("reading columns: %v", err)
+		}
+
+		if len(known) == 0 {
+			if !c.CreateTables {
+				return nil, fmt.Errorf("table does not exist")
+			}
+			if err := c.createTable(table, columns, orderBy); err != nil {
+				return nil, err
+			}
+			known = make(map[string]string, len(columns))
+			for _, col := range columns {
+				known[col.name] = col.chType
+			}
+		}
+		c.tables[table] = known
+	}
+
+	for _, col := range columns {
+		if _, ok := known[col.name]; ok {
+			continue
+		}
+		if !c.CreateTables {
+			return nil, fmt.Errorf("column %q does not exist", col.name)
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s",
+			c.tableIdent(table), quoteIdent(col.name), col.chType)
+		if err := c.exec(query, nil); err != nil {
+			return nil, fmt.Errorf("adding column %q: %v", col.name, err)
+		}
+		c.Log.Debugf("Added column %q to table %q", col.name, table)
+		known[col.name] = col.chType
+	}
+	return known, nil
+}
+
+// columns returns the columns of the table, or no columns if the table does
+// not exist.
+func (c *ClickHouse) columns(table string) (map[string]string, error) {
+	query := fmt.Sprintf("SELECT name, type FROM system.columns WHERE database = %s AND table = %s FORMAT JSONEachRow",
+		quoteString(c.Database), quoteString(table))
+
+	var resp bytes.Buffer
+	if err := c.exec(query, &resp); err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]string)
+	scanner := bufio.NewScanner(&resp)
+	for scanner.Scan() {
+		var col struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &col); err != nil {
+			return nil, err
+		}
+		columns[col.Name] = col.Type
+	}
+	return columns, scanner.Err()
+}
+
+func (c *ClickHouse) createTable(table string, columns []column, orderBy []string) error {
+	defs := make([]string, 0, len(columns))
+	for _, col := range columns {
+		defs = append(defs, quoteIdent(col.name)+" "+col.chType)
+	}
+	keys := make([]string, 0, len(orderBy))
+	for _, key := range orderBy {
+		keys = append(keys, quoteIdent(key))
+	}
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = %s ORDER BY (%s)",
+		c.tableIdent(table), strings.Join(defs, ", "), c.Engine, strings.Join(keys, ", "))
+	if c.TTL.Duration > 0 {
+		query += fmt.Sprintf(" TTL toDateTime(%s) + INTERVAL %d SECOND",
+			quoteIdent(c.TimestampColumn), int64(c.TTL.Duration.Seconds()))
+	}
+
+	if err := c.exec(query, nil); err != nil {
+		return fmt.Errorf("creating table: %v", err)
+	}
+	c.Log.Debugf("Created table %q", table)
+	return nil
+}
+
+func (c *ClickHouse) insert(table string, columns []column, rows [][]interface{}) error {
+	body, err := c.encoder.encode(columns, rows)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, quoteIdent(col.name))
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) FORMAT %s",
+		c.tableIdent(table), strings.Join(names, ", "), c.encoder.format())
+	return c.post(query, bytes.NewReader(body), nil)
+}
+
+// exec executes the query, writing the result to w if not nil.
+func (c *ClickHouse) exec(query string, w io.Writer) error {
+	return c.post("", strings.NewReader(query), w)
+}
+
+// post sends the body to the HTTP interface, with the query, if any, as URL
+// parameter.
+func (c *ClickHouse) post(query string, body io.Reader, w io.Writer) error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+	params := u.Query()
+	if c.Database != "" {
+		params.Set("database", c.Database)
+	}
+	if query != "" {
+		params.Set("query", query)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if c.Username != "" {
+		req.Header.Set("X-ClickHouse-User", c.Username)
+	}
+	if c.Password != "" {
+		req.Header.Set("X-ClickHouse-Key", c.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if w != nil {
+		_, err = io.Copy(w, resp.Body)
+	}
+	return err
+}
+
+func (c *ClickHouse) tableIdent(table string) string {
+	if c.Database == "" {
+		return quoteIdent(table)
+	}
+	return quoteIdent(c.Database) + "." + quoteIdent(table)
+}
+
+// columnType returns the ClickHouse type used to store the value.
+func columnType(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return "Int64"
+	case uint64:
+		return "UInt64"
+	case float64:
+		return "Float64"
+	case bool:
+		return "UInt8"
+	default:
+		return "String"
+	}
+}
+
+// quoteIdent quotes an identifier for use in a query.
+func quoteIdent(name string) string {
+	return "`" + escape(name, '`') + "`"
+}
+
+// quoteString quotes a string literal for use in a query.
+func quoteString(s string) string {
+	return "'" + escape(s, '\'') + "'"
+}
+
+func escape(s string, quote byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || s[i] == quote {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	outputs.Add("clickhouse", func() telegraf.Output {
+		return &ClickHouse{
+			URL:             "http://127.0.0.1:8123",
+			Database:        "default",
+			Timeout:         internal.Duration{Duration: 5 * time.Second},
+			Format:          "RowBinary",
+			TableLayout:     layoutWide,
+			Table:           "telegraf",
+			TimestampColumn: "timestamp",
+			CreateTables:    true,
+			Engine:          "MergeTree()",
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// server is a stub of the ClickHouse HTTP interface recording the queries.
+type server struct {
+	sync.Mutex
+	columns map[string]string
+	queries []string
+	inserts []string
+	user    string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.user = r.Header.Get("X-ClickHouse-User")
+	body, _ := ioutil.ReadAll(r.Body)
+	if query := r.URL.Query().Get("query"); query != "" {
+		s.queries = append(s.queries, query)
+		s.inserts = append(s.inserts, string(body))
+		return
+	}
+
+	query := string(body)
+	s.queries = append(s.queries, query)
+	if strings.HasPrefix(query, "SELECT name, type FROM system.columns") {
+		for name, chType := range s.columns {
+			fmt.Fprintf(w, "{\"name\":%q,\"type\":%q}\n", name, chType)
+		}
+	}
+}
+
+func newClickHouse(t *testing.T, url string) *ClickHouse {
+	plugin := &ClickHouse{
+		URL:             url,
+		Username:        "telegraf",
+		Database:        "metrics",
+		Timeout:         internal.Duration{Duration: 5 * time.Second},
+		Format:          "JSONEachRow",
+		TableLayout:     layoutWide,
+		Table:           "telegraf",
+		TimestampColumn: "timestamp",
+		CreateTables:    true,
+		Engine:          "MergeTree()",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	return plugin
+}
+
+func TestWideCreatesTable(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	plugin := newClickHouse(t, ts.URL)
+	plugin.TTL = internal.Duration{Duration: 24 * time.Hour}
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 99.5, "count": int64(3)},
+			time.Unix(1, 5),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 10.0, "ok": true, "status": "fine"},
+			time.Unix(2, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Equal(t, "telegraf", s.user)
+	require.Equal(t, []string{
+		"SELECT name, type FROM system.columns WHERE database = 'metrics' AND table = 'cpu' FORMAT JSONEachRow",
+		"CREATE TABLE IF NOT EXISTS `metrics`.`cpu` (`timestamp` DateTime64(9), `cpu` LowCardinality(String), " +
+			"`host` LowCardinality(String), `count` Nullable(Int64), `ok` Nullable(UInt8), `status` Nullable(String), " +
+			"`usage_idle` Nullable(Float64)) ENGINE = MergeTree() ORDER BY (`cpu`, `host`, `timestamp`) " +
+			"TTL toDateTime(`timestamp`) + INTERVAL 86400 SECOND",
+		"INSERT INTO `metrics`.`cpu` (`timestamp`, `cpu`, `host`, `count`, `ok`, `status`, `usage_idle`) FORMAT JSONEachRow",
+	}, s.queries)
+
+	require.Equal(t,
+		`{"count":3,"cpu":"cpu0","host":"a","ok":null,"status":null,"timestamp":"1.000000005","usage_idle":99.5}`+"\n"+
+			`{"count":null,"cpu":"","host":"b","ok":1,"status":"fine","timestamp":"2.000000000","usage_idle":10}`+"\n",
+		s.inserts[0])
+}
+
+func TestWideAddsColumns(t *testing.T) {
+	s := &server{columns: map[string]string{
+		"timestamp": "DateTime64(9)",
+		"host":      "String",
+		"used":      "Float64",
+	}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	plugin := newClickHouse(t, ts.URL)
+	plugin.TablePrefix = "telegraf_"
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(42), "free": uint64(7)},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Equal(t, []string{
+		"SELECT name, type FROM system.columns WHERE database = 'metrics' AND table = 'telegraf_mem' FORMAT JSONEachRow",
+		"ALTER TABLE `metrics`.`telegraf_mem` ADD COLUMN IF NOT EXISTS `free` Nullable(UInt64)",
+		"INSERT INTO `metrics`.`telegraf_mem` (`timestamp`, `host`, `free`, `used`) FORMAT JSONEachRow",
+	}, s.queries)
+
+	// the existing column converts the integer
+	require.Equal(t,
+		`{"free":7,"host":"a","timestamp":"1.000000000","used":42}`+"\n",
+		s.inserts[0])
+
+	// the columns are cached
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, s.queries, 4)
+}
+
+func TestNarrowRowBinary(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	plugin := newClickHouse(t, ts.URL)
+	plugin.Format = "RowBinary"
+	plugin.TableLayout = layoutNarrow
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 99.5, "status": "ok"},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Equal(t, []string{
+		"SELECT name, type FROM system.columns WHERE database = 'metrics' AND table = 'telegraf' FORMAT JSONEachRow",
+		"CREATE TABLE IF NOT EXISTS `metrics`.`telegraf` (`timestamp` DateTime64(9), `name` LowCardinality(String), " +
+			"`tags` Map(String, String), `field` LowCardinality(String), `value` Float64) " +
+			"ENGINE = MergeTree() ORDER BY (`name`, `field`, `timestamp`)",
+		"INSERT INTO `metrics`.`telegraf` (`timestamp`, `name`, `tags`, `field`, `value`) FORMAT RowBinary",
+	}, s.queries)
+
+	var expected bytes.Buffer
+	binary.Write(&expected, binary.LittleEndian, int64(1e9))
+	expected.Write([]byte{3, 'c', 'p', 'u'})
+	expected.Write([]byte{1, 4, 'h', 'o', 's', 't', 1, 'a'})
+	expected.Write([]byte{10})
+	expected.WriteString("usage_idle")
+	binary.Write(&expected, binary.LittleEndian, math.Float64bits(99.5))
+	require.Equal(t, expected.String(), s.inserts[0])
+}
+
+func TestRowBinaryNullable(t *testing.T) {
+	columns := []column{
+		{name: "a", chType: "Nullable(Int64)"},
+		{name: "b", chType: "Nullable(String)"},
+		{name: "c", chType: "UInt8"},
+	}
+	body, err := (&rowBinary{}).encode(columns, [][]interface{}{{int64(-1), nil, uint8(1)}})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 1}, body)
+}
+
+func TestRowBinaryMismatch(t *testing.T) {
+	columns := []column{{name: "a", chType: "Int32"}}
+	_, err := (&rowBinary{}).encode(columns, [][]interface{}{{int64(1)}})
+	require.EqualError(t, err, `column "a": cannot encode int64 as Int32`)
+
+	columns = []column{{name: "a", chType: "Nullable(Int64)"}}
+	_, err = (&rowBinary{}).encode(columns, [][]interface{}{{"1"}})
+	require.EqualError(t, err, `column "a": cannot encode string as Int64`)
+}
+
+func TestTimestampColumnTypes(t *testing.T) {
+	tm := time.Unix(1600000000, 123456789)
+	tests := []struct {
+		chType string
+		binary interface{}
+		json   string
+		err    string
+	}{
+		{chType: "DateTime", binary: uint32(1600000000), json: `1600000000`},
+		{chType: "DateTime('UTC')", binary: uint32(1600000000), json: `1600000000`},
+		{chType: "DateTime64(0)", binary: int64(1600000000), json: `"1600000000"`},
+		{chType: "DateTime64(3, 'Europe/Berlin')", binary: int64(1600000000123), json: `"1600000000.123"`},
+		{chType: "Nullable(DateTime64(9))", binary: int64(1600000000123456789), json: `"1600000000.123456789"`},
+		{chType: "Date", err: "cannot encode time as Date"},
+		{chType: "DateTime64(10)", err: "cannot encode time as DateTime64(10)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.chType, func(t *testing.T) {
+			columns := []column{{name: "timestamp", chType: tt.chType}}
+			rows := [][]interface{}{{tm}}
+
+			body, binErr := (&rowBinary{}).encode(columns, rows)
+			jsonBody, jsonErr := (&jsonEachRow{}).encode(columns, rows)
+			if tt.err != "" {
+				require.Error(t, binErr)
+				require.Contains(t, jsonErr.Error(), tt.err)
+				return
+			}
+			require.NoError(t, binErr)
+			require.NoError(t, jsonErr)
+
+			var expected bytes.Buffer
+			if _, ok := unwrapType(tt.chType, "Nullable"); ok {
+				expected.WriteByte(0)
+			}
+			binary.Write(&expected, binary.LittleEndian, tt.binary)
+			require.Equal(t, expected.Bytes(), body)
+			require.Equal(t, `{"timestamp":`+tt.json+"}\n", string(jsonBody))
+		})
+	}
+
+	_, err := (&rowBinary{}).encode([]column{{name: "timestamp", chType: "DateTime"}}, [][]interface{}{{time.Unix(-1, 0)}})
+	require.EqualError(t, err, `column "timestamp": time 1969-12-31T23:59:59Z is out of the range of DateTime`)
+}
+
+func TestUnsupportedColumnType(t *testing.T) {
+	tests := []struct {
+		columns  map[string]string
+		expected string
+	}{
+		{
+			columns:  map[string]string{"timestamp": "UInt64", "value": "Float64"},
+			expected: `timestamp column "timestamp" has type UInt64 instead of DateTime or DateTime64`,
+		},
+		{
+			columns:  map[string]string{"timestamp": "DateTime", "value": "Nullable(Int32)"},
+			expected: `column "value" has unsupported type Nullable(Int32)`,
+		},
+	}
+	for _, tt := range tests {
+		s := &server{columns: tt.columns}
+		ts := httptest.NewServer(s)
+
+		plugin := newClickHouse(t, ts.URL)
+		err := plugin.Write([]telegraf.Metric{
+			testutil.MustMetric("cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 1.0},
+				time.Unix(1, 0),
+			),
+		})
+		require.EqualError(t, err, `writing to table "cpu": `+tt.expected)
+		require.Empty(t, s.inserts)
+		ts.Close()
+	}
+}
+
+func TestMissingTableWithoutCreate(t *testing.T) {
+	s := &server{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	plugin := newClickHouse(t, ts.URL)
+	plugin.CreateTables = false
+
+	err := plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 0),
+		),
+	})
+	require.Error(t, err)
+}
+
+func TestInitErrors(t *testing.T) {
+	plugin := &ClickHouse{URL: "http://localhost:8123", Format: "CSV", TableLayout: layoutWide}
+	require.Error(t, plugin.Init())
+
+	plugin = &ClickHouse{URL: "http://localhost:8123", Format: "RowBinary", TableLayout: "tall"}
+	require.Error(t, plugin.Init())
+}
+
+func TestClickHouseIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	plugin := &ClickHouse{
+		URL:             fmt.Sprintf("http://%s:8123", testutil.GetLocalHost()),
+		Database:        "default",
+		Timeout:         internal.Duration{Duration: 5 * time.Second},
+		Format:          "RowBinary",
+		TableLayout:     layoutWide,
+		TablePrefix:     fmt.Sprintf("telegraf_%d_", time.Now().UnixNano()),
+		TimestampColumn: "timestamp",
+		CreateTables:    true,
+		Engine:          "MergeTree()",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+	defer plugin.exec("DROP TABLE IF EXISTS "+plugin.tableIdent(plugin.TablePrefix+"cpu"), nil)
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 99.5, "count": int64(3)},
+			time.Unix(1, 0),
+		),
+	}))
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 10.0, "ok": true},
+			time.Unix(2, 0),
+		),
+	}))
+
+	var resp bytes.Buffer
+	query := "SELECT host, cpu, count, ok FROM " + plugin.tableIdent(plugin.TablePrefix+"cpu") +
+		" ORDER BY timestamp FORMAT CSV"
+	require.NoError(t, plugin.exec(query, &resp))
+	require.Equal(t, "\"a\",\"\",3,\\N\n\"b\",\"cpu0\",\\N,1\n", resp.String())
+}
@@ -0,0 +1,349 @@
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encoder encodes rows for the body of an insert query.
+type encoder interface {
+	// format is the name of the ClickHouse input format.
+	format() string
+
+	// encode encodes the rows, the values of a row are in the order of the
+	// columns and are already converted to the column type, nil if null.
+	encode(columns []column, rows [][]interface{}) ([]byte, error)
+}
+
+var encoders = map[string]encoder{
+	"RowBinary":   &rowBinary{},
+	"JSONEachRow": &jsonEachRow{},
+}
+
+type rowBinary struct{}
+
+func (e *rowBinary) format() string {
+	return "RowBinary"
+}
+
+func (e *rowBinary) encode(columns []column, rows [][]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		for i, col := range columns {
+			if err := e.write(&buf, col.chType, row[i]); err != nil {
+				return nil, fmt.Errorf("column %q: %v", col.name, err)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// write encodes the value as the column type, the value has to be of the Go
+// type used for the column type.
+func (e *rowBinary) write(buf *bytes.Buffer, chType string, value interface{}) error {
+	if inner, ok := unwrapType(chType, "Nullable"); ok {
+		if value == nil {
+			return buf.WriteByte(1)
+		}
+		buf.WriteByte(0)
+		return e.write(buf, inner, value)
+	}
+	if inner, ok := unwrapType(chType, "LowCardinality"); ok {
+		return e.write(buf, inner, value)
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+	switch v := value.(type) {
+	case string:
+		if chType != "String" {
+			break
+		}
+		n := binary.PutUvarint(scratch[:], uint64(len(v)))
+		buf.Write(scratch[:n])
+		buf.WriteString(v)
+		return nil
+	case int64:
+		if chType != "Int64" {
+			break
+		}
+		binary.LittleEndian.PutUint64(scratch[:], uint64(v))
+		buf.Write(scratch[:8])
+		return nil
+	case uint64:
+		if chType != "UInt64" {
+			break
+		}
+		binary.LittleEndian.PutUint64(scratch[:], v)
+		buf.Write(scratch[:8])
+		return nil
+	case float64:
+		if chType != "Float64" {
+			break
+		}
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v))
+		buf.Write(scratch[:8])
+		return nil
+	case uint8:
+		if chType != "UInt8" {
+			break
+		}
+		buf.WriteByte(v)
+		return nil
+	case time.Time:
+		precision, ok := timePrecision(chType)
+		if !ok {
+			break
+		}
+		if precision == precisionDateTime {
+			sec, err := dateTimeSeconds(v, chType)
+			if err != nil {
+				return err
+			}
+			binary.LittleEndian.PutUint32(scratch[:], sec)
+			buf.Write(scratch[:4])
+			return nil
+		}
+		binary.LittleEndian.PutUint64(scratch[:], uint64(timeTicks(v, precision)))
+		buf.Write(scratch[:8])
+		return nil
+	case map[string]string:
+		if chType != "Map(String, String)" {
+			break
+		}
+		keys := sortedKeys(v)
+		n := binary.PutUvarint(scratch[:], uint64(len(keys)))
+		buf.Write(scratch[:n])
+		for _, k := range keys {
+			e.write(buf, "String", k)
+			e.write(buf, "String", v[k])
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot encode %T as %s", value, chType)
+}
+
+type jsonEachRow struct{}
+
+func (e *jsonEachRow) format() string {
+	return "JSONEachRow"
+}
+
+func (e *jsonEachRow) encode(columns []column, rows [][]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, row := range rows {
+		obj := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			switch v := row[i].(type) {
+			case time.Time:
+				value, err := jsonTime(v, col.chType)
+				if err != nil {
+					return nil, fmt.Errorf("column %q: %v", col.name, err)
+				}
+				obj[col.name] = value
+			case float64:
+				// JSON cannot represent these values
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					obj[col.name] = nil
+				} else {
+					obj[col.name] = v
+				}
+			default:
+				obj[col.name] = v
+			}
+		}
+		if err := enc.Encode(obj); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonTime returns the JSON value of the time in a DateTime or DateTime64
+// column: the unix time in seconds, with the fractional digits of the
+// precision for DateTime64.
+func jsonTime(t time.Time, chType string) (interface{}, error) {
+	precision, ok := timePrecision(chType)
+	if !ok {
+		return nil, fmt.Errorf("cannot encode time as %s", chType)
+	}
+	if precision == precisionDateTime {
+		return dateTimeSeconds(t, chType)
+	}
+
+	ticks := timeTicks(t, precision)
+	if precision == 0 {
+		return strconv.FormatInt(ticks, 10), nil
+	}
+	sec, frac := ticks/pow10[precision], ticks%pow10[precision]
+	if frac < 0 {
+		sec, frac = sec-1, frac+pow10[precision]
+	}
+	return fmt.Sprintf("%d.%0*d", sec, precision, frac), nil
+}
+
+// precisionDateTime is the precision returned by timePrecision for the
+// DateTime type, which unlike DateTime64(0) is stored as 32 bit unsigned
+// integer.
+const precisionDateTime = -1
+
+var pow10 = [...]int64{1, 10, 100, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9}
+
+// timePrecision returns the number of fractional digits of the seconds of a
+// DateTime64 type, precisionDateTime for a DateTime type, and false for
+// other types.  Both types may have a time zone argument.
+func timePrecision(chType string) (int, bool) {
+	chType = baseType(chType)
+	if chType == "DateTime" {
+		return precisionDateTime, true
+	}
+	if _, ok := unwrapType(chType, "DateTime"); ok {
+		return precisionDateTime, true
+	}
+
+	args, ok := unwrapType(chType, "DateTime64")
+	if !ok {
+		return 0, false
+	}
+	if i := strings.IndexByte(args, ','); i >= 0 {
+		args = args[:i]
+	}
+	precision, err := strconv.Atoi(strings.TrimSpace(args))
+	if err != nil || precision < 0 || precision >= len(pow10) {
+		return 0, false
+	}
+	return precision, true
+}
+
+// timeTicks returns the time in units of the precision since the epoch.
+func timeTicks(t time.Time, precision int) int64 {
+	return t.Unix()*pow10[precision] + int64(t.Nanosecond())/pow10[9-precision]
+}
+
+// dateTimeSeconds returns the time in seconds since the epoch, or an error if
+// the time is outside of the range of the DateTime type.
+func dateTimeSeconds(t time.Time, chType string) (uint32, error) {
+	sec := t.Unix()
+	if sec < 0 || sec > math.MaxUint32 {
+		return 0, fmt.Errorf("time %s is out of the range of %s", t.UTC().Format(time.RFC3339), chType)
+	}
+	return uint32(sec), nil
+}
+
+// unwrapType returns the inner type if the type is of the form
+// "wrapper(inner)".
+func unwrapType(chType, wrapper string) (string, bool) {
+	if strings.HasPrefix(chType, wrapper+"(") && strings.HasSuffix(chType, ")") {
+		return chType[len(wrapper)+1 : len(chType)-1], true
+	}
+	return "", false
+}
+
+// baseType strips the Nullable and LowCardinality wrappers from the type.
+func baseType(chType string) string {
+	for {
+		if inner, ok := unwrapType(chType, "Nullable"); ok {
+			chType = inner
+		} else if inner, ok := unwrapType(chType, "LowCardinality"); ok {
+			chType = inner
+		} else {
+			return chType
+		}
+	}
+}
+
+// supportedType reports whether values can be converted to and encoded as
+// the column type.
+func supportedType(chType string) bool {
+	switch baseType(chType) {
+	case "String", "Float64", "Int64", "UInt64", "UInt8", "Map(String, String)":
+		return true
+	}
+	_, ok := timePrecision(chType)
+	return ok
+}
+
+// defaultValue returns the value stored for a missing tag or field, nil for
+// nullable types.  The type must be supported and not a time type.
+func defaultValue(chType string) interface{} {
+	if _, ok := unwrapType(chType, "Nullable"); ok {
+		return nil
+	}
+	switch baseType(chType) {
+	case "String":
+		return ""
+	case "Float64":
+		return float64(0)
+	case "Int64":
+		return int64(0)
+	case "UInt64":
+		return uint64(0)
+	case "UInt8":
+		return uint8(0)
+	case "Map(String, String)":
+		return map[string]string{}
+	}
+	return nil
+}
+
+// convert converts a field value to the Go type used to encode the column
+// type, returning false if the value cannot be represented.
+func convert(value interface{}, chType string) (interface{}, bool) {
+	switch baseType(chType) {
+	case "String":
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case bool:
+			return strconv.FormatBool(v), true
+		default:
+			return fmt.Sprint(v), true
+		}
+	case "Float64":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), true
+		case uint64:
+			return float64(v), true
+		case float64:
+			return v, true
+		case bool:
+			if v {
+				return float64(1), true
+			}
+			return float64(0), true
+		}
+	case "Int64":
+		switch v := value.(type) {
+		case int64:
+			return v, true
+		case uint64:
+			if v <= math.MaxInt64 {
+				return int64(v), true
+			}
+		}
+	case "UInt64":
+		switch v := value.(type) {
+		case uint64:
+			return v, true
+		case int64:
+			if v >= 0 {
+				return uint64(v), true
+			}
+		}
+	case "UInt8":
+		if v, ok := value.(bool); ok {
+			if v {
+				return uint8(1), true
+			}
+			return uint8(0), true
+		}
+	}
+	return nil, false
+}
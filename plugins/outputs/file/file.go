@@ -1,34 +1,65 @@
 package file
 
 import (
+	"container/list"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/rotate"
+	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
+const defaultMaxOpenFiles = 100
+
 type File struct {
 	Files               []string          `toml:"files"`
 	RotationInterval    internal.Duration `toml:"rotation_interval"`
 	RotationMaxSize     internal.Size     `toml:"rotation_max_size"`
 	RotationMaxArchives int               `toml:"rotation_max_archives"`
 	UseBatchFormat      bool              `toml:"use_batch_format"`
+	MaxOpenFiles        int               `toml:"max_open_files"`
 	Log                 telegraf.Logger   `toml:"-"`
 
 	writer     io.Writer
 	closers    []io.Closer
 	serializer serializers.Serializer
+
+	// templates are the file names rendered for each metric, the files are
+	// opened when first written to.  The open files are kept in templated
+	// and in the least recently written order in lru, the front being the
+	// most recently written file.
+	templates []*templating.MetricTemplate
+	templated map[string]*list.Element
+	lru       *list.List
+}
+
+// templatedFile is an open file named with a template.
+type templatedFile struct {
+	path   string
+	writer io.WriteCloser
 }
 
 var sampleConfig = `
   ## Files to write to, "stdout" is a specially handled file.
   files = ["stdout", "/tmp/metrics.out"]
 
+  ## A file name may be a Go template to write metrics to a file depending on
+  ## the metric.  The template can use {{.Name}}, {{.Tag "key"}} and
+  ## {{.Tags}}; path separators in their values are replaced by "_", and
+  ## missing directories are created.
+  # files = ['/tmp/metrics/{{.Name}}-{{.Tag "host"}}.out']
+
+  ## Maximum number of files named with a template kept open.  When another
+  ## file has to be opened, the least recently written file is closed.
+  # max_open_files = 100
+
   ## Use batch serialization format instead of line based delimiting.  The
   ## batch format allows for the production of non line based output formats and
   ## may more efficiently encode metric groups.
@@ -67,6 +98,13 @@ func (f *File) Connect() error {
 	for _, file := range f.Files {
 		if file == "stdout" {
 			writers = append(writers, os.Stdout)
+		} else if templating.IsMetricTemplate(file) {
+			tmpl, err := templating.NewMetricTemplate(file)
+			if err != nil {
+				return fmt.Errorf("invalid file template %q: %v", file, err)
+			}
+			tmpl.SetEscape(escapePathSegment)
+			f.templates = append(f.templates, tmpl)
 		} else {
 			of, err := rotate.NewFileWriter(
 				file, f.RotationInterval.Duration, f.RotationMaxSize.Size, f.RotationMaxArchives)
@@ -78,6 +116,11 @@ func (f *File) Connect() error {
 			f.closers = append(f.closers, of)
 		}
 	}
+	if f.MaxOpenFiles <= 0 {
+		f.MaxOpenFiles = defaultMaxOpenFiles
+	}
+	f.templated = make(map[string]*list.Element)
+	f.lru = list.New()
 	f.writer = io.MultiWriter(writers...)
 	return nil
 }
@@ -90,9 +133,76 @@ func (f *File) Close() error {
 			err = errClose
 		}
 	}
+	for f.lru.Len() > 0 {
+		errClose := f.closeTemplated(f.lru.Back())
+		if errClose != nil {
+			err = errClose
+		}
+	}
 	return err
 }
 
+// templatedWriter returns the writer of a file name rendered from tmpl,
+// opening the file if needed.  If max_open_files files are open the least
+// recently written file is closed.
+func (f *File) templatedWriter(tmpl *templating.MetricTemplate, path string) (io.Writer, error) {
+	if e, ok := f.templated[path]; ok {
+		f.lru.MoveToFront(e)
+		return e.Value.(*templatedFile).writer, nil
+	}
+
+	// The escaped values cannot add parent directory references, except by
+	// rendering adjacent dots.
+	if parentRefs(path) > parentRefs(tmpl.String()) {
+		return nil, fmt.Errorf("file name %q refers to a parent directory", path)
+	}
+
+	for f.lru.Len() >= f.MaxOpenFiles {
+		if err := f.closeTemplated(f.lru.Back()); err != nil {
+			f.Log.Errorf("Error closing file: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	w, err := rotate.NewFileWriter(
+		path, f.RotationInterval.Duration, f.RotationMaxSize.Size, f.RotationMaxArchives)
+	if err != nil {
+		return nil, err
+	}
+	f.templated[path] = f.lru.PushFront(&templatedFile{path: path, writer: w})
+	return w, nil
+}
+
+func (f *File) closeTemplated(e *list.Element) error {
+	file := f.lru.Remove(e).(*templatedFile)
+	delete(f.templated, file.path)
+	return file.writer.Close()
+}
+
+// escapePathSegment replaces path separators in values rendered into a file
+// name, and the "." and ".." directory names, so that metrics cannot choose
+// the directory of the file.
+func escapePathSegment(s string) string {
+	s = strings.NewReplacer("/", "_", `\`, "_").Replace(s)
+	if s == "." || s == ".." {
+		return strings.Repeat("_", len(s))
+	}
+	return s
+}
+
+// parentRefs returns the number of ".." elements of the path.
+func parentRefs(path string) int {
+	var n int
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if elem == ".." {
+			n++
+		}
+	}
+	return n
+}
+
 func (f *File) SampleConfig() string {
 	return sampleConfig
 }
@@ -114,6 +224,26 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		if err != nil {
 			f.Log.Errorf("Error writing to file: %v", err)
 		}
+
+		for _, tmpl := range f.templates {
+			groups, err := tmpl.Group(metrics)
+			if err != nil {
+				f.Log.Errorf("Could not render file name %q: %v", tmpl, err)
+			}
+			for _, group := range groups {
+				if group.Value == "" {
+					continue
+				}
+				octets, err := f.serializer.SerializeBatch(group.Metrics)
+				if err != nil {
+					f.Log.Errorf("Could not serialize metric: %v", err)
+					continue
+				}
+				if err := f.writeTemplated(tmpl, group.Value, octets); err != nil {
+					f.Log.Errorf("Error writing to file: %v", err)
+				}
+			}
+		}
 	} else {
 		for _, metric := range metrics {
 			b, err := f.serializer.Serialize(metric)
@@ -125,14 +255,39 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 			if err != nil {
 				writeErr = fmt.Errorf("E! [outputs.file] failed to write message: %v", err)
 			}
+
+			for _, tmpl := range f.templates {
+				path, err := tmpl.Render(metric)
+				if err != nil {
+					f.Log.Errorf("Could not render file name %q: %v", tmpl, err)
+					continue
+				}
+				if path == "" {
+					continue
+				}
+				if err := f.writeTemplated(tmpl, path, b); err != nil {
+					writeErr = fmt.Errorf("E! [outputs.file] failed to write message: %v", err)
+				}
+			}
 		}
 	}
 
 	return writeErr
 }
 
+func (f *File) writeTemplated(tmpl *templating.MetricTemplate, path string, octets []byte) error {
+	w, err := f.templatedWriter(tmpl, path)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(octets)
+	return err
+}
+
 func init() {
 	outputs.Add("file", func() telegraf.Output {
-		return &File{}
+		return &File{
+			MaxOpenFiles: defaultMaxOpenFiles,
+		}
 	})
 }
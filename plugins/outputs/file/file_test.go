@@ -2,13 +2,18 @@ package file
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
@@ -204,3 +209,111 @@ func validateFile(fname, expS string, t *testing.T) {
 	}
 	assert.Equal(t, expS, string(buf))
 }
+
+func TestFileTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+
+	for _, batch := range []bool{false, true} {
+		s, _ := serializers.NewInfluxSerializer()
+		f := File{
+			Files:          []string{filepath.Join(dir, fmt.Sprintf("%v", batch), `{{.Tag "host"}}`, "{{.Name}}.out")},
+			UseBatchFormat: batch,
+			serializer:     s,
+			Log:            testutil.Logger{},
+		}
+		require.NoError(t, f.Connect())
+		require.NoError(t, f.Write(metrics))
+		require.NoError(t, f.Close())
+
+		validateFile(filepath.Join(dir, fmt.Sprintf("%v", batch), "a", "cpu.out"), "cpu,host=a value=1 0\n", t)
+		validateFile(filepath.Join(dir, fmt.Sprintf("%v", batch), "a", "mem.out"), "mem,host=a value=2 0\n", t)
+		validateFile(filepath.Join(dir, fmt.Sprintf("%v", batch), "b", "cpu.out"), "cpu,host=b value=3 0\n", t)
+	}
+}
+
+func TestFileInvalidTemplate(t *testing.T) {
+	f := File{Files: []string{"/tmp/{{.Name"}}
+	require.Error(t, f.Connect())
+}
+
+func TestFileTemplateEscape(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "../a/b"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": ".."}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:      []string{filepath.Join(dir, "metrics", `{{.Tag "host"}}`, "{{.Name}}.out")},
+		serializer: s,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, f.Connect())
+	require.NoError(t, f.Write(metrics))
+	require.NoError(t, f.Close())
+
+	// An empty value next to dots of the template refers to the parent
+	// directory.
+	f = File{
+		Files:      []string{filepath.Join(dir, "metrics", `{{.Tag "missing"}}..`, "{{.Name}}.out")},
+		serializer: s,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, f.Connect())
+	require.Error(t, f.Write(metrics))
+	require.NoError(t, f.Close())
+
+	validateFile(filepath.Join(dir, "metrics", ".._a_b", "cpu.out"), "cpu,host=../a/b value=1 0\n", t)
+	validateFile(filepath.Join(dir, "metrics", "__", "cpu.out"), "cpu,host=.. value=2 0\n", t)
+
+	// Only the directories of the written files exist.
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	infos, err = ioutil.ReadDir(filepath.Join(dir, "metrics"))
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+}
+
+func TestFileTemplateMaxOpenFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:        []string{filepath.Join(dir, "{{.Name}}.out")},
+		MaxOpenFiles: 2,
+		serializer:   s,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, f.Connect())
+
+	for i, name := range []string{"a", "b", "a", "c", "b"} {
+		m := testutil.MustMetric(name, map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+		require.NoError(t, f.Write([]telegraf.Metric{m}))
+		require.LessOrEqual(t, f.lru.Len(), 2)
+	}
+
+	// "b" was the least recently written file when "c" was opened.
+	require.Len(t, f.templated, 2)
+	require.Contains(t, f.templated, filepath.Join(dir, "c.out"))
+	require.Contains(t, f.templated, filepath.Join(dir, "b.out"))
+	require.NoError(t, f.Close())
+
+	validateFile(filepath.Join(dir, "a.out"), "a value=0i 0\na value=2i 0\n", t)
+	validateFile(filepath.Join(dir, "b.out"), "b value=1i 0\nb value=4i 0\n", t)
+	validateFile(filepath.Join(dir, "c.out"), "c value=3i 0\n", t)
+}
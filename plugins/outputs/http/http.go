@@ -7,11 +7,13 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -27,6 +29,13 @@ var sampleConfig = `
   ## URL is the address to send metrics to
   url = "http://127.0.0.1:8080/telegraf"
 
+  ## Go template of a path appended to the path of the url, to send metrics
+  ## to a path depending on the metric.  The template can use {{.Name}},
+  ## {{.Tag "key"}}, {{.Tags}} and {{.Time}}; the name and the tags are
+  ## escaped, so they cannot add segments to the path.  Metrics are sent in a
+  ## request for each path, metrics without a valid path are rejected.
+  # path_template = '{{.Name}}/{{.Tag "host"}}'
+
   ## Timeout for HTTP message
   # timeout = "5s"
 
@@ -84,10 +93,14 @@ type HTTP struct {
 	TokenURL        string            `toml:"token_url"`
 	Scopes          []string          `toml:"scopes"`
 	ContentEncoding string            `toml:"content_encoding"`
+	PathTemplate    string            `toml:"path_template"`
 	tls.ClientConfig
 
-	client     *http.Client
-	serializer serializers.Serializer
+	Log telegraf.Logger `toml:"-"`
+
+	client       *http.Client
+	serializer   serializers.Serializer
+	pathTemplate *templating.MetricTemplate
 }
 
 func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
@@ -135,6 +148,18 @@ func (h *HTTP) Connect() error {
 		h.Timeout.Duration = defaultClientTimeout
 	}
 
+	if h.PathTemplate != "" {
+		if _, err := url.Parse(h.URL); err != nil {
+			return fmt.Errorf("invalid url [%s]: %v", h.URL, err)
+		}
+		tmpl, err := templating.NewMetricTemplate(h.PathTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid path_template: %v", err)
+		}
+		tmpl.SetEscape(url.PathEscape)
+		h.pathTemplate = tmpl
+	}
+
 	ctx := context.Background()
 	client, err := h.createClient(ctx)
 	if err != nil {
@@ -159,19 +184,140 @@ func (h *HTTP) SampleConfig() string {
 }
 
 func (h *HTTP) Write(metrics []telegraf.Metric) error {
+	if h.pathTemplate == nil {
+		return h.writeMetrics(h.URL, metrics)
+	}
+
+	groups, rejected := h.groupByURL(metrics)
+
+	// Metrics rejected for a path do not stop writing the other paths.  On
+	// other errors the metrics of the paths already written are reported as
+	// written, so that only the remaining metrics are retried.
+	var written []telegraf.Metric
+	for _, group := range groups {
+		err := h.writeMetrics(group.Value, group.Metrics)
+		if partial, ok := err.(*telegraf.PartialWriteError); ok {
+			rejected = append(rejected, partial.Rejected...)
+			continue
+		}
+		if err != nil {
+			if len(rejected) > 0 || len(written) > 0 {
+				return &telegraf.PartialWriteError{Rejected: rejected, Err: err, Written: written}
+			}
+			return err
+		}
+		written = append(written, group.Metrics...)
+	}
+	if len(rejected) > 0 {
+		return &telegraf.PartialWriteError{Rejected: rejected}
+	}
+	return nil
+}
+
+// groupByURL groups the metrics by the url of their rendered path, in the
+// order of the first appearance of the urls.  Metrics without a valid path
+// are rejected, as sending them again cannot succeed.
+func (h *HTTP) groupByURL(metrics []telegraf.Metric) ([]templating.MetricGroup, []telegraf.MetricError) {
+	var groups []templating.MetricGroup
+	var rejected []telegraf.MetricError
+	index := make(map[string]int)
+	for _, metric := range metrics {
+		path, err := h.pathTemplate.Render(metric)
+		var reqURL string
+		if err == nil {
+			reqURL, err = h.urlWithPath(path)
+		}
+		if err != nil {
+			rejected = append(rejected, telegraf.MetricError{
+				Metric: metric,
+				Err:    fmt.Errorf("rendering path_template failed: %v", err),
+			})
+			continue
+		}
+
+		i, ok := index[reqURL]
+		if !ok {
+			i = len(groups)
+			index[reqURL] = i
+			groups = append(groups, templating.MetricGroup{Value: reqURL})
+		}
+		groups[i].Metrics = append(groups[i].Metrics, metric)
+	}
+	return groups, rejected
+}
+
+// urlWithPath returns the url with the path appended to its path.  The name
+// and the tags are escaped when rendering the path, so a slash in them does
+// not add a segment; the other characters of the segments are escaped here.
+// Paths with a "." or ".." segment are refused, as they could leave the path
+// of the url.
+func (h *HTTP) urlWithPath(path string) (string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return h.URL, nil
+	}
+
+	// the url is validated when connecting
+	u, _ := url.Parse(h.URL)
+	segments := strings.Split(path, "/")
+	escaped := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		segment, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("path %q has a relative segment", path)
+		}
+		segments[i] = segment
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + "/" + strings.Join(escaped, "/")
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.Join(segments, "/")
+	return u.String(), nil
+}
+
+func (h *HTTP) writeMetrics(reqURL string, metrics []telegraf.Metric) error {
 	reqBody, err := h.serializer.SerializeBatch(metrics)
 	if err != nil {
 		return err
 	}
 
-	if err := h.write(reqBody); err != nil {
-		return err
+	err = h.write(reqURL, reqBody)
+	if statusErr, ok := err.(*statusError); ok && statusErr.permanent() {
+		rejected := make([]telegraf.MetricError, 0, len(metrics))
+		for _, metric := range metrics {
+			rejected = append(rejected, telegraf.MetricError{Metric: metric, Err: err})
+		}
+		return &telegraf.PartialWriteError{Rejected: rejected}
 	}
+	return err
+}
 
-	return nil
+// statusError is the error of a request answered with an unsuccessful status.
+type statusError struct {
+	url        string
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("when writing to [%s] received status code: %d", e.url, e.statusCode)
+}
+
+// permanent returns true if the server refused the content of the request,
+// so that sending the same metrics again cannot succeed.  Other client errors
+// like authentication failures or a wrong url are fixed by the configuration
+// or on the server, and the metrics are retried.  A request that is too large
+// is retried as well, as the metrics might be sent in smaller batches.
+func (e *statusError) permanent() bool {
+	switch e.statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
 }
 
-func (h *HTTP) write(reqBody []byte) error {
+func (h *HTTP) write(reqURL string, reqBody []byte) error {
 	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
 
 	var err error
@@ -184,7 +330,7 @@ func (h *HTTP) write(reqBody []byte) error {
 		reqBodyBuffer = rc
 	}
 
-	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
+	req, err := http.NewRequest(h.Method, reqURL, reqBodyBuffer)
 	if err != nil {
 		return err
 	}
@@ -213,7 +359,7 @@ func (h *HTTP) write(reqBody []byte) error {
 	_, err = ioutil.ReadAll(resp.Body)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("when writing to [%s] received status code: %d", h.URL, resp.StatusCode)
+		return &statusError{url: reqURL, statusCode: resp.StatusCode}
 	}
 
 	return nil
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -451,3 +454,194 @@ func TestDefaultUserAgent(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestPathTemplate(t *testing.T) {
+	var paths []string
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		paths = append(paths, r.URL.EscapedPath())
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:          ts.URL + "/telegraf/",
+		Method:       defaultMethod,
+		PathTemplate: `{{.Name}}/{{.Tag "host"}}`,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a b"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a b"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Equal(t, []string{"/telegraf/cpu/a%20b", "/telegraf/mem"}, paths)
+	require.Equal(t, []string{
+		"cpu,host=a\\ b value=1 0\ncpu,host=a\\ b value=3 0\n",
+		"mem value=2 0\n",
+	}, bodies)
+}
+
+func TestPathTemplateEscape(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:          ts.URL + "/telegraf",
+		Method:       defaultMethod,
+		PathTemplate: `{{.Name}}/{{if eq (.Tag "host") "x"}}{{.Unknown}}{{end}}{{.Tag "host"}}`,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "../admin"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": ".."}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "x"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	partial, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok, err)
+	require.NoError(t, partial.Err)
+	require.Len(t, partial.Rejected, 2)
+	require.Equal(t, metrics[1], partial.Rejected[0].Metric)
+	require.Equal(t, metrics[2], partial.Rejected[1].Metric)
+
+	require.Equal(t, []string{"/telegraf/cpu/..%2Fadmin"}, paths)
+}
+
+func TestInvalidPathTemplate(t *testing.T) {
+	plugin := &HTTP{
+		URL:          defaultURL,
+		Method:       defaultMethod,
+		PathTemplate: "{{.Name",
+	}
+	require.Error(t, plugin.Connect())
+}
+
+func TestPermanentStatusCode(t *testing.T) {
+	var statusCode int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:    ts.URL,
+		Method: defaultMethod,
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{getMetric(), getMetric()}
+
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnprocessableEntity} {
+		statusCode = code
+		err := plugin.Write(metrics)
+		partial, ok := err.(*telegraf.PartialWriteError)
+		require.True(t, ok, err)
+		require.NoError(t, partial.Err)
+		require.Len(t, partial.Rejected, 2)
+		require.Equal(t, metrics[0], partial.Rejected[0].Metric)
+		require.Contains(t, partial.Rejected[0].Err.Error(), strconv.Itoa(code))
+	}
+
+	for _, code := range []int{
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+		http.StatusProxyAuthRequired,
+		http.StatusRequestTimeout,
+		http.StatusRequestEntityTooLarge,
+		http.StatusTooManyRequests,
+		http.StatusServiceUnavailable,
+	} {
+		statusCode = code
+		err := plugin.Write(metrics)
+		require.Error(t, err)
+		_, ok := err.(*telegraf.PartialWriteError)
+		require.False(t, ok, code)
+	}
+}
+
+func TestPathTemplatePermanentStatusCode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/mem") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:          ts.URL,
+		Method:       defaultMethod,
+		PathTemplate: `{{.Name}}`,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	partial, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok, err)
+	require.NoError(t, partial.Err)
+	require.Len(t, partial.Rejected, 1)
+	require.Equal(t, metrics[0], partial.Rejected[0].Metric)
+}
+
+func TestPathTemplatePartialWrite(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/mem") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:          ts.URL,
+		Method:       defaultMethod,
+		PathTemplate: `{{.Name}}`,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	partial, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok, err)
+	require.Error(t, partial.Err)
+	require.Empty(t, partial.Rejected)
+	require.Equal(t, []telegraf.Metric{metrics[0], metrics[2]}, partial.Written)
+
+	// Without a written path the error is returned as is.
+	err = plugin.Write(metrics[1:2])
+	require.Error(t, err)
+	_, ok = err.(*telegraf.PartialWriteError)
+	require.False(t, ok)
+}
@@ -0,0 +1,344 @@
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	defaultEndpoint = "/loki/api/v1/push"
+	defaultTimeout  = 5 * time.Second
+)
+
+var sampleConfig = `
+  ## The Loki push URL
+  url = "http://127.0.0.1:3100/loki/api/v1/push"
+
+  ## Timeout for HTTP requests
+  # timeout = "5s"
+
+  ## HTTP Basic Auth credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Bearer token sent in the Authorization header
+  # token = ""
+
+  ## Format of the log line rendered from the metric fields, "logfmt" or
+  ## "json".
+  # line_format = "logfmt"
+
+  ## Name of the label holding the measurement name, set to an empty string
+  ## to not add the measurement as label.
+  # name_label = "measurement"
+
+  ## HTTP Content-Encoding for write request body, can be set to "gzip" to
+  ## compress body or "identity" to apply no encoding; by default the body is
+  ## not encoded.
+  # content_encoding = ""
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Additional HTTP headers, for example the tenant of a multi-tenant Loki
+  # [outputs.loki.headers]
+  #   X-Scope-OrgID = "tenant"
+`
+
+type Loki struct {
+	URL             string            `toml:"url"`
+	Timeout         internal.Duration `toml:"timeout"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	Token           string            `toml:"token"`
+	LineFormat      string            `toml:"line_format"`
+	NameLabel       string            `toml:"name_label"`
+	ContentEncoding string            `toml:"content_encoding"`
+	Headers         map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+// pushRequest is the body of a Loki push API request.
+type pushRequest struct {
+	Streams []*stream `json:"streams"`
+}
+
+type stream struct {
+	Labels map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+
+	timestamps []int64
+}
+
+func (s *stream) Len() int {
+	return len(s.Values)
+}
+
+func (s *stream) Less(i, j int) bool {
+	return s.timestamps[i] < s.timestamps[j]
+}
+
+func (s *stream) Swap(i, j int) {
+	s.Values[i], s.Values[j] = s.Values[j], s.Values[i]
+	s.timestamps[i], s.timestamps[j] = s.timestamps[j], s.timestamps[i]
+}
+
+func (l *Loki) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Loki) Description() string {
+	return "Send metrics as log lines to Grafana Loki"
+}
+
+func (l *Loki) Connect() error {
+	if l.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	switch l.LineFormat {
+	case "logfmt", "json":
+	default:
+		return fmt.Errorf("unknown line_format %q", l.LineFormat)
+	}
+
+	switch l.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("unknown content_encoding %q", l.ContentEncoding)
+	}
+
+	tlsCfg, err := l.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	l.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: l.Timeout.Duration,
+	}
+	return nil
+}
+
+func (l *Loki) Close() error {
+	return nil
+}
+
+func (l *Loki) Write(metrics []telegraf.Metric) error {
+	req := &pushRequest{}
+	streams := make(map[string]*stream)
+	for _, m := range metrics {
+		line, err := l.line(m)
+		if err != nil {
+			return err
+		}
+
+		labels := l.labels(m)
+		key := streamKey(labels)
+		s, ok := streams[key]
+		if !ok {
+			s = &stream{Labels: labels}
+			streams[key] = s
+			req.Streams = append(req.Streams, s)
+		}
+		ts := m.Time().UnixNano()
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(ts, 10), line})
+		s.timestamps = append(s.timestamps, ts)
+	}
+
+	// Loki rejects entries which are out of order within a stream.
+	for _, s := range req.Streams {
+		sort.Stable(s)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return l.push(body)
+}
+
+func (l *Loki) push(body []byte) error {
+	var reqBody io.Reader = bytes.NewBuffer(body)
+	if l.ContentEncoding == "gzip" {
+		rc, err := internal.CompressWithGzip(reqBody)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		reqBody = rc
+	}
+
+	req, err := http.NewRequest(http.MethodPost, l.URL, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if l.Username != "" || l.Password != "" {
+		req.SetBasicAuth(l.Username, l.Password)
+	}
+	if l.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+l.Token)
+	}
+
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", "application/json")
+	if l.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range l.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("when writing to [%s] received status code %d: %s",
+			l.URL, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// labels returns the stream labels of the metric.
+func (l *Loki) labels(m telegraf.Metric) map[string]string {
+	labels := make(map[string]string, len(m.TagList())+1)
+	for _, tag := range m.TagList() {
+		labels[sanitizeLabel(tag.Key)] = tag.Value
+	}
+	if l.NameLabel != "" {
+		labels[sanitizeLabel(l.NameLabel)] = m.Name()
+	}
+	return labels
+}
+
+// line renders the fields of the metric as log line.
+func (l *Loki) line(m telegraf.Metric) (string, error) {
+	if l.LineFormat == "json" {
+		octets, err := json.Marshal(m.Fields())
+		if err != nil {
+			return "", err
+		}
+		return string(octets), nil
+	}
+
+	fields := m.FieldList()
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(logfmtKey(field.Key))
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(field.Value))
+	}
+	return b.String(), nil
+}
+
+// streamKey builds a unique key of the label set.
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// sanitizeLabel replaces characters which are not allowed in label names.
+func sanitizeLabel(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, isControl) >= 0 {
+			return strconv.Quote(v)
+		}
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
+
+func init() {
+	outputs.Add("loki", func() telegraf.Output {
+		return &Loki{
+			URL:        "http://127.0.0.1:3100" + defaultEndpoint,
+			Timeout:    internal.Duration{Duration: defaultTimeout},
+			LineFormat: "logfmt",
+			NameLabel:  "measurement",
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package loki
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRequest struct {
+	header http.Header
+	body   map[string]interface{}
+}
+
+func newStub(t *testing.T, status int) (*httptest.Server, *[]stubRequest) {
+	var requests []stubRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = gz
+		}
+		octets, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+
+		var parsed map[string]interface{}
+		require.NoError(t, json.Unmarshal(octets, &parsed))
+		requests = append(requests, stubRequest{header: r.Header, body: parsed})
+
+		w.WriteHeader(status)
+		if status >= 300 {
+			w.Write([]byte("entry out of order"))
+		}
+	}))
+	return ts, &requests
+}
+
+func newLoki(url string) *Loki {
+	return &Loki{
+		URL:        url,
+		Timeout:    internal.Duration{Duration: 5 * time.Second},
+		LineFormat: "logfmt",
+		NameLabel:  "measurement",
+	}
+}
+
+func TestWriteGroupsStreams(t *testing.T) {
+	ts, requests := newStub(t, http.StatusNoContent)
+	defer ts.Close()
+
+	plugin := newLoki(ts.URL)
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "a", "app.name": "sshd"},
+			map[string]interface{}{"message": "session opened", "severity_code": int64(6)},
+			time.Unix(0, 300),
+		),
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"message": "boot"},
+			time.Unix(0, 200),
+		),
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "a", "app.name": "sshd"},
+			map[string]interface{}{"message": "ok", "ratio": 0.5, "ack": true},
+			time.Unix(0, 100),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, *requests, 1)
+
+	expected := map[string]interface{}{
+		"streams": []interface{}{
+			map[string]interface{}{
+				"stream": map[string]interface{}{
+					"host":        "a",
+					"app_name":    "sshd",
+					"measurement": "syslog",
+				},
+				"values": []interface{}{
+					[]interface{}{"100", "ack=true message=ok ratio=0.5"},
+					[]interface{}{"300", `message="session opened" severity_code=6`},
+				},
+			},
+			map[string]interface{}{
+				"stream": map[string]interface{}{
+					"host":        "b",
+					"measurement": "syslog",
+				},
+				"values": []interface{}{
+					[]interface{}{"200", "message=boot"},
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, (*requests)[0].body)
+	require.Equal(t, "application/json", (*requests)[0].header.Get("Content-Type"))
+}
+
+func TestWriteJSONLine(t *testing.T) {
+	ts, requests := newStub(t, http.StatusNoContent)
+	defer ts.Close()
+
+	plugin := newLoki(ts.URL)
+	plugin.LineFormat = "json"
+	plugin.NameLabel = ""
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("app",
+			map[string]string{"level": "error"},
+			map[string]interface{}{"msg": "failed", "code": int64(500)},
+			time.Unix(1, 0),
+		),
+	}))
+	require.Len(t, *requests, 1)
+
+	streams := (*requests)[0].body["streams"].([]interface{})
+	require.Len(t, streams, 1)
+	stream := streams[0].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"level": "error"}, stream["stream"])
+	require.Equal(t, []interface{}{
+		[]interface{}{"1000000000", `{"code":500,"msg":"failed"}`},
+	}, stream["values"])
+}
+
+func TestWriteGzipAndAuth(t *testing.T) {
+	ts, requests := newStub(t, http.StatusNoContent)
+	defer ts.Close()
+
+	plugin := newLoki(ts.URL)
+	plugin.ContentEncoding = "gzip"
+	plugin.Token = "secret"
+	plugin.Headers = map[string]string{"X-Scope-OrgID": "tenant1"}
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("app",
+			map[string]string{},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(1, 0),
+		),
+	}))
+	require.Len(t, *requests, 1)
+
+	header := (*requests)[0].header
+	require.Equal(t, "gzip", header.Get("Content-Encoding"))
+	require.Equal(t, "Bearer secret", header.Get("Authorization"))
+	require.Equal(t, "tenant1", header.Get("X-Scope-OrgID"))
+
+	plugin.Token = ""
+	plugin.Username = "user"
+	plugin.Password = "pass"
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("app",
+			map[string]string{},
+			map[string]interface{}{"value": int64(2)},
+			time.Unix(2, 0),
+		),
+	}))
+	require.Len(t, *requests, 2)
+
+	req := &http.Request{Header: (*requests)[1].header}
+	username, password, ok := req.BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "user", username)
+	require.Equal(t, "pass", password)
+}
+
+func TestWriteErrorStatus(t *testing.T) {
+	ts, _ := newStub(t, http.StatusBadRequest)
+	defer ts.Close()
+
+	plugin := newLoki(ts.URL)
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("app",
+			map[string]string{},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(1, 0),
+		),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "entry out of order")
+}
+
+func TestConnectErrors(t *testing.T) {
+	plugin := newLoki("http://localhost:3100")
+	plugin.LineFormat = "xml"
+	require.Error(t, plugin.Connect())
+
+	plugin = newLoki("http://localhost:3100")
+	plugin.ContentEncoding = "br"
+	require.Error(t, plugin.Connect())
+}
@@ -0,0 +1,299 @@
+package opentelemetry
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	commonpb "github.com/influxdata/telegraf/plugins/common/otlp/common/v1"
+	metricspb "github.com/influxdata/telegraf/plugins/common/otlp/metrics/v1"
+	resourcepb "github.com/influxdata/telegraf/plugins/common/otlp/resource/v1"
+)
+
+const instrumentationLibrary = "telegraf"
+
+// converter converts telegraf metrics into OTLP resource metrics.
+type converter struct {
+	resourceTags       map[string]bool
+	resourceAttributes []*commonpb.KeyValue
+}
+
+func newConverter(resourceTags []string, resourceAttributes map[string]string) *converter {
+	c := &converter{resourceTags: make(map[string]bool, len(resourceTags))}
+	for _, tag := range resourceTags {
+		c.resourceTags[tag] = true
+	}
+	for k, v := range resourceAttributes {
+		c.resourceAttributes = append(c.resourceAttributes, stringAttribute(k, v))
+	}
+	sort.Slice(c.resourceAttributes, func(i, j int) bool {
+		return c.resourceAttributes[i].Key < c.resourceAttributes[j].Key
+	})
+	return c
+}
+
+// resource collects the OTLP metrics sharing the same resource attributes.
+type resource struct {
+	metrics *metricspb.ResourceMetrics
+	byName  map[string]*metricspb.Metric
+}
+
+func (r *resource) metric(name string, newData func() *metricspb.Metric) *metricspb.Metric {
+	if m, ok := r.byName[name]; ok {
+		return m
+	}
+	m := newData()
+	m.Name = name
+	r.byName[name] = m
+	library := r.metrics.InstrumentationLibraryMetrics[0]
+	library.Metrics = append(library.Metrics, m)
+	return m
+}
+
+func (c *converter) convert(metrics []telegraf.Metric, log telegraf.Logger) []*metricspb.ResourceMetrics {
+	var result []*metricspb.ResourceMetrics
+	resources := make(map[string]*resource)
+	for _, m := range metrics {
+		resourceAttrs, attrs := c.attributes(m)
+
+		key := attributesKey(resourceAttrs)
+		r, ok := resources[key]
+		if !ok {
+			r = &resource{
+				metrics: &metricspb.ResourceMetrics{
+					Resource: &resourcepb.Resource{Attributes: resourceAttrs},
+					InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{
+						{
+							InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+								Name:    instrumentationLibrary,
+								Version: internal.Version(),
+							},
+						},
+					},
+				},
+				byName: make(map[string]*metricspb.Metric),
+			}
+			resources[key] = r
+			result = append(result, r.metrics)
+		}
+
+		ts := uint64(m.Time().UnixNano())
+		switch m.Type() {
+		case telegraf.Histogram:
+			if !addHistogram(r, m, attrs, ts) {
+				log.Debugf("Dropping histogram %q without valid buckets", m.Name())
+			}
+		case telegraf.Summary:
+			addSummary(r, m, attrs, ts)
+		default:
+			addNumbers(r, m, attrs, ts)
+		}
+	}
+	return result
+}
+
+// attributes splits the metric tags into resource and data point attributes.
+func (c *converter) attributes(m telegraf.Metric) ([]*commonpb.KeyValue, []*commonpb.KeyValue) {
+	resourceAttrs := make([]*commonpb.KeyValue, 0, len(c.resourceAttributes)+len(c.resourceTags))
+	for _, attr := range c.resourceAttributes {
+		// resource tags take precedence over the static attributes
+		if c.resourceTags[attr.Key] && m.HasTag(attr.Key) {
+			continue
+		}
+		resourceAttrs = append(resourceAttrs, attr)
+	}
+
+	var attrs []*commonpb.KeyValue
+	for _, tag := range m.TagList() {
+		if c.resourceTags[tag.Key] {
+			resourceAttrs = append(resourceAttrs, stringAttribute(tag.Key, tag.Value))
+		} else {
+			attrs = append(attrs, stringAttribute(tag.Key, tag.Value))
+		}
+	}
+	return resourceAttrs, attrs
+}
+
+// addNumbers adds a gauge or, for counters, a cumulative monotonic sum data
+// point for each numeric field.  As in the prometheus output the "value"
+// field, and the "counter" and "gauge" fields produced by the prometheus
+// input, are named after the measurement alone.
+func addNumbers(r *resource, m telegraf.Metric, attrs []*commonpb.KeyValue, ts uint64) {
+	for _, field := range m.FieldList() {
+		dp := &metricspb.NumberDataPoint{Attributes: attrs, TimeUnixNano: ts}
+		switch v := field.Value.(type) {
+		case int64:
+			dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: v}
+		case uint64:
+			if v <= math.MaxInt64 {
+				dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(v)}
+			} else {
+				dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: float64(v)}
+			}
+		case float64:
+			dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: v}
+		default:
+			continue
+		}
+
+		name := m.Name() + "_" + field.Key
+		switch {
+		case field.Key == "value",
+			field.Key == "counter" && m.Type() == telegraf.Counter,
+			field.Key == "gauge" && m.Type() == telegraf.Gauge:
+			name = m.Name()
+		}
+
+		if m.Type() == telegraf.Counter {
+			om := r.metric(name, func() *metricspb.Metric {
+				return &metricspb.Metric{Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					IsMonotonic:            true,
+				}}}
+			})
+			if sum := om.GetSum(); sum != nil {
+				sum.DataPoints = append(sum.DataPoints, dp)
+			}
+			continue
+		}
+
+		om := r.metric(name, func() *metricspb.Metric {
+			return &metricspb.Metric{Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}}
+		})
+		if gauge := om.GetGauge(); gauge != nil {
+			gauge.DataPoints = append(gauge.DataPoints, dp)
+		}
+	}
+}
+
+// addHistogram adds a histogram data point built from the "sum" and "count"
+// fields and the cumulative bucket counts stored in fields named after the
+// upper bound of the bucket, the format used by the prometheus plugins.
+func addHistogram(r *resource, m telegraf.Metric, attrs []*commonpb.KeyValue, ts uint64) bool {
+	dp := &metricspb.HistogramDataPoint{Attributes: attrs, TimeUnixNano: ts}
+
+	type bucket struct {
+		bound float64
+		count uint64
+	}
+	var buckets []bucket
+	for _, field := range m.FieldList() {
+		value, ok := toFloat(field.Value)
+		if !ok {
+			continue
+		}
+		switch field.Key {
+		case "sum":
+			dp.Sum = value
+		case "count":
+			dp.Count = uint64(value)
+		default:
+			bound, err := strconv.ParseFloat(field.Key, 64)
+			if err != nil || math.IsNaN(bound) {
+				continue
+			}
+			buckets = append(buckets, bucket{bound: bound, count: uint64(value)})
+		}
+	}
+	if len(buckets) == 0 {
+		return false
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+
+	// OTLP uses per bucket counts with an implicit overflow bucket.
+	var previous uint64
+	for _, b := range buckets {
+		if math.IsInf(b.bound, 1) {
+			break
+		}
+		dp.ExplicitBounds = append(dp.ExplicitBounds, b.bound)
+		dp.BucketCounts = append(dp.BucketCounts, b.count-previous)
+		previous = b.count
+	}
+	var overflow uint64
+	if dp.Count > previous {
+		overflow = dp.Count - previous
+	}
+	dp.BucketCounts = append(dp.BucketCounts, overflow)
+
+	om := r.metric(m.Name(), func() *metricspb.Metric {
+		return &metricspb.Metric{Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}}
+	})
+	if histogram := om.GetHistogram(); histogram != nil {
+		histogram.DataPoints = append(histogram.DataPoints, dp)
+	}
+	return true
+}
+
+// addSummary adds a summary data point built from the "sum" and "count"
+// fields and the quantile fields.
+func addSummary(r *resource, m telegraf.Metric, attrs []*commonpb.KeyValue, ts uint64) {
+	dp := &metricspb.SummaryDataPoint{Attributes: attrs, TimeUnixNano: ts}
+	for _, field := range m.FieldList() {
+		value, ok := toFloat(field.Value)
+		if !ok {
+			continue
+		}
+		switch field.Key {
+		case "sum":
+			dp.Sum = value
+		case "count":
+			dp.Count = uint64(value)
+		default:
+			quantile, err := strconv.ParseFloat(field.Key, 64)
+			if err != nil {
+				continue
+			}
+			dp.QuantileValues = append(dp.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+				Quantile: quantile,
+				Value:    value,
+			})
+		}
+	}
+	sort.Slice(dp.QuantileValues, func(i, j int) bool {
+		return dp.QuantileValues[i].Quantile < dp.QuantileValues[j].Quantile
+	})
+
+	om := r.metric(m.Name(), func() *metricspb.Metric {
+		return &metricspb.Metric{Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{}}}
+	})
+	if summary := om.GetSummary(); summary != nil {
+		summary.DataPoints = append(summary.DataPoints, dp)
+	}
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// attributesKey builds a unique key of the attributes.
+func attributesKey(attrs []*commonpb.KeyValue) string {
+	var b strings.Builder
+	for _, attr := range attrs {
+		b.WriteString(attr.Key)
+		b.WriteByte(0)
+		b.WriteString(attr.Value.GetStringValue())
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,148 @@
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	colmetricspb "github.com/influxdata/telegraf/plugins/common/otlp/collector/metrics/v1"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+)
+
+const sampleConfig = `
+  ## Address of the OpenTelemetry collector accepting OTLP over gRPC.
+  service_address = "localhost:4317"
+
+  ## Timeout for a single export request.
+  # timeout = "5s"
+
+  ## Tags moved from the data point attributes to the resource attributes,
+  ## for example the host the metrics are collected on.
+  # resource_tags = ["host"]
+
+  ## Additional static resource attributes.
+  # [outputs.opentelemetry.resource_attributes]
+  #   "service.name" = "telegraf"
+
+  ## Compression used for the export requests, "gzip" or "none".
+  # compression = "none"
+
+  ## Optional TLS Config; the connection is unencrypted unless set.
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Additional gRPC request metadata
+  # [outputs.opentelemetry.headers]
+  #   key1 = "value1"
+`
+
+type OpenTelemetry struct {
+	ServiceAddress     string            `toml:"service_address"`
+	Timeout            internal.Duration `toml:"timeout"`
+	ResourceTags       []string          `toml:"resource_tags"`
+	ResourceAttributes map[string]string `toml:"resource_attributes"`
+	Compression        string            `toml:"compression"`
+	Headers            map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	conn   *grpc.ClientConn
+	client colmetricspb.MetricsServiceClient
+	conv   *converter
+}
+
+func (o *OpenTelemetry) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpenTelemetry) Description() string {
+	return "Send metrics to an OpenTelemetry collector using OTLP over gRPC"
+}
+
+func (o *OpenTelemetry) Init() error {
+	if o.ServiceAddress == "" {
+		return fmt.Errorf("service_address is required")
+	}
+
+	switch o.Compression {
+	case "", "none", "gzip":
+	default:
+		return fmt.Errorf("unknown compression %q", o.Compression)
+	}
+
+	o.conv = newConverter(o.ResourceTags, o.ResourceAttributes)
+	return nil
+}
+
+func (o *OpenTelemetry) Connect() error {
+	tlsConfig, err := o.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	var opts []grpc.DialOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if o.Compression == "gzip" {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+
+	conn, err := grpc.Dial(o.ServiceAddress, opts...)
+	if err != nil {
+		return err
+	}
+	o.conn = conn
+	o.client = colmetricspb.NewMetricsServiceClient(conn)
+	return nil
+}
+
+func (o *OpenTelemetry) Close() error {
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
+
+func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
+	resourceMetrics := o.conv.convert(metrics, o.Log)
+	if len(resourceMetrics) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout.Duration)
+	defer cancel()
+	if len(o.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+	}
+
+	req := &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: resourceMetrics}
+	if _, err := o.client.Export(ctx, req); err != nil {
+		return fmt.Errorf("exporting metrics to %q failed: %v", o.ServiceAddress, err)
+	}
+	return nil
+}
+
+func init() {
+	outputs.Add("opentelemetry", func() telegraf.Output {
+		return &OpenTelemetry{
+			ServiceAddress: "localhost:4317",
+			Timeout:        internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package opentelemetry
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	colmetricspb "github.com/influxdata/telegraf/plugins/common/otlp/collector/metrics/v1"
+	commonpb "github.com/influxdata/telegraf/plugins/common/otlp/common/v1"
+	metricspb "github.com/influxdata/telegraf/plugins/common/otlp/metrics/v1"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type collector struct {
+	sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+	metadata []metadata.MD
+}
+
+func (c *collector) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	c.Lock()
+	defer c.Unlock()
+	c.requests = append(c.requests, req)
+	c.metadata = append(c.metadata, md)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func newCollector(t *testing.T) (*collector, string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &collector{}
+	server := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(server, c)
+	go server.Serve(listener)
+	return c, listener.Addr().String(), server.Stop
+}
+
+func newOutput(address string) *OpenTelemetry {
+	return &OpenTelemetry{
+		ServiceAddress: address,
+		Timeout:        internal.Duration{Duration: 5 * time.Second},
+		Log:            testutil.Logger{},
+	}
+}
+
+// attributes formats the attributes for comparison.
+func attributes(attrs []*commonpb.KeyValue) []string {
+	result := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, attr.Key+"="+attr.Value.GetStringValue())
+	}
+	return result
+}
+
+func TestWrite(t *testing.T) {
+	c, address, stop := newCollector(t)
+	defer stop()
+
+	plugin := newOutput(address)
+	plugin.ResourceTags = []string{"host"}
+	plugin.ResourceAttributes = map[string]string{"service.name": "telegraf"}
+	plugin.Compression = "gzip"
+	plugin.Headers = map[string]string{"api-key": "secret"}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 99.5, "status": "ok"},
+			time.Unix(0, 10),
+		),
+		testutil.MustMetric("net",
+			map[string]string{"host": "a", "interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(100)},
+			time.Unix(0, 10),
+			telegraf.Counter,
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 50.0},
+			time.Unix(0, 20),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, c.requests, 1)
+	require.Equal(t, []string{"secret"}, c.metadata[0].Get("api-key"))
+
+	rms := c.requests[0].ResourceMetrics
+	require.Len(t, rms, 2)
+
+	require.Equal(t, []string{"service.name=telegraf", "host=a"}, attributes(rms[0].Resource.Attributes))
+	require.Equal(t, []string{"service.name=telegraf", "host=b"}, attributes(rms[1].Resource.Attributes))
+
+	ms := rms[0].InstrumentationLibraryMetrics[0].Metrics
+	require.Len(t, ms, 2)
+
+	require.Equal(t, "cpu_usage_idle", ms[0].Name)
+	gauge := ms[0].GetGauge()
+	require.NotNil(t, gauge)
+	require.Len(t, gauge.DataPoints, 1)
+	require.Equal(t, []string{"cpu=cpu0"}, attributes(gauge.DataPoints[0].Attributes))
+	require.Equal(t, 99.5, gauge.DataPoints[0].GetAsDouble())
+	require.Equal(t, uint64(10), gauge.DataPoints[0].TimeUnixNano)
+
+	require.Equal(t, "net_bytes_recv", ms[1].Name)
+	sum := ms[1].GetSum()
+	require.NotNil(t, sum)
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Equal(t, int64(100), sum.DataPoints[0].GetAsInt())
+}
+
+func TestConvertHistogram(t *testing.T) {
+	m, err := metric.New("http_request_duration_seconds",
+		map[string]string{"path": "/"},
+		map[string]interface{}{
+			"0.1":   float64(2),
+			"0.5":   float64(5),
+			"1":     float64(7),
+			"+Inf":  float64(8),
+			"sum":   4.5,
+			"count": float64(8),
+		},
+		time.Unix(0, 10),
+		telegraf.Histogram,
+	)
+	require.NoError(t, err)
+
+	rms := newConverter(nil, nil).convert([]telegraf.Metric{m}, testutil.Logger{})
+	require.Len(t, rms, 1)
+	ms := rms[0].InstrumentationLibraryMetrics[0].Metrics
+	require.Len(t, ms, 1)
+	require.Equal(t, "http_request_duration_seconds", ms[0].Name)
+
+	histogram := ms[0].GetHistogram()
+	require.NotNil(t, histogram)
+	require.Len(t, histogram.DataPoints, 1)
+
+	dp := histogram.DataPoints[0]
+	require.Equal(t, []float64{0.1, 0.5, 1}, dp.ExplicitBounds)
+	require.Equal(t, []uint64{2, 3, 2, 1}, dp.BucketCounts)
+	require.Equal(t, uint64(8), dp.Count)
+	require.Equal(t, 4.5, dp.Sum)
+	require.Equal(t, []string{"path=/"}, attributes(dp.Attributes))
+}
+
+func TestConvertPrometheusNames(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("go_goroutines",
+			map[string]string{},
+			map[string]interface{}{"gauge": float64(12)},
+			time.Unix(0, 10),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric("http_requests_total",
+			map[string]string{},
+			map[string]interface{}{"counter": float64(3)},
+			time.Unix(0, 10),
+			telegraf.Counter,
+		),
+		testutil.MustMetric("temperature",
+			map[string]string{},
+			map[string]interface{}{"value": int64(20)},
+			time.Unix(0, 10),
+		),
+	}
+
+	rms := newConverter(nil, nil).convert(metrics, testutil.Logger{})
+	require.Len(t, rms, 1)
+
+	var names []string
+	for _, m := range rms[0].InstrumentationLibraryMetrics[0].Metrics {
+		names = append(names, m.Name)
+	}
+	require.Equal(t, []string{"go_goroutines", "http_requests_total", "temperature"}, names)
+}
+
+func TestInitErrors(t *testing.T) {
+	plugin := newOutput("")
+	require.Error(t, plugin.Init())
+
+	plugin = newOutput("localhost:4317")
+	plugin.Compression = "snappy"
+	require.Error(t, plugin.Init())
+}
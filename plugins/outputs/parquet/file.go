@@ -0,0 +1,340 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/klauspost/compress/snappy"
+)
+
+var magic = []byte("PAR1")
+
+// column is a column of a file, the values of a row are in the order of the
+// columns.
+type column struct {
+	name     string
+	element  schemaElement
+	required bool
+	tag      bool
+}
+
+func timestampColumn(name string) column {
+	return column{
+		name:     name,
+		required: true,
+		element: schemaElement{
+			name:         name,
+			physicalType: typeInt64,
+			repetition:   repetitionRequired,
+			logicalType:  logicalTimestampNanos,
+		},
+	}
+}
+
+// valueColumn returns the column for a tag or field value, false if the
+// value type is not supported.
+func valueColumn(name string, value interface{}, tag bool) (column, bool) {
+	element := schemaElement{name: name, repetition: repetitionOptional}
+	switch value.(type) {
+	case string:
+		element.physicalType = typeByteArray
+		element.convertedType = convertedUTF8
+		element.logicalType = logicalString
+	case int64:
+		element.physicalType = typeInt64
+	case uint64:
+		element.physicalType = typeInt64
+		element.convertedType = convertedUint64
+		element.logicalType = logicalUint64
+	case float64:
+		element.physicalType = typeDouble
+	case bool:
+		element.physicalType = typeBoolean
+	default:
+		return column{}, false
+	}
+	return column{name: name, element: element, tag: tag}, true
+}
+
+// file is a Parquet file being written, it is written to a temporary path
+// and moved to the final path once the footer is written.
+type file struct {
+	path    string
+	tmpPath string
+	columns []column
+	codec   int32
+	created time.Time
+
+	f         *os.File
+	offset    int64
+	numRows   int64
+	rowGroups []rowGroup
+}
+
+func createFile(path, tmpPath string, columns []column, codec int32, created time.Time) (*file, error) {
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(magic); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	return &file{
+		path:    path,
+		tmpPath: tmpPath,
+		columns: columns,
+		codec:   codec,
+		created: created,
+		f:       f,
+		offset:  int64(len(magic)),
+	}, nil
+}
+
+// contains returns true if the file has all the columns of the schema.
+func (f *file) contains(s schema) bool {
+	for _, col := range s {
+		found := false
+		for _, existing := range f.columns[1:] {
+			if existing.name == col.name && existing.element == col.element {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// size returns the number of bytes written so far.
+func (f *file) size() int64 {
+	return f.offset
+}
+
+// writeRowGroup writes the rows as a row group with a single page per
+// column.  On error the file is truncated to the end of the previous row
+// group.
+func (f *file) writeRowGroup(rows [][]interface{}) error {
+	start := f.offset
+	group := rowGroup{numRows: int64(len(rows))}
+	for i, col := range f.columns {
+		chunk, err := f.writeColumnChunk(rows, i, col)
+		if err != nil {
+			if terr := f.truncate(start); terr != nil {
+				return fmt.Errorf("%v; truncating file failed: %v", err, terr)
+			}
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+		group.totalByteSize += chunk.metaData.totalUncompressedSize
+	}
+	f.rowGroups = append(f.rowGroups, group)
+	f.numRows += group.numRows
+	return nil
+}
+
+func (f *file) writeColumnChunk(rows [][]interface{}, i int, col column) (columnChunk, error) {
+	var page bytes.Buffer
+	if !col.required {
+		levels := make([]bool, len(rows))
+		for j, row := range rows {
+			levels[j] = row[i] != nil
+		}
+		encoded := encodeDefinitionLevels(levels)
+		binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+		page.Write(encoded)
+	}
+	if err := encodeValues(&page, rows, i); err != nil {
+		return columnChunk{}, fmt.Errorf("column %q: %v", col.name, err)
+	}
+
+	data, err := compress(f.codec, page.Bytes())
+	if err != nil {
+		return columnChunk{}, err
+	}
+	header, err := encodePageHeader(pageHeader{
+		uncompressedPageSize: int32(page.Len()),
+		compressedPageSize:   int32(len(data)),
+		dataPageHeader: dataPageHeader{
+			numValues:               int32(len(rows)),
+			encoding:                encodingPlain,
+			definitionLevelEncoding: encodingRLE,
+			repetitionLevelEncoding: encodingRLE,
+		},
+	})
+	if err != nil {
+		return columnChunk{}, err
+	}
+
+	offset := f.offset
+	if err := f.write(header); err != nil {
+		return columnChunk{}, err
+	}
+	if err := f.write(data); err != nil {
+		return columnChunk{}, err
+	}
+
+	return columnChunk{
+		fileOffset: offset,
+		metaData: columnMetaData{
+			physicalType:          col.element.physicalType,
+			encodings:             []int32{encodingPlain, encodingRLE},
+			path:                  []string{col.name},
+			codec:                 f.codec,
+			numValues:             int64(len(rows)),
+			totalUncompressedSize: int64(len(header) + page.Len()),
+			totalCompressedSize:   int64(len(header) + len(data)),
+			dataPageOffset:        offset,
+		},
+	}, nil
+}
+
+func (f *file) write(b []byte) error {
+	n, err := f.f.Write(b)
+	f.offset += int64(n)
+	return err
+}
+
+func (f *file) truncate(offset int64) error {
+	if err := f.f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := f.f.Seek(offset, 0); err != nil {
+		return err
+	}
+	f.offset = offset
+	return nil
+}
+
+// finalize writes the footer and moves the file to its final path.
+func (f *file) finalize() error {
+	schema := []schemaElement{{name: "schema", root: true, numChildren: int32(len(f.columns))}}
+	for _, col := range f.columns {
+		schema = append(schema, col.element)
+	}
+	meta, err := encodeFileMetaData(fileMetaData{
+		schema:    schema,
+		numRows:   f.numRows,
+		rowGroups: f.rowGroups,
+		createdBy: "telegraf version " + internal.Version(),
+	})
+	if err != nil {
+		f.discard()
+		return err
+	}
+
+	var footer bytes.Buffer
+	footer.Write(meta)
+	binary.Write(&footer, binary.LittleEndian, uint32(len(meta)))
+	footer.Write(magic)
+	if err := f.write(footer.Bytes()); err != nil {
+		f.discard()
+		return err
+	}
+	if err := f.f.Sync(); err != nil {
+		f.discard()
+		return err
+	}
+	if err := f.f.Close(); err != nil {
+		os.Remove(f.tmpPath)
+		return err
+	}
+	return os.Rename(f.tmpPath, f.path)
+}
+
+// discard closes and removes the unfinished file.
+func (f *file) discard() {
+	f.f.Close()
+	os.Remove(f.tmpPath)
+}
+
+// encodeDefinitionLevels encodes the levels of an optional column using the
+// run length encoding of the RLE/bit-packing hybrid with a bit width of 1.
+func encodeDefinitionLevels(levels []bool) []byte {
+	var buf bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(j-i)<<1)
+		buf.Write(scratch[:n])
+		if levels[i] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		i = j
+	}
+	return buf.Bytes()
+}
+
+// encodeValues writes the non-null values of the column using the plain
+// encoding.
+func encodeValues(buf *bytes.Buffer, rows [][]interface{}, i int) error {
+	var scratch [8]byte
+	var bits, nbits uint8
+	for _, row := range rows {
+		if row[i] == nil {
+			continue
+		}
+		switch v := row[i].(type) {
+		case int64:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(v))
+			buf.Write(scratch[:8])
+		case uint64:
+			binary.LittleEndian.PutUint64(scratch[:], v)
+			buf.Write(scratch[:8])
+		case float64:
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v))
+			buf.Write(scratch[:8])
+		case string:
+			binary.LittleEndian.PutUint32(scratch[:], uint32(len(v)))
+			buf.Write(scratch[:4])
+			buf.WriteString(v)
+		case bool:
+			if v {
+				bits |= 1 << nbits
+			}
+			nbits++
+			if nbits == 8 {
+				buf.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		default:
+			return fmt.Errorf("cannot encode %T", v)
+		}
+	}
+	if nbits > 0 {
+		buf.WriteByte(bits)
+	}
+	return nil
+}
+
+func compress(codec int32, data []byte) ([]byte, error) {
+	switch codec {
+	case codecSnappy:
+		return snappy.Encode(nil, data), nil
+	case codecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return data, nil
+}
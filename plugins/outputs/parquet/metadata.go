@@ -0,0 +1,302 @@
+package parquet
+
+import (
+	"context"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// The subset of the Parquet file metadata written by the plugin, see
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+// for the definitions.  The field IDs below are the ones of the Thrift
+// definitions.
+
+// Physical types
+const (
+	typeBoolean   int32 = 0
+	typeInt64     int32 = 2
+	typeDouble    int32 = 5
+	typeByteArray int32 = 6
+)
+
+// Field repetition types
+const (
+	repetitionRequired int32 = 0
+	repetitionOptional int32 = 1
+)
+
+// Converted types, the legacy annotations understood by older readers
+const (
+	convertedUTF8   int32 = 0
+	convertedUint64 int32 = 16
+)
+
+// Encodings
+const (
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+)
+
+// Compression codecs
+const (
+	codecUncompressed int32 = 0
+	codecSnappy       int32 = 1
+	codecGzip         int32 = 2
+)
+
+const pageTypeData int32 = 0
+
+// logicalType is the logical type annotation of a column.
+type logicalType int
+
+const (
+	logicalNone logicalType = iota
+	logicalString
+	logicalUint64
+	logicalTimestampNanos
+)
+
+type schemaElement struct {
+	name          string
+	physicalType  int32
+	repetition    int32
+	convertedType int32
+	logicalType   logicalType
+	// root is set for the root element holding the columns.
+	root        bool
+	numChildren int32
+}
+
+type columnMetaData struct {
+	physicalType          int32
+	encodings             []int32
+	path                  []string
+	codec                 int32
+	numValues             int64
+	totalUncompressedSize int64
+	totalCompressedSize   int64
+	dataPageOffset        int64
+}
+
+type columnChunk struct {
+	fileOffset int64
+	metaData   columnMetaData
+}
+
+type rowGroup struct {
+	columns       []columnChunk
+	totalByteSize int64
+	numRows       int64
+}
+
+type fileMetaData struct {
+	schema    []schemaElement
+	numRows   int64
+	rowGroups []rowGroup
+	createdBy string
+}
+
+type dataPageHeader struct {
+	numValues               int32
+	encoding                int32
+	definitionLevelEncoding int32
+	repetitionLevelEncoding int32
+}
+
+type pageHeader struct {
+	uncompressedPageSize int32
+	compressedPageSize   int32
+	dataPageHeader       dataPageHeader
+}
+
+// encoder writes Thrift structs using the compact protocol, keeping the first
+// error encountered.
+type encoder struct {
+	buf *thrift.TMemoryBuffer
+	p   *thrift.TCompactProtocol
+	err error
+}
+
+func newEncoder() *encoder {
+	buf := thrift.NewTMemoryBuffer()
+	return &encoder{buf: buf, p: thrift.NewTCompactProtocol(buf)}
+}
+
+func (e *encoder) bytes() ([]byte, error) {
+	if e.err == nil {
+		e.err = e.p.Flush(context.Background())
+	}
+	return e.buf.Bytes(), e.err
+}
+
+func (e *encoder) do(f func() error) {
+	if e.err == nil {
+		e.err = f()
+	}
+}
+
+func (e *encoder) structBegin() {
+	e.do(func() error { return e.p.WriteStructBegin("") })
+}
+
+func (e *encoder) structEnd() {
+	e.do(e.p.WriteFieldStop)
+	e.do(e.p.WriteStructEnd)
+}
+
+func (e *encoder) field(id int16, fieldType thrift.TType) {
+	e.do(func() error { return e.p.WriteFieldBegin("", fieldType, id) })
+}
+
+func (e *encoder) i32(id int16, v int32) {
+	e.field(id, thrift.I32)
+	e.do(func() error { return e.p.WriteI32(v) })
+}
+
+func (e *encoder) i64(id int16, v int64) {
+	e.field(id, thrift.I64)
+	e.do(func() error { return e.p.WriteI64(v) })
+}
+
+func (e *encoder) byte(id int16, v int8) {
+	e.field(id, thrift.BYTE)
+	e.do(func() error { return e.p.WriteByte(v) })
+}
+
+func (e *encoder) bool(id int16, v bool) {
+	e.field(id, thrift.BOOL)
+	e.do(func() error { return e.p.WriteBool(v) })
+}
+
+func (e *encoder) string(id int16, v string) {
+	e.field(id, thrift.STRING)
+	e.do(func() error { return e.p.WriteString(v) })
+}
+
+// emptyStruct writes a struct without fields, used for the members of the
+// logical type unions.
+func (e *encoder) emptyStruct(id int16) {
+	e.field(id, thrift.STRUCT)
+	e.structBegin()
+	e.structEnd()
+}
+
+// list writes a list field of n elements written by the elem function.
+func (e *encoder) list(id int16, elemType thrift.TType, n int, elem func(i int)) {
+	e.field(id, thrift.LIST)
+	e.do(func() error { return e.p.WriteListBegin(elemType, n) })
+	for i := 0; i < n; i++ {
+		elem(i)
+	}
+}
+
+func (e *encoder) writeLogicalType(t logicalType) {
+	e.field(10, thrift.STRUCT)
+	e.structBegin()
+	switch t {
+	case logicalString:
+		e.emptyStruct(1)
+	case logicalUint64:
+		e.field(10, thrift.STRUCT)
+		e.structBegin()
+		e.byte(1, 64)
+		e.bool(2, false)
+		e.structEnd()
+	case logicalTimestampNanos:
+		e.field(8, thrift.STRUCT)
+		e.structBegin()
+		e.bool(1, true)
+		e.field(2, thrift.STRUCT)
+		e.structBegin()
+		e.emptyStruct(3)
+		e.structEnd()
+		e.structEnd()
+	}
+	e.structEnd()
+}
+
+func (e *encoder) writeSchemaElement(s schemaElement) {
+	e.structBegin()
+	if !s.root {
+		e.i32(1, s.physicalType)
+		e.i32(3, s.repetition)
+	}
+	e.string(4, s.name)
+	if s.root {
+		e.i32(5, s.numChildren)
+	}
+	if s.logicalType == logicalString || s.logicalType == logicalUint64 {
+		e.i32(6, s.convertedType)
+	}
+	if s.logicalType != logicalNone {
+		e.writeLogicalType(s.logicalType)
+	}
+	e.structEnd()
+}
+
+func (e *encoder) writeColumnChunk(c columnChunk) {
+	e.structBegin()
+	e.i64(2, c.fileOffset)
+	e.field(3, thrift.STRUCT)
+	e.structBegin()
+	m := c.metaData
+	e.i32(1, m.physicalType)
+	e.list(2, thrift.I32, len(m.encodings), func(i int) {
+		e.do(func() error { return e.p.WriteI32(m.encodings[i]) })
+	})
+	e.list(3, thrift.STRING, len(m.path), func(i int) {
+		e.do(func() error { return e.p.WriteString(m.path[i]) })
+	})
+	e.i32(4, m.codec)
+	e.i64(5, m.numValues)
+	e.i64(6, m.totalUncompressedSize)
+	e.i64(7, m.totalCompressedSize)
+	e.i64(9, m.dataPageOffset)
+	e.structEnd()
+	e.structEnd()
+}
+
+func (e *encoder) writeRowGroup(g rowGroup) {
+	e.structBegin()
+	e.list(1, thrift.STRUCT, len(g.columns), func(i int) {
+		e.writeColumnChunk(g.columns[i])
+	})
+	e.i64(2, g.totalByteSize)
+	e.i64(3, g.numRows)
+	e.structEnd()
+}
+
+// encodeFileMetaData returns the encoded footer metadata.
+func encodeFileMetaData(m fileMetaData) ([]byte, error) {
+	e := newEncoder()
+	e.structBegin()
+	e.i32(1, 1)
+	e.list(2, thrift.STRUCT, len(m.schema), func(i int) {
+		e.writeSchemaElement(m.schema[i])
+	})
+	e.i64(3, m.numRows)
+	e.list(4, thrift.STRUCT, len(m.rowGroups), func(i int) {
+		e.writeRowGroup(m.rowGroups[i])
+	})
+	e.string(6, m.createdBy)
+	e.structEnd()
+	return e.bytes()
+}
+
+// encodePageHeader returns the encoded header of a data page.
+func encodePageHeader(h pageHeader) ([]byte, error) {
+	e := newEncoder()
+	e.structBegin()
+	e.i32(1, pageTypeData)
+	e.i32(2, h.uncompressedPageSize)
+	e.i32(3, h.compressedPageSize)
+	e.field(5, thrift.STRUCT)
+	e.structBegin()
+	e.i32(1, h.dataPageHeader.numValues)
+	e.i32(2, h.dataPageHeader.encoding)
+	e.i32(3, h.dataPageHeader.definitionLevelEncoding)
+	e.i32(4, h.dataPageHeader.repetitionLevelEncoding)
+	e.structEnd()
+	e.structEnd()
+	return e.bytes()
+}
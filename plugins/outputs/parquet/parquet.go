@@ -0,0 +1,336 @@
+package parquet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+var sampleConfig = `
+  ## Directory the files are written to.  The files of a measurement are
+  ## written to a directory named after the measurement, partitioned by the
+  ## metric time.
+  directory = "/var/lib/telegraf/parquet"
+
+  ## Go time layout of the time partition directories below the measurement
+  ## directory, the metric time is formatted in UTC.  The default partitions
+  ## the files into daily directories with hourly subdirectories.
+  # partition_format = "2006-01-02/15"
+
+  ## Name of the timestamp column.
+  # timestamp_column = "time"
+
+  ## Compression of the pages, one of "snappy", "gzip" or "none".
+  # compression = "snappy"
+
+  ## Maximum number of rows of a row group; each write creates at least one
+  ## row group for each file written to.
+  # row_group_size = 10000
+
+  ## A file is finalized and a new file started after the time interval
+  ## specified.  Files are readable only once finalized.
+  # rotation_interval = "1h"
+
+  ## A file is finalized and a new file started when it becomes larger than
+  ## the specified size.  When set to 0 no size based rotation is performed.
+  # rotation_max_size = "128MB"
+`
+
+var codecs = map[string]int32{
+	"none":   codecUncompressed,
+	"snappy": codecSnappy,
+	"gzip":   codecGzip,
+}
+
+type Parquet struct {
+	Directory        string            `toml:"directory"`
+	PartitionFormat  string            `toml:"partition_format"`
+	TimestampColumn  string            `toml:"timestamp_column"`
+	Compression      string            `toml:"compression"`
+	RowGroupSize     int               `toml:"row_group_size"`
+	RotationInterval internal.Duration `toml:"rotation_interval"`
+	RotationMaxSize  internal.Size     `toml:"rotation_max_size"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	codec      int32
+	partitions map[string]*partition
+	now        func() time.Time
+}
+
+// partition is a directory with the currently open file, if any.
+type partition struct {
+	dir  string
+	file *file
+}
+
+// schema is the set of columns of the rows of a row group.
+type schema map[string]column
+
+func (s schema) conflicts(columns []column) bool {
+	for _, col := range columns {
+		if existing, ok := s[col.name]; ok && existing.element != col.element {
+			return true
+		}
+	}
+	return false
+}
+
+func (s schema) add(columns []column) {
+	for _, col := range columns {
+		if _, ok := s[col.name]; !ok {
+			s[col.name] = col
+		}
+	}
+}
+
+// sorted returns the timestamp column followed by the sorted tag columns and
+// the sorted field columns.
+func (s schema) sorted(timestampName string) []column {
+	var tags, fields []column
+	for _, col := range s {
+		if col.tag {
+			tags = append(tags, col)
+		} else {
+			fields = append(fields, col)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].name < tags[j].name })
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	columns := []column{timestampColumn(timestampName)}
+	columns = append(columns, tags...)
+	return append(columns, fields...)
+}
+
+func (p *Parquet) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Parquet) Description() string {
+	return "Write metrics to Parquet files partitioned by measurement and time"
+}
+
+func (p *Parquet) Init() error {
+	if p.Directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+
+	codec, ok := codecs[p.Compression]
+	if !ok {
+		return fmt.Errorf("unknown compression %q", p.Compression)
+	}
+	p.codec = codec
+
+	if p.RowGroupSize <= 0 {
+		return fmt.Errorf("row_group_size must be positive")
+	}
+
+	if p.now == nil {
+		p.now = time.Now
+	}
+	p.partitions = make(map[string]*partition)
+	return nil
+}
+
+func (p *Parquet) Connect() error {
+	return os.MkdirAll(p.Directory, 0755)
+}
+
+// Close finalizes all open files.
+func (p *Parquet) Close() error {
+	var lastErr error
+	for key, part := range p.partitions {
+		if err := p.finalize(part); err != nil {
+			p.Log.Errorf("Finalizing file in %q failed: %v", part.dir, err)
+			lastErr = err
+		}
+		delete(p.partitions, key)
+	}
+	return lastErr
+}
+
+func (p *Parquet) Write(metrics []telegraf.Metric) error {
+	// group the metrics by partition, in the order of the first appearance
+	var order []*partition
+	batches := make(map[*partition][]telegraf.Metric)
+	for _, metric := range metrics {
+		dir := p.partitionDir(metric)
+		part, ok := p.partitions[dir]
+		if !ok {
+			part = &partition{dir: dir}
+			p.partitions[dir] = part
+		}
+		if _, ok := batches[part]; !ok {
+			order = append(order, part)
+		}
+		batches[part] = append(batches[part], metric)
+	}
+
+	for _, part := range order {
+		if err := p.writePartition(part, batches[part]); err != nil {
+			return fmt.Errorf("writing to %q failed: %v", part.dir, err)
+		}
+	}
+
+	now := p.now()
+	for key, part := range p.partitions {
+		if part.file != nil && p.RotationInterval.Duration > 0 &&
+			now.Sub(part.file.created) >= p.RotationInterval.Duration {
+			if err := p.finalize(part); err != nil {
+				return fmt.Errorf("finalizing file in %q failed: %v", part.dir, err)
+			}
+		}
+		if part.file == nil {
+			delete(p.partitions, key)
+		}
+	}
+	return nil
+}
+
+func (p *Parquet) partitionDir(metric telegraf.Metric) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(metric.Name())
+	if name == "." || name == ".." {
+		name = strings.Repeat("_", len(name))
+	}
+	return filepath.Join(p.Directory, name, metric.Time().UTC().Format(p.PartitionFormat))
+}
+
+// writePartition writes the metrics in row groups of at most row_group_size
+// rows, a row group is started early if the type of a column changes.
+func (p *Parquet) writePartition(part *partition, metrics []telegraf.Metric) error {
+	s := schema{}
+	var batch []telegraf.Metric
+	for _, metric := range metrics {
+		columns := p.columns(metric)
+		if s.conflicts(columns) {
+			if err := p.writeRowGroup(part, batch, s); err != nil {
+				return err
+			}
+			batch = nil
+			s = schema{}
+		}
+
+		s.add(columns)
+		batch = append(batch, metric)
+		if len(batch) == p.RowGroupSize {
+			if err := p.writeRowGroup(part, batch, s); err != nil {
+				return err
+			}
+			batch = nil
+			s = schema{}
+		}
+	}
+	return p.writeRowGroup(part, batch, s)
+}
+
+// columns returns the tag and field columns of the metric, fields with the
+// name of a tag or the timestamp column are ignored.
+func (p *Parquet) columns(metric telegraf.Metric) []column {
+	var columns []column
+	for _, tag := range metric.TagList() {
+		if tag.Key == p.TimestampColumn {
+			continue
+		}
+		col, _ := valueColumn(tag.Key, tag.Value, true)
+		columns = append(columns, col)
+	}
+	for _, field := range metric.FieldList() {
+		if field.Key == p.TimestampColumn || metric.HasTag(field.Key) {
+			continue
+		}
+		if col, ok := valueColumn(field.Key, field.Value, false); ok {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+func (p *Parquet) writeRowGroup(part *partition, metrics []telegraf.Metric, s schema) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	// start a new file if the columns are not part of the open file
+	if part.file != nil && !part.file.contains(s) {
+		if err := p.finalize(part); err != nil {
+			return err
+		}
+	}
+	if part.file == nil {
+		if err := os.MkdirAll(part.dir, 0755); err != nil {
+			return err
+		}
+		created := p.now()
+		name := fmt.Sprintf("%d.parquet", created.UnixNano())
+		f, err := createFile(filepath.Join(part.dir, name), filepath.Join(part.dir, "."+name+".tmp"),
+			s.sorted(p.TimestampColumn), p.codec, created)
+		if err != nil {
+			return err
+		}
+		part.file = f
+	}
+
+	index := make(map[string]int, len(part.file.columns))
+	for i, col := range part.file.columns {
+		index[col.name] = i
+	}
+	rows := make([][]interface{}, 0, len(metrics))
+	for _, metric := range metrics {
+		row := make([]interface{}, len(part.file.columns))
+		row[0] = metric.Time().UnixNano()
+		for _, tag := range metric.TagList() {
+			if i, ok := index[tag.Key]; ok && i > 0 {
+				row[i] = tag.Value
+			}
+		}
+		for _, field := range metric.FieldList() {
+			if i, ok := index[field.Key]; ok && i > 0 && !metric.HasTag(field.Key) {
+				row[i] = field.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := part.file.writeRowGroup(rows); err != nil {
+		return err
+	}
+
+	if p.RotationMaxSize.Size > 0 && part.file.size() >= p.RotationMaxSize.Size {
+		return p.finalize(part)
+	}
+	return nil
+}
+
+// finalize finalizes the open file of the partition.
+func (p *Parquet) finalize(part *partition) error {
+	if part.file == nil {
+		return nil
+	}
+	f := part.file
+	part.file = nil
+	if err := f.finalize(); err != nil {
+		return err
+	}
+	p.Log.Debugf("Finalized %q with %d rows", f.path, f.numRows)
+	return nil
+}
+
+func init() {
+	outputs.Add("parquet", func() telegraf.Output {
+		return &Parquet{
+			PartitionFormat:  "2006-01-02/15",
+			TimestampColumn:  "time",
+			Compression:      "snappy",
+			RowGroupSize:     10000,
+			RotationInterval: internal.Duration{Duration: time.Hour},
+			RotationMaxSize:  internal.Size{Size: 128 * 1024 * 1024},
+		}
+	})
+}
@@ -0,0 +1,412 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftStruct is a decoded Thrift struct indexed by field ID.
+type thriftStruct map[int16]interface{}
+
+func decodeValue(p *thrift.TCompactProtocol, t thrift.TType) (interface{}, error) {
+	switch t {
+	case thrift.BOOL:
+		return p.ReadBool()
+	case thrift.BYTE:
+		return p.ReadByte()
+	case thrift.I32:
+		return p.ReadI32()
+	case thrift.I64:
+		return p.ReadI64()
+	case thrift.STRING:
+		return p.ReadString()
+	case thrift.STRUCT:
+		return decodeStruct(p)
+	case thrift.LIST:
+		elemType, n, err := p.ReadListBegin()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := decodeValue(p, elemType)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.ReadListEnd()
+	}
+	return nil, p.Skip(t)
+}
+
+func decodeStruct(p *thrift.TCompactProtocol) (thriftStruct, error) {
+	s := thriftStruct{}
+	if _, err := p.ReadStructBegin(); err != nil {
+		return nil, err
+	}
+	for {
+		_, t, id, err := p.ReadFieldBegin()
+		if err != nil {
+			return nil, err
+		}
+		if t == thrift.STOP {
+			break
+		}
+		if s[id], err = decodeValue(p, t); err != nil {
+			return nil, err
+		}
+	}
+	return s, p.ReadStructEnd()
+}
+
+// readFile returns the content and decoded footer of a Parquet file.
+func readFile(t *testing.T, path string) ([]byte, thriftStruct) {
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, magic, data[:4])
+	require.Equal(t, magic, data[len(data)-4:])
+
+	size := binary.LittleEndian.Uint32(data[len(data)-8:])
+	footer := data[len(data)-8-int(size) : len(data)-8]
+	meta, err := decodeStruct(thrift.NewTCompactProtocol(thrift.NewStreamTransportR(bytes.NewReader(footer))))
+	require.NoError(t, err)
+	return data, meta
+}
+
+func schemaNames(meta thriftStruct) []string {
+	var names []string
+	for _, element := range meta[2].([]interface{})[1:] {
+		names = append(names, element.(thriftStruct)[4].(string))
+	}
+	return names
+}
+
+func numRowGroups(meta thriftStruct) int {
+	return len(meta[4].([]interface{}))
+}
+
+// readColumn decodes the values of a column of the first row group, nil for
+// null values.
+func readColumn(t *testing.T, data []byte, meta thriftStruct, index int) []interface{} {
+	element := meta[2].([]interface{})[index+1].(thriftStruct)
+	group := meta[4].([]interface{})[0].(thriftStruct)
+	chunk := group[1].([]interface{})[index].(thriftStruct)[3].(thriftStruct)
+	offset := chunk[9].(int64)
+
+	buf := thrift.NewTMemoryBuffer()
+	buf.Write(data[offset:])
+	header, err := decodeStruct(thrift.NewTCompactProtocol(buf))
+	require.NoError(t, err)
+	pos := len(data[offset:]) - buf.Len()
+	page := data[int(offset)+pos : int(offset)+pos+int(header[3].(int32))]
+	switch chunk[4].(int32) {
+	case codecSnappy:
+		page, err = snappy.Decode(nil, page)
+		require.NoError(t, err)
+	case codecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(page))
+		require.NoError(t, err)
+		page, err = ioutil.ReadAll(zr)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int(header[2].(int32)), len(page))
+
+	numValues := int(header[5].(thriftStruct)[1].(int32))
+	defined := make([]bool, 0, numValues)
+	if element[3].(int32) == repetitionOptional {
+		size := int(binary.LittleEndian.Uint32(page))
+		levels := page[4 : 4+size]
+		page = page[4+size:]
+		for len(levels) > 0 {
+			run, n := binary.Uvarint(levels)
+			for i := uint64(0); i < run>>1; i++ {
+				defined = append(defined, levels[n] == 1)
+			}
+			levels = levels[n+1:]
+		}
+	} else {
+		for i := 0; i < numValues; i++ {
+			defined = append(defined, true)
+		}
+	}
+	require.Len(t, defined, numValues)
+
+	var values []interface{}
+	var bit uint
+	for _, ok := range defined {
+		if !ok {
+			values = append(values, nil)
+			continue
+		}
+		switch element[1].(int32) {
+		case typeInt64:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case typeDouble:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case typeByteArray:
+			size := int(binary.LittleEndian.Uint32(page))
+			values = append(values, string(page[4:4+size]))
+			page = page[4+size:]
+		case typeBoolean:
+			values = append(values, page[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		}
+	}
+	return values
+}
+
+func newParquet(t *testing.T, dir string) *Parquet {
+	p := &Parquet{
+		Directory:       dir,
+		PartitionFormat: "2006-01-02/15",
+		TimestampColumn: "time",
+		Compression:     "none",
+		RowGroupSize:    10000,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	return p
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, rel)
+		}
+		return err
+	})
+	require.NoError(t, err)
+	sort.Strings(files)
+	return files
+}
+
+func TestPartitionsAndFinalizeOnClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newParquet(t, dir)
+	p.now = func() time.Time { return time.Unix(0, 42) }
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 99.5},
+			time.Date(2021, 4, 13, 10, 1, 0, 0, time.UTC),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 98.5},
+			time.Date(2021, 4, 13, 11, 1, 0, 0, time.UTC),
+		),
+		testutil.MustMetric("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(42)},
+			time.Date(2021, 4, 13, 10, 2, 0, 0, time.UTC),
+		),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	// files are only visible once finalized
+	require.Equal(t, []string{
+		filepath.Join("cpu", "2021-04-13", "10", ".42.parquet.tmp"),
+		filepath.Join("cpu", "2021-04-13", "11", ".42.parquet.tmp"),
+		filepath.Join("mem", "2021-04-13", "10", ".42.parquet.tmp"),
+	}, listFiles(t, dir))
+
+	require.NoError(t, p.Close())
+	require.Equal(t, []string{
+		filepath.Join("cpu", "2021-04-13", "10", "42.parquet"),
+		filepath.Join("cpu", "2021-04-13", "11", "42.parquet"),
+		filepath.Join("mem", "2021-04-13", "10", "42.parquet"),
+	}, listFiles(t, dir))
+
+	_, meta := readFile(t, filepath.Join(dir, "mem", "2021-04-13", "10", "42.parquet"))
+	require.Equal(t, int64(1), meta[3])
+	require.Equal(t, []string{"time", "host", "used"}, schemaNames(meta))
+}
+
+func TestColumnValues(t *testing.T) {
+	for _, compression := range []string{"none", "snappy", "gzip"} {
+		t.Run(compression, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "parquet")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			p := newParquet(t, dir)
+			p.Compression = compression
+			require.NoError(t, p.Init())
+
+			metrics := []telegraf.Metric{
+				testutil.MustMetric("test",
+					map[string]string{"host": "a", "time": "ignored"},
+					map[string]interface{}{"i": int64(-1), "u": uint64(math.MaxUint64), "b": true, "host": 1.0},
+					time.Unix(0, 1),
+				),
+				testutil.MustMetric("test",
+					map[string]string{},
+					map[string]interface{}{"f": 1.5, "s": "text", "b": false},
+					time.Unix(0, 2),
+				),
+				testutil.MustMetric("test",
+					map[string]string{"host": "c"},
+					map[string]interface{}{"b": true},
+					time.Unix(0, 3),
+				),
+			}
+			require.NoError(t, p.Write(metrics))
+			require.NoError(t, p.Close())
+
+			files, err := filepath.Glob(filepath.Join(dir, "test", "1970-01-01", "00", "*.parquet"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			data, meta := readFile(t, files[0])
+			require.Equal(t, int64(3), meta[3])
+			require.Equal(t, []string{"time", "host", "b", "f", "i", "s", "u"}, schemaNames(meta))
+
+			columns := [][]interface{}{
+				{int64(1), int64(2), int64(3)},
+				{"a", nil, "c"},
+				{true, false, true},
+				{nil, 1.5, nil},
+				{int64(-1), nil, nil},
+				{nil, "text", nil},
+				{int64(-1), nil, nil},
+			}
+			for i, expected := range columns {
+				require.Equal(t, expected, readColumn(t, data, meta, i), "column %d", i)
+			}
+		})
+	}
+}
+
+func TestSchemaChangeStartsNewFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newParquet(t, dir)
+	var now int64
+	p.now = func() time.Time { now++; return time.Unix(0, now) }
+
+	write := func(fields map[string]interface{}) {
+		require.NoError(t, p.Write([]telegraf.Metric{
+			testutil.MustMetric("test", map[string]string{}, fields, time.Unix(0, 0)),
+		}))
+	}
+
+	// the same or fewer columns are appended to the open file
+	write(map[string]interface{}{"a": 1.0, "b": 2.0})
+	write(map[string]interface{}{"a": 1.0})
+	// a new column and a changed type start new files
+	write(map[string]interface{}{"a": 1.0, "c": 3.0})
+	write(map[string]interface{}{"a": "text"})
+	require.NoError(t, p.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "test", "*", "*", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	var names [][]string
+	for _, file := range files {
+		_, meta := readFile(t, file)
+		names = append(names, schemaNames(meta))
+	}
+	require.Equal(t, [][]string{{"time", "a", "b"}, {"time", "a", "c"}, {"time", "a"}}, names)
+}
+
+func TestRowGroupSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newParquet(t, dir)
+	p.RowGroupSize = 2
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, testutil.MustMetric("test",
+			map[string]string{},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	require.NoError(t, p.Write(metrics))
+	require.NoError(t, p.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "test", "*", "*", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	_, meta := readFile(t, files[0])
+	require.Equal(t, int64(5), meta[3])
+	require.Equal(t, 3, numRowGroups(meta))
+}
+
+func TestRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := newParquet(t, dir)
+	p.RotationInterval = internal.Duration{Duration: time.Minute}
+	now := time.Unix(0, 0)
+	p.now = func() time.Time { return now }
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("test",
+			map[string]string{},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, p.Write(metrics))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, p.Write(metrics))
+	require.Equal(t, []string{filepath.Join("test", "1970-01-01", "00", ".0.parquet.tmp")}, listFiles(t, dir))
+
+	// the interval has passed
+	now = now.Add(30 * time.Second)
+	require.NoError(t, p.Write(metrics))
+	require.Equal(t, []string{filepath.Join("test", "1970-01-01", "00", "0.parquet")}, listFiles(t, dir))
+	_, meta := readFile(t, filepath.Join(dir, "test", "1970-01-01", "00", "0.parquet"))
+	require.Equal(t, int64(3), meta[3])
+
+	// exceeding the size finalizes the file after writing
+	p.RotationMaxSize = internal.Size{Size: 1}
+	require.NoError(t, p.Write(metrics))
+	require.Len(t, listFiles(t, dir), 2)
+	require.NoError(t, p.Close())
+}
+
+func TestInitErrors(t *testing.T) {
+	p := &Parquet{Compression: "none", RowGroupSize: 1}
+	require.Error(t, p.Init())
+
+	p = &Parquet{Directory: "/tmp", Compression: "lz4", RowGroupSize: 1}
+	require.Error(t, p.Init())
+
+	p = &Parquet{Directory: "/tmp", Compression: "none"}
+	require.Error(t, p.Init())
+}
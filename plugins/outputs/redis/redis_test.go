@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T, mode string) *Redis {
+	r := &Redis{
+		Mode:                mode,
+		Key:                 `telegraf:{{.Name}}{{range $k, $v := .Tags}}:{{$k}}={{$v}}{{end}}`,
+		TimestampField:      "timestamp",
+		ApproximateTrimming: true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+	return r
+}
+
+var cpu = testutil.MustMetric("cpu",
+	map[string]string{"host": "a", "cpu": "cpu0"},
+	map[string]interface{}{"usage_idle": 99.5},
+	time.Unix(1, 5),
+)
+
+func TestHashCommands(t *testing.T) {
+	r := newRedis(t, modeHash)
+	r.Expiration = internal.Duration{Duration: 90 * time.Second}
+
+	commands, err := r.commands(cpu)
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{
+		{"hmset", "telegraf:cpu:cpu=cpu0:host=a", "usage_idle", 99.5, "timestamp", int64(1000000005)},
+		{"pexpire", "telegraf:cpu:cpu=cpu0:host=a", int64(90000)},
+	}, commands)
+}
+
+func TestStreamCommands(t *testing.T) {
+	r := newRedis(t, modeStream)
+	r.Key = `metrics:{{.Name}}:{{.Tag "host"}}{{.Tag "missing"}}`
+	r.TimestampField = ""
+	r.MaxLen = 1000
+	require.NoError(t, r.Init())
+
+	commands, err := r.commands(cpu)
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{
+		{"xadd", "metrics:cpu:a", "maxlen", "~", int64(1000), "*",
+			"name", "cpu", "cpu", "cpu0", "host", "a", "usage_idle", 99.5},
+	}, commands)
+
+	r.ApproximateTrimming = false
+	r.MaxLen = 0
+	commands, err = r.commands(cpu)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"xadd", "metrics:cpu:a", "*"}, commands[0][:3])
+}
+
+func TestEmptyKey(t *testing.T) {
+	r := newRedis(t, modeHash)
+	r.Key = `{{.Tag "missing"}}`
+	require.NoError(t, r.Init())
+
+	_, err := r.commands(cpu)
+	require.Error(t, err)
+}
+
+func TestInitErrors(t *testing.T) {
+	r := &Redis{Mode: "list", Key: "k"}
+	require.Error(t, r.Init())
+
+	r = &Redis{Mode: modeHash, Key: "{{.Name"}
+	require.Error(t, r.Init())
+
+	r = &Redis{Mode: modeStream, Key: "k", MaxLen: -1}
+	require.Error(t, r.Init())
+}
+
+func TestRedisIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	prefix := fmt.Sprintf("telegraf_%d", time.Now().UnixNano())
+
+	r := newRedis(t, modeHash)
+	r.Server = fmt.Sprintf("tcp://%s:6379", testutil.GetLocalHost())
+	r.Timeout = internal.Duration{Duration: 5 * time.Second}
+	r.Key = prefix + `:{{.Name}}:{{.Tag "host"}}`
+	r.Expiration = internal.Duration{Duration: time.Minute}
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Connect())
+	defer r.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 99.5, "ok": true},
+			time.Unix(1, 0),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 10.0},
+			time.Unix(2, 0),
+		),
+	}
+	require.NoError(t, r.Write(metrics))
+
+	key := prefix + ":cpu:a"
+	defer r.client.Del(key)
+	values, err := r.client.HGetAll(key).Result()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"usage_idle": "10",
+		"ok":         "1",
+		"timestamp":  "2000000000",
+	}, values)
+	ttl, err := r.client.TTL(key).Result()
+	require.NoError(t, err)
+	require.True(t, ttl > 0)
+
+	r.Mode = modeStream
+	r.Key = prefix + ":stream"
+	r.MaxLen = 1
+	r.ApproximateTrimming = false
+	require.NoError(t, r.Init())
+	require.NoError(t, r.Write(metrics))
+	defer r.client.Del(r.Key)
+
+	cmd := redis.NewCmd("xlen", r.Key)
+	require.NoError(t, r.client.Process(cmd))
+	length, err := cmd.Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), length)
+}
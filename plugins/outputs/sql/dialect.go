@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/stdlib"
+)
+
+// dialect contains the database specific parts of the output.
+type dialect interface {
+	// columnType returns the column type used to store the value.
+	columnType(value interface{}) string
+
+	// timestampType returns the column type of the timestamp column.
+	timestampType() string
+
+	// convert converts a field value for insertion.
+	convert(value interface{}) interface{}
+
+	// columns returns the types of the columns of the table, or no columns
+	// if the table does not exist.
+	columns(ctx context.Context, db *sql.DB, table string) (map[string]string, error)
+
+	// insert writes the rows to the table using the bulk insert method of
+	// the database.
+	insert(ctx context.Context, db *sql.DB, table string, columns []string, rows [][]interface{}) error
+}
+
+// dialects maps the driver names to their dialect; dialects of drivers which
+// require cgo are added in cgo builds only.
+var dialects = map[string]dialect{
+	"pgx": &postgres{},
+}
+
+type postgres struct{}
+
+func (d *postgres) columnType(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return "BIGINT"
+	case uint64:
+		return "NUMERIC(20)"
+	case float64:
+		return "DOUBLE PRECISION"
+	case bool:
+		return "BOOLEAN"
+	default:
+		return "TEXT"
+	}
+}
+
+func (d *postgres) timestampType() string {
+	return "TIMESTAMP WITH TIME ZONE"
+}
+
+func (d *postgres) convert(value interface{}) interface{} {
+	return value
+}
+
+func (d *postgres) columns(ctx context.Context, db *sql.DB, table string) (map[string]string, error) {
+	query := `SELECT column_name, data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1`
+	return queryColumns(ctx, db, query, table)
+}
+
+// insert uses the COPY protocol to bulk load the rows.
+func (d *postgres) insert(ctx context.Context, db *sql.DB, table string, columns []string, rows [][]interface{}) error {
+	conn, err := stdlib.AcquireConn(db)
+	if err != nil {
+		return err
+	}
+	defer stdlib.ReleaseConn(db, conn)
+
+	_, err = conn.CopyFrom(pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	return err
+}
+
+func queryColumns(ctx context.Context, db *sql.DB, query string, table string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, sqlType string
+		if err := rows.Scan(&name, &sqlType); err != nil {
+			return nil, err
+		}
+		columns[name] = normalizeType(sqlType)
+	}
+	return columns, rows.Err()
+}
+
+// normalizeType returns the SQL type in upper case and without type
+// modifiers, so that "NUMERIC(20)" and "numeric" compare equal.
+func normalizeType(sqlType string) string {
+	if i := strings.IndexByte(sqlType, '('); i >= 0 {
+		sqlType = sqlType[:i]
+	}
+	return strings.ToUpper(strings.TrimSpace(sqlType))
+}
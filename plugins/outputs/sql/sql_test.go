@@ -0,0 +1,118 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func queryRows(t *testing.T, db *sql.DB, query string) [][]interface{} {
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		require.NoError(t, rows.Scan(ptrs...))
+		result = append(result, values)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		kind     interface{}
+		expected interface{}
+		err      bool
+	}{
+		{value: int64(2), kind: float64(0), expected: 2.0},
+		{value: uint64(2), kind: int64(0), expected: int64(2)},
+		{value: uint64(math.MaxUint64), kind: int64(0), err: true},
+		{value: 2.0, kind: int64(0), expected: int64(2)},
+		{value: 2.5, kind: int64(0), err: true},
+		{value: int64(-1), kind: uint64(0), err: true},
+		{value: true, kind: int64(0), expected: int64(1)},
+		{value: "42", kind: int64(0), expected: int64(42)},
+		{value: "true", kind: false, expected: true},
+		{value: int64(1), kind: false, err: true},
+		{value: 1.5, kind: "", expected: "1.5"},
+		{value: "x", kind: nil, expected: "x"},
+	}
+	for _, tt := range tests {
+		actual, err := convertValue(tt.value, tt.kind)
+		if tt.err {
+			require.Error(t, err, "%v to %T", tt.value, tt.kind)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, actual)
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	plugin := &SQL{Driver: "oracle"}
+	require.Error(t, plugin.Init())
+}
+
+func TestPostgresIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	plugin := &SQL{
+		Driver: "pgx",
+		DataSourceName: fmt.Sprintf("host=%s user=postgres sslmode=disable",
+			testutil.GetLocalHost()),
+		Timeout:         internal.Duration{Duration: 5 * time.Second},
+		TablePrefix:     fmt.Sprintf("telegraf_%d_", time.Now().UnixNano()),
+		TimestampColumn: "timestamp",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 99.5, "count": int64(3), "total": uint64(7)},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	metrics = []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 10.0, "ok": true},
+			time.Unix(2, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	table := quoteIdent(plugin.TablePrefix + "cpu")
+	defer plugin.db.Exec("DROP TABLE " + table)
+
+	rows := queryRows(t, plugin.db, `SELECT "host", "cpu", "count", "ok" FROM `+table+` ORDER BY "timestamp"`)
+	require.Equal(t, [][]interface{}{
+		{"a", nil, int64(3), nil},
+		{"b", "cpu0", nil, true},
+	}, rows)
+}
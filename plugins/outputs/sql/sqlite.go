@@ -0,0 +1,86 @@
+// +build cgo
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	// The SQLite driver requires cgo.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	dialects["sqlite3"] = &sqlite{}
+}
+
+type sqlite struct{}
+
+func (d *sqlite) columnType(value interface{}) string {
+	switch value.(type) {
+	case int64, uint64:
+		return "INTEGER"
+	case float64:
+		return "REAL"
+	case bool:
+		return "BOOLEAN"
+	default:
+		return "TEXT"
+	}
+}
+
+func (d *sqlite) timestampType() string {
+	return "TIMESTAMP"
+}
+
+// convert stores unsigned integers outside of the signed range, which SQLite
+// cannot represent as integer, as floating point.
+func (d *sqlite) convert(value interface{}) interface{} {
+	if v, ok := value.(uint64); ok {
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+		return int64(v)
+	}
+	return value
+}
+
+func (d *sqlite) columns(ctx context.Context, db *sql.DB, table string) (map[string]string, error) {
+	return queryColumns(ctx, db, "SELECT name, type FROM pragma_table_info(?)", table)
+}
+
+// insert executes a prepared statement for each row within a single
+// transaction.
+func (d *sqlite) insert(ctx context.Context, db *sql.DB, table string, columns []string, rows [][]interface{}) error {
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quoted = append(quoted, quoteIdent(col))
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdent(table),
+		strings.Join(quoted, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,169 @@
+// +build cgo
+
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLite(t *testing.T) (*SQL, string) {
+	dir, err := ioutil.TempDir("", "telegraf-sql")
+	require.NoError(t, err)
+	dbfile := filepath.Join(dir, "metrics.db")
+
+	plugin := &SQL{
+		Driver:          "sqlite3",
+		DataSourceName:  dbfile,
+		Timeout:         internal.Duration{Duration: 5 * time.Second},
+		TimestampColumn: "timestamp",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	return plugin, dir
+}
+
+func TestSQLiteWrite(t *testing.T) {
+	plugin, dir := newSQLite(t)
+	defer os.RemoveAll(dir)
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 99.5, "count": int64(3)},
+			time.Unix(1, 0),
+		),
+		testutil.MustMetric("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": uint64(42), "ok": true, "status": "fine"},
+			time.Unix(2, 0),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 10.0},
+			time.Unix(3, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	rows := queryRows(t, plugin.db, `SELECT "host", "cpu", "count", "usage_idle" FROM "cpu" ORDER BY "timestamp"`)
+	require.Equal(t, [][]interface{}{
+		{"a", "cpu0", int64(3), 99.5},
+		{"b", nil, nil, 10.0},
+	}, rows)
+
+	rows = queryRows(t, plugin.db, `SELECT "host", "ok", "status", "used" FROM "mem"`)
+	require.Equal(t, [][]interface{}{
+		{"a", true, "fine", int64(42)},
+	}, rows)
+}
+
+func TestSQLiteAddsColumns(t *testing.T) {
+	plugin, dir := newSQLite(t)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("disk",
+			map[string]string{"path": "/"},
+			map[string]interface{}{"free": int64(1)},
+			time.Unix(1, 0),
+		),
+	}))
+	require.NoError(t, plugin.Close())
+
+	// A new instance has to discover the existing columns.
+	plugin.Connect()
+	defer plugin.Close()
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("disk",
+			map[string]string{"path": "/", "fstype": "ext4"},
+			map[string]interface{}{"free": int64(2), "used": int64(3)},
+			time.Unix(2, 0),
+		),
+	}))
+
+	rows := queryRows(t, plugin.db, `SELECT "path", "fstype", "free", "used" FROM "disk" ORDER BY "timestamp"`)
+	require.Equal(t, [][]interface{}{
+		{"/", nil, int64(1), nil},
+		{"/", "ext4", int64(2), int64(3)},
+	}, rows)
+}
+
+func TestSQLiteTagFieldConflict(t *testing.T) {
+	plugin, dir := newSQLite(t)
+	defer os.RemoveAll(dir)
+	defer plugin.Close()
+	plugin.TablePrefix = "telegraf_"
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("net",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{"interface": "x", "bytes": int64(1)},
+			time.Unix(1, 0),
+		),
+	}))
+
+	rows := queryRows(t, plugin.db, `SELECT "interface", "bytes" FROM "telegraf_net"`)
+	require.Equal(t, [][]interface{}{{"eth0", int64(1)}}, rows)
+}
+
+func TestSQLiteConvertsTypes(t *testing.T) {
+	plugin, dir := newSQLite(t)
+	defer os.RemoveAll(dir)
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("load",
+			map[string]string{},
+			map[string]interface{}{"value": int64(1), "state": "ok"},
+			time.Unix(1, 0),
+		),
+		testutil.MustMetric("load",
+			map[string]string{},
+			map[string]interface{}{"value": 2.0, "state": int64(2)},
+			time.Unix(2, 0),
+		),
+		testutil.MustMetric("load",
+			map[string]string{},
+			map[string]interface{}{"value": 2.5},
+			time.Unix(3, 0),
+		),
+	}))
+	require.NoError(t, plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("load",
+			map[string]string{},
+			map[string]interface{}{"value": "3"},
+			time.Unix(4, 0),
+		),
+		testutil.MustMetric("load",
+			map[string]string{},
+			map[string]interface{}{"value": "high"},
+			time.Unix(5, 0),
+		),
+	}))
+
+	// Values are converted to the types of the columns created for the first
+	// metric; the metrics whose value has no integer representation are
+	// dropped.
+	rows := queryRows(t, plugin.db, `SELECT "value", "state" FROM "load" ORDER BY "timestamp"`)
+	require.Equal(t, [][]interface{}{
+		{int64(1), "ok"},
+		{int64(2), "2"},
+		{int64(3), nil},
+	}, rows)
+}
+
+func TestSQLiteTimescaleHypertable(t *testing.T) {
+	plugin := &SQL{Driver: "sqlite3", TimescaleHypertable: true}
+	require.Error(t, plugin.Init())
+}
@@ -0,0 +1,342 @@
+package join
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Tags whose values identify related metrics.  Metrics missing any of
+  ## the key tags are passed through unchanged.
+  key_tags = ["container_id"]
+
+  ## Measurement of the metrics to enrich (the left side of the join).
+  left = "procstat"
+
+  ## Measurements joined onto the left metrics (the right side of the join).
+  right = ["docker_container_mem"]
+
+  ## Maximum difference between the timestamps of joined metrics, a left
+  ## metric also waits up to this long for its right metrics to arrive.
+  # window = "10s"
+
+  ## Join type:
+  ##   left  - emit left metrics even if not all right metrics were found
+  ##   inner - only emit left metrics joined with all right metrics
+  # join_type = "left"
+
+  ## Name of the combined metric, defaults to the left measurement.
+  # name = ""
+
+  ## Drop the right metrics after they have been joined instead of passing
+  ## them through unchanged.
+  # drop_right = false
+
+  ## Maximum number of left metrics waiting for a match and of right
+  ## metrics held for joining.  When exceeded the oldest entry is released.
+  # max_pending = 10000
+`
+
+const (
+	joinLeft  = "left"
+	joinInner = "inner"
+)
+
+type Join struct {
+	KeyTags    []string          `toml:"key_tags"`
+	Left       string            `toml:"left"`
+	Right      []string          `toml:"right"`
+	Window     internal.Duration `toml:"window"`
+	JoinType   string            `toml:"join_type"`
+	Name       string            `toml:"name"`
+	DropRight  bool              `toml:"drop_right"`
+	MaxPending int               `toml:"max_pending"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	mu      sync.Mutex
+	acc     telegraf.Accumulator
+	right   map[string]bool
+	pending []*pendingMetric
+	cache   map[string]*cacheEntry
+	now     func() time.Time
+	cancel  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// pendingMetric is a left metric waiting for its right metrics.
+type pendingMetric struct {
+	key      string
+	metric   telegraf.Metric
+	deadline time.Time
+}
+
+// cacheEntry holds the latest right metrics received for a key.
+type cacheEntry struct {
+	metrics map[string]telegraf.Metric
+	expires time.Time
+}
+
+func (j *Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Description() string {
+	return "Join metrics of different measurements sharing the same key tags"
+}
+
+func (j *Join) Init() error {
+	if len(j.KeyTags) == 0 {
+		return fmt.Errorf("key_tags must not be empty")
+	}
+	if j.Left == "" || len(j.Right) == 0 {
+		return fmt.Errorf("left and right measurements are required")
+	}
+	switch j.JoinType {
+	case joinLeft, joinInner:
+	default:
+		return fmt.Errorf("unknown join_type %q", j.JoinType)
+	}
+	if j.MaxPending <= 0 {
+		return fmt.Errorf("max_pending must be greater than zero")
+	}
+
+	j.right = make(map[string]bool, len(j.Right))
+	for _, name := range j.Right {
+		if name == j.Left {
+			return fmt.Errorf("measurement %q is on both sides of the join", name)
+		}
+		j.right[name] = true
+	}
+	j.cache = make(map[string]*cacheEntry)
+	return nil
+}
+
+func (j *Join) Start(acc telegraf.Accumulator) error {
+	j.acc = acc
+	j.cancel = make(chan struct{})
+
+	interval := j.Window.Duration / 2
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.cancel:
+				return
+			case <-ticker.C:
+				j.mu.Lock()
+				j.expire(j.now())
+				j.mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+func (j *Join) Add(m telegraf.Metric, acc telegraf.Accumulator) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	j.expire(now)
+
+	key, ok := j.key(m)
+	switch {
+	case !ok:
+		acc.AddMetric(m)
+	case m.Name() == j.Left:
+		j.addLeft(key, m, now)
+	case j.right[m.Name()]:
+		j.addRight(key, m, now, acc)
+	default:
+		acc.AddMetric(m)
+	}
+}
+
+func (j *Join) Stop() error {
+	close(j.cancel)
+	j.wg.Wait()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range j.pending {
+		j.release(p)
+	}
+	j.pending = nil
+	j.cache = make(map[string]*cacheEntry)
+	return nil
+}
+
+// key builds the join key from the key tag values, it returns false if the
+// metric is missing any of the key tags.
+func (j *Join) key(m telegraf.Metric) (string, bool) {
+	values := make([]string, 0, len(j.KeyTags))
+	for _, tag := range j.KeyTags {
+		value, ok := m.GetTag(tag)
+		if !ok {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+func (j *Join) addLeft(key string, m telegraf.Metric, now time.Time) {
+	p := &pendingMetric{key: key, metric: m, deadline: now.Add(j.Window.Duration)}
+	if j.complete(p) {
+		j.emit(p)
+		return
+	}
+
+	if len(j.pending) >= j.MaxPending {
+		j.Log.Debugf("Pending metrics limit reached, releasing oldest metric")
+		j.release(j.pending[0])
+		j.pending = j.pending[1:]
+	}
+	j.pending = append(j.pending, p)
+}
+
+func (j *Join) addRight(key string, m telegraf.Metric, now time.Time, acc telegraf.Accumulator) {
+	entry, ok := j.cache[key]
+	if !ok {
+		if len(j.cache) >= j.MaxPending {
+			j.evictOldest()
+		}
+		entry = &cacheEntry{metrics: make(map[string]telegraf.Metric)}
+		j.cache[key] = entry
+	}
+	entry.metrics[m.Name()] = m.Copy()
+	entry.expires = now.Add(j.Window.Duration)
+
+	if j.DropRight {
+		m.Drop()
+	} else {
+		acc.AddMetric(m)
+	}
+
+	// Emit any pending left metric completed by this one.
+	remaining := j.pending[:0]
+	for _, p := range j.pending {
+		if p.key == key && j.complete(p) {
+			j.emit(p)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	j.pending = remaining
+}
+
+// expire releases the pending metrics and forgets the right metrics which
+// have exceeded the window.
+func (j *Join) expire(now time.Time) {
+	remaining := j.pending[:0]
+	for _, p := range j.pending {
+		if now.Before(p.deadline) {
+			remaining = append(remaining, p)
+			continue
+		}
+		j.release(p)
+	}
+	j.pending = remaining
+
+	for key, entry := range j.cache {
+		if !now.Before(entry.expires) {
+			delete(j.cache, key)
+		}
+	}
+}
+
+func (j *Join) evictOldest() {
+	var oldest string
+	var expires time.Time
+	for key, entry := range j.cache {
+		if oldest == "" || entry.expires.Before(expires) {
+			oldest = key
+			expires = entry.expires
+		}
+	}
+	delete(j.cache, oldest)
+}
+
+// matches returns the right metrics within the window of the pending metric.
+func (j *Join) matches(p *pendingMetric) []telegraf.Metric {
+	entry, ok := j.cache[p.key]
+	if !ok {
+		return nil
+	}
+
+	var matches []telegraf.Metric
+	for _, name := range j.Right {
+		m, ok := entry.metrics[name]
+		if !ok {
+			continue
+		}
+		diff := m.Time().Sub(p.metric.Time())
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= j.Window.Duration {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+func (j *Join) complete(p *pendingMetric) bool {
+	return len(j.matches(p)) == len(j.Right)
+}
+
+// release handles a pending metric whose wait is over according to the
+// join type.
+func (j *Join) release(p *pendingMetric) {
+	if j.JoinType == joinInner && !j.complete(p) {
+		p.metric.Drop()
+		return
+	}
+	j.emit(p)
+}
+
+// emit adds the fields and tags of the matching right metrics to the left
+// metric and passes it on.  Right fields are prefixed with their measurement
+// name, tags already present on the left metric are kept.
+func (j *Join) emit(p *pendingMetric) {
+	m := p.metric
+	for _, r := range j.matches(p) {
+		for _, tag := range r.TagList() {
+			if !m.HasTag(tag.Key) {
+				m.AddTag(tag.Key, tag.Value)
+			}
+		}
+		for _, field := range r.FieldList() {
+			m.AddField(r.Name()+"_"+field.Key, field.Value)
+		}
+	}
+	if j.Name != "" {
+		m.SetName(j.Name)
+	}
+	j.acc.AddMetric(m)
+}
+
+func init() {
+	processors.AddStreaming("join", func() telegraf.StreamingProcessor {
+		return &Join{
+			Window:     internal.Duration{Duration: 10 * time.Second},
+			JoinType:   joinLeft,
+			MaxPending: 10000,
+			now:        time.Now,
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newJoin(now *time.Time) *Join {
+	return &Join{
+		KeyTags:    []string{"container_id"},
+		Left:       "procstat",
+		Right:      []string{"docker"},
+		Window:     internal.Duration{Duration: 10 * time.Second},
+		JoinType:   joinLeft,
+		MaxPending: 10000,
+		Log:        testutil.Logger{},
+		now:        func() time.Time { return *now },
+	}
+}
+
+func procstat(id string, ts time.Time) telegraf.Metric {
+	return testutil.MustMetric("procstat",
+		map[string]string{"container_id": id, "pid": "42"},
+		map[string]interface{}{"memory_rss": int64(100)},
+		ts,
+	)
+}
+
+func docker(id string, ts time.Time) telegraf.Metric {
+	return testutil.MustMetric("docker",
+		map[string]string{"container_id": id, "container_name": "web"},
+		map[string]interface{}{"usage": int64(200)},
+		ts,
+	)
+}
+
+func joined(id string, ts time.Time) telegraf.Metric {
+	return testutil.MustMetric("procstat",
+		map[string]string{"container_id": id, "pid": "42", "container_name": "web"},
+		map[string]interface{}{"memory_rss": int64(100), "docker_usage": int64(200)},
+		ts,
+	)
+}
+
+func TestRightBeforeLeft(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(docker("a", now), acc)
+	plugin.Add(procstat("a", now.Add(time.Second)), acc)
+	require.NoError(t, plugin.Stop())
+
+	expected := []telegraf.Metric{
+		docker("a", now),
+		joined("a", now.Add(time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLeftWaitsForRight(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	plugin.DropRight = true
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(procstat("a", now), acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	now = now.Add(5 * time.Second)
+	plugin.Add(docker("a", now), acc)
+	require.NoError(t, plugin.Stop())
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{joined("a", time.Unix(100, 0))}, acc.GetTelegrafMetrics())
+}
+
+func TestLeftJoinReleasedUnmatched(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(procstat("a", now), acc)
+	plugin.Add(docker("b", now), acc)
+
+	// the window expires for the left metric when the next metric arrives
+	now = now.Add(11 * time.Second)
+	plugin.Add(docker("a", now), acc)
+	require.NoError(t, plugin.Stop())
+
+	expected := []telegraf.Metric{
+		docker("b", time.Unix(100, 0)),
+		procstat("a", time.Unix(100, 0)),
+		docker("a", now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestInnerJoinDropsUnmatched(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	plugin.JoinType = joinInner
+	plugin.Name = "container_process"
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(procstat("a", now), acc)
+	plugin.Add(procstat("b", now), acc)
+	plugin.Add(docker("b", now), acc)
+	require.NoError(t, plugin.Stop())
+
+	combined := joined("b", now)
+	combined.SetName("container_process")
+	expected := []telegraf.Metric{
+		docker("b", now),
+		combined,
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTimestampOutsideWindow(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(docker("a", now.Add(-time.Minute)), acc)
+	plugin.Add(procstat("a", now), acc)
+	require.NoError(t, plugin.Stop())
+
+	expected := []telegraf.Metric{
+		docker("a", now.Add(-time.Minute)),
+		procstat("a", now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestPassThrough(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	other := testutil.MustMetric("cpu",
+		map[string]string{"container_id": "a"},
+		map[string]interface{}{"usage": 1.0},
+		now,
+	)
+	untagged := testutil.MustMetric("procstat",
+		map[string]string{},
+		map[string]interface{}{"memory_rss": int64(1)},
+		now,
+	)
+	plugin.Add(other, acc)
+	plugin.Add(untagged, acc)
+	require.NoError(t, plugin.Stop())
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{other, untagged}, acc.GetTelegrafMetrics())
+}
+
+func TestMaxPending(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := newJoin(&now)
+	plugin.MaxPending = 1
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	plugin.Add(procstat("a", now), acc)
+	plugin.Add(procstat("b", now), acc)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{procstat("a", now)}, acc.GetTelegrafMetrics())
+	require.NoError(t, plugin.Stop())
+}
+
+func TestInitErrors(t *testing.T) {
+	now := time.Unix(100, 0)
+
+	plugin := newJoin(&now)
+	plugin.KeyTags = nil
+	require.Error(t, plugin.Init())
+
+	plugin = newJoin(&now)
+	plugin.Right = []string{"procstat"}
+	require.Error(t, plugin.Init())
+
+	plugin = newJoin(&now)
+	plugin.JoinType = "outer"
+	require.Error(t, plugin.Init())
+}
@@ -0,0 +1,256 @@
+package math
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/expr"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## How to handle an expression referencing a tag or field that is missing
+  ## from the metric:
+  ##   skip - do not set the output field (default)
+  ##   zero - use 0 in place of the missing value
+  ##   drop - drop the metric
+  # missing = "skip"
+
+  ## Fields are computed in order, an expression can use the output of the
+  ## expressions defined before it.
+  [[processors.math.field]]
+    ## Name of the output field
+    name = "used_percent"
+
+    ## Expression computing the field value.  Fields and tags are referenced
+    ## as "fields.<key>" and "tags.<key>"; tag values are strings, use the
+    ## float() or int() functions to use them in arithmetic.
+    expression = "fields.used / float(fields.total) * 100"
+
+    ## Output type, one of "float", "integer", "unsigned", "string" or
+    ## "boolean".  When not set the type of the expression result is used.
+    # type = "float"
+`
+
+const (
+	missingSkip = "skip"
+	missingZero = "zero"
+	missingDrop = "drop"
+)
+
+type Math struct {
+	Missing string   `toml:"missing"`
+	Fields  []*Field `toml:"field"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+// Field is a single derived field.
+type Field struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+	Type       string `toml:"type"`
+
+	expression *expr.Expression
+}
+
+func (m *Math) SampleConfig() string {
+	return sampleConfig
+}
+
+func (m *Math) Description() string {
+	return "Compute new fields from expressions over the metric's fields and tags"
+}
+
+func (m *Math) Init() error {
+	switch m.Missing {
+	case missingSkip, missingZero, missingDrop:
+	default:
+		return fmt.Errorf("unknown missing policy %q", m.Missing)
+	}
+
+	for _, field := range m.Fields {
+		if field.Name == "" {
+			return fmt.Errorf("field name is required")
+		}
+
+		switch field.Type {
+		case "", "float", "integer", "unsigned", "string", "boolean":
+		default:
+			return fmt.Errorf("unknown type %q for field %q", field.Type, field.Name)
+		}
+
+		var err error
+		field.expression, err = expr.Compile(field.Expression)
+		if err != nil {
+			return fmt.Errorf("compiling expression for field %q: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Math) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, metric := range in {
+		if m.apply(metric) {
+			out = append(out, metric)
+		} else {
+			metric.Drop()
+		}
+	}
+	return out
+}
+
+// apply adds the derived fields to the metric, it returns false if the
+// metric should be dropped.
+func (m *Math) apply(metric telegraf.Metric) bool {
+	for _, field := range m.Fields {
+		var value interface{}
+		var err error
+		if m.Missing == missingZero {
+			value, err = field.expression.EvalDefault(metric, int64(0))
+		} else {
+			value, err = field.expression.Eval(metric)
+		}
+
+		if _, ok := err.(*expr.MissingError); ok {
+			if m.Missing == missingDrop {
+				return false
+			}
+			continue
+		}
+		if err != nil {
+			m.Log.Debugf("Cannot compute field %q: %v", field.Name, err)
+			continue
+		}
+
+		value, err = convert(value, field.Type)
+		if err != nil {
+			m.Log.Debugf("Cannot convert field %q: %v", field.Name, err)
+			continue
+		}
+
+		// NaN and infinite values, for instance from a division by zero,
+		// cannot be represented by most outputs.
+		if v, ok := value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			m.Log.Debugf("Skipping non-finite value for field %q", field.Name)
+			continue
+		}
+
+		metric.AddField(field.Name, value)
+	}
+	return true
+}
+
+// convert casts the expression result to the configured output type.
+func convert(value interface{}, typ string) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		value = v.UnixNano()
+	case time.Duration:
+		value = int64(v)
+	}
+
+	switch typ {
+	case "":
+		return value, nil
+	case "float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case uint64:
+			if v > math.MaxInt64 {
+				return nil, fmt.Errorf("value %d out of range", v)
+			}
+			return int64(v), nil
+		case float64:
+			if v < math.MinInt64 || v > math.MaxInt64 {
+				return nil, fmt.Errorf("value %v out of range", v)
+			}
+			return int64(v), nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case "unsigned":
+		switch v := value.(type) {
+		case int64:
+			if v < 0 {
+				return nil, fmt.Errorf("value %d out of range", v)
+			}
+			return uint64(v), nil
+		case uint64:
+			return v, nil
+		case float64:
+			if v < 0 || v > math.MaxUint64 {
+				return nil, fmt.Errorf("value %v out of range", v)
+			}
+			return uint64(v), nil
+		case bool:
+			if v {
+				return uint64(1), nil
+			}
+			return uint64(0), nil
+		case string:
+			return strconv.ParseUint(v, 10, 64)
+		}
+	case "string":
+		switch v := value.(type) {
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case uint64:
+			return strconv.FormatUint(v, 10), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			return v, nil
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case int64:
+			return v != 0, nil
+		case uint64:
+			return v != 0, nil
+		case float64:
+			return v != 0, nil
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, typ)
+}
+
+func init() {
+	processors.Add("math", func() telegraf.Processor {
+		return &Math{
+			Missing: missingSkip,
+		}
+	})
+}
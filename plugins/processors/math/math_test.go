@@ -0,0 +1,157 @@
+package math
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func memMetric(fields map[string]interface{}) telegraf.Metric {
+	return testutil.MustMetric("mem",
+		map[string]string{"cores": "4"},
+		fields,
+		time.Unix(0, 0),
+	)
+}
+
+func TestApply(t *testing.T) {
+	plugin := &Math{
+		Missing: missingSkip,
+		Fields: []*Field{
+			{Name: "used_percent", Expression: "fields.used / float(fields.total) * 100"},
+			{Name: "used_bits", Expression: "fields.used * 8", Type: "unsigned"},
+			{Name: "per_core", Expression: "fields.used / int(tags.cores)"},
+			{Name: "high", Expression: "if(fields.used_percent > 90, 1, 0)", Type: "boolean"},
+			{Name: "label", Expression: `"used:" + string(fields.used)`},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(memMetric(map[string]interface{}{
+		"used":  int64(95),
+		"total": int64(100),
+	}))
+
+	expected := []telegraf.Metric{
+		memMetric(map[string]interface{}{
+			"used":         int64(95),
+			"total":        int64(100),
+			"used_percent": 95.0,
+			"used_bits":    uint64(760),
+			"per_core":     int64(23),
+			"high":         true,
+			"label":        "used:95",
+		}),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		missing  string
+		expected []telegraf.Metric
+	}{
+		{
+			name:    "skip",
+			missing: missingSkip,
+			expected: []telegraf.Metric{
+				memMetric(map[string]interface{}{"used": int64(5)}),
+			},
+		},
+		{
+			name:    "zero",
+			missing: missingZero,
+			expected: []telegraf.Metric{
+				memMetric(map[string]interface{}{"used": int64(5), "sum": int64(5)}),
+			},
+		},
+		{
+			name:     "drop",
+			missing:  missingDrop,
+			expected: []telegraf.Metric{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Math{
+				Missing: tt.missing,
+				Fields: []*Field{
+					{Name: "sum", Expression: "fields.used + fields.cached"},
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(memMetric(map[string]interface{}{"used": int64(5)}))
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestNonFiniteSkipped(t *testing.T) {
+	plugin := &Math{
+		Missing: missingSkip,
+		Fields: []*Field{
+			{Name: "ratio", Expression: "fields.used / float(fields.total)"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(memMetric(map[string]interface{}{
+		"used":  int64(5),
+		"total": int64(0),
+	}))
+	expected := []telegraf.Metric{
+		memMetric(map[string]interface{}{
+			"used":  int64(5),
+			"total": int64(0),
+		}),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *Math
+	}{
+		{
+			name:   "unknown missing policy",
+			plugin: &Math{Missing: "ignore"},
+		},
+		{
+			name: "missing name",
+			plugin: &Math{
+				Missing: missingSkip,
+				Fields:  []*Field{{Expression: "1"}},
+			},
+		},
+		{
+			name: "unknown type",
+			plugin: &Math{
+				Missing: missingSkip,
+				Fields:  []*Field{{Name: "a", Expression: "1", Type: "double"}},
+			},
+		},
+		{
+			name: "invalid expression",
+			plugin: &Math{
+				Missing: missingSkip,
+				Fields:  []*Field{{Name: "a", Expression: "1 +"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.plugin.Init())
+		})
+	}
+}
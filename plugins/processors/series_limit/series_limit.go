@@ -0,0 +1,208 @@
+package serieslimit
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const sampleConfig = `
+  ## Maximum number of distinct series per measurement within the window.
+  limit = 1000
+
+  ## Series which have not been seen for this long are forgotten and no
+  ## longer count against the limit; must be greater than zero.
+  # window = "1h"
+
+  ## What to do with metrics of new series once the limit is exceeded:
+  ##   drop    - drop the metric
+  ##   strip   - remove the tag named by "tag" from the metric
+  ##   replace - replace the value of the tag named by "tag" with "placeholder"
+  # action = "drop"
+
+  ## Name of the high cardinality tag used by the "strip" and "replace"
+  ## actions.
+  # tag = "url"
+
+  ## Tag value used by the "replace" action.
+  # placeholder = "other"
+`
+
+// instances numbers the processors, so that each of them has its own
+// internal stats.
+var instances int64
+
+const (
+	actionDrop    = "drop"
+	actionStrip   = "strip"
+	actionReplace = "replace"
+)
+
+type SeriesLimit struct {
+	Limit       int               `toml:"limit"`
+	Window      internal.Duration `toml:"window"`
+	Action      string            `toml:"action"`
+	Tag         string            `toml:"tag"`
+	Placeholder string            `toml:"placeholder"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	measurements map[string]*measurement
+	lastCleanup  time.Time
+	now          func() time.Time
+	instance     string
+
+	metricsDropped  selfstat.Stat
+	metricsModified selfstat.Stat
+}
+
+// measurement holds the series seen for a single measurement name.
+type measurement struct {
+	series   map[uint64]time.Time
+	tracked  selfstat.Stat
+	exceeded bool
+}
+
+func (s *SeriesLimit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SeriesLimit) Description() string {
+	return "Limit the number of distinct series per measurement"
+}
+
+func (s *SeriesLimit) Init() error {
+	if s.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than zero")
+	}
+	if s.Window.Duration <= 0 {
+		return fmt.Errorf("window must be greater than zero")
+	}
+
+	switch s.Action {
+	case actionDrop:
+	case actionStrip, actionReplace:
+		if s.Tag == "" {
+			return fmt.Errorf("action %q requires a tag", s.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", s.Action)
+	}
+
+	s.measurements = make(map[string]*measurement)
+	s.lastCleanup = s.now()
+	s.instance = strconv.FormatInt(atomic.AddInt64(&instances, 1), 10)
+	s.metricsDropped = selfstat.Register("series_limit", "metrics_dropped", s.statTags(""))
+	s.metricsModified = selfstat.Register("series_limit", "metrics_modified", s.statTags(""))
+	return nil
+}
+
+// statTags returns the tags of the internal stats of the processor, and of
+// the measurement if name is not empty.
+func (s *SeriesLimit) statTags(name string) map[string]string {
+	tags := map[string]string{"instance": s.instance}
+	if name != "" {
+		tags["measurement"] = name
+	}
+	return tags
+}
+
+func (s *SeriesLimit) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := s.now()
+	s.cleanup(now)
+
+	out := in[:0]
+	for _, m := range in {
+		meas := s.measurement(m.Name())
+
+		id := m.HashID()
+		if _, ok := meas.series[id]; ok || len(meas.series) < s.Limit {
+			meas.series[id] = now
+			out = append(out, m)
+			continue
+		}
+
+		if !meas.exceeded {
+			meas.exceeded = true
+			s.Log.Warnf("Series limit of %d exceeded for measurement %q", s.Limit, m.Name())
+		}
+
+		if s.Action == actionDrop || !m.HasTag(s.Tag) {
+			s.metricsDropped.Incr(1)
+			m.Drop()
+			continue
+		}
+
+		if s.Action == actionStrip {
+			m.RemoveTag(s.Tag)
+		} else {
+			m.AddTag(s.Tag, s.Placeholder)
+		}
+		s.metricsModified.Incr(1)
+
+		// The modified metric collapses into an overflow series, it is
+		// tracked so it is accounted for but is never limited itself.
+		meas.series[m.HashID()] = now
+		out = append(out, m)
+	}
+
+	for _, meas := range s.measurements {
+		meas.tracked.Set(int64(len(meas.series)))
+	}
+	return out
+}
+
+func (s *SeriesLimit) measurement(name string) *measurement {
+	meas, ok := s.measurements[name]
+	if !ok {
+		meas = &measurement{
+			series:  make(map[uint64]time.Time),
+			tracked: selfstat.Register("series_limit", "series", s.statTags(name)),
+		}
+		s.measurements[name] = meas
+	}
+	return meas
+}
+
+// cleanup forgets the series that were not seen within the window, and the
+// measurements without series along with their internal stat.
+func (s *SeriesLimit) cleanup(now time.Time) {
+	// Scanning all series is expensive, only do it a few times per window.
+	if now.Sub(s.lastCleanup) < s.Window.Duration/10 {
+		return
+	}
+	s.lastCleanup = now
+
+	for name, meas := range s.measurements {
+		for id, seen := range meas.series {
+			if now.Sub(seen) >= s.Window.Duration {
+				delete(meas.series, id)
+			}
+		}
+		if len(meas.series) == 0 {
+			selfstat.Unregister("series_limit", "series", s.statTags(name))
+			delete(s.measurements, name)
+			continue
+		}
+		if len(meas.series) < s.Limit {
+			meas.exceeded = false
+		}
+	}
+}
+
+func init() {
+	processors.Add("series_limit", func() telegraf.Processor {
+		return &SeriesLimit{
+			Window:      internal.Duration{Duration: time.Hour},
+			Action:      actionDrop,
+			Placeholder: "other",
+			now:         time.Now,
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package serieslimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newSeriesLimit(now *time.Time) *SeriesLimit {
+	return &SeriesLimit{
+		Limit:       2,
+		Window:      internal.Duration{Duration: time.Hour},
+		Action:      actionDrop,
+		Placeholder: "other",
+		Log:         testutil.Logger{},
+		now:         func() time.Time { return *now },
+	}
+}
+
+func request(url string) telegraf.Metric {
+	return testutil.MustMetric("http",
+		map[string]string{"host": "a", "url": url},
+		map[string]interface{}{"value": int64(1)},
+		time.Unix(0, 0),
+	)
+}
+
+func TestDrop(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(request("/a"), request("/b"), request("/c"), request("/a"))
+	expected := []telegraf.Metric{request("/a"), request("/b"), request("/a")}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// other measurements have their own limit
+	other := testutil.MustMetric("other",
+		map[string]string{"url": "/c"},
+		map[string]interface{}{"value": int64(1)},
+		time.Unix(0, 0),
+	)
+	actual = plugin.Apply(other)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{other}, actual)
+}
+
+func TestStrip(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	plugin.Action = actionStrip
+	plugin.Tag = "url"
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(request("/a"), request("/b"), request("/c"), request("/d"))
+	expected := []telegraf.Metric{
+		request("/a"),
+		request("/b"),
+		testutil.MustMetric("http",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("http",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestReplace(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	plugin.Action = actionReplace
+	plugin.Tag = "url"
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(request("/a"), request("/b"), request("/c"))
+	expected := []telegraf.Metric{request("/a"), request("/b"), request("other")}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestMissingTagIsDropped(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	plugin.Action = actionReplace
+	plugin.Tag = "path"
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(request("/a"), request("/b"), request("/c"))
+	expected := []telegraf.Metric{request("/a"), request("/b")}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestWindowExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(request("/a"), request("/b"))
+
+	now = now.Add(30 * time.Minute)
+	actual := plugin.Apply(request("/b"), request("/c"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{request("/b")}, actual)
+
+	// "/a" expires, "/b" was refreshed and is still tracked
+	now = now.Add(45 * time.Minute)
+	actual = plugin.Apply(request("/c"), request("/d"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{request("/c")}, actual)
+}
+
+func TestExpiredMeasurementIsForgotten(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := newSeriesLimit(&now)
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(request("/a"))
+	require.Contains(t, plugin.measurements, "http")
+	require.True(t, hasSeriesStat(plugin, "http"))
+
+	now = now.Add(time.Hour)
+	plugin.Apply(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	require.NotContains(t, plugin.measurements, "http")
+	require.Contains(t, plugin.measurements, "cpu")
+	require.False(t, hasSeriesStat(plugin, "http"))
+}
+
+// hasSeriesStat returns whether the series stat of the measurement is
+// registered for the processor.
+func hasSeriesStat(plugin *SeriesLimit, name string) bool {
+	for _, m := range selfstat.Metrics() {
+		if m.Name() == "internal_series_limit" &&
+			m.Tags()["instance"] == plugin.instance &&
+			m.Tags()["measurement"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInstancesHaveSeparateStats(t *testing.T) {
+	now := time.Unix(0, 0)
+	first := newSeriesLimit(&now)
+	require.NoError(t, first.Init())
+	second := newSeriesLimit(&now)
+	require.NoError(t, second.Init())
+	require.NotEqual(t, first.instance, second.instance)
+
+	first.Apply(request("/a"), request("/b"), request("/c"))
+	require.Equal(t, int64(1), first.metricsDropped.Get())
+	require.Equal(t, int64(0), second.metricsDropped.Get())
+
+	now = now.Add(30 * time.Minute)
+	second.Apply(request("/a"))
+
+	// Forgetting the measurement in one processor keeps the stat of the
+	// other.
+	now = now.Add(45 * time.Minute)
+	first.Apply(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	second.Apply(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+	require.False(t, hasSeriesStat(first, "http"))
+	require.True(t, hasSeriesStat(second, "http"))
+	require.Equal(t, int64(1), second.measurements["http"].tracked.Get())
+}
+
+func TestInitErrors(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	plugin := newSeriesLimit(&now)
+	plugin.Limit = 0
+	require.Error(t, plugin.Init())
+
+	plugin = newSeriesLimit(&now)
+	plugin.Action = "truncate"
+	require.Error(t, plugin.Init())
+
+	plugin = newSeriesLimit(&now)
+	plugin.Action = actionStrip
+	require.Error(t, plugin.Init())
+
+	plugin = newSeriesLimit(&now)
+	plugin.Window.Duration = 0
+	require.EqualError(t, plugin.Init(), "window must be greater than zero")
+}
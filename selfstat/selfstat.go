@@ -76,6 +76,12 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 	return registry.registerTiming("internal_"+measurement, field, tags)
 }
 
+// Unregister removes the stat of the given measurement, field, and tags from
+// the selfstat registry, so that it is no longer returned by Metrics().
+func Unregister(measurement, field string, tags map[string]string) {
+	registry.unregister("internal_"+measurement, field, tags)
+}
+
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
 	registry.mu.Lock()
@@ -160,6 +166,17 @@ func (r *Registry) registerTiming(measurement, field string, tags map[string]str
 	return s
 }
 
+func (r *Registry) unregister(measurement, field string, tags map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := key(measurement, tags)
+	delete(r.stats[key], field)
+	if len(r.stats[key]) == 0 {
+		delete(r.stats, key)
+	}
+}
+
 func (r *Registry) get(key uint64, field string) (Stat, bool) {
 	if _, ok := r.stats[key]; !ok {
 		return nil, false
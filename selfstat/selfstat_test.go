@@ -211,3 +211,24 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestUnregister(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	Register("unregister", "test_field1", map[string]string{"test": "foo"})
+	Register("unregister", "test_field2", map[string]string{"test": "foo"})
+
+	Unregister("unregister", "test_field1", map[string]string{"test": "foo"})
+	var fields []map[string]interface{}
+	for _, m := range Metrics() {
+		if m.Name() == "internal_unregister" {
+			fields = append(fields, m.Fields())
+		}
+	}
+	require.Equal(t, []map[string]interface{}{{"test_field2": int64(0)}}, fields)
+
+	Unregister("unregister", "test_field2", map[string]string{"test": "foo"})
+	for _, m := range Metrics() {
+		require.NotEqual(t, "internal_unregister", m.Name())
+	}
+}